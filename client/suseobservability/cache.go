@@ -0,0 +1,66 @@
+package suseobservability
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultMetricNamesTTL is how long a client created by NewClient reuses fetched metric names
+const DefaultMetricNamesTTL = time.Minute
+
+// metricNamesCache holds the metric names fetched by ListMetrics per window length, so that
+// searches in a row share one catalog fetch while their windows slide. It is safe for
+// concurrent use.
+type metricNamesCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[time.Duration]metricNamesEntry
+}
+
+type metricNamesEntry struct {
+	names     []string
+	fetchedAt time.Time
+}
+
+func newMetricNamesCache(ttl time.Duration) *metricNamesCache {
+	return &metricNamesCache{ttl: ttl, now: time.Now, entries: map[time.Duration]metricNamesEntry{}}
+}
+
+// window is the cache key of a time range: its length to the minute
+func (c *metricNamesCache) window(start, end time.Time) time.Duration {
+	return end.Sub(start).Round(time.Minute)
+}
+
+// get returns the names of the window when they were fetched less than the TTL ago, as a
+// copy the caller may sort or filter in place
+func (c *metricNamesCache) get(start, end time.Time) ([]string, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[c.window(start, end)]
+	if !ok || c.now().Sub(entry.fetchedAt) >= c.ttl {
+		return nil, false
+	}
+	return slices.Clone(entry.names), true
+}
+
+// put stores the names of the window, dropping the expired entries of other windows
+func (c *metricNamesCache) put(start, end time.Time, names []string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for window, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= c.ttl {
+			delete(c.entries, window)
+		}
+	}
+	c.entries[c.window(start, end)] = metricNamesEntry{names: slices.Clone(names), fetchedAt: now}
+}
@@ -17,9 +17,15 @@ import (
 )
 
 type Client struct {
-	soURL    string
-	token    string
-	apiToken bool
+	soURL          string
+	metricsURL     string
+	token          string
+	apiToken       bool
+	receiverAPIKey string
+	timeout        time.Duration
+	retry          RetryPolicy
+	metricNames    *metricNamesCache
+	httpClient     *http.Client
 }
 
 var (
@@ -28,18 +34,80 @@ var (
 	}
 )
 
-func NewClient(soURL, serviceToken string, apiToken bool) (c *Client, err error) {
-	_, err = url.ParseRequestURI(soURL)
+// DefaultRequestTimeout is the request timeout used when NewClient is given none
+const DefaultRequestTimeout = 30 * time.Second
+
+// NewClient creates a client for the SUSE Observability API. The timeout bounds every
+// HTTP request and is sent as the default timeout of metric queries, zero means DefaultRequestTimeout.
+// The URL must be an absolute http or https URL and the token must not be empty.
+func NewClient(soURL, serviceToken string, apiToken bool, timeout time.Duration) (c *Client, err error) {
+	if strings.TrimSpace(soURL) == "" {
+		return nil, errors.New("the SUSE Observability URL is required")
+	}
+	u, err := url.ParseRequestURI(soURL)
 	if err != nil {
-		return
+		return nil, fmt.Errorf("invalid SUSE Observability URL %q: %w", soURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid SUSE Observability URL %q: must be an absolute http or https URL, e.g. https://observability.example.com", soURL)
+	}
+	if strings.TrimSpace(serviceToken) == "" {
+		return nil, errors.New("the SUSE Observability token is required")
+	}
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
 	}
 	c = new(Client)
 	c.soURL, _ = strings.CutSuffix(soURL, "/")
+	c.metricsURL = c.soURL
 	c.token = serviceToken
 	c.apiToken = apiToken
+	c.timeout = timeout
+	c.retry = DefaultRetryPolicy
+	c.metricNames = newMetricNamesCache(DefaultMetricNamesTTL)
+	c.httpClient = &http.Client{Transport: classifyingTransport{base: transport, timeout: timeout}}
 	return
 }
 
+// queryTimeout returns the timeout of a metric query: the requested one, or the client
+// timeout shortened to the context deadline, so the server gives up before the caller does
+func (c Client) queryTimeout(ctx context.Context, requested string) string {
+	if requested != "" {
+		return requested
+	}
+	timeout := c.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+	return fmt.Sprintf("%dms", max(timeout.Milliseconds(), 1))
+}
+
+// WithMetricsURL sets the base URL of the metrics API, for installations serving it under
+// a different base path or host than the rest of the API. An empty URL keeps the main URL.
+func (c *Client) WithMetricsURL(metricsURL string) (*Client, error) {
+	if metricsURL == "" {
+		return c, nil
+	}
+	if _, err := url.ParseRequestURI(metricsURL); err != nil {
+		return nil, err
+	}
+	c.metricsURL, _ = strings.CutSuffix(metricsURL, "/")
+	return c, nil
+}
+
+// WithMetricNamesTTL sets how long ListMetrics reuses the metric names it fetched for a window
+// length, zero disables the reuse
+func (c *Client) WithMetricNamesTTL(ttl time.Duration) *Client {
+	c.metricNames = newMetricNamesCache(ttl)
+	return c
+}
+
+// WithReceiverAPIKey sets the API key used to send data to the receiver
+func (c *Client) WithReceiverAPIKey(key string) *Client {
+	c.receiverAPIKey = key
+	return c
+}
+
 const (
 	GroovyScript   string = "GroovyScript"
 	DefaultTimeout string = "10s"
@@ -56,6 +124,44 @@ func (c Client) Status(ctx context.Context) (*ServerInfo, error) {
 	return &s, nil
 }
 
+// CheckMetrics verifies that the metrics API answers queries
+func (c Client) CheckMetrics(ctx context.Context) error {
+	_, err := c.QueryMetric(ctx, "vector(1)", time.Now(), "")
+	return err
+}
+
+// CheckToken verifies that the configured token is still accepted. The API does not expose
+// the expiry of the calling token, so a rejected request is the only signal available.
+func (c Client) CheckToken(ctx context.Context) error {
+	_, err := c.Status(ctx)
+	return err
+}
+
+// Ping requests the server info, a lightweight authenticated endpoint, to verify that
+// SUSE Observability is reachable and accepts the configured token
+func (c Client) Ping(ctx context.Context) (*PingResult, error) {
+	started := time.Now()
+	info, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokenType := "service token"
+	if c.apiToken {
+		tokenType = "api token"
+	}
+	return &PingResult{Server: *info, TokenType: tokenType, Latency: time.Since(started)}, nil
+}
+
+// IsUnauthorized reports whether err is caused by SUSE Observability rejecting the token
+func IsUnauthorized(err error) bool {
+	return rq.HasStatusErr(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is caused by the token lacking a permission
+func IsForbidden(err error) bool {
+	return rq.HasStatusErr(err, http.StatusForbidden)
+}
+
 func (c Client) GetTrace(ctx context.Context, id string) (*Trace, error) {
 	var res Trace
 	err := c.apiRequests(fmt.Sprintf("traces/%s", id)).
@@ -99,55 +205,127 @@ func toMs(t time.Time) string {
 	return strconv.FormatInt(t.UnixMilli(), 10)
 }
 
-// ListMetrics fetches all available metrics
+// ListMetrics fetches all available metrics, retried on transient failures. The names are
+// reused for calls with a window of the same length until the metric names TTL passes.
 func (c Client) ListMetrics(ctx context.Context, start, end time.Time) ([]string, error) {
+	if names, ok := c.metricNames.get(start, end); ok {
+		return names, nil
+	}
 	var res struct {
 		Data []string `json:"data"`
 	}
-	err := c.apiRequests("metrics/label/__name__/values").
+	err := c.fetchWithRetry(ctx, c.metricsRequests("label/__name__/values").
 		Param("start", toMs(start)).
 		Param("end", toMs(end)).
-		ToJSON(&res).
-		Fetch(ctx)
+		ToJSON(&res))
+	if err != nil {
+		return nil, err
+	}
+	c.metricNames.put(start, end, res.Data)
+	return res.Data, nil
+}
+
+// GetMetricLabels fetches the label names of the series of a metric, retried on transient failures
+func (c Client) GetMetricLabels(ctx context.Context, metric string, start, end time.Time) ([]string, error) {
+	var res struct {
+		Data []string `json:"data"`
+	}
+	err := c.fetchWithRetry(ctx, c.metricsRequests("labels").
+		Param("match[]", metric).
+		Param("start", toMs(start)).
+		Param("end", toMs(end)).
+		ToJSON(&res))
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// GetMetricLabelValues fetches the distinct values of a label across the series of a metric,
+// retried on transient failures
+func (c Client) GetMetricLabelValues(ctx context.Context, metric, label string, start, end time.Time) ([]string, error) {
+	var res struct {
+		Data []string `json:"data"`
+	}
+	err := c.fetchWithRetry(ctx, c.metricsRequests("label/"+url.PathEscape(label)+"/values").
+		Param("match[]", metric).
+		Param("start", toMs(start)).
+		Param("end", toMs(end)).
+		ToJSON(&res))
 	if err != nil {
 		return nil, err
 	}
 	return res.Data, nil
 }
 
-// QueryMetric is the instant query at a single point in time.
+// GetMetricSeries fetches the label sets of the series of a metric, retried on transient
+// failures. A positive limit asks the server for at most that many series, servers without
+// support for it return all of them.
+func (c Client) GetMetricSeries(ctx context.Context, metric string, start, end time.Time, limit int) ([]map[string]string, error) {
+	var res struct {
+		Data []map[string]string `json:"data"`
+	}
+	req := c.metricsRequests("series").
+		Param("match[]", metric).
+		Param("start", toMs(start)).
+		Param("end", toMs(end))
+	if limit > 0 {
+		req = req.Param("limit", strconv.Itoa(limit))
+	}
+	err := c.fetchWithRetry(ctx, req.ToJSON(&res))
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// GetMetricMetadata fetches the type, help and unit of every metric, keyed by metric name,
+// retried on transient failures
+func (c Client) GetMetricMetadata(ctx context.Context) (map[string][]MetricMetadata, error) {
+	var res struct {
+		Data map[string][]MetricMetadata `json:"data"`
+	}
+	err := c.fetchWithRetry(ctx, c.metricsRequests("metadata").
+		ToJSON(&res))
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// QueryMetric is the instant query at a single point in time, retried on transient failures.
+// A query still rate limited after the retries fails with a RateLimitError.
 // The endpoint evaluates an instant query at a single point in time.
 // Query is the promql query and Time the single point.
-// Timeout is in the form "<number><unit (y|w|d|h|m|s|ms)>". Example 10ms.
+// Timeout is in the form "<number><unit (y|w|d|h|m|s|ms)>". Example 10ms. An empty timeout uses the client timeout.
 func (c Client) QueryMetric(ctx context.Context, query string, at time.Time, timeout string) (*MetricQueryResponse, error) {
 	var m MetricQueryResponse
-	err := c.apiRequests("metrics/query").
+	err := c.fetchWithRetry(ctx, c.metricsRequests("query").
 		Param("query", query).
-		Param("timeout", timeout).
+		Param("timeout", c.queryTimeout(ctx, timeout)).
 		Param("time", toMs(at)).
-		ToJSON(&m).
-		Fetch(ctx)
+		ToJSON(&m))
 	if err != nil {
 		return nil, err
 	}
 	return &m, nil
 }
 
-// QueryRangeMetric is the query over a range of time
+// QueryRangeMetric is the query over a range of time, retried on transient failures. A query
+// still rate limited after the retries fails with a RateLimitError.
 // The endpoint evaluates an expression query over a range of time
 // Query is the promql query. Start and End times indicate the range.
 // Step is the promstep in the same format as Timeout.
-// Timeout is in the form "<number><unit (y|w|d|h|m|s|ms)>". Example 10ms.
+// Timeout is in the form "<number><unit (y|w|d|h|m|s|ms)>". Example 10ms. An empty timeout uses the client timeout.
 func (c Client) QueryRangeMetric(ctx context.Context, query string, start time.Time, end time.Time, step, timeout string) (*MetricQueryResponse, error) {
 	var m MetricQueryResponse
-	err := c.apiRequests("metrics/query_range").
+	err := c.fetchWithRetry(ctx, c.metricsRequests("query_range").
 		Param("query", query).
-		Param("timeout", timeout).
+		Param("timeout", c.queryTimeout(ctx, timeout)).
 		Param("step", step).
 		Param("start", toMs(start)).
 		Param("end", toMs(end)).
-		ToJSON(&m).
-		Fetch(ctx)
+		ToJSON(&m))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +333,18 @@ func (c Client) QueryRangeMetric(ctx context.Context, query string, start time.T
 }
 
 func (c Client) SnapShotTopologyQuery(ctx context.Context, query string) ([]ViewComponent, error) {
+	return c.snapshotComponents(ctx, NewViewSnapshotRequest(query))
+}
+
+// SnapShotTopologyQueryAt queries the topology as it was at a point in time
+func (c Client) SnapShotTopologyQueryAt(ctx context.Context, query string, at time.Time) ([]ViewComponent, error) {
 	req := NewViewSnapshotRequest(query)
+	req.Metadata.QueryTime = at.UnixMilli()
+	return c.snapshotComponents(ctx, req)
+}
+
+// snapshotComponents returns the components of a snapshot, or the first error of a failed query
+func (c Client) snapshotComponents(ctx context.Context, req *ViewSnapshotRequest) ([]ViewComponent, error) {
 	res, err := c.ViewSnapshot(ctx, req)
 	if err != nil {
 		return nil, err
@@ -166,6 +355,28 @@ func (c Client) SnapShotTopologyQuery(ctx context.Context, query string) ([]View
 	return res.Components, nil
 }
 
+// stqlValidationIdentifier is an identifier no component has, to restrict a query to check
+// to an empty result
+const stqlValidationIdentifier = "urn:suse-observability-mcp:stql-validation"
+
+// ValidateSTQL checks the syntax of an STQL query with the snapshot endpoint, which has no dry
+// run: the query is restricted to an identifier no component has, so no components are fetched.
+// Parser positions on the first line are one column further as the query is parenthesized.
+func (c Client) ValidateSTQL(ctx context.Context, query string) (*STQLValidation, error) {
+	checked := fmt.Sprintf("(%s) AND identifier = %q", query, stqlValidationIdentifier)
+	res, err := c.ViewSnapshot(ctx, NewViewSnapshotRequest(checked))
+	if err != nil {
+		return nil, err
+	}
+	validation := &STQLValidation{Valid: res.Success, CheckedQuery: checked}
+	for _, e := range res.Errors {
+		validation.Errors = append(validation.Errors, e.Message)
+	}
+	return validation, nil
+}
+
+// ViewSnapshot queries a topology snapshot. Query errors are returned in the response, a
+// rate limited request fails with a RateLimitError.
 func (c Client) ViewSnapshot(ctx context.Context, req *ViewSnapshotRequest) (*ViewSnapshotResponse, error) {
 	var res querySnapshotResult
 	var e ErrorResp
@@ -176,6 +387,10 @@ func (c Client) ViewSnapshot(ctx context.Context, req *ViewSnapshotRequest) (*Vi
 		ToJSON(&res).
 		Fetch(ctx)
 	if err != nil {
+		var rateLimit *RateLimitError
+		if err = rateLimited(err); errors.As(err, &rateLimit) {
+			return nil, err
+		}
 		if len(e.Errors) > 0 {
 			return &ViewSnapshotResponse{Success: false, Errors: e.Errors}, nil
 		}
@@ -201,6 +416,18 @@ func (c Client) Domains() (*map[int64]NodeType, error) {
 	return c.getNodesOfType("Domain")
 }
 
+// ListViews returns the saved topology views, in the order of the server
+func (c Client) ListViews(ctx context.Context) ([]QueryView, error) {
+	var res []QueryView
+	err := c.apiRequests("node/QueryView").
+		ToJSON(&res).
+		Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 func (c Client) getNodesOfType(t string) (*map[int64]NodeType, error) {
 	var res []NodeType
 	err := c.apiRequests(fmt.Sprintf("node/%s", t)).
@@ -300,9 +527,30 @@ func (c Client) GetEvent(ctx context.Context, eventId string, startMs int64, end
 	return &res, nil
 }
 
+// GetLogs retrieves the log lines of components based on topology, time and text selections
+func (c Client) GetLogs(ctx context.Context, req *LogListRequest) (*LogLinesWithTotal, error) {
+	var res LogLinesWithTotal
+	err := c.apiRequests("logs").
+		Post().
+		BodyJSON(req).
+		ToJSON(&res).
+		Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c Client) apiRequests(endpoint string) *rq.Builder {
 	uri := fmt.Sprintf("%s/api/%s", c.soURL, endpoint)
-	return request(uri).
+	return c.request(uri).
+		Header(c.GetXHeader(), c.token)
+}
+
+// metricsRequests builds a request to the metrics API, which may be served under its own base URL
+func (c Client) metricsRequests(endpoint string) *rq.Builder {
+	uri := fmt.Sprintf("%s/api/metrics/%s", c.metricsURL, endpoint)
+	return c.request(uri).
 		Header(c.GetXHeader(), c.token)
 }
 
@@ -313,10 +561,10 @@ func (c Client) GetXHeader() string {
 	return "X-API-Key"
 }
 
-func request(uri string) *rq.Builder {
+func (c Client) request(uri string) *rq.Builder {
 	b := rq.URL(uri).
 		ContentType("application/json").
-		Transport(transport)
+		Client(c.httpClient)
 	return b
 }
 
@@ -358,6 +606,30 @@ func (c Client) GetMonitorsOverview(ctx context.Context) (*MonitorOverviewList,
 
 // GetMonitorCheckStates returns the check states that a monitor generated
 func (c Client) GetMonitorCheckStates(ctx context.Context, monitorIdOrUrn string, healthState string, limit int, timestamp int64) (*MonitorCheckStates, error) {
+	return c.monitorCheckStates(ctx, monitorIdOrUrn, healthState, limit, 0, timestamp)
+}
+
+// EachMonitorCheckStatePage fetches the check states of a monitor pageSize at a time and
+// calls fn with every page before fetching the next one. It stops after a page that is not
+// full, when fn returns false or an error, or when fetching a page fails. Only one page is
+// held in memory at a time.
+func (c Client) EachMonitorCheckStatePage(ctx context.Context, monitorIdOrUrn string, healthState string, pageSize int, timestamp int64, fn func(states []ViewCheckState) (bool, error)) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	for offset := 0; ; offset += pageSize {
+		page, err := c.monitorCheckStates(ctx, monitorIdOrUrn, healthState, pageSize, offset, timestamp)
+		if err != nil {
+			return err
+		}
+		more, err := fn(page.States)
+		if err != nil || !more || len(page.States) < pageSize {
+			return err
+		}
+	}
+}
+
+func (c Client) monitorCheckStates(ctx context.Context, monitorIdOrUrn string, healthState string, limit, offset int, timestamp int64) (*MonitorCheckStates, error) {
 	var res MonitorCheckStates
 	req := c.apiRequests(fmt.Sprintf("monitors/%s/checkStates", monitorIdOrUrn))
 
@@ -367,6 +639,9 @@ func (c Client) GetMonitorCheckStates(ctx context.Context, monitorIdOrUrn string
 	if limit > 0 {
 		req.Param("limit", strconv.Itoa(limit))
 	}
+	if offset > 0 {
+		req.Param("offset", strconv.Itoa(offset))
+	}
 	if timestamp > 0 {
 		req.Param("timestamp", strconv.FormatInt(timestamp, 10))
 	}
@@ -419,3 +694,27 @@ func (c Client) GetComponent(ctx context.Context, componentID int64) (*Component
 	}
 	return &res, nil
 }
+
+// intakeHostname identifies the server as the sender of intake data
+const intakeHostname = "suse-observability-mcp"
+
+// PostEvent sends a custom event to the receiver intake
+func (c Client) PostEvent(ctx context.Context, event IntakeEvent) error {
+	if c.receiverAPIKey == "" {
+		return errors.New("no receiver API key configured")
+	}
+	payload := intakePayload{
+		CollectionTimestamp: time.Now().Unix(),
+		InternalHostname:    intakeHostname,
+		Events:              map[string][]IntakeEvent{event.EventType: {event}},
+		Metrics:             []any{},
+		ServiceChecks:       []any{},
+		Health:              []any{},
+		Topologies:          []any{},
+	}
+	return c.request(fmt.Sprintf("%s/receiver/stsAgent/intake", c.soURL)).
+		Param("api_key", c.receiverAPIKey).
+		BodyJSON(payload).
+		Post().
+		Fetch(ctx)
+}
@@ -0,0 +1,931 @@
+package suseobservability
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	rq "github.com/carlmjohnson/requests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		token string
+		err   string
+	}{
+		{name: "empty URL", url: " ", token: "token", err: "the SUSE Observability URL is required"},
+		{name: "malformed URL", url: "observability.example.com", token: "token", err: `invalid SUSE Observability URL "observability.example.com": parse "observability.example.com": invalid URI for request`},
+		{name: "URL without host", url: "https:///api", token: "token", err: `invalid SUSE Observability URL "https:///api": must be an absolute http or https URL, e.g. https://observability.example.com`},
+		{name: "empty token", url: "https://observability.example.com", token: "", err: "the SUSE Observability token is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.url, tt.token, false, 0)
+
+			assert.Nil(t, client)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		client, err := NewClient("https://observability.example.com/", "token", false, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://observability.example.com", client.soURL)
+	})
+}
+
+// captureDebugLogs routes the default logger to a JSON buffer at debug level for the test
+func captureDebugLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestRequestDebugLogging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+	logs := captureDebugLogs(t)
+
+	client, err := NewClient(server.URL, "secret-token", false, 0)
+	require.NoError(t, err)
+	_, err = client.QueryMetric(context.Background(), "up", time.Now(), "")
+	require.NoError(t, err)
+
+	var entry map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n")) {
+		require.NoError(t, json.Unmarshal(line, &entry))
+		if entry["msg"] == "API request" {
+			break
+		}
+	}
+	assert.Equal(t, "API request", entry["msg"])
+	assert.Equal(t, "DEBUG", entry["level"])
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.Equal(t, "/api/metrics/query", entry["path"])
+	assert.Equal(t, "up", entry["query"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Contains(t, entry, "duration")
+	assert.NotContains(t, logs.String(), "secret-token")
+}
+
+func TestPostEvent(t *testing.T) {
+	event := IntakeEvent{
+		Context: IntakeEventContext{
+			Category:           "Activities",
+			Data:               map[string]any{},
+			ElementIdentifiers: []string{"urn:kubernetes:/prod:default:pod/api-1"},
+			Source:             "mcp",
+			SourceLinks:        []any{},
+		},
+		EventType:      "MCPAnnotation",
+		MsgTitle:       "Acknowledged",
+		MsgText:        "Acknowledged",
+		SourceTypeName: "MCPAnnotation",
+		Tags:           []string{"source:mcp"},
+		Timestamp:      1700000000,
+	}
+
+	t.Run("posts the event to the receiver intake", func(t *testing.T) {
+		var (
+			method, path, apiKey string
+			body                 map[string]any
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method, path, apiKey = r.Method, r.URL.Path, r.URL.Query().Get("api_key")
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client, err := NewClient(server.URL+"/", "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.WithReceiverAPIKey("receiver-key").PostEvent(context.Background(), event)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, method)
+		assert.Equal(t, "/receiver/stsAgent/intake", path)
+		assert.Equal(t, "receiver-key", apiKey)
+		assert.Equal(t, "suse-observability-mcp", body["internalHostname"])
+		events := body["events"].(map[string]any)["MCPAnnotation"].([]any)
+		require.Len(t, events, 1)
+		posted := events[0].(map[string]any)
+		assert.Equal(t, "Acknowledged", posted["msg_text"])
+		assert.Equal(t, []any{"urn:kubernetes:/prod:default:pod/api-1"}, posted["context"].(map[string]any)["element_identifiers"])
+		assert.Equal(t, []any{}, body["metrics"])
+	})
+
+	t.Run("requires a receiver API key", func(t *testing.T) {
+		client, err := NewClient("https://observability.example.com", "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.PostEvent(context.Background(), event)
+
+		assert.EqualError(t, err, "no receiver API key configured")
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.WithReceiverAPIKey("wrong").PostEvent(context.Background(), event)
+
+		assert.ErrorContains(t, err, "403")
+	})
+}
+
+func TestClientTimeout(t *testing.T) {
+	t.Run("slow server times out within the configured window", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client, err := NewClient(server.URL, "token", false, 100*time.Millisecond)
+		require.NoError(t, err)
+
+		started := time.Now()
+		_, err = client.QueryRangeMetric(context.Background(), "up", started.Add(-time.Hour), started, "1m", "")
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "request timeout exceeded after 100ms")
+		assert.ErrorContains(t, err, "(timeout problem: the server did not answer in time, raise -request-timeout")
+		assert.Less(t, time.Since(started), 2*time.Second)
+	})
+
+	t.Run("caller deadline is not reported as a server timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client, err := NewClient(server.URL, "token", false, time.Minute)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err = client.QueryMetric(ctx, "up", time.Now(), "")
+
+		var transportErr *TransportError
+		require.ErrorAs(t, err, &transportErr)
+		assert.Equal(t, TransportCanceled, transportErr.Kind)
+		assert.NotContains(t, err.Error(), "-request-timeout")
+	})
+
+	t.Run("query timeout defaults to the client timeout", func(t *testing.T) {
+		var timeout string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			timeout = r.URL.Query().Get("timeout")
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`))
+		}))
+		defer server.Close()
+
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+		now := time.Now()
+
+		_, err = client.QueryRangeMetric(context.Background(), "up", now.Add(-time.Hour), now, "1m", "")
+		require.NoError(t, err)
+		assert.Equal(t, "30000ms", timeout)
+
+		_, err = client.QueryRangeMetric(context.Background(), "up", now.Add(-time.Hour), now, "1m", "5s")
+		require.NoError(t, err)
+		assert.Equal(t, "5s", timeout)
+	})
+
+	t.Run("query timeout is shortened to the context deadline", func(t *testing.T) {
+		client, err := NewClient("https://observability.example.com", "token", false, time.Minute)
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		timeout, err := time.ParseDuration(client.queryTimeout(ctx, ""))
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, timeout, 10*time.Second)
+		assert.Greater(t, timeout, 9*time.Second)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	fastRetries := RetryPolicy{Retries: 2, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+	// failingServer answers the first failures requests with status, then succeeds
+	failingServer := func(failures, status int, requests *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests++
+			if *requests <= failures {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"success","data":["up"]}`))
+		}))
+	}
+
+	t.Run("transient failures are retried", func(t *testing.T) {
+		for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway} {
+			var requests int
+			server := failingServer(2, status, &requests)
+			defer server.Close()
+			logs := captureDebugLogs(t)
+			client, err := NewClient(server.URL, "token", false, 0)
+			require.NoError(t, err)
+
+			names, err := client.WithRetryPolicy(fastRetries).ListMetrics(context.Background(), time.Now().Add(-time.Hour), time.Now())
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"up"}, names)
+			assert.Equal(t, 3, requests)
+			assert.Contains(t, logs.String(), `"msg":"API request succeeded after retries","path":"/api/metrics/label/__name__/values","retries":2`)
+		}
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		var requests int
+		server := failingServer(5, http.StatusServiceUnavailable, &requests)
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		_, err = client.WithRetryPolicy(fastRetries).GetMetricLabels(context.Background(), "up", time.Now().Add(-time.Hour), time.Now())
+
+		assert.ErrorContains(t, err, "unexpected status: 503")
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var requests int
+		server := failingServer(1, http.StatusBadRequest, &requests)
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		_, err = client.WithRetryPolicy(fastRetries).QueryRangeMetric(context.Background(), "up{", time.Now().Add(-time.Hour), time.Now(), "1m", "")
+
+		assert.ErrorContains(t, err, "unexpected status: 400")
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("every metric read is retried", func(t *testing.T) {
+		// every path fails once, then answers with a body of the expected shape
+		failed := map[string]bool{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !failed[r.URL.Path] {
+				failed[r.URL.Path] = true
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			switch r.URL.Path {
+			case "/api/metrics/query":
+				_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+			case "/api/metrics/series":
+				_, _ = w.Write([]byte(`{"status":"success","data":[{"job":"api"}]}`))
+			case "/api/metrics/metadata":
+				_, _ = w.Write([]byte(`{"status":"success","data":{}}`))
+			default:
+				_, _ = w.Write([]byte(`{"status":"success","data":["api"]}`))
+			}
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+		client.WithRetryPolicy(fastRetries)
+		ctx := context.Background()
+
+		_, err = client.QueryMetric(ctx, "up", time.Now(), "")
+		assert.NoError(t, err)
+		_, err = client.GetMetricSeries(ctx, "up", time.Now().Add(-time.Hour), time.Now(), 0)
+		assert.NoError(t, err)
+		_, err = client.GetMetricLabelValues(ctx, "up", "job", time.Now().Add(-time.Hour), time.Now())
+		assert.NoError(t, err)
+		_, err = client.GetMetricMetadata(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, failed, 4)
+	})
+
+	t.Run("other requests are not retried", func(t *testing.T) {
+		var requests int
+		server := failingServer(1, http.StatusBadGateway, &requests)
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		_, err = client.WithRetryPolicy(fastRetries).GetMonitors(context.Background())
+
+		assert.ErrorContains(t, err, "unexpected status: 502")
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("Retry-After longer than the maximum delay fails at once", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		_, err = client.WithRetryPolicy(fastRetries).ListMetrics(context.Background(), time.Now().Add(-time.Hour), time.Now())
+
+		var rateLimit *RateLimitError
+		require.ErrorAs(t, err, &rateLimit)
+		assert.Equal(t, 60*time.Second, rateLimit.RetryAfter)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		policy := RetryPolicy{Retries: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+		throttled := func(retryAfter string) error {
+			res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			res.Header.Set("Retry-After", retryAfter)
+			return fmt.Errorf("%w: unexpected status: 429", (*rq.ResponseError)(res))
+		}
+
+		for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+			delay, ok := policy.delay(retry, errors.New("connection reset"))
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, delay, want/2)
+			assert.LessOrEqual(t, delay, want)
+		}
+		delay, ok := policy.delay(0, throttled("3"))
+		assert.True(t, ok)
+		assert.Equal(t, 3*time.Second, delay)
+		delay, ok = policy.delay(0, throttled("5"))
+		assert.True(t, ok)
+		assert.Equal(t, 5*time.Second, delay)
+		delay, ok = policy.delay(0, throttled("120"))
+		assert.False(t, ok, "a Retry-After longer than MaxDelay is not waited for")
+		assert.Equal(t, 120*time.Second, delay)
+		_, ok = policy.delay(0, throttled(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)))
+		assert.False(t, ok)
+	})
+}
+
+func TestRateLimitError(t *testing.T) {
+	// rateLimitedServer rejects every request with status 429 and retryAfter as Retry-After
+	rateLimitedServer := func(retryAfter string, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	newClient := func(t *testing.T, server *httptest.Server) *Client {
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+		return client.WithRetryPolicy(RetryPolicy{})
+	}
+
+	t.Run("range query", func(t *testing.T) {
+		server := rateLimitedServer("30", "")
+		defer server.Close()
+
+		_, err := newClient(t, server).QueryRangeMetric(context.Background(), "up", time.Now().Add(-time.Hour), time.Now(), "1m", "")
+
+		var rateLimit *RateLimitError
+		require.ErrorAs(t, err, &rateLimit)
+		assert.Equal(t, 30*time.Second, rateLimit.RetryAfter)
+		assert.EqualError(t, err, "rate limited by SUSE Observability, retry in 30s")
+		var se *rq.ResponseError
+		require.ErrorAs(t, err, &se, "the response error is wrapped")
+		assert.Equal(t, http.StatusTooManyRequests, se.StatusCode)
+	})
+
+	t.Run("topology query, even with an error body", func(t *testing.T) {
+		server := rateLimitedServer(time.Now().Add(90*time.Second).UTC().Format(http.TimeFormat), `{"errors":[{"message":"Too many requests","errorCode":429}]}`)
+		defer server.Close()
+
+		_, err := newClient(t, server).SnapShotTopologyQuery(context.Background(), `type = "pod"`)
+
+		var rateLimit *RateLimitError
+		require.ErrorAs(t, err, &rateLimit)
+		assert.InDelta(t, 90*time.Second, rateLimit.RetryAfter, float64(2*time.Second), "an HTTP date is relative to now")
+		assert.Contains(t, err.Error(), "rate limited by SUSE Observability, retry in ")
+	})
+
+	t.Run("without Retry-After", func(t *testing.T) {
+		server := rateLimitedServer("", "")
+		defer server.Close()
+
+		_, err := newClient(t, server).SnapShotTopologyQueryAt(context.Background(), `type = "pod"`, time.Now().Add(-time.Hour))
+
+		var rateLimit *RateLimitError
+		require.ErrorAs(t, err, &rateLimit)
+		assert.Zero(t, rateLimit.RetryAfter)
+		assert.EqualError(t, err, "rate limited by SUSE Observability, retry later")
+	})
+
+	t.Run("other statuses are not rate limits", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := newClient(t, server).QueryRangeMetric(context.Background(), "up", time.Now().Add(-time.Hour), time.Now(), "1m", "")
+
+		var rateLimit *RateLimitError
+		assert.False(t, errors.As(err, &rateLimit))
+		assert.ErrorContains(t, err, "unexpected status: 503")
+	})
+}
+
+func TestValidateSTQL(t *testing.T) {
+	t.Run("parse errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"Unexpected token 'nme' at line 1, column 2","errorCode":400}]}`))
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		validation, err := client.ValidateSTQL(context.Background(), `nme = "checkout"`)
+
+		require.NoError(t, err)
+		assert.Equal(t, &STQLValidation{
+			Errors:       []string{"Unexpected token 'nme' at line 1, column 2"},
+			CheckedQuery: `(nme = "checkout") AND identifier = "urn:suse-observability-mcp:stql-validation"`,
+		}, validation)
+	})
+
+	t.Run("request failures", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		_, err = client.ValidateSTQL(context.Background(), `name = "checkout"`)
+
+		assert.True(t, IsUnauthorized(err))
+	})
+}
+
+func TestListMetricsCache(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"status":"success","data":["up","cpu"]}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token", false, 0)
+	require.NoError(t, err)
+	now := time.Now()
+	client.metricNames.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	names, err := client.ListMetrics(ctx, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"up", "cpu"}, names)
+	names[0] = "changed by the caller"
+
+	// A second search within the TTL, its window slid by a few seconds
+	now = now.Add(30 * time.Second)
+	names, err = client.ListMetrics(ctx, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"up", "cpu"}, names)
+	assert.Equal(t, 1, requests)
+
+	_, err = client.ListMetrics(ctx, now.Add(-2*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests, "another window length is fetched")
+
+	now = now.Add(DefaultMetricNamesTTL)
+	_, err = client.ListMetrics(ctx, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests, "expired names are fetched again")
+
+	_, err = client.WithMetricNamesTTL(0).ListMetrics(ctx, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, 4, requests, "a zero TTL disables the reuse")
+}
+
+func TestListMetricsCacheConcurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":["up"]}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token", false, 0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			end := time.Now()
+			names, err := client.ListMetrics(context.Background(), end.Add(-time.Duration(i%2+1)*time.Hour), end)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"up"}, names)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClassifyTransportError(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("failed to get monitors: %w", &url.Error{Op: "Get", URL: "https://observability.example.com/api/monitors", Err: err})
+	}
+	tests := []struct {
+		name string
+		err  error
+		kind TransportErrorKind
+	}{
+		{"unknown host", wrap(&net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "observabilty.example.com", IsNotFound: true}}), TransportDNS},
+		{"handshake alert", wrap(tls.AlertError(40)), TransportTLS},
+		{"plain http server on an https url", wrap(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), TransportTLS},
+		{"connection refused", wrap(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), TransportConnection},
+		{"deadline", wrap(context.DeadlineExceeded), TransportTimeout},
+		{"dial timeout", wrap(&net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}), TransportTimeout},
+		{"proxy", wrap(&net.OpError{Op: "proxyconnect", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), TransportProxy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, ok := ClassifyTransportError(context.Background(), tt.err)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+
+	t.Run("errors other than transport errors", func(t *testing.T) {
+		for _, err := range []error{nil, errors.New("401 Unauthorized"), wrap(context.Canceled)} {
+			_, ok := ClassifyTransportError(context.Background(), err)
+			assert.False(t, ok, "%v", err)
+		}
+	})
+
+	t.Run("errors of a done caller context are cancellations", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		<-ctx.Done()
+
+		kind, ok := ClassifyTransportError(ctx, wrap(context.DeadlineExceeded))
+
+		assert.True(t, ok)
+		assert.Equal(t, TransportCanceled, kind)
+	})
+
+	t.Run("client errors carry the hint", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+		client, err := NewClient("http://"+addr, "token", false, time.Second)
+		require.NoError(t, err)
+
+		err = client.CheckToken(context.Background())
+
+		var transportErr *TransportError
+		require.ErrorAs(t, err, &transportErr)
+		assert.Equal(t, TransportConnection, transportErr.Kind)
+		assert.ErrorContains(t, err, "connection refused (connection problem: nothing accepts connections on that host and port, check the port of -url")
+	})
+}
+
+// timeoutError is a net.Error reporting a timeout, like the errors of dial deadlines
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCheckToken(t *testing.T) {
+	t.Run("accepted token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/server/info", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get("X-API-Key"))
+			_, _ = w.Write([]byte(`{"version":{"major":7}}`))
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.CheckToken(context.Background())
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.CheckToken(context.Background())
+
+		assert.Error(t, err)
+		assert.True(t, IsUnauthorized(err))
+		assert.True(t, IsUnauthorized(fmt.Errorf("failed to get components: %w", err)))
+	})
+
+	t.Run("other failures are not unauthorized", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		client, err := NewClient(server.URL, "token", false, 0)
+		require.NoError(t, err)
+
+		err = client.CheckToken(context.Background())
+
+		assert.Error(t, err)
+		assert.False(t, IsUnauthorized(err))
+		assert.False(t, IsUnauthorized(nil))
+	})
+}
+
+func TestGetMetricMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/metrics/metadata", r.URL.Path)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"http_requests_total":[{"type":"counter","help":"Requests.","unit":""}]}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token", false, 0)
+	require.NoError(t, err)
+
+	metadata, err := client.GetMetricMetadata(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]MetricMetadata{"http_requests_total": {{Type: "counter", Help: "Requests."}}}, metadata)
+}
+
+func TestQueryMetricScalar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/metrics/query", r.URL.Path)
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"42.5"]}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, "token", false, 0)
+	require.NoError(t, err)
+
+	res, err := client.QueryMetric(context.Background(), "scalar(sum(up))", time.Unix(1700000000, 0), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, MetricData{ResultType: "scalar", Result: []MetricResult{{Labels: map[string]string{}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 42.5}}}}}, res.Data)
+}
+
+func TestMetricDataUnmarshal(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    MetricData
+	}{
+		{fixture: "matrix.json", want: MetricData{ResultType: "matrix", Result: []MetricResult{
+			{Labels: map[string]string{"__name__": "up", "job": "node"}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 1}, {Timestamp: 1700000060, Value: 0.5}}},
+			{Labels: map[string]string{"__name__": "up", "job": "api"}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 0}}},
+		}}},
+		{fixture: "vector.json", want: MetricData{ResultType: "vector", Result: []MetricResult{
+			{Labels: map[string]string{}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 3}}},
+		}}},
+		{fixture: "scalar.json", want: MetricData{ResultType: "scalar", Result: []MetricResult{
+			{Labels: map[string]string{}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 42.5}}},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "results", tt.fixture))
+			require.NoError(t, err)
+
+			var data MetricData
+			require.NoError(t, json.Unmarshal(body, &data))
+
+			assert.Equal(t, tt.want, data)
+		})
+	}
+
+	t.Run("string.json", func(t *testing.T) {
+		body, err := os.ReadFile(filepath.Join("testdata", "results", "string.json"))
+		require.NoError(t, err)
+
+		var data MetricData
+		require.NoError(t, json.Unmarshal(body, &data))
+
+		assert.Equal(t, "string", data.ResultType)
+		require.Len(t, data.Result, 1)
+		assert.Equal(t, "ready", data.Result[0].String)
+		require.Len(t, data.Result[0].Points, 1)
+		assert.Equal(t, int64(1700000000), data.Result[0].Points[0].Timestamp)
+		assert.True(t, math.IsNaN(data.Result[0].Points[0].Value))
+	})
+
+	t.Run("invalid results are errors", func(t *testing.T) {
+		for _, body := range []string{
+			`{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"abc"]}]}`,
+			`{"resultType":"scalar","result":[1700000000]}`,
+			`{"resultType":"histogram","result":[]}`,
+		} {
+			var data MetricData
+			assert.Error(t, json.Unmarshal([]byte(body), &data), body)
+		}
+	})
+}
+
+func TestMetricsURL(t *testing.T) {
+	recorder := func(paths *[]string, body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "token", r.Header.Get("X-API-Key"))
+			*paths = append(*paths, r.URL.Path)
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	var mainPaths, metricsPaths []string
+	mainServer := recorder(&mainPaths, `{}`)
+	defer mainServer.Close()
+	metricsServer := recorder(&metricsPaths, `{"status":"success","data":{"resultType":"matrix","result":[]}}`)
+	defer metricsServer.Close()
+
+	client, err := NewClient(mainServer.URL, "token", false, 0)
+	require.NoError(t, err)
+	_, err = client.WithMetricsURL(metricsServer.URL + "/prom/")
+	require.NoError(t, err)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err = client.QueryRangeMetric(ctx, "up", now.Add(-time.Hour), now, "1m", "")
+	require.NoError(t, err)
+	_, err = client.QueryMetric(ctx, "up", now, "")
+	require.NoError(t, err)
+	require.NoError(t, client.CheckMetrics(ctx))
+	_, _ = client.ListMetrics(ctx, now.Add(-time.Hour), now)
+	_, _ = client.GetMetricLabels(ctx, "up", now.Add(-time.Hour), now)
+	_, _ = client.GetMetricMetadata(ctx)
+	_, _ = client.GetMetricSeries(ctx, "up", now.Add(-time.Hour), now, 0)
+	_, err = client.Status(ctx)
+	require.NoError(t, err)
+	_, err = client.GetMonitors(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/prom/api/metrics/query_range",
+		"/prom/api/metrics/query",
+		"/prom/api/metrics/query",
+		"/prom/api/metrics/label/__name__/values",
+		"/prom/api/metrics/labels",
+		"/prom/api/metrics/metadata",
+		"/prom/api/metrics/series",
+	}, metricsPaths)
+	assert.Equal(t, []string{"/api/server/info", "/api/monitors"}, mainPaths)
+
+	t.Run("defaults to the main URL", func(t *testing.T) {
+		mainPaths = nil
+		client, err := NewClient(mainServer.URL, "token", false, 0)
+		require.NoError(t, err)
+		_, err = client.WithMetricsURL("")
+		require.NoError(t, err)
+
+		_, _ = client.QueryMetric(ctx, "up", now, "")
+
+		assert.Equal(t, []string{"/api/metrics/query"}, mainPaths)
+	})
+
+	t.Run("invalid URL", func(t *testing.T) {
+		_, err := client.WithMetricsURL("not a url")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestEachMonitorCheckStatePage(t *testing.T) {
+	// serves 5 check states in pages of the requested limit
+	var offsets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/monitors/42/checkStates", r.URL.Path)
+		assert.Equal(t, "CRITICAL", r.URL.Query().Get("healthState"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var res MonitorCheckStates
+		for i := offset; i < min(offset+limit, 5); i++ {
+			res.States = append(res.States, ViewCheckState{CheckStateId: strconv.Itoa(i)})
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "token", false, 0)
+	require.NoError(t, err)
+
+	t.Run("fetches every page", func(t *testing.T) {
+		offsets = nil
+		var pages [][]string
+
+		err := client.EachMonitorCheckStatePage(context.Background(), "42", "CRITICAL", 2, 0, func(states []ViewCheckState) (bool, error) {
+			var ids []string
+			for _, s := range states {
+				ids = append(ids, s.CheckStateId)
+			}
+			pages = append(pages, ids)
+			return true, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"0", "1"}, {"2", "3"}, {"4"}}, pages)
+		assert.Equal(t, []string{"", "2", "4"}, offsets)
+	})
+
+	t.Run("stops when the callback is done", func(t *testing.T) {
+		offsets = nil
+
+		err := client.EachMonitorCheckStatePage(context.Background(), "42", "CRITICAL", 2, 0, func(states []ViewCheckState) (bool, error) {
+			return false, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{""}, offsets)
+	})
+
+	t.Run("callback errors are returned", func(t *testing.T) {
+		err := client.EachMonitorCheckStatePage(context.Background(), "42", "CRITICAL", 2, 0, func(states []ViewCheckState) (bool, error) {
+			return true, fmt.Errorf("boom")
+		})
+
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestQueryTracesPaging(t *testing.T) {
+	totalPages, hasMore := 2, false
+	tests := []struct {
+		fixture    string
+		totalPages *int
+		hasMore    *bool
+	}{
+		{fixture: "trace_query.json"},
+		{fixture: "trace_query_paged.json", totalPages: &totalPages, hasMore: &hasMore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "contract", tt.fixture))
+			require.NoError(t, err)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(body)
+			}))
+			defer server.Close()
+			client, err := NewClient(server.URL, "token", false, 0)
+			require.NoError(t, err)
+
+			res, err := client.QueryTraces(context.Background(), &TraceQueryRequest{Page: 1, PageSize: 10})
+
+			require.NoError(t, err)
+			assert.Equal(t, 11, res.MatchesTotal)
+			assert.Equal(t, tt.totalPages, res.TotalPages)
+			assert.Equal(t, tt.hasMore, res.HasMore)
+		})
+	}
+}
@@ -0,0 +1,697 @@
+package suseobservability
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the covered API surface in testdata/contract")
+
+// contractDir holds the response fixtures and the generated surface summary
+const contractDir = "testdata/contract"
+
+// anyValue matches a query parameter that must be sent with a value that is not fixed, e.g. the current time
+const anyValue = "<any>"
+
+// nonRequestMethods are the Client methods that send no request, so they need no contract
+var nonRequestMethods = map[string]bool{
+	"GetXHeader":         true,
+	"WithMetricNamesTTL": true,
+	"WithMetricsURL":     true,
+	"WithReceiverAPIKey": true,
+	"WithRetryPolicy":    true,
+}
+
+type authMode string
+
+const (
+	// authToken sends the service or API token in the X-API-Key or X-API-Token header
+	authToken authMode = "token"
+	// authReceiverKey sends the receiver API key as api_key query parameter, without token
+	authReceiverKey authMode = "receiver api key"
+)
+
+// contract is the request a client method must send and the result it must decode from a
+// canonical response
+type contract struct {
+	method string
+	call   func(ctx context.Context, c *Client) (any, error)
+
+	httpMethod string
+	path       string
+	query      url.Values
+	// body is the expected JSON body, empty for none. bodyIgnore lists the top-level fields
+	// left out of the comparison because they are not fixed, e.g. the current time.
+	body       string
+	bodyIgnore []string
+	auth       authMode
+
+	// fixture is the file in contractDir served as response, empty for an empty response
+	fixture string
+	want    any
+}
+
+var (
+	contractStart = time.UnixMilli(1700000000000).UTC()
+	contractEnd   = contractStart.Add(time.Hour)
+)
+
+func contracts() []contract {
+	serverInfo := ServerInfo{DeploymentMode: "SaaS"}
+	serverInfo.Version.Major = 7
+	serverInfo.Version.Minor = 1
+	serverInfo.Version.Patch = 3
+	serverInfo.Version.Commit = "abc123"
+
+	span := Span{
+		StartTime:     SpanTime{Timestamp: 1700000000000},
+		EndTime:       SpanTime{Timestamp: 1700000000250},
+		DurationNanos: 250000000,
+		TraceID:       "trace-1",
+		SpanID:        "span-1",
+		SpanName:      "GET /cart",
+		ServiceName:   "checkout",
+		StatusCode:    "Ok",
+	}
+	rootSpan := span
+	rootSpan.SpanKind = "Server"
+	detailedSpan := span
+	detailedSpan.SpanAttributes = Attributes{"http.method": "GET"}
+
+	vector := &MetricQueryResponse{Status: "success", Data: MetricData{ResultType: "vector", Result: []MetricResult{
+		{Labels: map[string]string{"job": "node"}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 1}}},
+	}}}
+	matrix := &MetricQueryResponse{Status: "success", Data: MetricData{ResultType: "matrix", Result: []MetricResult{
+		{Labels: map[string]string{"job": "node"}, Points: []MetricPoint{{Timestamp: 1700000000, Value: 1}, {Timestamp: 1700000060, Value: 0.5}}},
+	}}}
+
+	component := ViewComponent{ID: 1, Name: "checkout", Type: 10, Tags: []string{"team:shop"}}
+	component.State.HealthState = "CRITICAL"
+	snapshotBody := `{"_type":"ViewSnapshotRequest","metadata":{"_type":"QueryMetadata","showFullComponent":false,"groupingEnabled":false,
+		"showIndirectRelations":false,"minGroupSize":2,"groupedByLayer":false,"groupedByDomain":false,"groupedByRelation":false,
+		"showCause":"NONE","autoGrouping":false,"connectedComponents":false,"neighboringComponents":false},
+		"query":"name = \"checkout\"","queryVersion":"0.0.1"}`
+
+	nodes := &map[int64]NodeType{3: {TypeName: "Layer", ID: 3, Identifier: "urn:stackpack:common:layer:services", Name: "Services", Type: "Layer"}}
+	nodeContract := func(method, nodeType string, call func(c *Client) (*map[int64]NodeType, error)) contract {
+		return contract{
+			method: method,
+			call: func(_ context.Context, c *Client) (any, error) {
+				return call(c)
+			},
+			httpMethod: http.MethodGet, path: "/api/node/" + nodeType, auth: authToken,
+			fixture: "nodes.json", want: nodes,
+		}
+	}
+
+	topology := &TopoQueryResponse{Success: true, Data: []SyncComponent{
+		{Id: 1, Name: "checkout", Identifiers: []string{"urn:service:/checkout"}, Tags: []string{"team:shop"}},
+	}}
+
+	event := TopologyEvent{
+		Identifier:         "event-1",
+		ElementIdentifiers: []string{"urn:service:/checkout"},
+		Source:             "Kubernetes",
+		Category:           EventCategoryChanges,
+		Name:               "Deployment updated",
+		EventType:          "Deployment",
+		EventTime:          1700000000000,
+		ProcessedTime:      1700000001000,
+	}
+	taggedEvent := event
+	taggedEvent.Tags = []EventTag{{Key: "namespace", Value: "shop"}}
+
+	monitor := Monitor{
+		Id: 42, Name: "CPU throttling", Identifier: "urn:monitor:cpu", FunctionId: 7, IntervalSeconds: 60,
+		Source: "StackPack", Status: MonitorStatusEnabled, RuntimeStatus: MonitorRuntimeStatusEnabled,
+	}
+	taggedMonitor := monitor
+	taggedMonitor.Tags = []string{"cluster-name:prod"}
+
+	checkStates := []ViewCheckState{{CheckStateId: "cs-1", TopologyElementId: 5, TopologyElementIdType: "id", Name: "checkout", Health: "CRITICAL", Message: "CPU throttled"}}
+
+	intakeEvent := IntakeEvent{
+		Context:        IntakeEventContext{Category: "Activities", Data: map[string]any{}, ElementIdentifiers: []string{"urn:service:/checkout"}, Source: "mcp", SourceLinks: []any{}},
+		EventType:      "MCPAnnotation",
+		MsgTitle:       "Deployed",
+		MsgText:        "Deployed v2",
+		SourceTypeName: "MCPAnnotation",
+		Tags:           []string{"source:mcp"},
+		Timestamp:      1700000000,
+	}
+
+	return []contract{
+		{
+			method: "Status",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.Status(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/server/info", auth: authToken,
+			fixture: "server_info.json", want: &serverInfo,
+		},
+		{
+			method: "Ping",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				res, err := c.Ping(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return &res.Server, nil
+			},
+			httpMethod: http.MethodGet, path: "/api/server/info", auth: authToken,
+			fixture: "server_info.json", want: &serverInfo,
+		},
+		{
+			method: "CheckToken",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return nil, c.CheckToken(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/server/info", auth: authToken,
+			fixture: "server_info.json",
+		},
+		{
+			method: "CheckMetrics",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return nil, c.CheckMetrics(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/query", auth: authToken,
+			query:   url.Values{"query": {"vector(1)"}, "timeout": {"30000ms"}, "time": {anyValue}},
+			fixture: "query_vector.json",
+		},
+		{
+			method: "GetTrace",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetTrace(ctx, "trace-1")
+			},
+			httpMethod: http.MethodGet, path: "/api/traces/trace-1", auth: authToken,
+			fixture: "trace.json", want: &Trace{TraceID: "trace-1", Spans: []Span{rootSpan}},
+		},
+		{
+			method: "GetTraceSpan",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetTraceSpan(ctx, "trace-1", "span-1")
+			},
+			httpMethod: http.MethodGet, path: "/api/traces/trace-1/spans/span-1", auth: authToken,
+			fixture: "span.json", want: &detailedSpan,
+		},
+		{
+			method: "QueryTraces",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.QueryTraces(ctx, &TraceQueryRequest{
+					TraceQuery: TraceQuery{
+						SpanFilter: SpanFilter{ServiceName: []string{"checkout"}, StatusCode: []StatusCode{StatusError}, DurationFromNanos: 1000000},
+						SortBy:     []SortBy{{Field: SpanSortStartTime, Direction: SortDirectionDescending}},
+					},
+					Start:    contractStart,
+					End:      contractEnd,
+					Page:     1,
+					PageSize: 10,
+				})
+			},
+			httpMethod: http.MethodPost, path: "/api/traces/query", auth: authToken,
+			query: url.Values{"start": {"1700000000000"}, "end": {"1700003600000"}, "page": {"1"}, "pageSize": {"10"}},
+			body: `{"spanFilter":{"serviceName":["checkout"],"durationFromNanos":1000000,"statusCode":["error"]},"filter":{},
+				"sortBy":[{"field":"StartTime","direction":"Descending"}],"traceAttributes":null}`,
+			fixture: "trace_query.json",
+			want:    &TraceQueryResponse{Traces: []TraceRef{{TraceID: "trace-1", SpanID: "span-1"}}, PageSize: 10, Page: 1, MatchesTotal: 11},
+		},
+		{
+			method: "ListMetrics",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.ListMetrics(ctx, contractStart, contractEnd)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/label/__name__/values", auth: authToken,
+			query:   url.Values{"start": {"1700000000000"}, "end": {"1700003600000"}},
+			fixture: "metric_names.json", want: []string{"http_requests_total", "up"},
+		},
+		{
+			method: "GetMetricLabels",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMetricLabels(ctx, "up", contractStart, contractEnd)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/labels", auth: authToken,
+			query:   url.Values{"match[]": {"up"}, "start": {"1700000000000"}, "end": {"1700003600000"}},
+			fixture: "metric_names.json", want: []string{"http_requests_total", "up"},
+		},
+		{
+			method: "GetMetricLabelValues",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMetricLabelValues(ctx, "up", "job", contractStart, contractEnd)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/label/job/values", auth: authToken,
+			query:   url.Values{"match[]": {"up"}, "start": {"1700000000000"}, "end": {"1700003600000"}},
+			fixture: "label_values.json", want: []string{"apiserver", "node"},
+		},
+		{
+			method: "GetMetricSeries",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMetricSeries(ctx, "up", contractStart, contractEnd, 0)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/series", auth: authToken,
+			query:   url.Values{"match[]": {"up"}, "start": {"1700000000000"}, "end": {"1700003600000"}},
+			fixture: "metric_series.json", want: []map[string]string{{"__name__": "up", "job": "node", "instance": "a:9100"}},
+		},
+		{
+			method: "GetMetricSeries",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMetricSeries(ctx, "up", contractStart, contractEnd, 1000)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/series", auth: authToken,
+			query:   url.Values{"match[]": {"up"}, "start": {"1700000000000"}, "end": {"1700003600000"}, "limit": {"1000"}},
+			fixture: "metric_series.json", want: []map[string]string{{"__name__": "up", "job": "node", "instance": "a:9100"}},
+		},
+		{
+			method: "GetMetricMetadata",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMetricMetadata(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/metadata", auth: authToken,
+			fixture: "metric_metadata.json", want: map[string][]MetricMetadata{"up": {{Type: "gauge", Help: "Target is up."}}},
+		},
+		{
+			method: "QueryMetric",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.QueryMetric(ctx, `up{job="node"}`, contractStart, "")
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/query", auth: authToken,
+			query:   url.Values{"query": {`up{job="node"}`}, "timeout": {"30000ms"}, "time": {"1700000000000"}},
+			fixture: "query_vector.json", want: vector,
+		},
+		{
+			method: "QueryRangeMetric",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.QueryRangeMetric(ctx, "up", contractStart, contractEnd, "1m", "5s")
+			},
+			httpMethod: http.MethodGet, path: "/api/metrics/query_range", auth: authToken,
+			query:   url.Values{"query": {"up"}, "timeout": {"5s"}, "step": {"1m"}, "start": {"1700000000000"}, "end": {"1700003600000"}},
+			fixture: "query_matrix.json", want: matrix,
+		},
+		{
+			method: "ViewSnapshot",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.ViewSnapshot(ctx, NewViewSnapshotRequest(`name = "checkout"`))
+			},
+			httpMethod: http.MethodPost, path: "/api/snapshot", auth: authToken,
+			body:    snapshotBody,
+			fixture: "snapshot.json", want: &ViewSnapshotResponse{Success: true, Components: []ViewComponent{component}},
+		},
+		{
+			method: "SnapShotTopologyQuery",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.SnapShotTopologyQuery(ctx, `name = "checkout"`)
+			},
+			httpMethod: http.MethodPost, path: "/api/snapshot", auth: authToken,
+			body:    snapshotBody,
+			fixture: "snapshot.json", want: []ViewComponent{component},
+		},
+		{
+			method: "SnapShotTopologyQueryAt",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.SnapShotTopologyQueryAt(ctx, `name = "checkout"`, time.UnixMilli(1700000000000))
+			},
+			httpMethod: http.MethodPost, path: "/api/snapshot", auth: authToken,
+			body:    strings.Replace(snapshotBody, `"neighboringComponents":false}`, `"neighboringComponents":false,"queryTime":1700000000000}`, 1),
+			fixture: "snapshot.json", want: []ViewComponent{component},
+		},
+		{
+			method: "ValidateSTQL",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.ValidateSTQL(ctx, `name = "checkout"`)
+			},
+			httpMethod: http.MethodPost, path: "/api/snapshot", auth: authToken,
+			body:    strings.Replace(snapshotBody, `"query":"name = \"checkout\""`, `"query":"(name = \"checkout\") AND identifier = \"urn:suse-observability-mcp:stql-validation\""`, 1),
+			fixture: "snapshot_empty.json", want: &STQLValidation{Valid: true, CheckedQuery: `(name = "checkout") AND identifier = "urn:suse-observability-mcp:stql-validation"`},
+		},
+		nodeContract("Layers", "Layer", (*Client).Layers),
+		nodeContract("ComponentTypes", "ComponentType", (*Client).ComponentTypes),
+		nodeContract("RelationTypes", "RelationType", (*Client).RelationTypes),
+		nodeContract("Domains", "Domain", (*Client).Domains),
+		{
+			method: "ListViews",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.ListViews(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/node/QueryView", auth: authToken,
+			fixture: "views.json",
+			want: []QueryView{{
+				ID: 12, Identifier: "urn:stackpack:kubernetes:query-view:pods", Name: "Pods",
+				Description: "All pods of the cluster", Query: `type = "pod"`,
+			}},
+		},
+		{
+			method: "TopologyQuery",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.TopologyQuery(ctx, "name = 'checkout'", "1700000000000", true)
+			},
+			httpMethod: http.MethodPost, path: "/api/script", auth: authToken,
+			body:    `{"_type":"GroovyScript","body":"Topology.query('name = \"checkout\"').at('1700000000000').fullComponents()"}`,
+			fixture: "script.json", want: topology,
+		},
+		{
+			method: "TopologyStreamQuery",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.TopologyStreamQuery(ctx, `name = "checkout"`, "", true)
+			},
+			httpMethod: http.MethodPost, path: "/api/script", auth: authToken,
+			body:    `{"_type":"GroovyScript","body":"TopologyStream.query('name = \"checkout\"').withSynchronizationData()"}`,
+			fixture: "script.json", want: topology,
+		},
+		{
+			method: "GetEvents",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetEvents(ctx, &EventListRequest{
+					StartTimestampMs: 1700000000000,
+					EndTimestampMs:   1700003600000,
+					TopologyQuery:    `name = "checkout"`,
+					Limit:            10,
+					EventCategories:  []EventCategory{EventCategoryChanges},
+				})
+			},
+			httpMethod: http.MethodPost, path: "/api/events", auth: authToken,
+			body: `{"startTimestampMs":1700000000000,"endTimestampMs":1700003600000,"topologyQuery":"name = \"checkout\"",
+				"limit":10,"eventCategories":["Changes"]}`,
+			fixture: "events.json", want: &EventItemsWithTotal{Items: []TopologyEvent{taggedEvent}, Total: 1},
+		},
+		{
+			method: "GetEvent",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetEvent(ctx, "event-1", 1700000000000, 1700003600000)
+			},
+			httpMethod: http.MethodGet, path: "/api/events/event-1", auth: authToken,
+			query:   url.Values{"startTimestampMs": {"1700000000000"}, "endTimestampMs": {"1700003600000"}},
+			fixture: "event.json", want: &event,
+		},
+		{
+			method: "GetLogs",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetLogs(ctx, &LogListRequest{
+					StartTimestampMs: 1700000000000,
+					EndTimestampMs:   1700003600000,
+					TopologyQuery:    "id = 5",
+					Query:            "timeout",
+					Limit:            10,
+				})
+			},
+			httpMethod: http.MethodPost, path: "/api/logs", auth: authToken,
+			body:    `{"startTimestampMs":1700000000000,"endTimestampMs":1700003600000,"topologyQuery":"id = 5","query":"timeout","limit":10}`,
+			fixture: "logs.json",
+			want: &LogLinesWithTotal{Items: []LogLine{{
+				Timestamp: 1700000000000, Level: "ERROR", Message: "upstream timeout",
+				ComponentIdentifier: "urn:kubernetes:/prod:shop:pod/checkout-1", ContainerName: "checkout",
+			}}, Total: 1},
+		},
+		{
+			method: "GetMonitors",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMonitors(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/monitors", auth: authToken,
+			fixture: "monitors.json", want: &MonitorList{Monitors: []Monitor{taggedMonitor}},
+		},
+		{
+			method: "GetMonitor",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMonitor(ctx, "urn:monitor:cpu")
+			},
+			httpMethod: http.MethodGet, path: "/api/monitors/urn:monitor:cpu", auth: authToken,
+			fixture: "monitor.json", want: &monitor,
+		},
+		{
+			method: "GetMonitorsOverview",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMonitorsOverview(ctx)
+			},
+			httpMethod: http.MethodGet, path: "/api/monitors/overview", auth: authToken,
+			fixture: "monitors_overview.json",
+			want: &MonitorOverviewList{Monitors: []MonitorOverview{{
+				Monitor:        Monitor{Id: 42, Name: "CPU throttling", Status: MonitorStatusEnabled},
+				Function:       MonitorFunction{Id: 7, Name: "Threshold"},
+				RuntimeMetrics: MonitorRuntimeMetrics{CriticalCount: 2, ClearCount: 10},
+			}}},
+		},
+		{
+			method: "GetMonitorCheckStates",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMonitorCheckStates(ctx, "42", "CRITICAL", 50, 1700000000000)
+			},
+			httpMethod: http.MethodGet, path: "/api/monitors/42/checkStates", auth: authToken,
+			query:   url.Values{"healthState": {"CRITICAL"}, "limit": {"50"}, "timestamp": {"1700000000000"}},
+			fixture: "check_states.json", want: &MonitorCheckStates{States: checkStates},
+		},
+		{
+			method: "EachMonitorCheckStatePage",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				var states []ViewCheckState
+				err := c.EachMonitorCheckStatePage(ctx, "42", "CRITICAL", 50, 0, func(page []ViewCheckState) (bool, error) {
+					states = append(states, page...)
+					return true, nil
+				})
+				return states, err
+			},
+			httpMethod: http.MethodGet, path: "/api/monitors/42/checkStates", auth: authToken,
+			query:   url.Values{"healthState": {"CRITICAL"}, "limit": {"50"}},
+			fixture: "check_states.json", want: checkStates,
+		},
+		{
+			method: "GetMonitorCheckStatus",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetMonitorCheckStatus(ctx, 7, 1700000000000)
+			},
+			httpMethod: http.MethodGet, path: "/api/monitor/checkStatus/7", auth: authToken,
+			query:   url.Values{"topologyTime": {"1700000000000"}},
+			fixture: "check_status.json",
+			want: &MonitorCheckStatus{
+				Id: 7, CheckStateId: "cs-1", Message: "CPU throttled", Health: "CRITICAL", TriggeredTimestamp: 1700000000000,
+				Metrics:     []MonitorCheckStatusMetric{},
+				Component:   MonitorCheckStatusComponent{Id: 5, Identifier: "urn:service:/checkout", Name: "checkout", Type: "service"},
+				MonitorId:   float64(42),
+				MonitorName: "CPU throttling", TopologyTime: 1700000000000,
+			},
+		},
+		{
+			method: "GetBoundMetricsWithData",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetBoundMetricsWithData(ctx, 5, contractStart, contractEnd)
+			},
+			httpMethod: http.MethodGet, path: "/api/components/5/boundMetricsWithData", auth: authToken,
+			query:   url.Values{"startSeconds": {"1700000000"}, "endSeconds": {"1700003600"}},
+			fixture: "bound_metrics.json",
+			want: &BoundMetricsResponse{Type: "BoundMetrics", BoundMetrics: []BoundMetric{{
+				Type: "BoundMetric", Name: "CPU usage", Unit: "short",
+				BoundQueries: []BoundQuery{{Expression: "sum(rate(cpu[1m]))", Alias: "cpu"}},
+			}}},
+		},
+		{
+			method: "GetComponent",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return c.GetComponent(ctx, 5)
+			},
+			httpMethod: http.MethodGet, path: "/api/components/5", auth: authToken,
+			fixture: "component.json",
+			want: &ComponentResponse{
+				Node:         ComponentNode{ID: 5, Name: "checkout", SyncedCheckStates: []map[string]any{{"health": "CRITICAL"}}},
+				InternalType: "ComponentViewResponse",
+			},
+		},
+		{
+			method: "PostEvent",
+			call: func(ctx context.Context, c *Client) (any, error) {
+				return nil, c.PostEvent(ctx, intakeEvent)
+			},
+			httpMethod: http.MethodPost, path: "/receiver/stsAgent/intake", auth: authReceiverKey,
+			query: url.Values{"api_key": {"receiver-key"}},
+			body: `{"internalHostname":"suse-observability-mcp","events":{"MCPAnnotation":[{"context":{"category":"Activities","data":{},
+				"element_identifiers":["urn:service:/checkout"],"source":"mcp","source_links":[]},"event_type":"MCPAnnotation",
+				"msg_title":"Deployed","msg_text":"Deployed v2","source_type_name":"MCPAnnotation","tags":["source:mcp"],"timestamp":1700000000}]},
+				"metrics":[],"service_checks":[],"health":[],"topologies":[]}`,
+			bodyIgnore: []string{"collection_timestamp"},
+		},
+	}
+}
+
+// TestClientContracts checks every client method against its contract, with both token types
+func TestClientContracts(t *testing.T) {
+	for _, c := range contracts() {
+		for _, apiToken := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/apitoken=%t", c.method, apiToken), func(t *testing.T) {
+				checkContract(t, c, apiToken)
+			})
+		}
+	}
+}
+
+func checkContract(t *testing.T, c contract, apiToken bool) {
+	var response []byte
+	if c.fixture != "" {
+		var err error
+		response, err = os.ReadFile(filepath.Join(contractDir, c.fixture))
+		require.NoError(t, err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, c.httpMethod, r.Method)
+		assert.Equal(t, c.path, r.URL.Path)
+		assertQuery(t, c.query, r.URL.Query())
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		tokenHeader, otherHeader := "X-API-Key", "X-API-Token"
+		if apiToken {
+			tokenHeader, otherHeader = otherHeader, tokenHeader
+		}
+		switch c.auth {
+		case authToken:
+			assert.Equal(t, "token", r.Header.Get(tokenHeader))
+			assert.Empty(t, r.Header.Get(otherHeader))
+		case authReceiverKey:
+			assert.Empty(t, r.Header.Get("X-API-Key"))
+			assert.Empty(t, r.Header.Get("X-API-Token"))
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assertBody(t, c, body)
+
+		if response == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(response)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "token", apiToken, 0)
+	require.NoError(t, err)
+	client.WithReceiverAPIKey("receiver-key")
+
+	got, err := c.call(context.Background(), client)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "number of requests sent")
+	if c.want != nil {
+		assert.Equal(t, c.want, got)
+	}
+}
+
+// assertQuery compares the query parameters, anyValue only requires the parameter to be set
+func assertQuery(t *testing.T, want, got url.Values) {
+	t.Helper()
+	if len(want) == 0 {
+		assert.Empty(t, got, "query parameters")
+		return
+	}
+	got = cloneValues(got)
+	for key, values := range want {
+		if len(values) == 1 && values[0] == anyValue {
+			assert.NotEmpty(t, got.Get(key), "query parameter %s", key)
+			got[key] = values
+		}
+	}
+	assert.Equal(t, want, got)
+}
+
+// cloneValues returns a copy of the query parameters
+func cloneValues(v url.Values) url.Values {
+	c := make(url.Values, len(v))
+	for k, values := range v {
+		c[k] = append([]string(nil), values...)
+	}
+	return c
+}
+
+// assertBody compares the JSON body without the ignored fields
+func assertBody(t *testing.T, c contract, body []byte) {
+	t.Helper()
+	if c.body == "" {
+		assert.Empty(t, body, "request body")
+		return
+	}
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(body, &fields), "request body %s", body)
+	for _, key := range c.bodyIgnore {
+		assert.Contains(t, fields, key)
+		delete(fields, key)
+	}
+	got, err := json.Marshal(fields)
+	require.NoError(t, err)
+	assert.JSONEq(t, c.body, string(got))
+}
+
+// TestContractCoverage fails when a client method sending requests has no contract, and
+// keeps the summary of the covered API surface in testdata/contract/surface.md current.
+// Run with -update after changing a contract.
+func TestContractCoverage(t *testing.T) {
+	covered := map[string]bool{}
+	for _, c := range contracts() {
+		covered[c.method] = true
+	}
+
+	clientType := reflect.TypeOf(&Client{})
+	var missing []string
+	for i := range clientType.NumMethod() {
+		name := clientType.Method(i).Name
+		if !covered[name] && !nonRequestMethods[name] {
+			missing = append(missing, name)
+		}
+		delete(covered, name)
+	}
+	assert.Empty(t, missing, "client methods without a contract test")
+	assert.Empty(t, covered, "contracts of methods the client does not have")
+
+	path := filepath.Join(contractDir, "surface.md")
+	surface := contractSurface(contracts())
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(surface), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), surface, "the covered surface changed, run go test ./client/... -update")
+}
+
+// contractSurface renders the contracts as a markdown table, one row per method in name order
+func contractSurface(contracts []contract) string {
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].method < contracts[j].method
+	})
+
+	var sb strings.Builder
+	sb.WriteString("# Covered API surface\n\n")
+	sb.WriteString("Generated by TestContractCoverage from the contracts in contract_test.go, do not edit.\n\n")
+	sb.WriteString("| Client method | Request | Query parameters | Body | Auth |\n")
+	sb.WriteString("|---|---|---|---|---|\n")
+	for _, c := range contracts {
+		params := make([]string, 0, len(c.query))
+		for key := range c.query {
+			params = append(params, key)
+		}
+		sort.Strings(params)
+		query := "-"
+		if len(params) > 0 {
+			query = strings.Join(params, ", ")
+		}
+		body := "-"
+		if c.body != "" {
+			body = "JSON"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s %s | %s | %s | %s |\n", c.method, c.httpMethod, c.path, query, body, c.auth))
+	}
+	return sb.String()
+}
@@ -0,0 +1,184 @@
+package suseobservability
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	rq "github.com/carlmjohnson/requests"
+)
+
+// TransportErrorKind is the category of a request that failed before a response was received
+type TransportErrorKind string
+
+const (
+	TransportDNS        TransportErrorKind = "DNS"
+	TransportTLS        TransportErrorKind = "TLS"
+	TransportConnection TransportErrorKind = "connection"
+	TransportTimeout    TransportErrorKind = "timeout"
+	TransportProxy      TransportErrorKind = "proxy"
+	// TransportCanceled is a request abandoned by its caller, e.g. a cancelled tool call,
+	// rather than a problem of the server or the network
+	TransportCanceled TransportErrorKind = "cancellation"
+)
+
+// transportHints tell the user how to act on each kind of transport error
+var transportHints = map[TransportErrorKind]string{
+	TransportDNS:        "the host name could not be resolved, check -url and -metrics-url for typos and that DNS works from this machine",
+	TransportTLS:        "the TLS handshake failed, check that -url uses https:// only when the server serves TLS on that port, and that no proxy or load balancer in between rejects or downgrades the connection",
+	TransportConnection: "nothing accepts connections on that host and port, check the port of -url and that SUSE Observability is running and reachable",
+	TransportTimeout:    "the server did not answer in time, raise -request-timeout or narrow the request, and check the network path to -url",
+	TransportProxy:      "the connection through the proxy failed, check HTTPS_PROXY, HTTP_PROXY and NO_PROXY",
+	TransportCanceled:   "the caller gave up on the request, its context was cancelled or its deadline passed before SUSE Observability answered",
+}
+
+// TransportError is a request that failed before a response was received, with a hint
+// on how to remediate it
+type TransportError struct {
+	Kind TransportErrorKind
+	Hint string
+	Err  error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%v (%s problem: %s)", e.Err, e.Kind, e.Hint)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyTransportError returns the kind of a transport level error of a request made with
+// ctx, false when err is not one. A request whose ctx is done failed because the caller gave
+// up, whatever err says, and is reported as TransportCanceled.
+func ClassifyTransportError(ctx context.Context, err error) (TransportErrorKind, bool) {
+	var (
+		opErr     *net.OpError
+		dnsErr    *net.DNSError
+		recordErr tls.RecordHeaderError
+		alertErr  tls.AlertError
+		netErr    net.Error
+	)
+	switch {
+	case err == nil:
+		return "", false
+	case ctx.Err() != nil:
+		return TransportCanceled, true
+	case errors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		return TransportProxy, true
+	case errors.As(err, &dnsErr):
+		return TransportDNS, true
+	// Certificates are not verified, so only handshake and protocol failures occur
+	case errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return TransportTLS, true
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return TransportConnection, true
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return TransportTimeout, true
+	}
+	return "", false
+}
+
+// errRequestTimeout is the cause of the context of a request exceeding the client timeout
+var errRequestTimeout = errors.New("request timeout exceeded")
+
+// classifyingTransport wraps the transport level errors of its base in a TransportError,
+// so tool errors and the -check output carry a remediation hint, and logs every request.
+// It bounds every request by the client timeout, including reading the response body, so
+// that a timeout is told apart from the caller giving up.
+type classifyingTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t classifyingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	caller := req.Context()
+	ctx, cancel := context.WithTimeoutCause(caller, t.timeout, errRequestTimeout)
+	start := time.Now()
+	res, err := t.base.RoundTrip(req.WithContext(ctx))
+	logRequest(req, res, err, time.Since(start))
+	if err != nil {
+		timedOut := errors.Is(context.Cause(ctx), errRequestTimeout)
+		cancel()
+		kind, ok := ClassifyTransportError(caller, err)
+		if timedOut && caller.Err() == nil {
+			kind, ok, err = TransportTimeout, true, fmt.Errorf("%w after %s: %w", errRequestTimeout, t.timeout, err)
+		}
+		if ok {
+			return nil, &TransportError{Kind: kind, Hint: transportHints[kind], Err: err}
+		}
+		return nil, err
+	}
+	res.Body = cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// cancelOnClose releases the timeout of a request once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// logRequest logs an API request at debug level. Only the method, the path and the PromQL
+// query are logged of the request: the credentials are in its headers and in the api_key
+// parameter of the receiver intake.
+func logRequest(req *http.Request, res *http.Response, err error, duration time.Duration) {
+	ctx := req.Context()
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{"method", req.Method, "path", req.URL.Path}
+	if query := req.URL.Query().Get("query"); query != "" {
+		attrs = append(attrs, "query", query)
+	}
+	attrs = append(attrs, "duration", duration)
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	} else {
+		attrs = append(attrs, "status", res.StatusCode)
+	}
+	slog.DebugContext(ctx, "API request", attrs...)
+}
+
+// RateLimitError is a request SUSE Observability rejected with status 429, once retries are
+// exhausted. RetryAfter is the wait the server asked for in its Retry-After header, zero when
+// it did not send one.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		seconds := (e.RetryAfter + time.Second - 1) / time.Second
+		return fmt.Sprintf("rate limited by SUSE Observability, retry in %ds", seconds)
+	}
+	return "rate limited by SUSE Observability, retry later"
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// rateLimited returns err as a RateLimitError when it is a response with status 429, else
+// err unchanged
+func rateLimited(err error) error {
+	var se *rq.ResponseError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+	after, _ := retryAfter(se.Header.Get("Retry-After"), time.Now())
+	return &RateLimitError{RetryAfter: after, Err: err}
+}
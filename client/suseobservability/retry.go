@@ -0,0 +1,125 @@
+package suseobservability
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	rq "github.com/carlmjohnson/requests"
+)
+
+// RetryPolicy configures the retries of transient metric query failures
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt, zero disables them
+	Retries int
+	// BaseDelay is the delay before the first retry, doubled for every following one
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff before a retry. A request whose Retry-After asks for a longer
+	// wait is not retried.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a client created by NewClient
+var DefaultRetryPolicy = RetryPolicy{Retries: 2, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+// WithRetryPolicy sets how transient failures of metric queries are retried
+func (c *Client) WithRetryPolicy(policy RetryPolicy) *Client {
+	c.retry = policy
+	return c
+}
+
+// fetchWithRetry fetches a GET request, retrying it while it fails with a transient error:
+// status 429 or 5xx, or a connection that could not be made or was reset. Requests with
+// another method are not idempotent and fetched once. A request still rate limited when
+// it is not retried anymore fails with a RateLimitError.
+func (c Client) fetchWithRetry(ctx context.Context, b *rq.Builder) error {
+	req, err := b.Request(ctx)
+	if err != nil {
+		return err
+	}
+	if req.Method != http.MethodGet {
+		return b.Do(req)
+	}
+	return rateLimited(c.doWithRetry(ctx, b, req))
+}
+
+// doWithRetry does the GET request req of b, retrying it as described by fetchWithRetry
+func (c Client) doWithRetry(ctx context.Context, b *rq.Builder, req *http.Request) error {
+	for retry := 0; ; retry++ {
+		err := b.Do(req)
+		if err == nil {
+			if retry > 0 {
+				slog.DebugContext(ctx, "API request succeeded after retries", "path", req.URL.Path, "retries", retry)
+			}
+			return nil
+		}
+		if retry >= c.retry.Retries || !isTransient(err) {
+			if retry > 0 {
+				slog.DebugContext(ctx, "API request failed after retries", "path", req.URL.Path, "retries", retry, "error", err)
+			}
+			return err
+		}
+		delay, ok := c.retry.delay(retry, err)
+		if !ok {
+			slog.DebugContext(ctx, "API request not retried, Retry-After is longer than the maximum delay", "path", req.URL.Path, "error", err)
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		slog.DebugContext(ctx, "Retrying API request", "path", req.URL.Path, "retry", retry+1, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isTransient reports whether a failed request may succeed when retried. Timeouts are not
+// retried: a query that timed out likely does so again, multiplying the wait.
+func isTransient(err error) bool {
+	var se *rq.ResponseError
+	if errors.As(err, &se) {
+		return se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= 500
+	}
+	var te *TransportError
+	return errors.As(err, &te) && te.Kind == TransportConnection
+}
+
+// delay returns how long to wait before retry number retry+1: the Retry-After of the failed
+// response, else the exponentially growing base delay with jitter, at most MaxDelay. It
+// reports false when the Retry-After is longer than MaxDelay: the wait asked for is honored
+// in full or not at all.
+func (p RetryPolicy) delay(retry int, err error) (time.Duration, bool) {
+	var se *rq.ResponseError
+	if errors.As(err, &se) {
+		if after, ok := retryAfter(se.Header.Get("Retry-After"), time.Now()); ok {
+			return after, after <= p.MaxDelay
+		}
+	}
+	backoff := min(p.BaseDelay<<retry, p.MaxDelay)
+	if backoff <= 0 {
+		return 0, true
+	}
+	// Half of the backoff is random, so clients failing together do not retry together
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
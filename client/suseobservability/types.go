@@ -2,6 +2,8 @@ package suseobservability
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +14,7 @@ import (
 type ServerInfo struct {
 	Version struct {
 		Major  int    `json:"major"`
+		Minor  int    `json:"minor"`
 		Patch  int    `json:"patch"`
 		Diff   string `json:"diff"`
 		Commit string `json:"commit"`
@@ -20,6 +23,24 @@ type ServerInfo struct {
 	DeploymentMode string `json:"deploymentMode"`
 }
 
+// VersionString renders the server version as major.minor.patch, with the diff appended
+// for builds between releases
+func (s ServerInfo) VersionString() string {
+	v := fmt.Sprintf("%d.%d.%d", s.Version.Major, s.Version.Minor, s.Version.Patch)
+	if s.Version.Diff != "" {
+		v += "-" + s.Version.Diff
+	}
+	return v
+}
+
+// PingResult is the outcome of a successful Ping
+type PingResult struct {
+	Server ServerInfo
+	// TokenType is "api token" or "service token", the header the token is sent in
+	TokenType string
+	Latency   time.Duration
+}
+
 type SyncComponent struct {
 	Id                  int                      `json:"id"`
 	Identifiers         []string                 `json:"identifiers"`
@@ -86,80 +107,122 @@ type MetricData struct {
 	Result     []MetricResult `json:"result"`
 }
 
+// UnmarshalJSON decodes the result of every result type into series of points: a matrix as
+// series with their points, a vector as series with a single point, and a scalar or string as
+// one series without labels and with a single point. The point of a string is NaN, its text
+// is kept in String.
 func (m *MetricData) UnmarshalJSON(data []byte) error {
-	mapFromArray := func(m *MetricPoint, data []any) error {
-		// Assign array values to the struct fields
-		var err error
-		m.Timestamp = int64(data[0].(float64))
-		m.Value, err = strconv.ParseFloat(strings.TrimSpace(data[1].(string)), 64)
-		if err != nil {
-			return err
-		}
-		return nil
+	var raw struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
 	}
-
-	var dataMap map[string]any
-	if err := json.Unmarshal(data, &dataMap); err != nil {
+	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
 	}
-	m.ResultType = dataMap["resultType"].(string)
-
-	resultList := dataMap["result"].([]any)
-	if m.ResultType == "scalar" || m.ResultType == "string" {
-		mr := MetricResult{
-			Labels: make(map[string]string),
-			Points: make([]MetricPoint, 0, 1),
-		}
-		mr.Points = append(mr.Points, MetricPoint{})
-		err := mapFromArray(&mr.Points[0], resultList)
-		if err != nil {
-			return err
-		}
-		m.Result = []MetricResult{mr}
+	m.ResultType = raw.ResultType
+	m.Result = []MetricResult{}
+	if len(raw.Result) == 0 || string(raw.Result) == "null" {
 		return nil
 	}
 
-	valueKey := "value"
-	if m.ResultType == "matrix" {
-		valueKey = "values"
-	}
-	m.Result = make([]MetricResult, 0, len(resultList))
-	for _, item := range resultList {
-		resultMap := item.(map[string]any)
-		labels := resultMap["metric"].(map[string]any)
-		mr := MetricResult{
-			Labels: make(map[string]string, len(labels)),
-			Points: make([]MetricPoint, 0, len(resultMap[valueKey].([]any))),
+	switch raw.ResultType {
+	case "matrix", "vector":
+		var series []struct {
+			Metric map[string]string   `json:"metric"`
+			Value  []json.RawMessage   `json:"value"`
+			Values [][]json.RawMessage `json:"values"`
 		}
-
-		for k, v := range labels {
-			mr.Labels[k] = v.(string)
+		if err := json.Unmarshal(raw.Result, &series); err != nil {
+			return fmt.Errorf("invalid %s result: %w", raw.ResultType, err)
 		}
-
-		if m.ResultType == "matrix" {
-			for x, point := range resultMap["values"].([]any) {
-				mr.Points = append(mr.Points, MetricPoint{})
-				err := mapFromArray(&mr.Points[x], point.([]any))
+		m.Result = make([]MetricResult, 0, len(series))
+		for _, s := range series {
+			samples := s.Values
+			// a backend may answer with either key whatever the result type
+			if len(samples) == 0 && len(s.Value) > 0 {
+				samples = [][]json.RawMessage{s.Value}
+			}
+			mr := MetricResult{Labels: s.Metric, Points: make([]MetricPoint, 0, len(samples))}
+			if mr.Labels == nil {
+				mr.Labels = map[string]string{}
+			}
+			for _, sample := range samples {
+				point, err := decodeSample(sample)
 				if err != nil {
-					return err
+					return fmt.Errorf("invalid %s result: %w", raw.ResultType, err)
 				}
+				mr.Points = append(mr.Points, point)
 			}
-		} else {
-			mr.Points = append(mr.Points, MetricPoint{})
-			err := mapFromArray(&mr.Points[0], resultMap["value"].([]any))
-			if err != nil {
-				return err
-			}
+			m.Result = append(m.Result, mr)
+		}
+	case "scalar":
+		var sample []json.RawMessage
+		if err := json.Unmarshal(raw.Result, &sample); err != nil {
+			return fmt.Errorf("invalid scalar result: %w", err)
+		}
+		point, err := decodeSample(sample)
+		if err != nil {
+			return fmt.Errorf("invalid scalar result: %w", err)
+		}
+		m.Result = []MetricResult{{Labels: map[string]string{}, Points: []MetricPoint{point}}}
+	case "string":
+		var sample []json.RawMessage
+		if err := json.Unmarshal(raw.Result, &sample); err != nil {
+			return fmt.Errorf("invalid string result: %w", err)
+		}
+		timestamp, text, err := decodeSampleText(sample)
+		if err != nil {
+			return fmt.Errorf("invalid string result: %w", err)
 		}
-		m.Result = append(m.Result, mr)
+		m.Result = []MetricResult{{Labels: map[string]string{}, Points: []MetricPoint{{Timestamp: timestamp, Value: math.NaN()}}, String: text}}
+	default:
+		return fmt.Errorf("unsupported result type %q", raw.ResultType)
 	}
-
 	return nil
 }
 
+// decodeSample decodes a [timestamp, "value"] sample of a number
+func decodeSample(sample []json.RawMessage) (MetricPoint, error) {
+	timestamp, text, err := decodeSampleText(sample)
+	if err != nil {
+		return MetricPoint{}, err
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return MetricPoint{}, fmt.Errorf("invalid sample value %q: %w", text, err)
+	}
+	return MetricPoint{Timestamp: timestamp, Value: value}, nil
+}
+
+// decodeSampleText decodes a [timestamp, "value"] sample without interpreting the value. The
+// timestamp is in seconds, fractions of a second are dropped.
+func decodeSampleText(sample []json.RawMessage) (int64, string, error) {
+	if len(sample) != 2 {
+		return 0, "", fmt.Errorf("sample must be [timestamp, value], got %d elements", len(sample))
+	}
+	var timestamp float64
+	if err := json.Unmarshal(sample[0], &timestamp); err != nil {
+		return 0, "", fmt.Errorf("invalid sample timestamp %s: %w", sample[0], err)
+	}
+	var text string
+	if err := json.Unmarshal(sample[1], &text); err != nil {
+		return 0, "", fmt.Errorf("invalid sample value %s: %w", sample[1], err)
+	}
+	return int64(timestamp), text, nil
+}
+
 type MetricResult struct {
 	Labels map[string]string `json:"metric"`
 	Points []MetricPoint     `json:"values"`
+	// String is the text of a string result, whose point value is NaN
+	String string `json:"string,omitempty"`
+}
+
+// MetricMetadata describes a metric as reported by its exporter
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
 }
 
 type MetricPoint struct {
@@ -201,6 +264,15 @@ type ViewSnapshotResponse struct {
 	Errors     []*ErrorMsg `json:"errors"`
 }
 
+// STQLValidation is the outcome of validating an STQL query
+type STQLValidation struct {
+	Valid bool `json:"valid"`
+	// Errors are the messages of the query parser, empty when the query is valid
+	Errors []string `json:"errors,omitempty"`
+	// CheckedQuery is the query sent to check the syntax, restricted to match no component
+	CheckedQuery string `json:"checked_query"`
+}
+
 type ViewComponent struct {
 	ID                  int64   `json:"id"`
 	Name                string  `json:"name"`
@@ -264,6 +336,16 @@ func NewViewSnapshotRequest(query string) *ViewSnapshotRequest {
 	}
 }
 
+// QueryView is a saved topology view: an STQL query stored under a name, which the
+// SUSE Observability UI lists as a view
+type QueryView struct {
+	ID          int64  `json:"id"`
+	Identifier  string `json:"identifier"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
 type NodeType struct {
 	TypeName            string `json:"typeName"`
 	ID                  int64  `json:"id"`
@@ -362,6 +444,9 @@ type TraceQueryResponse struct {
 	PageSize     int        `json:"pageSize"`
 	Page         int        `json:"page"`
 	MatchesTotal int        `json:"matchesTotal"`
+	// TotalPages and HasMore are only reported by some versions, nil when missing
+	TotalPages *int  `json:"totalPages,omitempty"`
+	HasMore    *bool `json:"hasMore,omitempty"`
 }
 
 // Legacy Api for Trace Query
@@ -503,6 +588,31 @@ type EventListRequest struct {
 	Cursor                     *EventCursor    `json:"cursor,omitempty"`
 }
 
+// Logs API Types
+
+type LogLine struct {
+	Timestamp           int64  `json:"timestamp"`
+	Level               string `json:"level,omitempty"`
+	Message             string `json:"message"`
+	ComponentIdentifier string `json:"componentIdentifier,omitempty"`
+	ContainerName       string `json:"containerName,omitempty"`
+}
+
+type LogLinesWithTotal struct {
+	Items []LogLine `json:"items"`
+	Total int64     `json:"total"`
+}
+
+// LogListRequest selects the log lines of the components matching TopologyQuery, newest first.
+// Query only keeps lines containing the text, case-insensitively.
+type LogListRequest struct {
+	StartTimestampMs int64  `json:"startTimestampMs"`
+	EndTimestampMs   int64  `json:"endTimestampMs"`
+	TopologyQuery    string `json:"topologyQuery"`
+	Query            string `json:"query,omitempty"`
+	Limit            int    `json:"limit"`
+}
+
 // Monitor API Types
 
 type MonitorIdOrUrn string
@@ -683,3 +793,33 @@ type ComponentNode struct {
 	Name              string                   `json:"name"`
 	SyncedCheckStates []map[string]interface{} `json:"syncedCheckStates"`
 }
+
+// IntakeEvent is a custom event sent to the receiver intake
+type IntakeEvent struct {
+	Context        IntakeEventContext `json:"context"`
+	EventType      string             `json:"event_type"`
+	MsgTitle       string             `json:"msg_title"`
+	MsgText        string             `json:"msg_text"`
+	SourceTypeName string             `json:"source_type_name"`
+	Tags           []string           `json:"tags"`
+	Timestamp      int64              `json:"timestamp"`
+}
+
+type IntakeEventContext struct {
+	Category           string         `json:"category"`
+	Data               map[string]any `json:"data"`
+	ElementIdentifiers []string       `json:"element_identifiers"`
+	Source             string         `json:"source"`
+	SourceLinks        []any          `json:"source_links"`
+}
+
+// intakePayload is the agent intake envelope, only carrying events
+type intakePayload struct {
+	CollectionTimestamp int64                    `json:"collection_timestamp"`
+	InternalHostname    string                   `json:"internalHostname"`
+	Events              map[string][]IntakeEvent `json:"events"`
+	Metrics             []any                    `json:"metrics"`
+	ServiceChecks       []any                    `json:"service_checks"`
+	Health              []any                    `json:"health"`
+	Topologies          []any                    `json:"topologies"`
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// checker is the part of the client probed by -check
+type checker interface {
+	CheckToken(ctx context.Context) error
+	CheckMetrics(ctx context.Context) error
+}
+
+// runCheck probes the main and the metrics API with the configured token and reports the
+// outcome of each probe to w. It returns whether all probes succeeded.
+func runCheck(ctx context.Context, w io.Writer, c checker) bool {
+	probes := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"SUSE Observability API", c.CheckToken},
+		{"metrics API", c.CheckMetrics},
+	}
+	ok := true
+	for _, p := range probes {
+		if err := p.check(ctx); err != nil {
+			fmt.Fprintf(w, "%s: failed: %v\n", p.name, err)
+			ok = false
+			continue
+		}
+		fmt.Fprintf(w, "%s: ok\n", p.name)
+	}
+	return ok
+}
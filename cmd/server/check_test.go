@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeChecker struct {
+	tokenErr   error
+	metricsErr error
+}
+
+func (f fakeChecker) CheckToken(ctx context.Context) error   { return f.tokenErr }
+func (f fakeChecker) CheckMetrics(ctx context.Context) error { return f.metricsErr }
+
+func TestRunCheck(t *testing.T) {
+	t.Run("all probes succeed", func(t *testing.T) {
+		var out bytes.Buffer
+
+		ok := runCheck(context.Background(), &out, fakeChecker{})
+
+		assert.True(t, ok)
+		assert.Equal(t, "SUSE Observability API: ok\nmetrics API: ok\n", out.String())
+	})
+
+	t.Run("metrics API unreachable", func(t *testing.T) {
+		var out bytes.Buffer
+
+		ok := runCheck(context.Background(), &out, fakeChecker{metricsErr: errors.New("404 Not Found")})
+
+		assert.False(t, ok)
+		assert.Equal(t, "SUSE Observability API: ok\nmetrics API: failed: 404 Not Found\n", out.String())
+	})
+
+	t.Run("transport errors carry their hint", func(t *testing.T) {
+		var out bytes.Buffer
+		dnsErr := &suseobservability.TransportError{
+			Kind: suseobservability.TransportDNS,
+			Hint: "check -url",
+			Err:  &net.DNSError{Err: "no such host", Name: "observabilty.example.com"},
+		}
+
+		ok := runCheck(context.Background(), &out, fakeChecker{tokenErr: dnsErr, metricsErr: dnsErr})
+
+		assert.False(t, ok)
+		assert.Contains(t, out.String(), "SUSE Observability API: failed: lookup observabilty.example.com: no such host (DNS problem: check -url)\n")
+	})
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+	"suse-observability-mcp/internal/diskcache"
+	"suse-observability-mcp/internal/tools"
+)
+
+// config holds the parsed command line flags. Credentials live in Secrets only, so the
+// rest of the configuration can be reported without leaking them.
+type config struct {
+	URL        string
+	MetricsURL string // empty to use URL
+	// TokenFile is the file the token is read from when -token is not set
+	TokenFile string
+	// TokenSource tells where the token was read from, set by resolveCredentials
+	TokenSource    string
+	UseAPIToken    bool
+	RequestTimeout time.Duration
+	// Retries configures the retries of transient metric query failures
+	Retries suseobservability.RetryPolicy
+	// TokenExpiresAt is the expiry of the token, zero when unknown
+	TokenExpiresAt     time.Time
+	TokenExpiryWarning time.Duration
+	TokenCheckInterval time.Duration
+	ListenAddr         string
+	// SocketActivation serves the http transport on the socket passed by systemd
+	SocketActivation bool
+	// HTTPLog logs the requests of the http transport
+	HTTPLog bool
+	// TLSCert and TLSKey are the PEM files the http transport serves HTTPS with, empty for
+	// plain HTTP
+	TLSCert string
+	TLSKey  string
+	Check   bool
+	// RunTool is the tool run once by -run-tool, with the JSON object RunParams as arguments
+	RunTool          string
+	RunParams        string
+	RunFormat        string
+	Limits           tools.Limits
+	NamespaceQueries tools.NamespaceQueries
+	// ComponentDisplayName is validated when the flag is parsed
+	ComponentDisplayName tools.DisplayName
+	// SavedQueries are registered as tools of their own, validated when the flag is parsed
+	SavedQueries     []tools.SavedQuery
+	EnableWriteTools bool
+	LogLevel         slog.Level
+	// CacheDir holds the disk cache of discovery data, empty to disable it
+	CacheDir   string
+	CacheClear bool
+	// MetricNamesTTL is how long the client reuses fetched metric names in memory
+	MetricNamesTTL time.Duration
+
+	Secrets secrets
+}
+
+// Output formats of -run-tool
+const (
+	runFormatText = "text"
+	runFormatJSON = "json"
+)
+
+// secrets holds the credentials passed on the command line, or read from a file or the
+// environment for the token
+type secrets struct {
+	Token          string
+	ReceiverAPIKey string
+	// AuthToken is the bearer token clients of the http transport must send, empty to
+	// accept every client
+	AuthToken string
+}
+
+// Environment variables read when the matching flags are not set, keeping the token out of
+// the shell history and process listings
+const (
+	envURL   = "SUSE_OBS_URL"
+	envToken = "SUSE_OBS_TOKEN"
+)
+
+// Sources of the token
+const (
+	tokenFromFlag = "flag"
+	tokenFromFile = "file"
+	tokenFromEnv  = "environment"
+)
+
+// resolveCredentials fills in the URL and token not passed as flags. The token is taken from
+// -token, else read from -token-file without surrounding whitespace such as a trailing
+// newline, else taken from SUSE_OBS_TOKEN. The URL is taken from -url, else SUSE_OBS_URL.
+func resolveCredentials(cfg config, getenv func(string) string, readFile func(string) ([]byte, error)) (config, error) {
+	if cfg.URL == "" {
+		cfg.URL = getenv(envURL)
+	}
+	switch {
+	case cfg.Secrets.Token != "":
+		cfg.TokenSource = tokenFromFlag
+	case cfg.TokenFile != "":
+		data, err := readFile(cfg.TokenFile)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read -token-file: %w", err)
+		}
+		cfg.Secrets.Token = strings.TrimSpace(string(data))
+		if cfg.Secrets.Token == "" {
+			return cfg, fmt.Errorf("-token-file %s is empty", cfg.TokenFile)
+		}
+		cfg.TokenSource = tokenFromFile
+	case getenv(envToken) != "":
+		cfg.Secrets.Token = getenv(envToken)
+		cfg.TokenSource = tokenFromEnv
+	}
+	return cfg, nil
+}
+
+// serverConfig returns the non-secret configuration reported by getServerConfig.
+// Only the host of the URL is kept, as the URL could carry credentials.
+func (cfg config) serverConfig(enabledTools []string) tools.ServerConfig {
+	transport := "stdio"
+	if cfg.ListenAddr != "" || cfg.SocketActivation {
+		transport = "http"
+	}
+	tokenType := "service token"
+	if cfg.UseAPIToken {
+		tokenType = "api token"
+	}
+	metricsHost := ""
+	if cfg.MetricsURL != "" {
+		metricsHost = hostname(cfg.MetricsURL)
+	}
+	return tools.ServerConfig{
+		Version:           version,
+		InstanceHost:      hostname(cfg.URL),
+		MetricsHost:       metricsHost,
+		Transport:         transport,
+		TokenType:         tokenType,
+		RequestTimeout:    cfg.RequestTimeout.String(),
+		WriteToolsEnabled: cfg.EnableWriteTools,
+		EnabledTools:      enabledTools,
+		Limits:            cfg.Limits,
+	}
+}
+
+// hostname returns the host of rawURL, empty when it cannot be parsed
+func hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// validate checks the flag combinations. Contradictory or missing flags are returned
+// as an error, flags without effect as warnings.
+func validate(cfg config) (warnings []string, err error) {
+	var errs []error
+	if cfg.URL == "" {
+		errs = append(errs, errors.New("-url or "+envURL+" is required"))
+	}
+	if cfg.MetricsURL != "" {
+		if u, err := url.ParseRequestURI(cfg.MetricsURL); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Errorf("-metrics-url must be an absolute URL, got %q", cfg.MetricsURL))
+		}
+	}
+	if cfg.Secrets.Token == "" {
+		if cfg.UseAPIToken {
+			errs = append(errs, errors.New("-apitoken requires -token, -token-file or "+envToken))
+		} else {
+			errs = append(errs, errors.New("-token, -token-file or "+envToken+" is required"))
+		}
+	}
+	if cfg.TokenFile != "" && cfg.TokenSource == tokenFromFlag {
+		warnings = append(warnings, "-token-file has no effect with -token")
+	}
+
+	if cfg.CacheClear && cfg.CacheDir == "" {
+		errs = append(errs, errors.New("-cache-clear requires -cache-dir"))
+	}
+	if cfg.MetricNamesTTL < 0 {
+		errs = append(errs, fmt.Errorf("-metric-names-ttl must not be negative, got %s", cfg.MetricNamesTTL))
+	}
+
+	if cfg.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("-request-timeout must not be negative, got %s", cfg.RequestTimeout))
+	}
+	if cfg.Retries.Retries < 0 {
+		errs = append(errs, fmt.Errorf("-request-retries must not be negative, got %d", cfg.Retries.Retries))
+	}
+	if cfg.Retries.BaseDelay < 0 {
+		errs = append(errs, fmt.Errorf("-request-retry-delay must not be negative, got %s", cfg.Retries.BaseDelay))
+	}
+
+	if cfg.TokenExpiryWarning < 0 {
+		errs = append(errs, fmt.Errorf("-token-expiry-warning must not be negative, got %s", cfg.TokenExpiryWarning))
+	}
+	if cfg.TokenCheckInterval < 0 {
+		errs = append(errs, fmt.Errorf("-token-check-interval must not be negative, got %s", cfg.TokenCheckInterval))
+	}
+	if !cfg.TokenExpiresAt.IsZero() && cfg.TokenExpiresAt.Before(time.Now()) {
+		warnings = append(warnings, fmt.Sprintf("-token-expires-at (%s) is in the past, requests will likely be rejected", cfg.TokenExpiresAt.Format(time.RFC3339)))
+	}
+
+	if cfg.ListenAddr != "" {
+		if _, err := normalizeListenAddr(cfg.ListenAddr); err != nil {
+			errs = append(errs, fmt.Errorf("-http %w", err))
+		}
+		if cfg.SocketActivation {
+			warnings = append(warnings, "-http has no effect with -socket-activation, the server listens on the activated socket")
+		}
+	}
+
+	if cfg.HTTPLog && cfg.ListenAddr == "" && !cfg.SocketActivation {
+		warnings = append(warnings, "-http-log has no effect without -http or -socket-activation")
+	}
+	if cfg.TLSCert != "" && cfg.TLSKey == "" {
+		errs = append(errs, errors.New("-tls-cert requires -tls-key"))
+	}
+	if cfg.TLSKey != "" && cfg.TLSCert == "" {
+		errs = append(errs, errors.New("-tls-key requires -tls-cert"))
+	}
+	httpOnly := []struct {
+		flag string
+		set  bool
+	}{
+		{"-tls-cert", cfg.TLSCert != ""},
+		{"-auth-token", cfg.Secrets.AuthToken != ""},
+	}
+	for _, f := range httpOnly {
+		switch {
+		case !f.set:
+		case cfg.Check:
+			warnings = append(warnings, f.flag+" has no effect with -check")
+		case cfg.RunTool != "":
+			warnings = append(warnings, f.flag+" has no effect with -run-tool")
+		case cfg.ListenAddr == "" && !cfg.SocketActivation:
+			errs = append(errs, errors.New(f.flag+" requires -http or -socket-activation, the stdio transport has no connections to secure"))
+		}
+	}
+	if cfg.Check && cfg.ListenAddr != "" {
+		warnings = append(warnings, "-http has no effect with -check")
+	}
+	if cfg.Check && cfg.SocketActivation {
+		warnings = append(warnings, "-socket-activation has no effect with -check")
+	}
+
+	if cfg.RunTool != "" {
+		var params map[string]any
+		if err := json.Unmarshal([]byte(cfg.RunParams), &params); err != nil || params == nil {
+			errs = append(errs, fmt.Errorf("-params must be a JSON object, got %q", cfg.RunParams))
+		}
+		if cfg.Check {
+			errs = append(errs, errors.New("-run-tool cannot be combined with -check"))
+		}
+		if cfg.ListenAddr != "" {
+			warnings = append(warnings, "-http has no effect with -run-tool")
+		}
+		if cfg.SocketActivation {
+			warnings = append(warnings, "-socket-activation has no effect with -run-tool")
+		}
+	} else {
+		if cfg.RunParams != "" && cfg.RunParams != "{}" {
+			warnings = append(warnings, "-params has no effect without -run-tool")
+		}
+		if cfg.RunFormat != "" && cfg.RunFormat != runFormatText {
+			warnings = append(warnings, "-run-format has no effect without -run-tool")
+		}
+	}
+	if cfg.RunFormat != "" && cfg.RunFormat != runFormatText && cfg.RunFormat != runFormatJSON {
+		errs = append(errs, fmt.Errorf("-run-format must be '%s' or '%s', got '%s'", runFormatText, runFormatJSON, cfg.RunFormat))
+	}
+
+	if cfg.EnableWriteTools && cfg.Secrets.ReceiverAPIKey == "" {
+		errs = append(errs, errors.New("-enable-write-tools requires -receiver-api-key"))
+	}
+	if !cfg.EnableWriteTools && cfg.Secrets.ReceiverAPIKey != "" {
+		warnings = append(warnings, "-receiver-api-key has no effect without -enable-write-tools")
+	}
+
+	namespaceQueries := []struct {
+		flag  string
+		value string
+	}{
+		{"-namespace-cpu-query", cfg.NamespaceQueries.CPU},
+		{"-namespace-memory-query", cfg.NamespaceQueries.Memory},
+		{"-namespace-pods-query", cfg.NamespaceQueries.Pods},
+	}
+	for _, q := range namespaceQueries {
+		if q.value != "" && !strings.Contains(q.value, "$cluster") {
+			warnings = append(warnings, fmt.Sprintf("%s does not contain $cluster, getNamespaceResourceUsage will not be limited to the requested cluster", q.flag))
+		}
+	}
+
+	if len(cfg.SavedQueries) > 0 {
+		builtin := builtinToolNames()
+		for _, q := range cfg.SavedQueries {
+			if slices.Contains(builtin, q.Name) {
+				errs = append(errs, fmt.Errorf("-queries defines %s, which is the name of a built-in tool", q.Name))
+			}
+		}
+	}
+
+	limits := []struct {
+		flag  string
+		value int
+	}{
+		{"-metric-target-points", cfg.Limits.MetricTargetPoints},
+		{"-metric-max-points", cfg.Limits.MetricMaxPoints},
+		{"-metric-backend-max-points", cfg.Limits.MetricBackendMaxPoints},
+		{"-metric-max-series", cfg.Limits.MetricMaxSeries},
+		{"-metric-max-rows", cfg.Limits.MetricMaxRows},
+		{"-metric-pivot-max-series", cfg.Limits.MetricPivotMaxSeries},
+		{"-metric-max-label-columns", cfg.Limits.MetricMaxLabelColumns},
+		{"-metric-max-range-hours", cfg.Limits.MetricMaxRangeHours},
+		{"-metric-cardinality-samples", cfg.Limits.MetricCardinalitySamples},
+		{"-metric-fallback-hours", cfg.Limits.MetricFallbackHours},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", l.flag, l.value))
+		}
+	}
+	if cfg.Limits.MetricTargetPoints > 0 && cfg.Limits.MetricMaxPoints > 0 && cfg.Limits.MetricTargetPoints > cfg.Limits.MetricMaxPoints {
+		warnings = append(warnings, fmt.Sprintf("-metric-target-points (%d) is above -metric-max-points (%d), automatically chosen steps may return more points than requested steps are allowed to",
+			cfg.Limits.MetricTargetPoints, cfg.Limits.MetricMaxPoints))
+	}
+	if cfg.Limits.MetricMaxSeries > 0 && cfg.Limits.MetricMaxRows > 0 && cfg.Limits.MetricMaxRows < cfg.Limits.MetricMaxSeries {
+		warnings = append(warnings, fmt.Sprintf("-metric-max-rows (%d) is below -metric-max-series (%d), the series beyond the row limit are dropped",
+			cfg.Limits.MetricMaxRows, cfg.Limits.MetricMaxSeries))
+	}
+
+	return warnings, errors.Join(errs...)
+}
+
+// builtinToolNames returns the names of the tools the server registers itself, write tools
+// included
+func builtinToolNames() []string {
+	return newRegistry(nil, config{EnableWriteTools: true}, nil).names
+}
+
+// openCache opens the disk cache of the instance in -cache-dir, emptied first with
+// -cache-clear. Every instance has its own subdirectory, so they can share -cache-dir.
+func openCache(cfg config) (*diskcache.Cache, error) {
+	instance := hostname(cfg.URL)
+	if instance == "" {
+		instance = "default"
+	}
+	cache, err := diskcache.New(filepath.Join(cfg.CacheDir, instance))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CacheClear {
+		if err := cache.Clear(); err != nil {
+			return nil, fmt.Errorf("failed to clear the cache: %w", err)
+		}
+	}
+	return cache, nil
+}
+
+// parseLogLevel parses the -log-level flag, one of debug, info, warn and error
+func parseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("must be one of debug, info, warn and error")
+}
+
+// parseExpiry parses the -token-expires-at flag, either an RFC 3339 timestamp or a date
+// which is taken as the start of that day in UTC
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or a date like 2026-12-31")
+	}
+	return t, nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"io/fs"
+	"log/slog"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	valid := config{URL: "https://observability.example.com", Secrets: secrets{Token: "token"}, Limits: tools.DefaultLimits()}
+
+	tests := []struct {
+		name     string
+		modify   func(cfg *config)
+		err      string
+		warnings []string
+	}{
+		{
+			name:   "defaults over stdio",
+			modify: func(cfg *config) {},
+		},
+		{
+			name:   "defaults over http with an api token",
+			modify: func(cfg *config) { cfg.ListenAddr = ":8080"; cfg.UseAPIToken = true },
+		},
+		{
+			name:   "missing url and token",
+			modify: func(cfg *config) { cfg.URL = ""; cfg.Secrets.Token = "" },
+			err:    "-url or SUSE_OBS_URL is required\n-token, -token-file or SUSE_OBS_TOKEN is required",
+		},
+		{
+			name:     "token file with a token flag",
+			modify:   func(cfg *config) { cfg.TokenFile = "/run/secrets/token"; cfg.TokenSource = tokenFromFlag },
+			warnings: []string{"-token-file has no effect with -token"},
+		},
+		{
+			name:   "token read from a file",
+			modify: func(cfg *config) { cfg.TokenFile = "/run/secrets/token"; cfg.TokenSource = tokenFromFile },
+		},
+		{
+			name:   "cache-clear without cache-dir",
+			modify: func(cfg *config) { cfg.CacheClear = true },
+			err:    "-cache-clear requires -cache-dir",
+		},
+		{
+			name: "saved query named like a built-in tool",
+			modify: func(cfg *config) {
+				cfg.SavedQueries = []tools.SavedQuery{{Name: "getMetrics"}, {Name: "apiServerLatency"}}
+			},
+			err: "-queries defines getMetrics, which is the name of a built-in tool",
+		},
+		{
+			name:   "apitoken without token",
+			modify: func(cfg *config) { cfg.Secrets.Token = ""; cfg.UseAPIToken = true },
+			err:    "-apitoken requires -token, -token-file or SUSE_OBS_TOKEN",
+		},
+		{
+			name:   "negative limits",
+			modify: func(cfg *config) { cfg.Limits.MetricMaxPoints = -1; cfg.Limits.MetricMaxRows = -5 },
+			err:    "-metric-max-points must not be negative, got -1\n-metric-max-rows must not be negative, got -5",
+		},
+		{
+			name:     "target points above max points",
+			modify:   func(cfg *config) { cfg.Limits.MetricTargetPoints = 2000 },
+			warnings: []string{"-metric-target-points (2000) is above -metric-max-points (1000), automatically chosen steps may return more points than requested steps are allowed to"},
+		},
+		{
+			name:     "max rows below max series",
+			modify:   func(cfg *config) { cfg.Limits.MetricMaxRows = 10 },
+			warnings: []string{"-metric-max-rows (10) is below -metric-max-series (20), the series beyond the row limit are dropped"},
+		},
+		{
+			name:   "negative request timeout",
+			modify: func(cfg *config) { cfg.RequestTimeout = -time.Second },
+			err:    "-request-timeout must not be negative, got -1s",
+		},
+		{
+			name:   "negative request retries",
+			modify: func(cfg *config) { cfg.Retries.Retries = -1 },
+			err:    "-request-retries must not be negative, got -1",
+		},
+		{
+			name:   "negative metric names TTL",
+			modify: func(cfg *config) { cfg.MetricNamesTTL = -time.Second },
+			err:    "-metric-names-ttl must not be negative, got -1s",
+		},
+		{
+			name:   "write tools with a receiver API key",
+			modify: func(cfg *config) { cfg.EnableWriteTools = true; cfg.Secrets.ReceiverAPIKey = "key" },
+		},
+		{
+			name:   "write tools without a receiver API key",
+			modify: func(cfg *config) { cfg.EnableWriteTools = true },
+			err:    "-enable-write-tools requires -receiver-api-key",
+		},
+		{
+			name:     "receiver API key without write tools",
+			modify:   func(cfg *config) { cfg.Secrets.ReceiverAPIKey = "key" },
+			warnings: []string{"-receiver-api-key has no effect without -enable-write-tools"},
+		},
+		{
+			name:   "negative token durations",
+			modify: func(cfg *config) { cfg.TokenExpiryWarning = -time.Hour; cfg.TokenCheckInterval = -time.Minute },
+			err:    "-token-expiry-warning must not be negative, got -1h0m0s\n-token-check-interval must not be negative, got -1m0s",
+		},
+		{
+			name:   "token expiring in the future",
+			modify: func(cfg *config) { cfg.TokenExpiresAt = time.Now().Add(24 * time.Hour) },
+		},
+		{
+			name:     "token expired in the past",
+			modify:   func(cfg *config) { cfg.TokenExpiresAt = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC) },
+			warnings: []string{"-token-expires-at (2020-01-02T00:00:00Z) is in the past, requests will likely be rejected"},
+		},
+		{
+			name:   "separate metrics URL",
+			modify: func(cfg *config) { cfg.MetricsURL = "https://metrics.example.com/prom" },
+		},
+		{
+			name:   "relative metrics URL",
+			modify: func(cfg *config) { cfg.MetricsURL = "metrics" },
+			err:    `-metrics-url must be an absolute URL, got "metrics"`,
+		},
+		{
+			name:   "bare port over http",
+			modify: func(cfg *config) { cfg.ListenAddr = "8080" },
+		},
+		{
+			name:   "invalid http addresses",
+			modify: func(cfg *config) { cfg.ListenAddr = "localhost" },
+			err:    `-http must be a host:port address such as ':8080', got "localhost"`,
+		},
+		{
+			name:   "http port out of range",
+			modify: func(cfg *config) { cfg.ListenAddr = "127.0.0.1:70000" },
+			err:    `-http port must be a number between 0 and 65535, got "70000"`,
+		},
+		{
+			name:   "socket activation",
+			modify: func(cfg *config) { cfg.SocketActivation = true },
+		},
+		{
+			name:     "socket activation with an http address",
+			modify:   func(cfg *config) { cfg.SocketActivation = true; cfg.ListenAddr = ":8080" },
+			warnings: []string{"-http has no effect with -socket-activation, the server listens on the activated socket"},
+		},
+		{
+			name:     "socket activation with check",
+			modify:   func(cfg *config) { cfg.SocketActivation = true; cfg.Check = true },
+			warnings: []string{"-socket-activation has no effect with -check"},
+		},
+		{
+			name:     "http log over stdio",
+			modify:   func(cfg *config) { cfg.HTTPLog = true },
+			warnings: []string{"-http-log has no effect without -http or -socket-activation"},
+		},
+		{
+			name: "tls and auth token over http",
+			modify: func(cfg *config) {
+				cfg.ListenAddr = ":8443"
+				cfg.TLSCert, cfg.TLSKey = "/etc/tls/tls.crt", "/etc/tls/tls.key"
+				cfg.Secrets.AuthToken = "secret"
+			},
+		},
+		{
+			name: "tls and auth token with socket activation",
+			modify: func(cfg *config) {
+				cfg.SocketActivation = true
+				cfg.TLSCert, cfg.TLSKey = "/etc/tls/tls.crt", "/etc/tls/tls.key"
+				cfg.Secrets.AuthToken = "secret"
+			},
+		},
+		{
+			name:   "tls cert over stdio",
+			modify: func(cfg *config) { cfg.TLSCert, cfg.TLSKey = "/etc/tls/tls.crt", "/etc/tls/tls.key" },
+			err:    "-tls-cert requires -http or -socket-activation, the stdio transport has no connections to secure",
+		},
+		{
+			name:   "auth token over stdio",
+			modify: func(cfg *config) { cfg.Secrets.AuthToken = "secret" },
+			err:    "-auth-token requires -http or -socket-activation, the stdio transport has no connections to secure",
+		},
+		{
+			name:   "tls cert without key",
+			modify: func(cfg *config) { cfg.ListenAddr = ":8443"; cfg.TLSCert = "/etc/tls/tls.crt" },
+			err:    "-tls-cert requires -tls-key",
+		},
+		{
+			name:   "tls key without cert",
+			modify: func(cfg *config) { cfg.ListenAddr = ":8443"; cfg.TLSKey = "/etc/tls/tls.key" },
+			err:    "-tls-key requires -tls-cert",
+		},
+		{
+			name: "tls and auth token with check",
+			modify: func(cfg *config) {
+				cfg.Check = true
+				cfg.TLSCert, cfg.TLSKey = "/etc/tls/tls.crt", "/etc/tls/tls.key"
+				cfg.Secrets.AuthToken = "secret"
+			},
+			warnings: []string{"-tls-cert has no effect with -check", "-auth-token has no effect with -check"},
+		},
+		{
+			name:     "auth token with run tool",
+			modify:   func(cfg *config) { cfg.RunTool = "getMonitors"; cfg.RunParams = "{}"; cfg.Secrets.AuthToken = "secret" },
+			warnings: []string{"-auth-token has no effect with -run-tool"},
+		},
+		{
+			name:     "check over http",
+			modify:   func(cfg *config) { cfg.Check = true; cfg.ListenAddr = ":8080" },
+			warnings: []string{"-http has no effect with -check"},
+		},
+		{
+			name: "run tool with params",
+			modify: func(cfg *config) {
+				cfg.RunTool = "getMonitors"
+				cfg.RunParams = `{"state":"CRITICAL"}`
+				cfg.RunFormat = "json"
+			},
+		},
+		{
+			name:   "run tool with params that are not an object",
+			modify: func(cfg *config) { cfg.RunTool = "getMonitors"; cfg.RunParams = `["CRITICAL"]` },
+			err:    `-params must be a JSON object, got "[\"CRITICAL\"]"`,
+		},
+		{
+			name:   "run tool with an unknown format",
+			modify: func(cfg *config) { cfg.RunTool = "getMonitors"; cfg.RunParams = "{}"; cfg.RunFormat = "yaml" },
+			err:    "-run-format must be 'text' or 'json', got 'yaml'",
+		},
+		{
+			name:   "run tool with check",
+			modify: func(cfg *config) { cfg.RunTool = "getMonitors"; cfg.RunParams = "{}"; cfg.Check = true },
+			err:    "-run-tool cannot be combined with -check",
+		},
+		{
+			name:     "run tool over http",
+			modify:   func(cfg *config) { cfg.RunTool = "getMonitors"; cfg.RunParams = "{}"; cfg.ListenAddr = ":8080" },
+			warnings: []string{"-http has no effect with -run-tool"},
+		},
+		{
+			name:     "params and run format without run tool",
+			modify:   func(cfg *config) { cfg.RunParams = `{"state":"CRITICAL"}`; cfg.RunFormat = "json" },
+			warnings: []string{"-params has no effect without -run-tool", "-run-format has no effect without -run-tool"},
+		},
+		{
+			name:     "namespace query without cluster",
+			modify:   func(cfg *config) { cfg.NamespaceQueries.Pods = "count by (namespace) (kube_pod_info)" },
+			warnings: []string{"-namespace-pods-query does not contain $cluster, getNamespaceResourceUsage will not be limited to the requested cluster"},
+		},
+		{
+			name:   "empty namespace query leaves the column out",
+			modify: func(cfg *config) { cfg.NamespaceQueries.Memory = "" },
+		},
+		{
+			name:   "zero limits fall back to defaults or disable the cap",
+			modify: func(cfg *config) { cfg.Limits = tools.Limits{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+
+			warnings, err := validate(cfg)
+
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+}
+
+func TestResolveCredentials(t *testing.T) {
+	env := map[string]string{"SUSE_OBS_URL": "https://env.example.com", "SUSE_OBS_TOKEN": "env-token"}
+	files := map[string]string{"/run/secrets/token": "file-token\n", "/run/secrets/empty": " \n"}
+	readFile := func(name string) ([]byte, error) {
+		data, ok := files[name]
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return []byte(data), nil
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config
+		env    map[string]string
+		url    string
+		token  string
+		source string
+		err    string
+	}{
+		{name: "flags", cfg: config{URL: "https://flag.example.com", TokenFile: "/run/secrets/token", Secrets: secrets{Token: "flag-token"}}, env: env,
+			url: "https://flag.example.com", token: "flag-token", source: tokenFromFlag},
+		{name: "file over environment", cfg: config{TokenFile: "/run/secrets/token"}, env: env,
+			url: "https://env.example.com", token: "file-token", source: tokenFromFile},
+		{name: "environment", env: env, url: "https://env.example.com", token: "env-token", source: tokenFromEnv},
+		{name: "nothing set"},
+		{name: "missing file", cfg: config{TokenFile: "/run/secrets/missing"}, env: env,
+			err: "failed to read -token-file: open /run/secrets/missing: file does not exist"},
+		{name: "empty file", cfg: config{TokenFile: "/run/secrets/empty"}, env: env, err: "-token-file /run/secrets/empty is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := resolveCredentials(tt.cfg, func(key string) string { return tt.env[key] }, readFile)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.url, cfg.URL)
+			assert.Equal(t, tt.token, cfg.Secrets.Token)
+			assert.Equal(t, tt.source, cfg.TokenSource)
+		})
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+		err      string
+	}{
+		{value: "2026-12-31T18:30:00Z", expected: time.Date(2026, 12, 31, 18, 30, 0, 0, time.UTC)},
+		{value: "2026-12-31", expected: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{value: "next week", err: "must be an RFC 3339 timestamp or a date like 2026-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			expiry, err := parseExpiry(tt.value)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(expiry))
+		})
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected slog.Level
+		err      string
+	}{
+		{value: "debug", expected: slog.LevelDebug},
+		{value: "INFO", expected: slog.LevelInfo},
+		{value: "warn", expected: slog.LevelWarn},
+		{value: "error", expected: slog.LevelError},
+		{value: "info+2", err: "must be one of debug, info, warn and error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			level, err := parseLogLevel(tt.value)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
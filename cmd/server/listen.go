@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation
+const listenFDsStart = 3
+
+// normalizeListenAddr validates the -http address and returns it as host:port. A bare
+// port such as "8080" listens on all interfaces.
+func normalizeListenAddr(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if _, err := strconv.Atoi(addr); err == nil {
+		addr = ":" + addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("must be a host:port address such as ':8080', got %q", addr)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", fmt.Errorf("port must be a number between 0 and 65535, got %q", port)
+	}
+	if strings.ContainsAny(host, " /") {
+		return "", fmt.Errorf("host must be a hostname or IP address, got %q", host)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// listenHTTP returns the listener of the http transport: the socket passed by systemd
+// when socket activation is enabled, otherwise a new listener on the -http address. The
+// bound address is logged, so the port chosen for ":0" is known.
+func listenHTTP(logger *slog.Logger, cfg config, getenv func(string) string) (net.Listener, error) {
+	var (
+		ln  net.Listener
+		err error
+	)
+	if cfg.SocketActivation {
+		ln, err = activationListener(logger, getenv, listenFDsStart)
+	} else {
+		var addr string
+		if addr, err = normalizeListenAddr(cfg.ListenAddr); err == nil {
+			ln, err = net.Listen("tcp", addr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TLSCert != "" {
+		if ln, err = tlsListener(ln, cfg.TLSCert, cfg.TLSKey); err != nil {
+			return nil, err
+		}
+	}
+	logger.Info("Server listening", "address", ln.Addr().String(), "socket_activation", cfg.SocketActivation, "tls", cfg.TLSCert != "")
+	return ln, nil
+}
+
+// tlsListener serves HTTPS on ln with the certificate and key of the PEM files. ln is closed
+// when they cannot be loaded.
+func tlsListener(ln net.Listener, certFile, keyFile string) (net.Listener, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to load -tls-cert and -tls-key: %w", err)
+	}
+	return tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}), nil
+}
+
+// activationListener returns the first socket passed through the LISTEN_FDS protocol of
+// systemd, starting at file descriptor firstFD
+func activationListener(logger *slog.Logger, getenv func(string) string, firstFD uintptr) (net.Listener, error) {
+	fds := getenv("LISTEN_FDS")
+	if fds == "" {
+		return nil, errors.New("socket activation is enabled but LISTEN_FDS is not set, start the server from a systemd socket unit")
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("LISTEN_FDS must be a positive number, got %q", fds)
+	}
+	if pid := getenv("LISTEN_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("LISTEN_PID %s does not match the server process %d, the sockets were passed to another process", pid, os.Getpid())
+	}
+	if n > 1 {
+		logger.Warn("Only the first activated socket is used", "listen_fds", n)
+	}
+
+	f := os.NewFile(firstFD, "LISTEN_FD_"+strconv.Itoa(int(firstFD)))
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("activated socket %d is not a listening socket: %w", firstFD, err)
+	}
+	return ln, nil
+}
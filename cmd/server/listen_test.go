@@ -0,0 +1,161 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeListenAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+		err  string
+	}{
+		{addr: ":8080", want: ":8080"},
+		{addr: " 8080 ", want: ":8080"},
+		{addr: "127.0.0.1:0", want: "127.0.0.1:0"},
+		{addr: "[::1]:8080", want: "[::1]:8080"},
+		{addr: "localhost", err: `must be a host:port address such as ':8080', got "localhost"`},
+		{addr: ":http", err: `port must be a number between 0 and 65535, got "http"`},
+		{addr: ":65536", err: `port must be a number between 0 and 65535, got "65536"`},
+		{addr: "http://localhost:8080", err: `must be a host:port address such as ':8080', got "http://localhost:8080"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := normalizeListenAddr(tt.addr)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListenHTTP(t *testing.T) {
+	noEnv := func(string) string { return "" }
+
+	t.Run("random port logs the bound address", func(t *testing.T) {
+		var logs bytes.Buffer
+		ln, err := listenHTTP(slog.New(slog.NewTextHandler(&logs, nil)), config{ListenAddr: "127.0.0.1:0"}, noEnv)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ln.Close() })
+
+		port := ln.Addr().(*net.TCPAddr).Port
+		assert.NotZero(t, port)
+		assert.Contains(t, logs.String(), "address=127.0.0.1:"+strconv.Itoa(port))
+		assert.Contains(t, logs.String(), "socket_activation=false")
+	})
+
+	t.Run("socket activation without LISTEN_FDS", func(t *testing.T) {
+		_, err := listenHTTP(slog.New(slog.DiscardHandler), config{SocketActivation: true}, noEnv)
+
+		assert.EqualError(t, err, "socket activation is enabled but LISTEN_FDS is not set, start the server from a systemd socket unit")
+	})
+}
+
+func TestListenHTTPTLS(t *testing.T) {
+	// The certificate of an httptest TLS server, written to PEM files
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	srv.Close()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert := srv.TLS.Certificates[0]
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600))
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+
+	t.Run("serves https", func(t *testing.T) {
+		var logs bytes.Buffer
+		ln, err := listenHTTP(slog.New(slog.NewTextHandler(&logs, nil)), config{ListenAddr: "127.0.0.1:0", TLSCert: certFile, TLSKey: keyFile}, func(string) string { return "" })
+		require.NoError(t, err)
+		go func() {
+			_ = http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }))
+		}()
+		t.Cleanup(func() { _ = ln.Close() })
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+		resp, err := client.Get("https://" + ln.Addr().String())
+
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+		assert.Contains(t, logs.String(), "tls=true")
+	})
+
+	t.Run("missing key file", func(t *testing.T) {
+		_, err := listenHTTP(slog.New(slog.DiscardHandler), config{ListenAddr: "127.0.0.1:0", TLSCert: certFile, TLSKey: filepath.Join(dir, "missing.key")}, func(string) string { return "" })
+
+		assert.ErrorContains(t, err, "failed to load -tls-cert and -tls-key: open "+filepath.Join(dir, "missing.key"))
+	})
+}
+
+func TestActivationListener(t *testing.T) {
+	// activated returns the file descriptor of a pre-created listener, as passed by systemd
+	activated := func(t *testing.T) (net.Listener, uintptr) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ln.Close() })
+		f, err := ln.(*net.TCPListener).File()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+		// The activated listener takes ownership of the descriptor, so it gets its own
+		fd, err := syscall.Dup(int(f.Fd()))
+		require.NoError(t, err)
+		return ln, uintptr(fd)
+	}
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("uses the inherited listener", func(t *testing.T) {
+		inherited, fd := activated(t)
+		var logs bytes.Buffer
+
+		ln, err := activationListener(slog.New(slog.NewTextHandler(&logs, nil)), env(map[string]string{"LISTEN_FDS": "2", "LISTEN_PID": pid}), fd)
+
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ln.Close() })
+		assert.Equal(t, inherited.Addr().String(), ln.Addr().String())
+		assert.Contains(t, logs.String(), "Only the first activated socket is used")
+
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+		_ = conn.Close()
+	})
+
+	t.Run("sockets passed to another process", func(t *testing.T) {
+		_, fd := activated(t)
+		t.Cleanup(func() { _ = syscall.Close(int(fd)) })
+
+		_, err := activationListener(slog.New(slog.DiscardHandler), env(map[string]string{"LISTEN_FDS": "1", "LISTEN_PID": "1"}), fd)
+
+		assert.EqualError(t, err, "LISTEN_PID 1 does not match the server process "+pid+", the sockets were passed to another process")
+	})
+
+	t.Run("invalid LISTEN_FDS", func(t *testing.T) {
+		_, err := activationListener(slog.New(slog.DiscardHandler), env(map[string]string{"LISTEN_FDS": "0"}), listenFDsStart)
+
+		assert.EqualError(t, err, `LISTEN_FDS must be a positive number, got "0"`)
+	})
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -12,26 +19,189 @@ import (
 	"suse-observability-mcp/internal/tools"
 )
 
+// version is reported to MCP clients and by getServerConfig
+const version = "v0.0.1"
+
 func main() {
+	var cfg config
+
 	// SUSE Observability flags
-	url := flag.String("url", "", "SUSE Observability API URL")
-	token := flag.String("token", "", "SUSE Observability API Token")
-	useAPIToken := flag.Bool("apitoken", false, "Indicates if the token is an API token, instead of a service token")
+	flag.StringVar(&cfg.URL, "url", "", "SUSE Observability API URL, defaults to "+envURL)
+	flag.StringVar(&cfg.MetricsURL, "metrics-url", "", "SUSE Observability metrics API base URL, defaults to -url")
+	flag.StringVar(&cfg.Secrets.Token, "token", "", "SUSE Observability API Token, prefer -token-file or "+envToken+" as flags show up in the shell history and process listings")
+	flag.StringVar(&cfg.TokenFile, "token-file", "", "file holding the SUSE Observability API Token, read when -token is not set")
+	flag.BoolVar(&cfg.UseAPIToken, "apitoken", false, "Indicates if the token is an API token, instead of a service token")
+	flag.DurationVar(&cfg.RequestTimeout, "request-timeout", suseobservability.DefaultRequestTimeout, "timeout of SUSE Observability API requests, also used as metric query timeout")
+	cfg.Retries = suseobservability.DefaultRetryPolicy
+	flag.IntVar(&cfg.Retries.Retries, "request-retries", cfg.Retries.Retries, "number of retries of metric queries failing with status 429 or 5xx or a connection error, 0 disables them")
+	flag.DurationVar(&cfg.Retries.BaseDelay, "request-retry-delay", cfg.Retries.BaseDelay, "delay before the first retry of a metric query, doubled for every following one with jitter, the Retry-After of the response takes precedence unless it is longer than 10s")
+	flag.Func("token-expires-at", "expiry of the token as RFC 3339 timestamp or date (e.g. 2026-12-31), enables the expiry warning", func(s string) (err error) {
+		cfg.TokenExpiresAt, err = parseExpiry(s)
+		return err
+	})
+	flag.DurationVar(&cfg.TokenExpiryWarning, "token-expiry-warning", 72*time.Hour, "how long before -token-expires-at tool outputs warn about the expiry")
+	flag.DurationVar(&cfg.TokenCheckInterval, "token-check-interval", time.Hour, "interval of the token validity check, 0 disables it")
 
 	// MCP server flags
-	listenAddr := flag.String("http", "", "address for http transport, defaults to stdio")
+	flag.StringVar(&cfg.ListenAddr, "http", "", "address for http transport, e.g. ':8080' or ':0' for a random port, defaults to stdio")
+	flag.BoolVar(&cfg.SocketActivation, "socket-activation", false, "serve the http transport on the socket passed by systemd socket activation (LISTEN_FDS) instead of -http")
+	flag.BoolVar(&cfg.HTTPLog, "http-log", false, "log every request of the http transport with its method, remote address, duration and status, and recover from request panics")
+	flag.StringVar(&cfg.TLSCert, "tls-cert", "", "PEM certificate file the http transport serves HTTPS with, requires -tls-key")
+	flag.StringVar(&cfg.TLSKey, "tls-key", "", "PEM private key file of -tls-cert")
+	flag.StringVar(&cfg.Secrets.AuthToken, "auth-token", "", "bearer token clients of the http transport must send in the Authorization header, every client is accepted when empty")
+	flag.BoolVar(&cfg.Check, "check", false, "check that the SUSE Observability and metrics APIs are reachable with the token, then exit")
+	flag.StringVar(&cfg.RunTool, "run-tool", "", "run this tool once, print its output and exit with 0 when it succeeded, 1 when its result is partial and 2 when it failed")
+	flag.StringVar(&cfg.RunParams, "params", "{}", "JSON object of the arguments of -run-tool, e.g. '{\"state\":\"CRITICAL\"}'")
+	flag.StringVar(&cfg.RunFormat, "run-format", runFormatText, "output format of -run-tool: 'text' for the text output or 'json' for the whole tool result")
+	flag.BoolVar(&cfg.EnableWriteTools, "enable-write-tools", false, "register tools that write to SUSE Observability, such as createAnnotation")
+	flag.StringVar(&cfg.Secrets.ReceiverAPIKey, "receiver-api-key", "", "SUSE Observability receiver API key, used by the write tools")
+	flag.StringVar(&cfg.CacheDir, "cache-dir", "", "directory caching slow-changing discovery data such as the metric names across server processes, disabled when empty")
+	flag.BoolVar(&cfg.CacheClear, "cache-clear", false, "remove the entries of -cache-dir at startup")
+	flag.DurationVar(&cfg.MetricNamesTTL, "metric-names-ttl", suseobservability.DefaultMetricNamesTTL, "how long the metric names fetched for a listMetrics lookback are reused in memory, 0 disables the reuse")
+	flag.Func("log-level", "minimum level of the logs written to stderr: debug, info, warn or error (default info), debug logs every tool call and API request", func(s string) (err error) {
+		cfg.LogLevel, err = parseLogLevel(s)
+		return err
+	})
+
+	// Tool limits
+	cfg.Limits = tools.DefaultLimits()
+	flag.IntVar(&cfg.Limits.MetricTargetPoints, "metric-target-points", cfg.Limits.MetricTargetPoints, "number of points per series an automatically chosen metrics step aims for")
+	flag.IntVar(&cfg.Limits.MetricMaxPoints, "metric-max-points", cfg.Limits.MetricMaxPoints, "maximum number of points per series before a requested metrics step is coarsened")
+	flag.IntVar(&cfg.Limits.MetricBackendMaxPoints, "metric-backend-max-points", cfg.Limits.MetricBackendMaxPoints, "maximum number of points per series the backend returns for one range query, longer getMetrics queries are split into chunks, 0 never splits them")
+	flag.IntVar(&cfg.Limits.MetricMaxSeries, "metric-max-series", cfg.Limits.MetricMaxSeries, "default maximum number of series rendered by getMetrics")
+	flag.IntVar(&cfg.Limits.MetricMaxRows, "metric-max-rows", cfg.Limits.MetricMaxRows, "default maximum number of rows rendered by getMetrics")
+	flag.IntVar(&cfg.Limits.MetricPivotMaxSeries, "metric-pivot-max-series", cfg.Limits.MetricPivotMaxSeries, "maximum number of series getMetrics aligns in the pivot layout, 0 for no maximum")
+	flag.IntVar(&cfg.Limits.MetricMaxLabelColumns, "metric-max-label-columns", cfg.Limits.MetricMaxLabelColumns, "maximum number of label columns of the getMetrics tables, the least distinctive labels are collapsed into one column above it, 0 for no maximum")
+	flag.IntVar(&cfg.Limits.MetricMaxRangeHours, "metric-max-range-hours", cfg.Limits.MetricMaxRangeHours, "longest time range in hours getMetrics queries in raw mode, 0 for no maximum")
+	flag.IntVar(&cfg.Limits.MetricFallbackHours, "metric-fallback-hours", cfg.Limits.MetricFallbackHours, "window in hours listMetrics searches for metrics without samples in the lookback when a search has no match, 0 disables it")
+	flag.IntVar(&cfg.Limits.MetricCardinalitySamples, "metric-cardinality-samples", cfg.Limits.MetricCardinalitySamples, "maximum number of series getMetricCardinality counts labels over, 0 for no maximum")
+	cfg.NamespaceQueries = tools.DefaultNamespaceQueries()
+	flag.StringVar(&cfg.NamespaceQueries.CPU, "namespace-cpu-query", cfg.NamespaceQueries.CPU, "PromQL query of the CPU usage per namespace of getNamespaceResourceUsage, $cluster and $window are replaced, empty leaves the column out")
+	flag.StringVar(&cfg.NamespaceQueries.Memory, "namespace-memory-query", cfg.NamespaceQueries.Memory, "PromQL query of the memory usage per namespace of getNamespaceResourceUsage, $cluster and $window are replaced, empty leaves the column out")
+	flag.StringVar(&cfg.NamespaceQueries.Pods, "namespace-pods-query", cfg.NamespaceQueries.Pods, "PromQL query of the pod count per namespace of getNamespaceResourceUsage, $cluster and $window are replaced, empty leaves the column out")
+	cfg.ComponentDisplayName = tools.DefaultDisplayName()
+	flag.Func("component-display-name", "Go template rendering component names in tool outputs, over .ID, .Name, .Namespace, .Cluster, .Identifiers, .Tags and .Properties, e.g. '{{.Namespace}}/{{.Name}}' (default '{{.Name}}')", func(s string) (err error) {
+		cfg.ComponentDisplayName, err = tools.ParseDisplayName(s)
+		return err
+	})
+	flag.Func("queries", "JSON file mapping tool names to saved PromQL queries, each registered as a tool of its own with the parameters of its $name placeholders", func(s string) error {
+		data, err := os.ReadFile(s)
+		if err != nil {
+			return err
+		}
+		cfg.SavedQueries, err = tools.ParseSavedQueries(data)
+		return err
+	})
 	flag.Parse()
+	slog.SetLogLoggerLevel(cfg.LogLevel)
 
-	client, err := suseobservability.NewClient(*url, *token, *useAPIToken)
+	cfg, err := resolveCredentials(cfg, os.Getenv, os.ReadFile)
 	if err != nil {
+		slog.Error("Invalid flags", "error", err)
+		os.Exit(2)
+	}
+	warnings, err := validate(cfg)
+	for _, w := range warnings {
+		slog.Warn("Ineffective flag combination", "warning", w)
+	}
+	if err != nil {
+		slog.Error("Invalid flags", "error", err)
+		os.Exit(2)
+	}
+
+	client, err := suseobservability.NewClient(cfg.URL, cfg.Secrets.Token, cfg.UseAPIToken, cfg.RequestTimeout)
+	if err != nil {
+		slog.Error("Failed to create SUSE Observability client", "error", err)
+		os.Exit(1)
+	}
+	client.WithReceiverAPIKey(cfg.Secrets.ReceiverAPIKey)
+	client.WithRetryPolicy(cfg.Retries)
+	client.WithMetricNamesTTL(cfg.MetricNamesTTL)
+	if _, err := client.WithMetricsURL(cfg.MetricsURL); err != nil {
+		slog.Error("Failed to configure the metrics URL", "error", err)
+		os.Exit(1)
+	}
+
+	var apiClient tools.SuseObservabilityClient = client
+	if cfg.CacheDir != "" {
+		cache, err := openCache(cfg)
+		if err != nil {
+			slog.Error("Failed to open the cache", "error", err)
+			os.Exit(1)
+		}
+		apiClient = tools.WithDiskCache(client, cache)
+	}
+
+	// ctx is cancelled on SIGINT and SIGTERM, so the transports stop cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Check {
+		if !runCheck(ctx, os.Stdout, client) {
+			os.Exit(1)
+		}
 		return
 	}
 
-	mcpTools := tools.NewBaseTool(client)
+	credentials := tools.NewCredentialMonitor(cfg.TokenExpiresAt, cfg.TokenExpiryWarning)
+
+	if cfg.RunTool != "" {
+		os.Exit(runTool(ctx, os.Stdout, os.Stderr, newRegistry(apiClient, cfg, credentials), cfg.RunTool, cfg.RunParams, cfg.RunFormat))
+	}
+
+	if cfg.TokenCheckInterval > 0 {
+		credentials.Start(ctx, client.CheckToken, cfg.TokenCheckInterval)
+	}
+
+	mcpServer := newServer(apiClient, cfg, credentials)
+
+	if cfg.ListenAddr == "" && !cfg.SocketActivation {
+		// Run the server on the stdio transport.
+		if err := mcpServer.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+			slog.Error("Server failed", "error", err)
+		}
+	} else {
+		// Create a streamable HTTP handler.
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+			return mcpServer
+		}, nil)
+		var h http.Handler = handler
+		if cfg.Secrets.AuthToken != "" {
+			h = requireAuthToken(cfg.Secrets.AuthToken, h)
+		}
+		if cfg.HTTPLog {
+			h = logRequests(slog.Default(), h)
+		}
+
+		// Run the server on the HTTP transport.
+		ln, err := listenHTTP(slog.Default(), cfg, os.Getenv)
+		if err != nil {
+			slog.Error("Failed to listen", "error", err)
+			os.Exit(1)
+		}
+		if err := serveHTTP(ctx, slog.Default(), ln, h, shutdownTimeout); err != nil {
+			slog.Error("Server failed", "error", err)
+		}
+	}
+}
+
+// newServer creates the MCP server with all tools registered. Write tools are only
+// registered when enabled in the configuration. Tool outputs and errors go through the
+// credential monitor, which may be nil.
+func newServer(client tools.SuseObservabilityClient, cfg config, credentials *tools.CredentialMonitor) *mcp.Server {
+	return newRegistry(client, cfg, credentials).server
+}
 
-	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "SUSE Observability MCP server", Version: "v0.0.1"}, nil)
+// newRegistry registers all tools on a new MCP server, see newServer. The registry also
+// runs the tools directly, for -run-tool.
+func newRegistry(client tools.SuseObservabilityClient, cfg config, credentials *tools.CredentialMonitor) *toolRegistry {
+	mcpTools := tools.NewBaseTool(client).WithLimits(cfg.Limits).WithNamespaceQueries(cfg.NamespaceQueries).
+		WithComponentDisplayName(cfg.ComponentDisplayName)
 
-	mcp.AddTool(mcpServer, &mcp.Tool{
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "SUSE Observability MCP server", Version: version}, nil)
+	registry := &toolRegistry{server: mcpServer, credentials: credentials, runners: map[string]toolRunner{}}
+
+	addTool(registry, &mcp.Tool{
 		Name: "getComponents",
 		Description: `Searches for topology components using STQL filters.
 		Arguments (all support comma-separated values for multiple items):
@@ -40,61 +210,491 @@ func main() {
 		- healthstates (optional): Health states (comma-separated, e.g., 'CRITICAL,DEVIATING'). Useful to query multiple states at once.
 		- domains (optional): Cluster names to filter (comma-separated, e.g., 'prod-cluster,staging-cluster'). Domain represents the cluster name.
 		- namespace (optional): Kubernetes namespace to filter (e.g., 'default', 'kube-system').
+		- id (optional): The ID of the component to match.
+		- identifier (optional): An identifier (URN) of the component to match, e.g. 'urn:kubernetes:/prod:shop:pod/checkout-1'.
+		- match_any (optional): Match components matching any of the filters (OR) instead of all of them (AND), e.g. type 'pod' OR healthstate 'CRITICAL'.
+		  The values of one filter, e.g. healthstates 'CRITICAL,DEVIATING', always match any of them.
 		- with_neighbors (optional): Include connected components using withNeighborsOf.
 		- with_neighbors_levels (optional): Number of levels (1-14) or 'all' (default: 1).
 		- with_neighbors_direction (optional): 'up', 'down', or 'both' (default: both).
+		- at (optional): Time of the topology snapshot, 'now', a duration back from now (e.g., '1h') or 'now-1h' to search the topology as it was then (default: now).
+		- limit (optional): Maximum number of components listed (default: 100).
+		- display_name (optional): Go template rendering component names over .ID, .Name, .Namespace, .Cluster, .Identifiers, .Tags and .Properties, e.g. '{{.Namespace}}/{{.Name}}' (default: configured on the server).
 		At least one filter must be provided. All filters use STQL IN operator for efficient multi-value queries.
 		Returns:
-		A markdown table of matching components with their IDs and identifiers`},
+		A markdown table of matching components with their IDs, health state and outgoing relations (count and first related IDs),
+		stating how many are shown out of those fetched. Structured content holds every fetched component, not capped by limit.`},
 		mcpTools.GetComponents,
 	)
-	mcp.AddTool(mcpServer, &mcp.Tool{
-		Name: "listMetrics",
-		Description: `Lists metrics for a specific component.
+	addTool(registry, &mcp.Tool{
+		Name: "getComponent",
+		Description: `Fetches a single topology component by ID with its full details.
+		Arguments:
+		- id (required): The ID of the component (from getComponents).
+		- display_name (optional): Go template rendering the component name, like for getComponents.
+		Returns:
+		The component health state, all identifiers, tags, properties and relation IDs.`},
+		mcpTools.GetComponent,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "topologyDiff",
+		Description: `Compares the components an STQL query matches at two points in time, e.g. to find what changed before an incident.
+		Arguments:
+		- query (required): The STQL query selecting the components, e.g. 'type = "pod" AND label = "namespace:shop"'.
+		- from (required): Time of the earlier snapshot, 'now', a duration back from now (e.g., '1h') or 'now-1h'; '/d' aligns it to the start of the day (e.g., 'now-1d/d').
+		- to (optional): Time of the later snapshot, must be after from (default: now).
+		- limit (optional): Maximum number of components listed per change (default: 100).
+		- display_name (optional): Go template rendering component names, like for getComponents.
+		Returns:
+		The number of components in both snapshots and markdown tables of the components added, removed and whose health
+		state changed (with the state before and after). Structured content holds every change.`},
+		mcpTools.TopologyDiff,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "validateSTQL",
+		Description: `Checks the syntax of an STQL query with SUSE Observability without fetching the components it matches.
+		Use it to iterate on a query cheaply before running it, instead of reading a failing topology query.
 		Arguments:
-		- component_id (required): The ID of the component to list bound metrics for.
+		- query (required): The STQL query to validate, e.g. 'type = "pod" AND label = "namespace:shop"'.
 		Returns:
-		A markdown table showing the bound metrics with their names, units, and query expressions.`,
+		"Valid STQL." or the messages of the STQL parser. The same information is returned as structured content.`},
+		mcpTools.ValidateSTQL,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "listViews",
+		Description: `Lists the saved topology views of SUSE Observability, the curated perspectives such as "all pods of the shop", ordered by name.
+		Arguments:
+		- search (optional): Only list the views whose name, description or identifier contains this text, case-insensitive.
+		- limit (optional): Maximum number of views listed (default: 100).
+		Returns:
+		A markdown table of the views with their name, ID, description and STQL query. Pass the query of a view to topologyDiff,
+		or adapt it into getComponents filters. Structured content holds every matching view with its identifier and full query.`},
+		mcpTools.ListViews,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getEvents",
+		Description: `Lists the events of a component, or of all components, such as deployments, configuration changes and state transitions, newest first.
+		Arguments:
+		- component_id (optional): The ID of the component (from getComponents), all components when omitted.
+		- categories (optional): Event categories to list: Changes, Deployments, Alerts, Anomalies, Activities or Others (default: all).
+		- lookback (optional): How far back to look for events, e.g. '15m' or '2d' (default: 1h, max: 7d).
+		- limit (optional): Maximum number of events listed (default: 50, max: 500).
+		Returns:
+		A markdown table of the events with their time, category, title and source, and how many more events matched.`},
+		mcpTools.GetEvents,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getLogs",
+		Description: `Returns the latest log lines of a component, e.g. to jump from a failing pod to its errors.
+		Arguments (exactly one of component_id or component_urn is required):
+		- component_id (optional): The ID of the component (from getComponents).
+		- component_urn (optional): The URN of the component, e.g. 'urn:kubernetes:/prod:shop:pod/checkout-1'.
+		- lookback (optional): How far back to look for log lines, e.g. '15m' or '2d' (default: 1h, max: 7d).
+		- filter (optional): Only return log lines containing this text (case-insensitive).
+		- limit (optional): Maximum number of log lines returned, the latest are kept (default: 100, max: 1000).
+		Returns:
+		The log lines oldest first in a code block with their time, level and message, and how many earlier lines matched.`},
+		mcpTools.GetLogs,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "listTraces",
+		Description: `Lists the traces with spans of a service over the last hour, newest first, one page at a time.
+		Arguments (exactly one of service_name, component_id or component_name is required):
+		- service_name (optional): The name of the service, e.g. 'checkout'.
+		- component_id (optional): The ID of the otel service component of the service.
+		- component_name (optional): The name of the otel service component of the service, fails when several components have it.
+		- page (optional): Page of traces to list, starting at 0 (default: 0).
+		- page_size (optional): Number of traces per page (default: 20, max: 1000), the spans of the first 20 traces are looked up and the others only show their ID.
+		- format (optional): 'markdown' (default) or 'json' for the raw query response with trace and span IDs only.
+		- min_duration_ms (optional): Only list traces with a span of the service lasting at least this many milliseconds, to find slow requests.
+		- errors_only (optional): Only list traces with a span of the service that has error status, combined with min_duration_ms the same span must match both (default: false).
+		- around_health_change (optional): With component_id, list the traces from 15 minutes before to 15 minutes after the most recent health state change of the component (looked up over the last 7 days) instead of the last hour (default: false).
+		Returns:
+		A markdown table of the traces with their root span name, service, start time, duration and number of spans,
+		with the page listed and a hint to pass the next page when the server reports more pages, or a full page suggests them.
+		With around_health_change the output states the window and the health change it is centered on, or notes that no change was found and the last hour is listed.
+		The paging state (has_more, next_page, total_pages), the time window and trace IDs are returned as structured content.`},
+		mcpTools.ListTraces,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "listMetrics",
+		Description: `Lists metrics for a specific component, or metrics by name.
+		Arguments (exactly one of component_id or search is required):
+		- component_id (optional): The ID of the component to list bound metrics for.
+		- search (optional): Text contained in the metric names (case-insensitive), e.g. 'cpu'.
+		- match_mode (optional): How search matches metric names: 'substring' (case-insensitive, default), 'regex' or 'exact'.
+		- case_sensitive (optional): Match the regex case-sensitively, only with match_mode 'regex' (default: false).
+		- limit (optional): Maximum number of metrics listed (default: 50, max: 500).
+		- offset (optional): Number of metrics to skip, to list the next page (default: 0).
+		- lookback (optional): How far back to look for metrics with data, e.g. '12h' or '2d' (default: '1h', max: '7d').
+		  Widen it for workloads that did not run recently, such as nightly jobs.
+		Returns:
+		A markdown table showing the bound metrics with their names, units, and query expressions,
+		or the matching metric names with their type (counter, gauge, histogram), unit and the label names of their series.
+		Label names all listed metrics share are stated once as common labels above the table, which then lists only the others.
+		Types and units missing from the metric metadata are guessed from the name and marked with '*'. Apply rate() to counters.
+		Metrics are sorted by name. When there are more, a footer shows the range listed and the exact parameters of the next page.
+		When a search matches nothing, metrics matching it with samples in the last 24h only are reported as existing
+		without samples in the lookback, with the approximate time they were last seen.
+		The same metrics are returned as structured content.`,
 	},
 		mcpTools.ListMetrics,
 	)
-	mcp.AddTool(mcpServer, &mcp.Tool{
+	addTool(registry, &mcp.Tool{
+		Name: "getMetricLabels",
+		Description: `Lists the label names of a metric whose exact name is known, optionally with example values.
+		Cheaper than listMetrics when only the dimensions of one metric are needed.
+		Arguments:
+		- metric_name (required): The exact metric name, e.g. 'container_cpu_usage_seconds_total'.
+		- lookback (optional): How far back to look for series of the metric, e.g. '12h' or '2d' (default: '1h', max: '7d').
+		- include_values (optional): Also return example values of every label, looked up from the series of the metric (default: false).
+		- max_values (optional): Maximum number of example values per label with include_values (default: 5, max: 50).
+		Returns:
+		A markdown table of the label names, with include_values also the number of distinct values and sorted example values per label.`},
+		mcpTools.GetMetricLabels,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMetricLabelValues",
+		Description: `Lists the distinct values of one label of a metric, to build PromQL selectors such as {namespace="shop"}.
+		Arguments:
+		- metric_name (required): The exact metric name, e.g. 'kube_pod_info'.
+		- label (required): The label to list the values of, e.g. 'namespace'. getMetricLabels lists the labels of a metric.
+		- lookback (optional): How far back to look for series of the metric, e.g. '12h' or '2d' (default: '1h', max: '7d').
+		- limit (optional): Maximum number of values listed (default: 100, max: 1000).
+		Returns:
+		A markdown table of the values in sorted order with their distinct count. When there are none, whether the metric has no series or lacks the label, then with the labels it has.`},
+		mcpTools.GetMetricLabelValues,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMetricCardinality",
+		Description: `Reports the cardinality of a metric, to diagnose metrics with so many series that queries get slow or costly.
+		Arguments:
+		- metric_name (required): The exact name of the metric, e.g. 'http_requests_total'.
+		- lookback (optional): How far back to count the series, e.g. '12h' or '2d' (default: 1h, max: 7d).
+		Returns:
+		The number of series, the top offending label and a table of the distinct values of every label, highest first.
+		At most the server's sample limit of series is counted; when the metric has more, a warning says the counts are lower bounds.`},
+		mcpTools.GetMetricCardinality,
+	)
+	addTool(registry, &mcp.Tool{
 		Name: "getMetrics",
 		Description: `Query metrics from SUSE Observability over a range of time.
 		Arguments:
-		- query (required): The PromQL query to execute.
-		- start (required): Start time for the query (e.g., 'now', '1h', '24h').
-		- end (required): End time for the query (e.g., 'now', '1h').
-		- step (optional): Query resolution step width (e.g., '15s', '1m', '5m'). Default: '1m'.
+		- query (optional): The PromQL query to execute.
+		- queries (optional): Instead of query, up to 10 queries to execute concurrently, each with a query and an optional alias,
+		  e.g. [{"query": "...", "alias": "cpu"}, {"query": "...", "alias": "memory"}].
+		- metric (optional): Instead of query, the name of a metric to select without writing PromQL, e.g. 'kube_pod_info'.
+		- labels (optional): With metric, the label values its series must have, e.g. {"namespace": "shop", "pod": "checkout-*"};
+		  a trailing * matches values starting with the rest. The built query is shown above the table.
+		  Exactly one of query, queries or metric is required.
+		- start (required): Start time for the query, a duration back from now (e.g., '1h', '24h') or Grafana style (e.g., 'now-6h'); '/d' aligns it to the start of the day (e.g., 'now-1d/d').
+		- end (optional): End time for the query (e.g., 'now', '1h'), must be after start (default: now).
+		  Raw mode is limited to a range of a few days; use summary mode for longer ranges.
+		- step (optional): Query resolution step width (e.g., '15s', '1m', '5m'). When omitted, a step of at least '1m'
+		  is chosen to keep the number of points per series under a target. Steps producing too many points are coarsened.
+		- format (optional): 'markdown' (default), 'json' or 'csv'.
+		- mode (optional): 'raw' (default) for every point, or 'summary' for one row per series with min, max, mean, p50, p95,
+		  last value, number of samples and the time range covered. Use summary to answer questions like "was it ever above X".
+		- max_series (optional): Maximum number of series in the markdown table (default: 20).
+		- max_rows (optional): Maximum number of rows in the markdown table (default: 500). Every kept series keeps at least its latest point, series beyond the limit are dropped.
+		- layout (optional): 'flat' for one markdown table of all series, 'grouped' for a table per series under a header with its labels,
+		  or 'pivot' for one row per timestamp with a value column per series, e.g. to compare the CPU of a few pods; missing samples show '-'
+		  and pivot is refused above a few series (default: grouped above 3 series, raw mode only).
+		- transpose (optional): true for one row per series with a value column per timestamp (at most 10). A result with a single
+		  timestamp is transposed unless a layout is requested, false keeps the row per timestamp.
+		- rank_by (optional): 'max', 'last' or 'avg' to order series by that value, highest first, so max_series keeps the top series deterministically; the note says how many were dropped.
+		- transform (optional): 'rate', 'increase' or 'irate' to wrap a plain metric selector such as 'http_requests_total{job="api"}'
+		  with that function over a window of the step (at least 2m). Use it for counters (_total) instead of reading their ever growing raw value.
+		  Queries that already call a function are refused.
+		- threshold (optional): Value to flag breaches of, e.g. an SLO target. Breaching rows get a Breach marker, a footer counts the
+		  breaching samples per series with the first and last one, and summary mode adds the share of the window spent breaching.
+		- threshold_direction (optional): 'above' (default) or 'below', whether values greater or smaller than threshold breach it.
+		- detect_anomalies (optional): Flag points deviating from the 10 points before them by their robust z-score (median and
+		  median absolute deviation); a footer states per series how many points are anomalous and between which times (default: false).
+		- anomaly_z_score (optional): Robust z-score above which detect_anomalies flags a point (default: 3.5).
+		- latest_only (optional): One row per series with only its latest sample and its age, for a quick look at current values
+		  over a short range such as start '5m'. Raw mode without a layout only (default: false).
+		- stale_after (optional): Age above which the latest sample is marked stale with latest_only (default: 5m).
+		- humanize (optional): Render byte and second values of the markdown output with units, e.g. '700 MiB' or '235 ms' (default: false).
+		  The unit comes from the metric metadata or name suffix; rates of bytes are bytes/s. JSON, CSV and structured content stay raw.
 		Returns:
-		A markdown table showing the time series data with timestamps, values, and labels.`},
+		The step used, followed by a markdown table showing the time series data with timestamps, values, and labels,
+		where drops of counters (_total, _count, _sum) are marked as "counter reset" rather than real decreases,
+		with a Metric column when the series have different metric names (otherwise the name is stated once above the table),
+		a JSON array of series with their labels and [timestamp, value] points,
+		or CSV with a timestamp, value and sorted label columns header.
+		Every series is also returned as structured content with its labels, timestamps and values.
+		Batched queries are rendered as one section per alias; a failing query is reported in its own section.`},
 		mcpTools.QueryMetric,
 	)
-	mcp.AddTool(mcpServer, &mcp.Tool{
+	addTool(registry, &mcp.Tool{
+		Name: "compareMetrics",
+		Description: `Compares a PromQL query over the current window and a baseline window, e.g. the last hour with the same hour yesterday.
+		Arguments:
+		- query (required): The PromQL query to run over both windows.
+		- window (optional): Length of the windows, the current one ending now (e.g., '1h', default: 1h, max: 7d).
+		- baseline_offset (optional): How far back the baseline window is shifted, at least the window (e.g., '7d' for last week, default: 1d).
+		- step (optional): Query resolution step (default: chosen from the window).
+		- threshold (optional): Change of the mean in percent below which a series is unchanged (default: 10).
+		- limit (optional): Maximum number of series listed (default: 20).
+		Returns:
+		A markdown table with a row per series: its verdict (higher, lower, unchanged, or missing in one of the windows) and
+		its mean, max and p95 in both windows with the absolute and percent delta. Missing series come first, then the
+		largest changes. Structured content holds the windows and every compared series.`},
+		mcpTools.CompareMetrics,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getHistogramQuantiles",
+		Description: `Computes quantiles, e.g. latency percentiles, of a Prometheus histogram over time. Builds the
+		histogram_quantile(q, sum by (le) (rate(<metric>_bucket[window]))) query, so prefer it over writing that query for getMetrics.
+		Arguments:
+		- metric (required): The base name of the histogram without the _bucket suffix, e.g. 'http_server_request_duration_seconds'.
+		- labels (optional): Label values the buckets must have, e.g. {"namespace": "shop"}; a value ending in * matches a prefix.
+		- quantiles (optional): Quantiles between 0 and 1, at most 5 (default: [0.5, 0.9, 0.99]).
+		- window (optional): Window of the rate of the buckets (default: 5m).
+		- by (optional): Comma-separated labels to compute the quantiles per, e.g. 'service' (default: over all buckets).
+		- start (optional): Start time, 'now', a duration or 'now-1h'; '/d' aligns it to the start of the day (default: 1h).
+		- end (optional): End time, 'now' or a duration (default: now).
+		- step (optional): Query resolution step (default: chosen from the range).
+		Returns:
+		The executed query and a markdown table with a row per timestamp (and by group) and a column per quantile.
+		Fails with guidance when the metric has no _bucket series, i.e. is not a histogram.`},
+		mcpTools.GetHistogramQuantiles,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMetricValue",
+		Description: `Evaluates a PromQL instant query and returns the single value of every series, e.g. the current value of a gauge.
+		Prefer it over getMetrics for "what is it right now" questions.
+		Arguments:
+		- query (required): The PromQL query to evaluate. Range vectors such as 'up[5m]' are refused, reduce them with a function like last_over_time.
+		- time (optional): 'now' (default), a duration back from now (e.g., '1h') or 'now-1h' to evaluate the query at; '/d' aligns it to the start of the day.
+		- limit (optional): Maximum number of series listed (default: 20).
+		Returns:
+		A markdown table with a column per label and the value of each series, or the single value of a scalar query.`},
+		mcpTools.GetMetricValue,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "validatePromQL",
+		Description: `Checks the syntax and value types of a PromQL query without executing it. Does not contact SUSE Observability.
+		Use it before getMetrics when unsure about a query, it is much cheaper than a failing range query.
+		Arguments:
+		- query (required): The PromQL query to validate.
+		Returns:
+		"Valid" with the result type (instant vector, range vector, scalar or string) and a table of the metric selectors
+		and label matchers the query references, or the parse error with its line and column.
+		The same information is returned as structured content.`},
+		mcpTools.ValidatePromQL,
+	)
+	addTool(registry, &mcp.Tool{
 		Name: "listMonitors",
 		Description: `Lists monitors for a specific component.
 		Arguments:
 		- component_id (required): The ID of the component to list monitors for (from topology queries).
+		- limit (optional): Maximum number of monitors listed (default: 50).
 		Returns:
-		A markdown table showing monitors associated with the specified component and their current states.`},
+		A markdown table showing monitors associated with the specified component and their current states,
+		followed by the full queries of every listed monitor, which explain why a check fired.`},
 		mcpTools.ListMonitors,
 	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMonitors",
+		Description: `Lists the monitors with results in the given health states across the environment, e.g. to triage an incident.
+		Arguments:
+		- state (optional): Comma-separated health states, any of CRITICAL, DEVIATING, UNKNOWN and CLEAR
+		  (e.g., 'CRITICAL,DEVIATING', default: 'CRITICAL').
+		- limit (optional): Maximum number of monitors listed (default: 50).
+		- merge_duplicates (optional): Merge the monitors installed once per cluster, i.e. with the same name up to a
+		  cluster suffix such as ' (prod)', into one row (default: false).
+		Returns:
+		The number of affected components per requested state summed over the monitors, followed by a markdown table
+		of the matching monitors with their IDs and counts per state, worst first.
+		When merging, a row per group with its clusters (from the cluster tags, the identifier or the name suffix),
+		the IDs of its monitors and the summed counts; the groups and their monitor IDs are also returned as structured content.`},
+		mcpTools.GetMonitors,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMonitor",
+		Description: `Inspects a single monitor in depth, e.g. after getMonitors pointed at it.
+		Arguments:
+		- id (required): The ID or URN of the monitor (from getMonitors).
+		Returns:
+		The monitor definition (ID, URN, function, status, interval, tags), its runtime metrics (counts per health state,
+		last runs and run errors), its thresholds and query, the remediation hint and every affected component, i.e. every
+		CRITICAL, DEVIATING or UNKNOWN check state, with its check state ID and full message.
+		For metric monitors the query is run and the current value of the first 20 affected components is shown
+		next to the threshold of their health state, answering "how far over the threshold are we".`},
+		mcpTools.GetMonitor,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getMonitorCheckStates",
+		Description: `Lists the check states of a monitor, i.e. the components it reports on and their health.
+		Arguments:
+		- monitor (required): The ID or URN of the monitor (from getMonitors).
+		- state (optional): Only list check states in this health state: CRITICAL, DEVIATING, UNKNOWN or CLEAR.
+		- limit (optional): Maximum number of check states listed (default: 500, max: 10000).
+		Returns:
+		The number of listed check states per health state and a markdown table of the components with their health and message.
+		Check states are fetched page by page and fetching stops at the limit, which is reported.`},
+		mcpTools.GetMonitorCheckStates,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "listMonitorsForType",
+		Description: `Lists the monitors whose definition targets a component type or layer, e.g. to check what is already monitored for pods.
+		Arguments (exactly one of type or layer is required):
+		- type (optional): Component type (e.g., 'pod', 'service').
+		- layer (optional): Component layer (e.g., 'Pods', 'Services').
+		- limit (optional): Maximum number of monitors listed (default: 50).
+		Returns:
+		A markdown table of matching monitors with their IDs, intervals, thresholds and a confidence rating.
+		Matching is a best-effort textual analysis of the STQL filters, URN templates and queries of the monitor definitions.`},
+		mcpTools.ListMonitorsForType,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getClusterHealth",
+		Description: `Summarizes the overall health of a cluster in one call.
+		Arguments:
+		- cluster (required): The cluster name (domain), e.g. 'prod-eu'.
+		Returns:
+		A scoreboard of component counts per health state, firing monitor counts per state and the top 5 worst monitors.
+		Sections that cannot be retrieved are reported as unavailable.`},
+		mcpTools.GetClusterHealth,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getNamespaceResourceUsage",
+		Description: `Ranks the namespaces of a cluster by CPU usage, memory working set or pod count in one call.
+		Use it for capacity questions like 'which namespaces use the most memory in cluster X'.
+		Arguments:
+		- cluster (required): The cluster name, e.g. 'prod-eu'.
+		- window (optional): The window usage is averaged over, e.g. '15m' or '1d' (default: '1h', max: '7d').
+		- sort_by (optional): The resource to rank by: 'cpu', 'memory' or 'pods' (default: 'cpu').
+		- limit (optional): Number of top namespaces to show (default: 10).
+		Returns:
+		A markdown table with one row per namespace and the PromQL queries used, which can be passed to getMetrics to drill down.
+		Resources whose metric has no data or fails are shown as '-' and the reason is noted below the table.`},
+		mcpTools.GetNamespaceResourceUsage,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "watchHealth",
+		Description: `Starts or stops background monitor health notifications for the current session.
+		Intended for long-lived sessions, such as the HTTP transport.
+		Arguments:
+		- filter (optional): Only watch monitors whose name contains this text (case-insensitive).
+		- interval_seconds (optional): Polling interval in seconds (default: 60, minimum: 10).
+		- stop (optional): Stop watching health for this session.
+		Returns:
+		A confirmation. Monitors starting or stopping to fire are then sent as 'health-watch' logging notifications,
+		at most one per minute. The watch ends when the session closes.`},
+		mcpTools.WatchHealth,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "getQuerySyntaxHelp",
+		Description: `Returns a built-in STQL or PromQL syntax reference. Does not contact SUSE Observability.
+		Arguments:
+		- language (required): 'stql' or 'promql'.
+		- topic (optional): 'functions', 'operators' or 'examples'. Omit to get all topics.
+		Returns:
+		A concise reference with syntax and examples for the requested language and topic.`},
+		mcpTools.GetQuerySyntaxHelp,
+	)
+	addTool(registry, &mcp.Tool{
+		Name: "healthCheck",
+		Description: `Verifies that this MCP server can reach SUSE Observability and that the token is accepted.
+		Use it first when other tools fail, to tell credential problems from connectivity problems.
+		Returns:
+		The server version, deployment mode, token type (api or service token) and response time.
+		On failure, an error saying whether the token was rejected (401), lacks permissions (403) or the server could not be reached (DNS, TLS, connection, timeout or proxy).`},
+		mcpTools.HealthCheck,
+	)
 
-	if *listenAddr == "" {
-		// Run the server on the stdio transport.
-		if err := mcpServer.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-			slog.Error("Server failed", "error", err)
+	if cfg.EnableWriteTools {
+		addTool(registry, &mcp.Tool{
+			Name: "createAnnotation",
+			Description: `Leaves a trail in SUSE Observability by posting an annotation event, e.g. "MCP agent acknowledged this incident".
+			Only use it when the user asks to record something.
+			Arguments:
+			- message (required): The annotation text (at most 1000 characters). Its first line is used as title.
+			- component_identifier (optional): URN of the component to attach the annotation to (e.g., 'urn:kubernetes:/prod:default:pod/api-1').
+			- tags (optional): Tags to add to the annotation (e.g., ['incident:1234']).
+			Returns:
+			A confirmation with the annotation title.`,
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Create annotation",
+				ReadOnlyHint:    false,
+				DestructiveHint: new(bool),
+			},
+		},
+			mcpTools.CreateAnnotation,
+		)
+	}
+
+	// Saved queries come after the built-in tools, validate keeps them from taking their names
+	for _, q := range cfg.SavedQueries {
+		schema, err := q.InputSchema()
+		if err != nil {
+			panic(fmt.Sprintf("input schema of %s: %v", q.Name, err))
 		}
-	} else {
-		// Create a streamable HTTP handler.
-		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
-			return mcpServer
-		}, nil)
+		addTool(registry, &mcp.Tool{Name: q.Name, Description: q.ToolDescription(), InputSchema: schema}, mcpTools.SavedQuery(q))
+	}
 
-		// Run the server on the HTTP transport.
-		slog.Info("Server listening", "address", *listenAddr)
-		if err := http.ListenAndServe(*listenAddr, handler); err != nil {
-			slog.Error("Server failed", "error", err)
+	// getServerConfig is registered last so that it can report every enabled tool
+	mcpTools.WithServerConfig(cfg.serverConfig(append(registry.names, "getServerConfig")))
+	addTool(registry, &mcp.Tool{
+		Name: "getServerConfig",
+		Description: `Reports the effective, non-secret configuration of this MCP server. Does not contact SUSE Observability.
+		Use it to explain behavior that differs between deployments, such as row caps or disabled tools.
+		Returns:
+		The version, SUSE Observability instance host, transport, token type, request timeout, enabled tools,
+		default time windows and output limits. Credentials are never included.`},
+		mcpTools.GetServerConfig,
+	)
+
+	return registry
+}
+
+// toolRegistry adds tools to a server and remembers their names and how to run them
+type toolRegistry struct {
+	server      *mcp.Server
+	credentials *tools.CredentialMonitor
+	names       []string
+	runners     map[string]toolRunner
+}
+
+// toolRunner calls a tool handler directly with JSON encoded arguments, without a transport
+type toolRunner func(ctx context.Context, arguments json.RawMessage) (*mcp.CallToolResult, error)
+
+func addTool[In, Out any](r *toolRegistry, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	// The schema carries the enum, default and examples tags the SDK does not read
+	if t.InputSchema == nil {
+		schema, err := tools.InputSchema[In]()
+		if err != nil {
+			panic(fmt.Sprintf("input schema of %s: %v", t.Name, err))
+		}
+		t.InputSchema = schema
+	}
+	h = tools.WithCredentialCheck(r.credentials, h)
+	h = tools.WithLogging(t.Name, h)
+	mcp.AddTool(r.server, t, h)
+	r.names = append(r.names, t.Name)
+	r.runners[t.Name] = func(ctx context.Context, arguments json.RawMessage) (*mcp.CallToolResult, error) {
+		var in In
+		decoder := json.NewDecoder(bytes.NewReader(arguments))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&in); err != nil {
+			return nil, fmt.Errorf("invalid arguments of %s: %w", t.Name, err)
+		}
+		result, out, err := h(ctx, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: t.Name, Arguments: arguments}}, in)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = &mcp.CallToolResult{}
+		}
+		// Like the server, the output is also returned as structured content
+		if result.StructuredContent == nil {
+			if structured, err := json.Marshal(out); err == nil && string(structured) != "null" {
+				result.StructuredContent = json.RawMessage(structured)
+			}
 		}
+		return result, nil
 	}
 }
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+	"suse-observability-mcp/internal/tools"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// connect returns a client session on a server built from cfg
+func connect(t *testing.T, cfg config) *mcp.ClientSession {
+	ctx := context.Background()
+	client, err := suseobservability.NewClient("https://observability.example.com", "token", false, 0)
+	require.NoError(t, err)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := newServer(client, cfg, nil).Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// listTools returns the tools of a server built from cfg by name
+func listTools(t *testing.T, cfg config) map[string]*mcp.Tool {
+	listed, err := connect(t, cfg).ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	byName := make(map[string]*mcp.Tool, len(listed.Tools))
+	for _, tool := range listed.Tools {
+		byName[tool.Name] = tool
+	}
+	return byName
+}
+
+func TestNewServer(t *testing.T) {
+	t.Run("write tools are excluded by default", func(t *testing.T) {
+		registered := listTools(t, config{Limits: tools.DefaultLimits()})
+
+		assert.Contains(t, registered, "getComponents")
+		assert.NotContains(t, registered, "createAnnotation")
+	})
+
+	t.Run("write tools are registered when enabled", func(t *testing.T) {
+		registered := listTools(t, config{Limits: tools.DefaultLimits(), EnableWriteTools: true, Secrets: secrets{ReceiverAPIKey: "key"}})
+
+		require.Contains(t, registered, "createAnnotation")
+		annotations := registered["createAnnotation"].Annotations
+		require.NotNil(t, annotations)
+		assert.False(t, annotations.ReadOnlyHint)
+		require.NotNil(t, annotations.DestructiveHint)
+		assert.False(t, *annotations.DestructiveHint)
+	})
+
+	t.Run("saved queries are registered as tools", func(t *testing.T) {
+		queries, err := tools.ParseSavedQueries([]byte(`{"namespaceRestarts": {
+			"description": "Container restarts of a namespace.",
+			"query": "sum(increase(kube_pod_container_status_restarts_total{namespace=\"$namespace\"}[1h]))",
+			"params": [{"name": "namespace"}]
+		}}`))
+		require.NoError(t, err)
+
+		cfg := config{Limits: tools.DefaultLimits(), SavedQueries: queries}
+		registered := listTools(t, cfg)
+
+		require.Contains(t, registered, "namespaceRestarts")
+		assert.Contains(t, registered["namespaceRestarts"].Description, "Container restarts of a namespace.")
+		b, err := json.Marshal(registered["namespaceRestarts"].InputSchema)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"required":["namespace"]`)
+		assert.Contains(t, newRegistry(nil, cfg, nil).names, "namespaceRestarts")
+	})
+}
+
+func TestToolSchemas(t *testing.T) {
+	registered := listTools(t, config{Limits: tools.DefaultLimits(), EnableWriteTools: true, Secrets: secrets{ReceiverAPIKey: "key"}})
+
+	t.Run("closed sets are enums and defaults are set", func(t *testing.T) {
+		property := func(tool, name string) map[string]any {
+			b, err := json.Marshal(registered[tool].InputSchema)
+			require.NoError(t, err)
+			var schema struct {
+				Properties map[string]map[string]any `json:"properties"`
+			}
+			require.NoError(t, json.Unmarshal(b, &schema))
+			require.Contains(t, schema.Properties, name)
+			return schema.Properties[name]
+		}
+
+		assert.Equal(t, []any{"markdown", "json", "csv"}, property("getMetrics", "format")["enum"])
+		assert.Equal(t, "markdown", property("getMetrics", "format")["default"])
+		assert.Equal(t, []any{"raw", "summary"}, property("getMetrics", "mode")["enum"])
+		assert.Equal(t, []any{"up", "down", "both"}, property("getComponents", "with_neighbors_direction")["enum"])
+		assert.Equal(t, "CRITICAL", property("getMonitors", "state")["default"])
+		assert.Equal(t, []any{"CRITICAL", "CRITICAL,DEVIATING"}, property("getMonitors", "state")["examples"])
+		assert.Equal(t, float64(50), property("getEvents", "limit")["default"])
+		assert.Equal(t, []any{"15m", "2d"}, property("getEvents", "lookback")["examples"])
+	})
+
+	t.Run("schemas match the snapshot", func(t *testing.T) {
+		schemas := make(map[string]any, len(registered))
+		for name, tool := range registered {
+			schemas[name] = tool.InputSchema
+		}
+		output, err := json.MarshalIndent(schemas, "", "  ")
+		require.NoError(t, err)
+
+		golden := filepath.Join("testdata", "tool_schemas.json.golden")
+		if *update {
+			require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+			require.NoError(t, os.WriteFile(golden, output, 0o644))
+		}
+		expected, err := os.ReadFile(golden)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(output), "run go test ./cmd/server -update after changing a params struct")
+	})
+}
+
+func TestGetServerConfig(t *testing.T) {
+	const secret = "s3cr3t-t0ken"
+
+	// The URL is misconfigured to carry the token as user info and query parameter
+	cfg := config{
+		URL:              "https://admin:" + secret + "@observability.example.com/?token=" + secret,
+		ListenAddr:       ":8080",
+		RequestTimeout:   45 * time.Second,
+		Limits:           tools.DefaultLimits(),
+		EnableWriteTools: true,
+		Secrets:          secrets{Token: secret, ReceiverAPIKey: secret},
+	}
+
+	result, err := connect(t, cfg).CallTool(context.Background(), &mcp.CallToolParams{Name: "getServerConfig", Arguments: map[string]any{}})
+
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	structured, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+
+	for _, output := range []string{text, string(structured)} {
+		assert.NotContains(t, output, secret)
+		assert.NotContains(t, output, "admin")
+	}
+	assert.Contains(t, text, "- Instance: observability.example.com\n")
+	assert.Contains(t, text, "- Transport: http\n")
+	assert.Contains(t, text, "- Request timeout: 45s\n")
+	assert.Contains(t, text, "createAnnotation, getServerConfig\n")
+	assert.Contains(t, text, "| Metric max series | 20 |")
+
+	var reported tools.ServerConfig
+	require.NoError(t, json.Unmarshal(structured, &reported))
+	assert.Equal(t, "v0.0.1", reported.Version)
+	assert.True(t, reported.WriteToolsEnabled)
+	assert.Contains(t, reported.EnabledTools, "getComponents")
+	assert.Equal(t, "1h", reported.DefaultWindows["listMetrics"])
+}
+
+func TestValidatePromQL(t *testing.T) {
+	session := connect(t, config{Limits: tools.DefaultLimits()})
+
+	for _, query := range []string{`sum by (pod) (rate(http_requests_total{code=~"5.."}[5m]))`, `rate(http_requests_total)`, `1 + 1`} {
+		t.Run(query, func(t *testing.T) {
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "validatePromQL", Arguments: map[string]any{"query": query}})
+
+			require.NoError(t, err)
+			assert.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+			assert.NotNil(t, result.StructuredContent)
+		})
+	}
+}
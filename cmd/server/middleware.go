@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// logRequests wraps the handler of the http transport so that every request is logged with
+// its method, path, remote address, duration and status. A panic of a request is recovered
+// and logged with its stack, answered with a 500 when nothing was written yet, so one bad
+// request cannot crash the server.
+func logRequests(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			attrs := []any{"method", r.Method, "path", r.URL.Path, "remote_addr", r.RemoteAddr}
+			if p := recover(); p != nil {
+				// The server aborts the response silently on ErrAbortHandler, keep that behavior
+				if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(p)
+				}
+				if rec.status == 0 {
+					http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				logger.Error("HTTP request panicked", append(attrs, "duration", time.Since(start), "status", rec.status, "panic", p, "stack", string(debug.Stack()))...)
+				return
+			}
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			logger.Info("HTTP request", append(attrs, "duration", time.Since(start), "status", rec.status)...)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// requireAuthToken wraps the handler of the http transport so that only requests sending
+// token as bearer token in the Authorization header are served, the others are answered
+// with a 401. The token is compared in constant time.
+func requireAuthToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="suse-observability-mcp"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status of a response. It passes flushes through, the
+// streamable transport flushes its server-sent events.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequests(t *testing.T) {
+	serve := func(handler http.HandlerFunc) (*httptest.ResponseRecorder, map[string]any) {
+		var logs bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logs, nil))
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		rec := httptest.NewRecorder()
+
+		logRequests(logger, handler).ServeHTTP(rec, req)
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		return rec, entry
+	}
+
+	t.Run("logs the request", func(t *testing.T) {
+		rec, entry := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "HTTP request", entry["msg"])
+		assert.Equal(t, "POST", entry["method"])
+		assert.Equal(t, "/mcp", entry["path"])
+		assert.Equal(t, "192.0.2.1:1234", entry["remote_addr"])
+		assert.Equal(t, float64(http.StatusAccepted), entry["status"])
+		assert.Contains(t, entry, "duration")
+	})
+
+	t.Run("implicit ok status", func(t *testing.T) {
+		_, entry := serve(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+	})
+
+	t.Run("recovers from a panic", func(t *testing.T) {
+		rec, entry := serve(func(w http.ResponseWriter, r *http.Request) {
+			panic("bad request")
+		})
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "HTTP request panicked", entry["msg"])
+		assert.Equal(t, "ERROR", entry["level"])
+		assert.Equal(t, "bad request", entry["panic"])
+		assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+		assert.Contains(t, entry["stack"], "middleware_test.go")
+	})
+
+	t.Run("passes flushes through", func(t *testing.T) {
+		rec, _ := serve(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, http.NewResponseController(w).Flush())
+		})
+
+		assert.True(t, rec.Flushed)
+	})
+}
+
+func TestRequireAuthToken(t *testing.T) {
+	handler := requireAuthToken("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{name: "matching token", authorization: "Bearer secret", status: http.StatusAccepted},
+		{name: "missing header", status: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer guess", status: http.StatusUnauthorized},
+		{name: "token without scheme", authorization: "secret", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="suse-observability-mcp"`, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
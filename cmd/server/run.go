@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"suse-observability-mcp/internal/tools"
+)
+
+// Exit codes of -run-tool
+const (
+	exitOK      = 0
+	exitPartial = 1
+	exitError   = 2
+)
+
+// runTool runs a single tool with params, a JSON object of its arguments, and writes its
+// output to stdout in format, 'text' or 'json'; in text format errors go to stderr. It
+// returns exitOK when the tool succeeded, exitPartial when its result lacks a failed part,
+// e.g. an unavailable section of getClusterHealth, and exitError when the tool is unknown
+// or failed.
+//
+// The handler is called directly, so unlike over a transport the arguments are not
+// validated against the input schema, only decoded; unknown arguments are rejected.
+func runTool(ctx context.Context, stdout, stderr io.Writer, r *toolRegistry, name, params, format string) int {
+	run, ok := r.runners[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown tool '%s', must be one of %s\n", name, strings.Join(r.names, ", "))
+		return exitError
+	}
+
+	result, err := run(ctx, json.RawMessage(params))
+	if err != nil {
+		// Mirror the server, which reports handler errors as error results
+		result = &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}
+	}
+
+	if format == runFormatJSON {
+		// The whole result goes to stdout, error results included, so scripts parse one document
+		encoded, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to encode the result of %s: %v\n", name, err)
+			return exitError
+		}
+		fmt.Fprintln(stdout, string(encoded))
+	} else {
+		out := stdout
+		if result.IsError {
+			out = stderr
+		}
+		for _, c := range result.Content {
+			if text, ok := c.(*mcp.TextContent); ok {
+				fmt.Fprintln(out, strings.TrimRight(text.Text, "\n"))
+			}
+		}
+	}
+
+	switch {
+	case result.IsError:
+		return exitError
+	case tools.IsPartial(result):
+		return exitPartial
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+	"suse-observability-mcp/internal/tools"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient answers the requests of the tools run in the tests, other methods panic
+type fakeClient struct {
+	tools.SuseObservabilityClient
+	events      []suseobservability.TopologyEvent
+	topologyErr error
+}
+
+func (c fakeClient) GetEvents(ctx context.Context, req *suseobservability.EventListRequest) (*suseobservability.EventItemsWithTotal, error) {
+	return &suseobservability.EventItemsWithTotal{Items: c.events, Total: int64(len(c.events))}, nil
+}
+
+func (c fakeClient) SnapShotTopologyQuery(ctx context.Context, query string) ([]suseobservability.ViewComponent, error) {
+	return nil, c.topologyErr
+}
+
+func (c fakeClient) GetMonitorsOverview(ctx context.Context) (*suseobservability.MonitorOverviewList, error) {
+	return &suseobservability.MonitorOverviewList{}, nil
+}
+
+func TestRunTool(t *testing.T) {
+	deployed := suseobservability.TopologyEvent{
+		Name:      "Deployment checkout updated",
+		Category:  suseobservability.EventCategoryDeployments,
+		Source:    "Kubernetes",
+		EventTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).UnixMilli(),
+	}
+	client := fakeClient{events: []suseobservability.TopologyEvent{deployed}, topologyErr: errors.New("topology unavailable")}
+	registry := newRegistry(client, config{Limits: tools.DefaultLimits()}, nil)
+
+	run := func(name, params, format string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runTool(context.Background(), &stdout, &stderr, registry, name, params, format)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("successful tool exits 0 with its text output", func(t *testing.T) {
+		code, stdout, stderr := run("getEvents", `{"component_id": 42}`, runFormatText)
+
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "Events of component ID 42 in the last 1h")
+		assert.Contains(t, stdout, "| 2026-01-02T03:04:05Z | Deployments | Deployment checkout updated | Kubernetes |")
+		assert.Empty(t, stderr)
+	})
+
+	t.Run("partial result exits 1", func(t *testing.T) {
+		code, stdout, stderr := run("getClusterHealth", `{"cluster": "prod-eu"}`, runFormatText)
+
+		assert.Equal(t, exitPartial, code)
+		assert.Contains(t, stdout, "topology unavailable")
+		assert.Empty(t, stderr)
+	})
+
+	t.Run("json format prints the whole result", func(t *testing.T) {
+		code, stdout, _ := run("getClusterHealth", `{"cluster": "prod-eu"}`, runFormatJSON)
+
+		assert.Equal(t, exitPartial, code)
+		var result struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+			Meta map[string]any `json:"_meta"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(stdout), &result))
+		require.Len(t, result.Content, 1)
+		assert.Equal(t, "text", result.Content[0].Type)
+		assert.Equal(t, true, result.Meta["partial"])
+	})
+
+	t.Run("tool error exits 2 on stderr", func(t *testing.T) {
+		code, stdout, stderr := run("getClusterHealth", `{}`, runFormatText)
+
+		assert.Equal(t, exitError, code)
+		assert.Empty(t, stdout)
+		assert.NotEmpty(t, stderr)
+	})
+
+	t.Run("unknown argument exits 2", func(t *testing.T) {
+		code, _, stderr := run("getEvents", `{"component": 42}`, runFormatText)
+
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, `invalid arguments of getEvents: json: unknown field "component"`)
+	})
+
+	t.Run("unknown tool exits 2", func(t *testing.T) {
+		code, _, stderr := run("getEverything", `{}`, runFormatText)
+
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "unknown tool 'getEverything', must be one of getComponents, getComponent, topologyDiff, validateSTQL, listViews")
+	})
+}
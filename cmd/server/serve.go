@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// shutdownTimeout is how long in-flight requests of the http transport may take to complete
+// once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// serveHTTP serves the http transport on ln until ctx is cancelled, e.g. by SIGTERM, then
+// stops accepting connections and waits up to timeout for in-flight requests to complete.
+// Requests still running after timeout, such as open event streams, are closed.
+func serveHTTP(ctx context.Context, logger *slog.Logger, ln net.Listener, h http.Handler, timeout time.Duration) error {
+	srv := &http.Server{Handler: h}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, waiting for in-flight requests", "timeout", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		_ = srv.Close()
+		return fmt.Errorf("in-flight requests did not complete within %s: %w", timeout, err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Info("Server stopped")
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTP(t *testing.T) {
+	// start serves a handler blocking each request until release is closed, and returns the
+	// address, a channel receiving a value per started request, a channel receiving the
+	// result of serveHTTP and the function stopping it
+	start := func(t *testing.T, release <-chan struct{}, timeout time.Duration) (string, chan struct{}, chan error, context.CancelFunc) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		started := make(chan struct{}, 1)
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			select {
+			case <-release:
+				_, _ = io.WriteString(w, "done")
+			case <-r.Context().Done():
+			}
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		done := make(chan error, 1)
+		go func() {
+			done <- serveHTTP(ctx, slog.New(slog.DiscardHandler), ln, h, timeout)
+		}()
+		return "http://" + ln.Addr().String(), started, done, cancel
+	}
+
+	t.Run("in-flight requests complete before shutdown", func(t *testing.T) {
+		release := make(chan struct{})
+		url, started, done, stop := start(t, release, 5*time.Second)
+		responses := make(chan string, 1)
+		go func() {
+			resp, err := http.Get(url)
+			if err != nil {
+				responses <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			responses <- string(body)
+		}()
+		<-started
+
+		stop()
+		select {
+		case err := <-done:
+			t.Fatalf("server stopped before the in-flight request completed: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+
+		assert.Equal(t, "done", <-responses)
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("server did not stop after the in-flight request completed")
+		}
+		_, err := http.Get(url)
+		assert.Error(t, err, "the server still accepts connections")
+	})
+
+	t.Run("requests running past the timeout are closed", func(t *testing.T) {
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+		url, started, done, stop := start(t, release, 50*time.Millisecond)
+		go func() {
+			if resp, err := http.Get(url); err == nil {
+				resp.Body.Close()
+			}
+		}()
+		<-started
+
+		stop()
+		select {
+		case err := <-done:
+			assert.EqualError(t, err, "in-flight requests did not complete within 50ms: context deadline exceeded")
+		case <-time.After(time.Second):
+			t.Fatal("server did not stop within the shutdown timeout")
+		}
+	})
+
+	t.Run("listener errors are returned", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		require.NoError(t, ln.Close())
+
+		err = serveHTTP(context.Background(), slog.New(slog.DiscardHandler), ln, http.NotFoundHandler(), time.Second)
+
+		assert.Error(t, err)
+	})
+}
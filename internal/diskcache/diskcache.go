@@ -0,0 +1,120 @@
+// Package diskcache stores JSON values in a directory, so data survives the short-lived
+// server processes some MCP hosts start per conversation.
+package diskcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileSuffix is the suffix of the cache entries, Clear only removes files with it
+const fileSuffix = ".json"
+
+// Cache is a directory of JSON entries, each stamped with the time it was stored
+type Cache struct {
+	dir string
+	now func() time.Time
+}
+
+// entry is the content of a cache file
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// New returns a cache storing its entries in dir, which is created when missing
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, now: time.Now}, nil
+}
+
+// Get decodes the entry of key into v when it was stored less than ttl ago. Missing and
+// expired entries are a miss, as are unreadable or corrupted ones, which are removed so the
+// caller refetches and stores them again.
+func (c *Cache) Get(key string, ttl time.Duration, v any) bool {
+	path := c.path(key)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(b, &e); err != nil || e.Value == nil {
+		_ = os.Remove(path)
+		return false
+	}
+	if c.now().Sub(e.StoredAt) >= ttl {
+		return false
+	}
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		_ = os.Remove(path)
+		return false
+	}
+	return true
+}
+
+// Put stores v as the entry of key. The entry is written to a temporary file renamed over
+// the previous one, so concurrent readers never see a partial entry.
+func (c *Cache) Put(key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(entry{StoredAt: c.now(), Value: value})
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// Clear removes every entry of the cache, leaving other files of the directory
+func (c *Cache) Clear() error {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), fileSuffix) {
+			if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// path returns the file of key, with the characters other than letters, digits, '-' and '.'
+// replaced so keys cannot escape the directory
+func (c *Cache) path(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '_'
+	}, key)
+	return filepath.Join(c.dir, name+fileSuffix)
+}
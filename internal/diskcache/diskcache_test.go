@@ -0,0 +1,96 @@
+package diskcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	newCache := func(t *testing.T) (*Cache, *time.Time) {
+		cache, err := New(filepath.Join(t.TempDir(), "instance"))
+		require.NoError(t, err)
+		now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		cache.now = func() time.Time { return now }
+		return cache, &now
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		cache, _ := newCache(t)
+		require.NoError(t, cache.Put("metric-names-1h", []string{"up", "cpu"}))
+
+		var names []string
+		assert.True(t, cache.Get("metric-names-1h", time.Minute, &names))
+		assert.Equal(t, []string{"up", "cpu"}, names)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		cache, _ := newCache(t)
+		require.NoError(t, cache.Put("key", 1))
+		require.NoError(t, cache.Put("key", 2))
+
+		var v int
+		assert.True(t, cache.Get("key", time.Minute, &v))
+		assert.Equal(t, 2, v)
+		files, err := os.ReadDir(cache.dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 1, "no temporary files are left behind")
+	})
+
+	t.Run("missing entry", func(t *testing.T) {
+		cache, _ := newCache(t)
+
+		var v int
+		assert.False(t, cache.Get("key", time.Minute, &v))
+	})
+
+	t.Run("expired entry", func(t *testing.T) {
+		cache, now := newCache(t)
+		require.NoError(t, cache.Put("key", 1))
+
+		*now = now.Add(time.Minute)
+		var v int
+		assert.False(t, cache.Get("key", time.Minute, &v))
+		assert.True(t, cache.Get("key", time.Hour, &v))
+	})
+
+	t.Run("corrupted entries are removed", func(t *testing.T) {
+		cache, _ := newCache(t)
+		for key, content := range map[string]string{
+			"truncated":  `{"stored_at":"2026-01-01T12:00:00Z","val`,
+			"no value":   `{"stored_at":"2026-01-01T12:00:00Z"}`,
+			"wrong type": `{"stored_at":"2026-01-01T12:00:00Z","value":"text"}`,
+		} {
+			require.NoError(t, os.WriteFile(cache.path(key), []byte(content), 0o600))
+
+			var v int
+			assert.False(t, cache.Get(key, time.Hour, &v), key)
+			assert.NoFileExists(t, cache.path(key), key)
+		}
+	})
+
+	t.Run("keys stay in the directory", func(t *testing.T) {
+		cache, _ := newCache(t)
+
+		assert.Equal(t, filepath.Join(cache.dir, ".._.._etc_passwd.json"), cache.path("../../etc/passwd"))
+	})
+
+	t.Run("clear removes the entries only", func(t *testing.T) {
+		cache, _ := newCache(t)
+		require.NoError(t, cache.Put("a", 1))
+		require.NoError(t, cache.Put("b", 2))
+		other := filepath.Join(cache.dir, "notes.txt")
+		require.NoError(t, os.WriteFile(other, []byte("keep"), 0o600))
+
+		require.NoError(t, cache.Clear())
+
+		var v int
+		assert.False(t, cache.Get("a", time.Hour, &v))
+		assert.False(t, cache.Get("b", time.Hour, &v))
+		assert.FileExists(t, other)
+	})
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type CreateAnnotationParams struct {
+	Message             string   `json:"message" jsonschema:"required,The annotation text (at most 1000 characters)"`
+	ComponentIdentifier string   `json:"component_identifier,omitempty" jsonschema:"URN of the component to attach the annotation to (e.g. 'urn:kubernetes:/prod:default:pod/api-1')" examples:"urn:kubernetes:/prod:default:pod/api-1"`
+	Tags                []string `json:"tags,omitempty" jsonschema:"Tags to add to the annotation (e.g. 'incident:1234')" examples:"incident:1234;source:deploy"`
+}
+
+const (
+	// maxAnnotationLength is the maximum number of characters of an annotation message
+	maxAnnotationLength = 1000
+	// maxAnnotationTitle is the maximum number of characters of the title derived from the message
+	maxAnnotationTitle  = 100
+	annotationEventType = "MCPAnnotation"
+	annotationSource    = "mcp"
+)
+
+// CreateAnnotation posts a custom event to the receiver, optionally attached to a component
+func (t tool) CreateAnnotation(ctx context.Context, request *mcp.CallToolRequest, params CreateAnnotationParams) (*mcp.CallToolResult, any, error) {
+	message := strings.TrimSpace(params.Message)
+	if message == "" {
+		return nil, nil, fmt.Errorf("message is required")
+	}
+	if n := utf8.RuneCountInString(message); n > maxAnnotationLength {
+		return nil, nil, fmt.Errorf("message is %d characters long, at most %d are allowed", n, maxAnnotationLength)
+	}
+
+	identifiers := []string{}
+	if id := params.ComponentIdentifier; id != "" {
+		if !strings.HasPrefix(id, "urn:") || strings.IndexFunc(id, unicode.IsSpace) >= 0 {
+			return nil, nil, fmt.Errorf("invalid component_identifier '%s'. Must be a URN starting with 'urn:' without whitespace", id)
+		}
+		identifiers = append(identifiers, id)
+	}
+
+	tags := []string{"source:" + annotationSource}
+	for _, tag := range params.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	title, _, _ := strings.Cut(message, "\n")
+	event := suseobservability.IntakeEvent{
+		Context: suseobservability.IntakeEventContext{
+			Category:           "Activities",
+			Data:               map[string]any{},
+			ElementIdentifiers: identifiers,
+			Source:             annotationSource,
+			SourceLinks:        []any{},
+		},
+		EventType:      annotationEventType,
+		MsgTitle:       Truncate(title, maxAnnotationTitle),
+		MsgText:        message,
+		SourceTypeName: annotationEventType,
+		Tags:           tags,
+		Timestamp:      t.clock.Now().Unix(),
+	}
+	if err := t.client.PostEvent(ctx, event); err != nil {
+		return nil, nil, fmt.Errorf("failed to create annotation: %w", err)
+	}
+
+	target := "without a component"
+	if len(identifiers) > 0 {
+		target = fmt.Sprintf("on component '%s'", identifiers[0])
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: fmt.Sprintf("Created annotation '%s' %s.", event.MsgTitle, target),
+			},
+		},
+	}, nil, nil
+}
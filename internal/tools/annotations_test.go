@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAnnotation(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	t.Run("posts an event attached to the component", func(t *testing.T) {
+		var posted suseobservability.IntakeEvent
+		mockClient.On("PostEvent", ctx, mock.AnythingOfType("suseobservability.IntakeEvent")).
+			Run(func(args mock.Arguments) { posted = args.Get(1).(suseobservability.IntakeEvent) }).
+			Return(nil).Once()
+
+		result, _, err := tools.CreateAnnotation(ctx, nil, CreateAnnotationParams{
+			Message:             "MCP agent acknowledged this incident\nRestarting the pod.",
+			ComponentIdentifier: "urn:kubernetes:/prod:default:pod/api-1",
+			Tags:                []string{"incident:1234", " "},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Created annotation 'MCP agent acknowledged this incident' on component 'urn:kubernetes:/prod:default:pod/api-1'.",
+			result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, "MCPAnnotation", posted.EventType)
+		assert.Equal(t, "MCP agent acknowledged this incident", posted.MsgTitle)
+		assert.Equal(t, "MCP agent acknowledged this incident\nRestarting the pod.", posted.MsgText)
+		assert.Equal(t, []string{"urn:kubernetes:/prod:default:pod/api-1"}, posted.Context.ElementIdentifiers)
+		assert.Equal(t, []string{"source:mcp", "incident:1234"}, posted.Tags)
+		assert.NotZero(t, posted.Timestamp)
+	})
+
+	t.Run("without a component", func(t *testing.T) {
+		mockClient.On("PostEvent", ctx, mock.MatchedBy(func(e suseobservability.IntakeEvent) bool {
+			return len(e.Context.ElementIdentifiers) == 0 && e.Context.ElementIdentifiers != nil
+		})).Return(nil).Once()
+
+		result, _, err := tools.CreateAnnotation(ctx, nil, CreateAnnotationParams{Message: "Deploy started"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Created annotation 'Deploy started' without a component.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			params CreateAnnotationParams
+			err    string
+		}{
+			{params: CreateAnnotationParams{Message: "  "}, err: "message is required"},
+			{params: CreateAnnotationParams{Message: strings.Repeat("é", 1001)}, err: "message is 1001 characters long, at most 1000 are allowed"},
+			{params: CreateAnnotationParams{Message: "ok", ComponentIdentifier: "api-1"}, err: "invalid component_identifier 'api-1'. Must be a URN starting with 'urn:' without whitespace"},
+			{params: CreateAnnotationParams{Message: "ok", ComponentIdentifier: "urn:pod/api 1"}, err: "invalid component_identifier 'urn:pod/api 1'. Must be a URN starting with 'urn:' without whitespace"},
+		}
+
+		for _, tt := range tests {
+			result, _, err := tools.CreateAnnotation(ctx, nil, tt.params)
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mockClient.On("PostEvent", ctx, mock.Anything).Return(errors.New("client error")).Once()
+
+		result, _, err := tools.CreateAnnotation(ctx, nil, CreateAnnotationParams{Message: "ok"})
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "client error")
+	})
+}
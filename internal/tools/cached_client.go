@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+	"suse-observability-mcp/internal/diskcache"
+)
+
+const (
+	// metricNamesTTL is how long the metric names of a lookback are served from the disk cache
+	metricNamesTTL = 10 * time.Minute
+	// metricMetadataTTL is how long the metric metadata is served from the disk cache
+	metricMetadataTTL = time.Hour
+)
+
+// cachedClient serves the slow-changing discovery data of a client from a disk cache. Query
+// results and anything else depending on the request are always fetched.
+type cachedClient struct {
+	SuseObservabilityClient
+	cache *diskcache.Cache
+}
+
+// WithDiskCache returns a client serving the metric names and the metric metadata from the
+// cache while they are fresh, fetching them from client otherwise
+func WithDiskCache(client SuseObservabilityClient, cache *diskcache.Cache) SuseObservabilityClient {
+	return &cachedClient{SuseObservabilityClient: client, cache: cache}
+}
+
+// ListMetrics caches the names per lookback, so listMetrics calls with the same lookback
+// share them while their windows slide
+func (c *cachedClient) ListMetrics(ctx context.Context, start, end time.Time) ([]string, error) {
+	key := fmt.Sprintf("metric-names-%s", formatLookback(end.Sub(start).Round(time.Minute)))
+	var names []string
+	if c.cache.Get(key, metricNamesTTL, &names) {
+		return names, nil
+	}
+	names, err := c.SuseObservabilityClient.ListMetrics(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, names)
+	return names, nil
+}
+
+func (c *cachedClient) GetMetricMetadata(ctx context.Context) (map[string][]suseobservability.MetricMetadata, error) {
+	const key = "metric-metadata"
+	var metadata map[string][]suseobservability.MetricMetadata
+	if c.cache.Get(key, metricMetadataTTL, &metadata) {
+		return metadata, nil
+	}
+	metadata, err := c.SuseObservabilityClient.GetMetricMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, metadata)
+	return metadata, nil
+}
+
+// put stores an entry, a failure only costs a refetch next time
+func (c *cachedClient) put(key string, v any) {
+	if err := c.cache.Put(key, v); err != nil {
+		slog.Warn("Disk cache write failed", "key", key, "error", err)
+	}
+}
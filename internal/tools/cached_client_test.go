@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+	"suse-observability-mcp/internal/diskcache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDiskCache(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(t *testing.T, dir string) (*MockSuseObservabilityClient, SuseObservabilityClient) {
+		cache, err := diskcache.New(dir)
+		require.NoError(t, err)
+		mockClient := new(MockSuseObservabilityClient)
+		return mockClient, WithDiskCache(mockClient, cache)
+	}
+
+	t.Run("metric names are cached per lookback across clients", func(t *testing.T) {
+		dir := t.TempDir()
+		first, client := newClient(t, dir)
+		first.On("ListMetrics", ctx, mock.Anything, mock.Anything).Return([]string{"up"}, nil).Once()
+		end := time.Now()
+
+		names, err := client.ListMetrics(ctx, end.Add(-time.Hour), end)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up"}, names)
+
+		// A new process with the same cache directory, a second later
+		second, client := newClient(t, dir)
+		names, err = client.ListMetrics(ctx, end.Add(-time.Hour+time.Second), end.Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up"}, names)
+		second.AssertNotCalled(t, "ListMetrics", mock.Anything, mock.Anything, mock.Anything)
+
+		second.On("ListMetrics", ctx, mock.Anything, mock.Anything).Return([]string{"up", "cpu"}, nil).Once()
+		names, err = client.ListMetrics(ctx, end.Add(-2*time.Hour), end)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"up", "cpu"}, names)
+		first.AssertExpectations(t)
+		second.AssertExpectations(t)
+	})
+
+	t.Run("metric metadata is cached", func(t *testing.T) {
+		mockClient, client := newClient(t, t.TempDir())
+		metadata := map[string][]suseobservability.MetricMetadata{"up": {{Type: "gauge"}}}
+		mockClient.On("GetMetricMetadata", ctx).Return(metadata, nil).Once()
+
+		for range 2 {
+			got, err := client.GetMetricMetadata(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, metadata, got)
+		}
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		mockClient, client := newClient(t, t.TempDir())
+		mockClient.On("GetMetricMetadata", ctx).Return(nil, errors.New("unavailable")).Once()
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{}, nil).Once()
+
+		_, err := client.GetMetricMetadata(ctx)
+		assert.EqualError(t, err, "unavailable")
+		_, err = client.GetMetricMetadata(ctx)
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("queries are never cached", func(t *testing.T) {
+		mockClient, client := newClient(t, t.TempDir())
+		mockClient.On("QueryMetric", ctx, "up", mock.Anything, "").Return(&suseobservability.MetricQueryResponse{}, nil).Twice()
+
+		for range 2 {
+			_, err := client.QueryMetric(ctx, "up", time.Now(), "")
+			require.NoError(t, err)
+		}
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMonitorCheckStatesParams struct {
+	Monitor string `json:"monitor" jsonschema:"required,The ID or URN of the monitor"`
+	State   string `json:"state,omitempty" jsonschema:"Only list check states in this health state, one of CRITICAL, DEVIATING, UNKNOWN or CLEAR" examples:"CRITICAL;DEVIATING;UNKNOWN;CLEAR"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of check states listed (default: 500, max: 10000)" default:"500"`
+}
+
+const (
+	defaultCheckStateLimit = 500
+	maxCheckStateLimit     = 10000
+	// checkStatePageSize is the number of check states fetched per request
+	checkStatePageSize = 200
+)
+
+// GetMonitorCheckStates lists the check states of a monitor. Monitors can affect tens of
+// thousands of elements, so the states are fetched and rendered page by page, stopping at
+// the limit.
+func (t tool) GetMonitorCheckStates(ctx context.Context, request *mcp.CallToolRequest, params GetMonitorCheckStatesParams) (*mcp.CallToolResult, any, error) {
+	monitor := strings.TrimSpace(params.Monitor)
+	if monitor == "" {
+		return nil, nil, fmt.Errorf("monitor is required")
+	}
+	state := strings.ToUpper(strings.TrimSpace(params.State))
+	if state != "" && !slices.Contains(healthStateOrder, state) {
+		return nil, nil, fmt.Errorf("invalid state %q, must be one of %s", params.State, strings.Join(healthStateOrder, ", "))
+	}
+	limit, err := displayLimit(params.Limit, defaultCheckStateLimit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > maxCheckStateLimit {
+		return nil, nil, fmt.Errorf("limit must be at most %d, got %d", maxCheckStateLimit, limit)
+	}
+
+	var (
+		rows      strings.Builder
+		counts    = map[string]int{}
+		seen      = map[string]bool{}
+		listed    int
+		truncated bool
+	)
+	err = t.client.EachMonitorCheckStatePage(ctx, monitor, state, min(checkStatePageSize, limit), 0, func(states []suseobservability.ViewCheckState) (bool, error) {
+		for _, s := range states {
+			if seen[s.CheckStateId] {
+				continue
+			}
+			if listed == limit {
+				truncated = true
+				return false, nil
+			}
+			seen[s.CheckStateId] = true
+			listed++
+			counts[valueOrDash(s.Health)]++
+			rows.WriteString(fmt.Sprintf("| %d | %s | %s | %s |\n", s.TopologyElementId, valueOrDash(s.Name), valueOrDash(s.Health), valueOrDash(Truncate(s.Message, 100))))
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get check states of monitor '%s': %w", monitor, err)
+	}
+
+	filter := ""
+	if state != "" {
+		filter = fmt.Sprintf(" in state %s", state)
+	}
+	if listed == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No check states%s found for monitor '%s'.", filter, monitor),
+				},
+			},
+		}, nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Listed %d check state(s)%s of monitor '%s':\n\n", listed, filter, monitor))
+	writeStateCounts(&sb, counts, nil)
+	sb.WriteString("| Component ID | Name | Health | Message |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	sb.WriteString(rows.String())
+	if truncated {
+		sb.WriteString(fmt.Sprintf("\nStopped after %d check states, the monitor has more. Pass a higher limit (max %d) or a state to see others.\n", limit, maxCheckStateLimit))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, nil, nil
+}
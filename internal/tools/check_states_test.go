@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkStatePages returns pages of check states with ids starting at first
+func checkStatePages(first, pages, size int, health string) [][]suseobservability.ViewCheckState {
+	var result [][]suseobservability.ViewCheckState
+	for p := 0; p < pages; p++ {
+		var page []suseobservability.ViewCheckState
+		for i := 0; i < size; i++ {
+			id := first + p*size + i
+			page = append(page, suseobservability.ViewCheckState{
+				CheckStateId:      fmt.Sprint(id),
+				TopologyElementId: int64(id),
+				Name:              fmt.Sprintf("pod-%d", id),
+				Health:            health,
+			})
+		}
+		result = append(result, page)
+	}
+	return result
+}
+
+func TestGetMonitorCheckStates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("processes every page", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		pages := append(checkStatePages(0, 2, 3, "CRITICAL"), checkStatePages(6, 1, 1, "DEVIATING")...)
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "", 3, int64(0)).Return(pages, nil).Once()
+
+		result, _, err := tools.GetMonitorCheckStates(ctx, nil, GetMonitorCheckStatesParams{Monitor: "42", Limit: 3})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Listed 3 check state(s) of monitor '42':")
+		assert.Contains(t, output, "Stopped after 3 check states")
+		assert.Equal(t, 2, mockClient.fetchedPages, "the second page only shows that there are more states")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("stops at the limit without fetching further pages", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "CRITICAL", checkStatePageSize, int64(0)).
+			Return(checkStatePages(0, 50, checkStatePageSize, "CRITICAL"), nil).Once()
+
+		result, _, err := tools.GetMonitorCheckStates(ctx, nil, GetMonitorCheckStatesParams{Monitor: "42", State: "critical", Limit: 450})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Listed 450 check state(s) in state CRITICAL of monitor '42':")
+		assert.Contains(t, output, "| CRITICAL | 450 |")
+		assert.Contains(t, output, "| 449 | pod-449 | CRITICAL | - |")
+		assert.NotContains(t, output, "pod-450 ")
+		assert.Equal(t, 3, mockClient.fetchedPages)
+	})
+
+	t.Run("duplicates across pages are listed once", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		pages := [][]suseobservability.ViewCheckState{
+			checkStatePages(0, 1, 2, "CRITICAL")[0],
+			checkStatePages(1, 1, 2, "CRITICAL")[0],
+		}
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "", 2, int64(0)).Return(pages, nil).Once()
+
+		result, _, err := tools.GetMonitorCheckStates(ctx, nil, GetMonitorCheckStatesParams{Monitor: "42", Limit: 2})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| CRITICAL | 2 |")
+		assert.Contains(t, output, "Stopped after 2 check states")
+	})
+
+	t.Run("no check states", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "", checkStatePageSize, int64(0)).Return(nil, nil).Once()
+
+		result, _, err := tools.GetMonitorCheckStates(ctx, nil, GetMonitorCheckStatesParams{Monitor: "42"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No check states found for monitor '42'.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params GetMonitorCheckStatesParams
+			err    string
+		}{
+			{GetMonitorCheckStatesParams{}, "monitor is required"},
+			{GetMonitorCheckStatesParams{Monitor: "42", State: "FIRING"}, `invalid state "FIRING", must be one of CRITICAL, DEVIATING, UNKNOWN, CLEAR`},
+			{GetMonitorCheckStatesParams{Monitor: "42", Limit: 20000}, "limit must be at most 10000, got 20000"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.GetMonitorCheckStates(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
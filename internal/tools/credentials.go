@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CredentialMonitor tracks the validity of the SUSE Observability token. With a known expiry it
+// warns ahead of time, otherwise the first rejected request after successful ones is reported
+// once as a likely expired token.
+type CredentialMonitor struct {
+	expiresAt  time.Time
+	warnBefore time.Duration
+	clock      clock
+
+	mu        sync.Mutex
+	succeeded bool
+	reported  bool
+}
+
+// NewCredentialMonitor returns a monitor warning warnBefore the token expires at expiresAt.
+// A zero expiresAt means the expiry is unknown.
+func NewCredentialMonitor(expiresAt time.Time, warnBefore time.Duration) *CredentialMonitor {
+	return &CredentialMonitor{expiresAt: expiresAt, warnBefore: warnBefore, clock: realClock{}}
+}
+
+// Banner returns the warning shown in tool outputs while the token is about to expire or has
+// expired, or an empty string
+func (m *CredentialMonitor) Banner() string {
+	if m == nil || m.expiresAt.IsZero() {
+		return ""
+	}
+	remaining := m.expiresAt.Sub(m.clock.Now())
+	switch {
+	case remaining <= 0:
+		return fmt.Sprintf("Warning: SUSE Observability credentials expired %s ago.", formatRemaining(-remaining))
+	case remaining <= m.warnBefore:
+		return fmt.Sprintf("Warning: SUSE Observability credentials expire in %s.", formatRemaining(remaining))
+	default:
+		return ""
+	}
+}
+
+// Observe records the outcome of a request to SUSE Observability. The first rejected request
+// after successful ones is classified as a likely expired token, all other errors are returned as is.
+func (m *CredentialMonitor) Observe(err error) error {
+	if m == nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		m.succeeded = true
+		return nil
+	}
+	if !suseobservability.IsUnauthorized(err) || !m.succeeded || m.reported {
+		return err
+	}
+	m.reported = true
+	slog.Warn("SUSE Observability rejected the token after earlier successful requests, the token may have expired")
+	return fmt.Errorf("token may have expired, SUSE Observability rejected it after earlier successful requests: %w", err)
+}
+
+// Start checks the token at startup and then every interval until ctx is done, logging a
+// warning while the token is rejected or about to expire
+func (m *CredentialMonitor) Start(ctx context.Context, check func(context.Context) error, interval time.Duration) {
+	go m.run(ctx, check, interval)
+}
+
+func (m *CredentialMonitor) run(ctx context.Context, check func(context.Context) error, interval time.Duration) {
+	ticks, stopTicker := m.clock.NewTicker(interval)
+	defer stopTicker()
+
+	for {
+		m.check(ctx, check)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticks:
+		}
+	}
+}
+
+func (m *CredentialMonitor) check(ctx context.Context, check func(context.Context) error) {
+	if err := m.Observe(check(ctx)); err != nil {
+		if suseobservability.IsUnauthorized(err) {
+			slog.Error("SUSE Observability rejected the token", "error", err)
+		} else {
+			slog.Warn("Token validity check failed", "error", err)
+		}
+	}
+	if banner := m.Banner(); banner != "" {
+		slog.Warn(banner)
+	}
+}
+
+// WithCredentialCheck wraps a tool handler so that its errors are classified by the monitor and
+// its output carries the expiry banner during the warning window
+func WithCredentialCheck[In, Out any](m *CredentialMonitor, h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	if m == nil {
+		return h
+	}
+	return func(ctx context.Context, request *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		result, out, err := h(ctx, request, in)
+		if err != nil {
+			return result, out, m.Observe(err)
+		}
+		m.Observe(nil)
+		if banner := m.Banner(); banner != "" && result != nil {
+			addBanner(result, banner)
+		}
+		return result, out, nil
+	}
+}
+
+// addBanner prepends the banner to the first text content of the result
+func addBanner(result *mcp.CallToolResult, banner string) {
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			text.Text = banner + "\n\n" + text.Text
+			return
+		}
+	}
+	result.Content = append([]mcp.Content{&mcp.TextContent{Text: banner}}, result.Content...)
+}
+
+// formatRemaining renders a duration in the largest whole unit, e.g. "2d", "5h" or "12m"
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return "less than a minute"
+	}
+}
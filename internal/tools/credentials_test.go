@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	rq "github.com/carlmjohnson/requests"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unauthorizedError() error {
+	return (*rq.ResponseError)(&http.Response{StatusCode: http.StatusUnauthorized, Request: httptest.NewRequest(http.MethodGet, "https://so.example.com/api/components", nil)})
+}
+
+func newTestCredentialMonitor(c clock, expiresIn time.Duration) *CredentialMonitor {
+	m := NewCredentialMonitor(time.Time{}, 72*time.Hour)
+	m.clock = c
+	if expiresIn != 0 {
+		m.expiresAt = c.Now().Add(expiresIn)
+	}
+	return m
+}
+
+func TestCredentialMonitorBanner(t *testing.T) {
+	c := newFakeClock()
+
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		banner    string
+	}{
+		{name: "unknown expiry", banner: ""},
+		{name: "outside the warning window", expiresIn: 96 * time.Hour, banner: ""},
+		{name: "inside the warning window", expiresIn: 50 * time.Hour, banner: "Warning: SUSE Observability credentials expire in 2d."},
+		{name: "hours left", expiresIn: 5*time.Hour + 30*time.Minute, banner: "Warning: SUSE Observability credentials expire in 5h."},
+		{name: "expired", expiresIn: -3 * time.Hour, banner: "Warning: SUSE Observability credentials expired 3h ago."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.banner, newTestCredentialMonitor(c, tt.expiresIn).Banner())
+		})
+	}
+}
+
+func TestCredentialMonitorObserve(t *testing.T) {
+	t.Run("first 401 after a success is classified once", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 0)
+
+		assert.NoError(t, m.Observe(nil))
+		err := m.Observe(unauthorizedError())
+		again := m.Observe(unauthorizedError())
+
+		assert.ErrorContains(t, err, "token may have expired")
+		assert.NotContains(t, again.Error(), "token may have expired")
+	})
+
+	t.Run("401 without earlier success is not classified", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 0)
+
+		err := m.Observe(unauthorizedError())
+
+		assert.NotContains(t, err.Error(), "token may have expired")
+	})
+
+	t.Run("other errors are returned as is", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 0)
+		failure := errors.New("connection refused")
+
+		assert.NoError(t, m.Observe(nil))
+
+		assert.Equal(t, failure, m.Observe(failure))
+	})
+}
+
+func TestWithCredentialCheck(t *testing.T) {
+	handler := func(err error) mcp.ToolHandlerFor[struct{}, any] {
+		return func(ctx context.Context, request *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+			if err != nil {
+				return nil, nil, err
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "result"}}}, nil, nil
+		}
+	}
+
+	t.Run("adds the banner during the warning window", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 50*time.Hour)
+
+		result, _, err := WithCredentialCheck(m, handler(nil))(context.Background(), nil, struct{}{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Warning: SUSE Observability credentials expire in 2d.\n\nresult", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("leaves the output untouched outside the warning window", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 0)
+
+		result, _, err := WithCredentialCheck(m, handler(nil))(context.Background(), nil, struct{}{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "result", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("classifies a 401 after a successful call", func(t *testing.T) {
+		m := newTestCredentialMonitor(newFakeClock(), 0)
+
+		_, _, err := WithCredentialCheck(m, handler(nil))(context.Background(), nil, struct{}{})
+		require.NoError(t, err)
+		_, _, err = WithCredentialCheck(m, handler(unauthorizedError()))(context.Background(), nil, struct{}{})
+
+		assert.ErrorContains(t, err, "token may have expired")
+	})
+
+	t.Run("nil monitor", func(t *testing.T) {
+		result, _, err := WithCredentialCheck[struct{}, any](nil, handler(nil))(context.Background(), nil, struct{}{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "result", result.Content[0].(*mcp.TextContent).Text)
+	})
+}
+
+func TestCredentialMonitorRun(t *testing.T) {
+	c := newFakeClock()
+	m := newTestCredentialMonitor(c, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	check := func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls > 1 {
+			return unauthorizedError()
+		}
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		m.run(ctx, check, time.Hour)
+		close(done)
+	}()
+
+	c.tick(t)
+	c.tick(t)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, calls)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	assert.True(t, m.succeeded)
+	assert.True(t, m.reported)
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// defaultDisplayName renders components by their plain name
+const defaultDisplayName = "{{.Name}}"
+
+// namespaceTag and clusterTag are the tags the Namespace and Cluster fields are taken from
+const (
+	namespaceTag = "namespace"
+	clusterTag   = "cluster-name"
+)
+
+// DisplayName renders the name of components in tool outputs from a text/template over
+// displayNameFields, e.g. "{{.Namespace}}/{{.Name}}"
+type DisplayName struct {
+	text string
+	tmpl *template.Template
+}
+
+// displayNameFields is the data a display name template is executed with
+type displayNameFields struct {
+	ID          int64
+	Name        string
+	Namespace   string
+	Cluster     string
+	Identifiers []string
+	// Tags holds the component tags split at their first colon, tags without a colon have an empty value
+	Tags       map[string]string
+	Properties map[string]string
+}
+
+// DefaultDisplayName returns the display name rendering components by their plain name
+func DefaultDisplayName() DisplayName {
+	d, err := ParseDisplayName(defaultDisplayName)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// ParseDisplayName parses a display name template. Besides syntax errors, blank templates
+// and templates failing for a component with every field set, e.g. by referring to an
+// unknown field, are rejected, so mistakes show up when the template is configured instead
+// of in outputs.
+func ParseDisplayName(text string) (DisplayName, error) {
+	if strings.TrimSpace(text) == "" {
+		return DisplayName{}, fmt.Errorf("invalid display name template %q: must not be empty", text)
+	}
+	tmpl, err := template.New("display name").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return DisplayName{}, fmt.Errorf("invalid display name template %q: %w", text, err)
+	}
+	sample := displayNameFields{
+		ID:          1,
+		Name:        "checkout",
+		Namespace:   "shop",
+		Cluster:     "prod",
+		Identifiers: []string{"urn:kubernetes:/prod:shop:service/checkout"},
+		Tags:        map[string]string{namespaceTag: "shop", clusterTag: "prod"},
+		Properties:  map[string]string{},
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return DisplayName{}, fmt.Errorf("invalid display name template %q: %w", text, err)
+	}
+	return DisplayName{text: text, tmpl: tmpl}, nil
+}
+
+// String returns the template text
+func (d DisplayName) String() string {
+	return d.text
+}
+
+// Render returns the display name of a component. Missing tags and properties render
+// empty; when the whole name renders blank or fails, the plain component name is used.
+func (d DisplayName) Render(c Component) string {
+	if d.tmpl == nil {
+		return c.Name
+	}
+	tags := make(map[string]string, len(c.Tags))
+	for _, tag := range c.Tags {
+		key, value, _ := strings.Cut(tag, ":")
+		tags[key] = value
+	}
+	properties := c.Properties
+	if properties == nil {
+		properties = map[string]string{}
+	}
+
+	var sb strings.Builder
+	err := d.tmpl.Execute(&sb, displayNameFields{
+		ID:          c.ID,
+		Name:        c.Name,
+		Namespace:   tags[namespaceTag],
+		Cluster:     tags[clusterTag],
+		Identifiers: c.Identifiers,
+		Tags:        tags,
+		Properties:  properties,
+	})
+	// names are rendered in table cells and headers, so they must fit on one line
+	name := strings.Join(strings.Fields(sb.String()), " ")
+	if err != nil || name == "" {
+		return c.Name
+	}
+	return name
+}
+
+// displayName returns the display name of a call, the per-call template when given,
+// otherwise the configured one
+func (t tool) displayName(text string) (DisplayName, error) {
+	if strings.TrimSpace(text) == "" {
+		return t.componentDisplayName, nil
+	}
+	return ParseDisplayName(text)
+}
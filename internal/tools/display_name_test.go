@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDisplayName(t *testing.T) {
+	t.Run("valid templates", func(t *testing.T) {
+		for _, text := range []string{
+			"{{.Name}}",
+			"{{.Namespace}}/{{.Name}}",
+			"{{.Cluster}}:{{.Namespace}}:{{.Name}}",
+			`{{with .Namespace}}{{.}}/{{end}}{{.Name}} ({{.ID}})`,
+			`{{index .Tags "app"}}{{.Properties.kind}}{{.Name}}`,
+		} {
+			d, err := ParseDisplayName(text)
+			require.NoError(t, err, text)
+			assert.Equal(t, text, d.String())
+		}
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		tests := []struct {
+			text string
+			err  string
+		}{
+			{"{{.Name", `invalid display name template "{{.Name": template: display name:1: unclosed action`},
+			{"{{.Label}}", `can't evaluate field Label`},
+			{"{{.Name.First}}", `can't evaluate field First`},
+			{"{{index .Identifiers 3}}", `index out of range: 3`},
+			{"", `invalid display name template "": must not be empty`},
+			{"  ", `invalid display name template "  ": must not be empty`},
+		}
+		for _, tt := range tests {
+			_, err := ParseDisplayName(tt.text)
+			assert.ErrorContains(t, err, tt.err, tt.text)
+		}
+	})
+
+	t.Run("default renders the plain name", func(t *testing.T) {
+		assert.Equal(t, "{{.Name}}", DefaultDisplayName().String())
+		assert.Equal(t, "checkout", DefaultDisplayName().Render(Component{Name: "checkout", Tags: []string{"namespace:shop"}}))
+	})
+}
+
+func TestDisplayNameRender(t *testing.T) {
+	component := Component{
+		ID:          42,
+		Name:        "checkout",
+		Tags:        []string{"namespace:shop", "cluster-name:prod", "image:registry:5000/checkout", "stackpack-kubernetes"},
+		Identifiers: []string{"urn:kubernetes:/prod:shop:service/checkout"},
+		Properties:  map[string]string{"kind": "Service"},
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		component Component
+		want      string
+	}{
+		{"namespace and name", "{{.Namespace}}/{{.Name}}", component, "shop/checkout"},
+		{"cluster, namespace and name", "{{.Cluster}}:{{.Namespace}}:{{.Name}}", component, "prod:shop:checkout"},
+		{"tags are split at the first colon", `{{index .Tags "image"}}`, component, "registry:5000/checkout"},
+		{"tags without value", `{{if index .Tags "stackpack-kubernetes"}}x{{else}}{{.Name}}{{end}}`, component, "checkout"},
+		{"properties and identifiers", `{{.Properties.kind}} {{index .Identifiers 0}}`, component, "Service urn:kubernetes:/prod:shop:service/checkout"},
+		{"missing namespace renders empty", "{{.Namespace}}/{{.Name}}", Component{Name: "checkout"}, "/checkout"},
+		{"missing namespace skipped with with", "{{with .Namespace}}{{.}}/{{end}}{{.Name}}", Component{Name: "checkout"}, "checkout"},
+		{"missing tag and property render empty", `{{.Tags.team}}{{.Properties.kind}}{{.Name}}`, Component{Name: "checkout"}, "checkout"},
+		{"blank result falls back to the name", "{{.Namespace}}", Component{Name: "checkout"}, "checkout"},
+		{"failing execution falls back to the name", "{{index .Identifiers 0}}", Component{Name: "checkout"}, "checkout"},
+		{"rendered on one line", "{{.Namespace}}\n{{.Name}}", component, "shop checkout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDisplayName(tt.text)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, d.Render(tt.component))
+		})
+	}
+
+	t.Run("zero value renders the plain name", func(t *testing.T) {
+		assert.Equal(t, "checkout", DisplayName{}.Render(component))
+	})
+}
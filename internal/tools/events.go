@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetEventsParams struct {
+	ComponentID int64    `json:"component_id,omitempty" jsonschema:"The ID of the component to list events of (default: all components)"`
+	Categories  []string `json:"categories,omitempty" jsonschema:"Event categories to list: Changes, Deployments, Alerts, Anomalies, Activities or Others (default: all)" examples:"Changes;Deployments;Alerts;Anomalies;Activities;Others"`
+	Lookback    string   `json:"lookback,omitempty" jsonschema:"How far back to look for events, e.g. '15m' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"15m;2d"`
+	Limit       int      `json:"limit,omitempty" jsonschema:"Maximum number of events listed (default: 50, max: 500)" default:"50"`
+}
+
+const (
+	defaultEventRows = 50
+	maxEventRows     = 500
+)
+
+// eventCategories are the categories getEvents accepts, in the order they are listed in errors
+var eventCategories = []suseobservability.EventCategory{
+	suseobservability.EventCategoryChanges,
+	suseobservability.EventCategoryDeployments,
+	suseobservability.EventCategoryAlerts,
+	suseobservability.EventCategoryAnomalies,
+	suseobservability.EventCategoryActivities,
+	suseobservability.EventCategoryOthers,
+}
+
+// GetEvents lists the events of a component, or of all components, newest first
+func (t tool) GetEvents(ctx context.Context, request *mcp.CallToolRequest, params GetEventsParams) (*mcp.CallToolResult, any, error) {
+	if params.ComponentID < 0 {
+		return nil, nil, fmt.Errorf("component_id must not be negative, got %d", params.ComponentID)
+	}
+	categories, err := parseEventCategories(params.Categories)
+	if err != nil {
+		return nil, nil, err
+	}
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit, err := displayLimit(params.Limit, defaultEventRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > maxEventRows {
+		return nil, nil, fmt.Errorf("limit must be at most %d, got %d", maxEventRows, limit)
+	}
+
+	// An empty topology query selects the events of all components
+	query := ""
+	subject := "all components"
+	if params.ComponentID != 0 {
+		query = fmt.Sprintf("id = %d", params.ComponentID)
+		subject = fmt.Sprintf("component ID %d", params.ComponentID)
+	}
+	if len(categories) > 0 {
+		names := make([]string, 0, len(categories))
+		for _, c := range categories {
+			names = append(names, string(c))
+		}
+		subject += fmt.Sprintf(" (categories: %s)", strings.Join(names, ", "))
+	}
+
+	end := t.clock.Now()
+	res, err := t.client.GetEvents(ctx, &suseobservability.EventListRequest{
+		StartTimestampMs: end.Add(-lookback).UnixMilli(),
+		EndTimestampMs:   end.UnixMilli(),
+		TopologyQuery:    query,
+		Limit:            limit,
+		EventCategories:  categories,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get events of %s: %w", subject, err)
+	}
+
+	if len(res.Items) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No events found for %s in the last %s.", subject, formatLookback(lookback)),
+				},
+			},
+		}, nil, nil
+	}
+
+	events := slices.Clone(res.Items)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].EventTime > events[j].EventTime
+	})
+	shown := events[:min(limit, len(events))]
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Events of %s in the last %s, newest first (%s):\n\n", subject, formatLookback(lookback), totalSummary(len(shown), len(events), int(res.Total))))
+	sb.WriteString("| Time | Category | Title | Source |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, e := range shown {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			time.UnixMilli(e.EventTime).UTC().Format(time.RFC3339), valueOrDash(string(e.Category)), valueOrDash(Truncate(e.Name, 100)), valueOrDash(e.Source)))
+	}
+	if int(res.Total) > len(shown) {
+		sb.WriteString(fmt.Sprintf("\n%d more events matched, narrow the categories or lookback, or raise limit, to see them.\n", int(res.Total)-len(shown)))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, nil, nil
+}
+
+// parseEventCategories matches the requested categories case-insensitively, without duplicates
+func parseEventCategories(requested []string) ([]suseobservability.EventCategory, error) {
+	var categories []suseobservability.EventCategory
+	seen := map[suseobservability.EventCategory]bool{}
+	for _, r := range requested {
+		r = strings.TrimSpace(r)
+		var category suseobservability.EventCategory
+		for _, c := range eventCategories {
+			if strings.EqualFold(r, string(c)) {
+				category = c
+				break
+			}
+		}
+		if category == "" {
+			names := make([]string, 0, len(eventCategories))
+			for _, c := range eventCategories {
+				names = append(names, string(c))
+			}
+			return nil, fmt.Errorf("invalid event category '%s', must be one of %s", r, strings.Join(names, ", "))
+		}
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func topologyEvent(name string, category suseobservability.EventCategory, source string, eventTime int64) suseobservability.TopologyEvent {
+	return suseobservability.TopologyEvent{Identifier: name, Name: name, Category: category, Source: source, EventTime: eventTime}
+}
+
+func TestGetEvents(t *testing.T) {
+	ctx := context.Background()
+	events := []suseobservability.TopologyEvent{
+		topologyEvent("Pod restarted", suseobservability.EventCategoryChanges, "Kubernetes", 1700000060000),
+		topologyEvent("Deployment checkout updated", suseobservability.EventCategoryDeployments, "Kubernetes", 1700000120000),
+		topologyEvent("CPU throttling", suseobservability.EventCategoryAlerts, "", 1700000000000),
+	}
+
+	t.Run("events of a component newest first", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		var req *suseobservability.EventListRequest
+		mockClient.On("GetEvents", ctx, mock.AnythingOfType("*suseobservability.EventListRequest")).
+			Run(func(args mock.Arguments) { req = args.Get(1).(*suseobservability.EventListRequest) }).
+			Return(&suseobservability.EventItemsWithTotal{Items: events, Total: 3}, nil).Once()
+
+		result, _, err := tools.GetEvents(ctx, nil, GetEventsParams{ComponentID: 42, Lookback: "2h"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "id = 42", req.TopologyQuery)
+		assert.Equal(t, defaultEventRows, req.Limit)
+		assert.Empty(t, req.EventCategories)
+		assert.Equal(t, 2*time.Hour, time.Duration(req.EndTimestampMs-req.StartTimestampMs)*time.Millisecond)
+		assert.Equal(t, "Events of component ID 42 in the last 2h, newest first (showing 3 of 3 fetched (3 total reported by server)):\n\n"+
+			"| Time | Category | Title | Source |\n"+
+			"|---|---|---|---|\n"+
+			"| 2023-11-14T22:15:20Z | Deployments | Deployment checkout updated | Kubernetes |\n"+
+			"| 2023-11-14T22:14:20Z | Changes | Pod restarted | Kubernetes |\n"+
+			"| 2023-11-14T22:13:20Z | Alerts | CPU throttling | - |\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("all components filtered by category", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetEvents", ctx, mock.MatchedBy(func(req *suseobservability.EventListRequest) bool {
+			return req.TopologyQuery == "" && req.Limit == 1 && assert.ObjectsAreEqual([]suseobservability.EventCategory{
+				suseobservability.EventCategoryDeployments, suseobservability.EventCategoryChanges,
+			}, req.EventCategories)
+		})).Return(&suseobservability.EventItemsWithTotal{Items: events[1:2], Total: 7}, nil).Once()
+
+		result, _, err := tools.GetEvents(ctx, nil, GetEventsParams{Categories: []string{"deployments", " Changes", "DEPLOYMENTS"}, Limit: 1})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Events of all components (categories: Deployments, Changes) in the last 1h, newest first (showing 1 of 1 fetched (7 total reported by server)):")
+		assert.Contains(t, output, "| Deployment checkout updated |")
+		assert.Contains(t, output, "6 more events matched, narrow the categories or lookback, or raise limit, to see them.")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetEvents", ctx, mock.Anything).Return(&suseobservability.EventItemsWithTotal{}, nil).Once()
+
+		result, _, err := tools.GetEvents(ctx, nil, GetEventsParams{ComponentID: 42})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No events found for component ID 42 in the last 1h.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetEvents", ctx, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+		_, _, err := tools.GetEvents(ctx, nil, GetEventsParams{})
+
+		assert.EqualError(t, err, "failed to get events of all components: boom")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params GetEventsParams
+			err    string
+		}{
+			{GetEventsParams{ComponentID: -1}, "component_id must not be negative, got -1"},
+			{GetEventsParams{Categories: []string{"Incidents"}}, "invalid event category 'Incidents', must be one of Changes, Deployments, Alerts, Anomalies, Activities, Others"},
+			{GetEventsParams{Limit: -1}, "limit must not be negative, got -1"},
+			{GetEventsParams{Limit: 501}, "limit must be at most 500, got 501"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.GetEvents(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+)
+
+// countSummary reports how many items are shown out of the fetched ones
+func countSummary(shown, fetched int) string {
+	return fmt.Sprintf("showing %d of %d fetched", shown, fetched)
+}
+
+// totalSummary is countSummary followed by the overall total reported by the server
+func totalSummary(shown, fetched, total int) string {
+	return fmt.Sprintf("%s (%d total reported by server)", countSummary(shown, fetched), total)
+}
+
+// displayLimit returns the number of rows to render: the requested limit when set, the default otherwise
+func displayLimit(requested, def int) (int, error) {
+	if requested < 0 {
+		return 0, fmt.Errorf("limit must not be negative, got %d", requested)
+	}
+	if requested == 0 {
+		return def, nil
+	}
+	return requested, nil
+}
+
+// valueOrDash renders empty table cells as "-"
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatUnix renders a Unix timestamp in RFC3339, in UTC like every timestamp of the tool
+// outputs so they do not depend on the time zone of the server
+func formatUnix(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
+
+// ellipsis is appended to truncated strings
+const ellipsis = "…"
+
+// Truncate shortens s to at most max runes, the ellipsis included. It cuts on
+// rune boundaries and never separates a character from the combining marks,
+// variation selectors or zero-width joiners that follow it, so the result is
+// always valid UTF-8. Strings that fit are returned unchanged.
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+
+	cut := max - 1
+	for cut > 0 && (isExtender(runes[cut]) || runes[cut-1] == zeroWidthJoiner) {
+		cut--
+	}
+	return string(runes[:cut]) + ellipsis
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isExtender reports whether r continues the character before it
+func isExtender(r rune) bool {
+	return r == zeroWidthJoiner ||
+		unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Mc, r) ||
+		unicode.Is(unicode.Variation_Selector, r) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) // emoji skin tone modifiers
+}
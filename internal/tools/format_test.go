@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountSummary(t *testing.T) {
+	assert.Equal(t, "showing 5 of 10 fetched", countSummary(5, 10))
+	assert.Equal(t, "showing 5 of 10 fetched (250 total reported by server)", totalSummary(5, 10, 250))
+}
+
+func TestDisplayLimit(t *testing.T) {
+	limit, err := displayLimit(0, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, limit)
+
+	limit, err = displayLimit(7, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, limit)
+
+	_, err = displayLimit(-1, 50)
+	assert.Error(t, err)
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		max      int
+		expected string
+	}{
+		{name: "ascii fits", input: "hello", max: 5, expected: "hello"},
+		{name: "ascii", input: "hello world", max: 6, expected: "hello…"},
+		{name: "cjk", input: "监控器名称很长", max: 4, expected: "监控器…"},
+		{name: "emoji", input: "🔥🔥🔥🔥", max: 3, expected: "🔥🔥…"},
+		{name: "emoji zwj sequence", input: "ab👩\u200d💻cd", max: 4, expected: "ab…"},
+		{name: "combining characters", input: "cafe\u0301 noir", max: 5, expected: "caf…"},
+		{name: "combining character kept whole", input: "cafe\u0301 noir", max: 7, expected: "cafe\u0301 …"},
+		{name: "zero max", input: "hello", max: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Truncate(tt.input, tt.max)
+
+			assert.Equal(t, tt.expected, result)
+			assert.True(t, utf8.ValidString(result))
+			assert.LessOrEqual(t, utf8.RuneCountInString(result), tt.max)
+		})
+	}
+
+	t.Run("ellipsis only when truncated", func(t *testing.T) {
+		assert.False(t, strings.HasSuffix(Truncate("short", 10), ellipsis))
+	})
+}
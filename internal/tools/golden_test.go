@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares output with the golden file at path, relative to testdata. With
+// -update the golden file is rewritten first, so output changes show up as a diff in review.
+func assertGolden(t *testing.T, path, output string) {
+	t.Helper()
+	golden := filepath.Join("testdata", path+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+		require.NoError(t, os.WriteFile(golden, []byte(output), 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err, "missing golden file, run the test with -update to create it")
+	assert.Equal(t, string(expected), output)
+}
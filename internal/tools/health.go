@@ -0,0 +1,234 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ClusterHealthParams struct {
+	Cluster string `json:"cluster" jsonschema:"required,The cluster name (domain) to summarize"`
+}
+
+// healthStateOrder is the display order of health states, worst first
+var healthStateOrder = []string{"CRITICAL", "DEVIATING", "UNKNOWN", "CLEAR"}
+
+// maxWorstMonitors is the number of monitors listed in the cluster health scoreboard
+const maxWorstMonitors = 5
+
+// maxOpenProblems is the number of open problems listed in the cluster health scoreboard
+const maxOpenProblems = 5
+
+// GetClusterHealth renders a one-call health scoreboard of a cluster
+func (t tool) GetClusterHealth(ctx context.Context, request *mcp.CallToolRequest, params ClusterHealthParams) (*mcp.CallToolResult, any, error) {
+	cluster := strings.TrimSpace(params.Cluster)
+	if cluster == "" {
+		return nil, nil, fmt.Errorf("cluster is required")
+	}
+
+	var (
+		wg          sync.WaitGroup
+		components  []suseobservability.ViewComponent
+		overview    *suseobservability.MonitorOverviewList
+		compErr     error
+		overviewErr error
+	)
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		components, compErr = t.client.SnapShotTopologyQuery(ctx, buildInClause("domain", cluster))
+	}()
+	go func() {
+		defer wg.Done()
+		overview, overviewErr = t.client.GetMonitorsOverview(ctx)
+	}()
+	wg.Wait()
+
+	if compErr != nil && overviewErr != nil {
+		return nil, nil, fmt.Errorf("failed to get health of cluster '%s': %w", cluster, errors.Join(compErr, overviewErr))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Health of cluster '%s':\n\n", cluster))
+
+	if compErr != nil {
+		sb.WriteString(fmt.Sprintf("Components: unavailable (%s)\n\n", compErr))
+	} else {
+		sb.WriteString(fmt.Sprintf("Components (%d):\n\n", len(components)))
+		writeStateCounts(&sb, componentHealthCounts(components), healthStateOrder)
+		writeOpenProblems(&sb, openProblems(components))
+	}
+
+	if overviewErr != nil {
+		sb.WriteString(fmt.Sprintf("Monitors: unavailable (%s)\n", overviewErr))
+	} else {
+		monitors := monitorsInScope(overview.Monitors, cluster)
+		firing := firingMonitors(monitors)
+		sb.WriteString(fmt.Sprintf("Firing monitors (%d of %d checking the cluster):\n\n", len(firing), len(monitors)))
+		writeStateCounts(&sb, monitorStateCounts(firing), healthStateOrder[:2])
+
+		worst := firing
+		if len(worst) > maxWorstMonitors {
+			worst = worst[:maxWorstMonitors]
+		}
+		if len(worst) > 0 {
+			sb.WriteString(fmt.Sprintf("Worst monitors, %s:\n\n", countSummary(len(worst), len(firing))))
+			sb.WriteString("| Monitor | Critical | Deviating |\n")
+			sb.WriteString("|---|---|---|\n")
+			for _, m := range worst {
+				sb.WriteString(fmt.Sprintf("| %s | %d | %d |\n", m.Monitor.Name, m.RuntimeMetrics.CriticalCount, m.RuntimeMetrics.DeviatingCount))
+			}
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}
+	if compErr != nil || overviewErr != nil {
+		markPartial(result)
+	}
+	return result, nil, nil
+}
+
+// componentHealthCounts counts components per health state
+func componentHealthCounts(components []suseobservability.ViewComponent) map[string]int {
+	counts := make(map[string]int)
+	for _, c := range components {
+		state := c.State.HealthState
+		if state == "" {
+			state = "UNKNOWN"
+		}
+		counts[state]++
+	}
+	return counts
+}
+
+// monitorsInScope keeps the monitors checking the given cluster, as named by their cluster tag
+// or identifier. Monitors naming no cluster check the whole environment and are left out, their
+// counts would not be those of the cluster.
+func monitorsInScope(monitors []suseobservability.MonitorOverview, cluster string) []suseobservability.MonitorOverview {
+	var scoped []suseobservability.MonitorOverview
+	for _, m := range monitors {
+		if monitorCluster(m.Monitor) == cluster {
+			scoped = append(scoped, m)
+		}
+	}
+	return scoped
+}
+
+// openProblems returns the components unhealthy on their own health checks, worst first.
+// Components only unhealthy through the propagated state of others are not problems of their own.
+func openProblems(components []suseobservability.ViewComponent) []suseobservability.ViewComponent {
+	var problems []suseobservability.ViewComponent
+	for _, c := range components {
+		if c.State.HealthState == "CRITICAL" || c.State.HealthState == "DEVIATING" {
+			problems = append(problems, c)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].State.HealthState != problems[j].State.HealthState {
+			return problems[i].State.HealthState == "CRITICAL"
+		}
+		return problems[i].Name < problems[j].Name
+	})
+	return problems
+}
+
+// writeOpenProblems renders the open problems, maxOpenProblems at most
+func writeOpenProblems(sb *strings.Builder, problems []suseobservability.ViewComponent) {
+	if len(problems) == 0 {
+		sb.WriteString("Open problems: none\n\n")
+		return
+	}
+	shown := problems
+	if len(shown) > maxOpenProblems {
+		shown = shown[:maxOpenProblems]
+	}
+	sb.WriteString(fmt.Sprintf("Open problems (components unhealthy on their own checks), %s:\n\n", countSummary(len(shown), len(problems))))
+	sb.WriteString("| Component | ID | Health State |\n")
+	sb.WriteString("|---|---|---|\n")
+	for _, c := range shown {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", c.Name, c.ID, c.State.HealthState))
+	}
+	sb.WriteString("\n")
+}
+
+// firingMonitors returns the monitors with critical or deviating results, worst first
+func firingMonitors(monitors []suseobservability.MonitorOverview) []suseobservability.MonitorOverview {
+	var firing []suseobservability.MonitorOverview
+	for _, m := range monitors {
+		if monitorState(m) != "" {
+			firing = append(firing, m)
+		}
+	}
+	sort.SliceStable(firing, func(i, j int) bool {
+		a, b := firing[i].RuntimeMetrics, firing[j].RuntimeMetrics
+		if a.CriticalCount != b.CriticalCount {
+			return a.CriticalCount > b.CriticalCount
+		}
+		if a.DeviatingCount != b.DeviatingCount {
+			return a.DeviatingCount > b.DeviatingCount
+		}
+		return firing[i].Monitor.Name < firing[j].Monitor.Name
+	})
+	return firing
+}
+
+// monitorState returns the worst state a monitor is firing with, or "" when it is not firing
+func monitorState(m suseobservability.MonitorOverview) string {
+	switch {
+	case m.RuntimeMetrics.CriticalCount > 0:
+		return "CRITICAL"
+	case m.RuntimeMetrics.DeviatingCount > 0:
+		return "DEVIATING"
+	default:
+		return ""
+	}
+}
+
+// monitorStateCounts counts monitors per the worst state they are firing with
+func monitorStateCounts(monitors []suseobservability.MonitorOverview) map[string]int {
+	counts := make(map[string]int)
+	for _, m := range monitors {
+		if state := monitorState(m); state != "" {
+			counts[state]++
+		}
+	}
+	return counts
+}
+
+// writeStateCounts renders a state/count table, listing the given states first
+func writeStateCounts(sb *strings.Builder, counts map[string]int, states []string) {
+	sb.WriteString("| Health State | Count |\n")
+	sb.WriteString("|---|---|\n")
+
+	seen := make(map[string]bool, len(states))
+	for _, state := range states {
+		seen[state] = true
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", state, counts[state]))
+	}
+
+	var others []string
+	for state := range counts {
+		if !seen[state] {
+			others = append(others, state)
+		}
+	}
+	sort.Strings(others)
+	for _, state := range others {
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", state, counts[state]))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type HealthCheckParams struct{}
+
+// HealthCheckResult is the structured content returned by healthCheck
+type HealthCheckResult struct {
+	Reachable      bool   `json:"reachable"`
+	Version        string `json:"version"`
+	DeploymentMode string `json:"deployment_mode,omitempty"`
+	TokenType      string `json:"token_type"`
+	LatencyMs      int64  `json:"latency_ms"`
+}
+
+// HealthCheck verifies that SUSE Observability is reachable and accepts the token, reporting
+// the server version. Failures tell rejected credentials apart from connectivity problems.
+func (t tool) HealthCheck(ctx context.Context, request *mcp.CallToolRequest, params HealthCheckParams) (*mcp.CallToolResult, *HealthCheckResult, error) {
+	res, err := t.client.Ping(ctx)
+	if err != nil {
+		return nil, nil, healthCheckError(err)
+	}
+
+	structured := &HealthCheckResult{
+		Reachable:      true,
+		Version:        res.Server.VersionString(),
+		DeploymentMode: res.Server.DeploymentMode,
+		TokenType:      res.TokenType,
+		LatencyMs:      res.Latency.Milliseconds(),
+	}
+	var sb strings.Builder
+	sb.WriteString("SUSE Observability is reachable and accepts the token.\n\n")
+	sb.WriteString(fmt.Sprintf("- Server version: %s\n", structured.Version))
+	if structured.DeploymentMode != "" {
+		sb.WriteString(fmt.Sprintf("- Deployment mode: %s\n", structured.DeploymentMode))
+	}
+	sb.WriteString(fmt.Sprintf("- Token type: %s\n", structured.TokenType))
+	sb.WriteString(fmt.Sprintf("- Response time: %dms\n", structured.LatencyMs))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// healthCheckError explains a failed ping: a rejected token, a token lacking permissions,
+// or a server that could not be reached
+func healthCheckError(err error) error {
+	var transportErr *suseobservability.TransportError
+	switch {
+	case suseobservability.IsUnauthorized(err):
+		return fmt.Errorf("authentication failed: SUSE Observability rejected the token (401). "+
+			"Check that the token is valid and not expired, and that -apitoken is set only for API tokens: %w", err)
+	case suseobservability.IsForbidden(err):
+		return fmt.Errorf("authorization failed: SUSE Observability accepted the token but denied access (403). "+
+			"Grant the token a role with read permissions: %w", err)
+	case errors.As(err, &transportErr):
+		return fmt.Errorf("connectivity failed: SUSE Observability could not be reached, the token was not checked: %w", err)
+	}
+	return fmt.Errorf("health check failed: %w", err)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reachable server reports its version and the token type", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/server/info", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get("X-API-Token"))
+			_, _ = w.Write([]byte(`{"version":{"major":7,"minor":1,"patch":3},"deploymentMode":"SaaS"}`))
+		}))
+		defer server.Close()
+		client, err := suseobservability.NewClient(server.URL, "token", true, time.Second)
+		require.NoError(t, err)
+
+		result, structured, err := NewBaseTool(client).HealthCheck(ctx, nil, HealthCheckParams{})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "SUSE Observability is reachable and accepts the token.")
+		assert.Contains(t, output, "- Server version: 7.1.3\n- Deployment mode: SaaS\n- Token type: api token\n")
+		assert.True(t, structured.Reachable)
+		assert.Equal(t, "7.1.3", structured.Version)
+		assert.Equal(t, "api token", structured.TokenType)
+	})
+
+	t.Run("rejected token is an authentication error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		client, err := suseobservability.NewClient(server.URL, "token", false, time.Second)
+		require.NoError(t, err)
+
+		_, _, err = NewBaseTool(client).HealthCheck(ctx, nil, HealthCheckParams{})
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "authentication failed: SUSE Observability rejected the token (401)")
+		assert.True(t, suseobservability.IsUnauthorized(err))
+	})
+
+	t.Run("denied access is an authorization error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+		client, err := suseobservability.NewClient(server.URL, "token", false, time.Second)
+		require.NoError(t, err)
+
+		_, _, err = NewBaseTool(client).HealthCheck(ctx, nil, HealthCheckParams{})
+
+		assert.ErrorContains(t, err, "authorization failed: SUSE Observability accepted the token but denied access (403)")
+	})
+
+	t.Run("refused connection is a connectivity error", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := ln.Addr().String()
+		require.NoError(t, ln.Close())
+		client, err := suseobservability.NewClient("http://"+addr, "token", false, time.Second)
+		require.NoError(t, err)
+
+		_, _, err = NewBaseTool(client).HealthCheck(ctx, nil, HealthCheckParams{})
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "connectivity failed: SUSE Observability could not be reached, the token was not checked")
+		assert.ErrorContains(t, err, "connection problem")
+		assert.False(t, suseobservability.IsUnauthorized(err))
+	})
+}
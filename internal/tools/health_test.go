@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func viewComponent(id int64, name, health string) suseobservability.ViewComponent {
+	c := suseobservability.ViewComponent{ID: id, Name: name}
+	c.State.HealthState = health
+	return c
+}
+
+func monitorOverview(name string, critical, deviating int, tags ...string) suseobservability.MonitorOverview {
+	return suseobservability.MonitorOverview{
+		Monitor: suseobservability.Monitor{Name: name, Tags: tags},
+		RuntimeMetrics: suseobservability.MonitorRuntimeMetrics{
+			CriticalCount:  critical,
+			DeviatingCount: deviating,
+		},
+	}
+}
+
+func TestClusterHealthAggregation(t *testing.T) {
+	t.Run("component counts", func(t *testing.T) {
+		counts := componentHealthCounts([]suseobservability.ViewComponent{
+			viewComponent(1, "a", "CRITICAL"),
+			viewComponent(2, "b", "CLEAR"),
+			viewComponent(3, "c", "CLEAR"),
+			viewComponent(4, "d", ""),
+		})
+
+		assert.Equal(t, map[string]int{"CRITICAL": 1, "CLEAR": 2, "UNKNOWN": 1}, counts)
+	})
+
+	t.Run("monitors scoped to the cluster", func(t *testing.T) {
+		scoped := monitorsInScope([]suseobservability.MonitorOverview{
+			monitorOverview("global", 1, 0),
+			monitorOverview("prod", 1, 0, "cluster-name:prod-eu"),
+			monitorOverview("staging", 1, 0, "cluster-name:staging"),
+			{Monitor: suseobservability.Monitor{Name: "by-urn", Identifier: "urn:stackpack:kubernetes:cluster:prod-eu:monitor:x"}},
+		}, "prod-eu")
+
+		var names []string
+		for _, m := range scoped {
+			names = append(names, m.Monitor.Name)
+		}
+		assert.Equal(t, []string{"prod", "by-urn"}, names)
+	})
+
+	t.Run("open problems sorted worst first", func(t *testing.T) {
+		problems := openProblems([]suseobservability.ViewComponent{
+			viewComponent(1, "b", "DEVIATING"),
+			viewComponent(2, "ok", "CLEAR"),
+			viewComponent(3, "c", "CRITICAL"),
+			viewComponent(4, "a", "DEVIATING"),
+			viewComponent(5, "unknown", ""),
+		})
+
+		var names []string
+		for _, c := range problems {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, []string{"c", "a", "b"}, names)
+	})
+
+	t.Run("firing monitors sorted worst first", func(t *testing.T) {
+		firing := firingMonitors([]suseobservability.MonitorOverview{
+			monitorOverview("quiet", 0, 0),
+			monitorOverview("deviating", 0, 7),
+			monitorOverview("critical-b", 2, 0),
+			monitorOverview("critical-a", 2, 0),
+			monitorOverview("very-critical", 9, 1),
+		})
+
+		var names []string
+		for _, m := range firing {
+			names = append(names, m.Monitor.Name)
+		}
+		assert.Equal(t, []string{"very-critical", "critical-a", "critical-b", "deviating"}, names)
+		assert.Equal(t, map[string]int{"CRITICAL": 3, "DEVIATING": 1}, monitorStateCounts(firing))
+	})
+}
+
+func TestGetClusterHealth(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	overview := &suseobservability.MonitorOverviewList{
+		Monitors: []suseobservability.MonitorOverview{
+			monitorOverview("m1", 1, 0, "cluster-name:prod-eu"),
+			monitorOverview("m2", 0, 1, "cluster-name:prod-eu"),
+			monitorOverview("m3", 3, 0, "cluster-name:prod-eu"),
+			monitorOverview("m4", 0, 2, "cluster-name:prod-eu"),
+			monitorOverview("m5", 0, 3, "cluster-name:prod-eu"),
+			monitorOverview("m6", 0, 4, "cluster-name:prod-eu"),
+			monitorOverview("m7", 0, 0, "cluster-name:prod-eu"),
+			monitorOverview("global", 9, 9),
+			monitorOverview("staging", 8, 0, "cluster-name:staging"),
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockClient.On("SnapShotTopologyQuery", ctx, "domain IN (\"prod-eu\")").
+			Return([]suseobservability.ViewComponent{
+				viewComponent(1, "a", "CRITICAL"),
+				viewComponent(2, "b", "CLEAR"),
+			}, nil).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(overview, nil).Once()
+
+		result, _, err := tools.GetClusterHealth(ctx, nil, ClusterHealthParams{Cluster: "prod-eu"})
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Components (2)")
+		assert.Contains(t, output, "| CRITICAL | 1 |")
+		assert.Contains(t, output, "Open problems (components unhealthy on their own checks)")
+		assert.Contains(t, output, "| a | 1 | CRITICAL |")
+		assert.Contains(t, output, "Firing monitors (6 of 7 checking the cluster)")
+		assert.NotContains(t, output, "environment-wide")
+		assert.Contains(t, output, "Worst monitors, showing 5 of 6 fetched")
+		assert.Contains(t, output, "| m3 | 3 | 0 |")
+		assert.NotContains(t, output, "| m2 |")
+		assert.NotContains(t, output, "| global |")
+		assert.NotContains(t, output, "| staging |")
+		assert.False(t, IsPartial(result))
+	})
+
+	t.Run("partial failure degrades the section", func(t *testing.T) {
+		mockClient.On("SnapShotTopologyQuery", ctx, "domain IN (\"prod-eu\")").
+			Return(nil, errors.New("topology down")).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(overview, nil).Once()
+
+		result, _, err := tools.GetClusterHealth(ctx, nil, ClusterHealthParams{Cluster: "prod-eu"})
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Components: unavailable (topology down)")
+		assert.Contains(t, output, "Firing monitors")
+		assert.True(t, IsPartial(result))
+	})
+
+	t.Run("all sections failing", func(t *testing.T) {
+		mockClient.On("SnapShotTopologyQuery", ctx, "domain IN (\"prod-eu\")").
+			Return(nil, errors.New("topology down")).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(nil, errors.New("monitors down")).Once()
+
+		result, _, err := tools.GetClusterHealth(ctx, nil, ClusterHealthParams{Cluster: "prod-eu"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "monitors down")
+	})
+
+	t.Run("missing cluster", func(t *testing.T) {
+		result, _, err := tools.GetClusterHealth(ctx, nil, ClusterHealthParams{})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
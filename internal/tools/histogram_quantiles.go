@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetHistogramQuantilesParams struct {
+	Metric    string            `json:"metric" jsonschema:"required,The base name of the histogram, without the _bucket suffix" examples:"http_server_request_duration_seconds"`
+	Labels    map[string]string `json:"labels,omitempty" jsonschema:"Label values the buckets must have, keyed by label name. A value ending in * matches the values starting with the rest of it, e.g. {\"namespace\": \"shop\"}"`
+	Quantiles []float64         `json:"quantiles,omitempty" jsonschema:"Quantiles to compute, between 0 and 1 (default: [0.5, 0.9, 0.99])" examples:"[0.5,0.9,0.99];[0.95]"`
+	Window    string            `json:"window,omitempty" jsonschema:"Window of the rate of the buckets, e.g. '5m' (default: 5m)" default:"5m" examples:"1m;5m"`
+	By        string            `json:"by,omitempty" jsonschema:"Labels to compute the quantiles per, comma-separated, e.g. 'service' (default: one quantile over all buckets)" examples:"service;namespace,pod"`
+	Start     string            `json:"start,omitempty" jsonschema:"Start time: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d') (default: 1h)" default:"1h" examples:"1h;24h;now-6h"`
+	End       string            `json:"end,omitempty" jsonschema:"End time: 'now', duration back from now or 'now-1h' like start, must be after start (default: now)" examples:"now;30m;now/d"`
+	Step      string            `json:"step,omitempty" jsonschema:"Query resolution step width in duration format or float number of seconds" examples:"1m;5m"`
+}
+
+// HistogramQuantile is the query of a quantile and its series
+type HistogramQuantile struct {
+	Quantile float64         `json:"quantile"`
+	Query    string          `json:"query"`
+	Series   []MetricsSeries `json:"series"`
+}
+
+// HistogramQuantilesResult is the structured content returned by getHistogramQuantiles
+type HistogramQuantilesResult struct {
+	Metric    string              `json:"metric"`
+	Step      string              `json:"step"`
+	Window    string              `json:"window"`
+	Quantiles []HistogramQuantile `json:"quantiles"`
+}
+
+// defaultHistogramQuantiles are the quantiles getHistogramQuantiles computes unless others are asked for
+var defaultHistogramQuantiles = []float64{0.5, 0.9, 0.99}
+
+const (
+	// defaultHistogramWindow is the rate window of the buckets of getHistogramQuantiles
+	defaultHistogramWindow = 5 * time.Minute
+	// maxHistogramQuantiles is the number of quantiles getHistogramQuantiles computes at most,
+	// each is a range query
+	maxHistogramQuantiles = 5
+)
+
+// GetHistogramQuantiles computes quantiles of a Prometheus histogram over time, building
+// histogram_quantile over the rate of its buckets so the query does not have to be written
+func (t tool) GetHistogramQuantiles(ctx context.Context, request *mcp.CallToolRequest, params GetHistogramQuantilesParams) (*mcp.CallToolResult, *HistogramQuantilesResult, error) {
+	metric := strings.TrimSuffix(strings.TrimSpace(params.Metric), "_bucket")
+	if metric == "" {
+		return nil, nil, fmt.Errorf("metric is required")
+	}
+	selector, err := buildSelector(metric+"_bucket", params.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	quantiles := params.Quantiles
+	if len(quantiles) == 0 {
+		quantiles = defaultHistogramQuantiles
+	}
+	if len(quantiles) > maxHistogramQuantiles {
+		return nil, nil, fmt.Errorf("at most %d quantiles can be computed at once, got %d", maxHistogramQuantiles, len(quantiles))
+	}
+	for _, q := range quantiles {
+		if !(q > 0 && q < 1) {
+			return nil, nil, fmt.Errorf("invalid quantile %s, quantiles are between 0 and 1, e.g. 0.99 for p99", formatValue(q))
+		}
+	}
+	window := defaultHistogramWindow
+	if params.Window != "" {
+		if window, err = time.ParseDuration(params.Window); err != nil || window <= 0 {
+			return nil, nil, fmt.Errorf("invalid window '%s', expected a positive duration like '5m'", params.Window)
+		}
+	}
+	by := []string{"le"}
+	for _, label := range strings.Split(params.By, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" || label == "le" {
+			continue
+		}
+		if !labelNamePattern.MatchString(label) {
+			return nil, nil, fmt.Errorf("invalid label '%s' in by: a label name consists of letters, digits and underscores and does not start with a digit", label)
+		}
+		by = append(by, label)
+	}
+
+	now := t.clock.Now()
+	startParam := params.Start
+	if startParam == "" {
+		startParam = "1h"
+	}
+	start, err := parseTime(startParam, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse start time: %w", err)
+	}
+	end := now
+	if params.End != "" {
+		if end, err = parseTime(params.End, now); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse end time: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return nil, nil, fmt.Errorf("start '%s' must be before end '%s'", startParam, params.End)
+	}
+	step, stepNote, err := resolveStep(params.Step, start, end, t.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A metric without buckets is not a histogram, querying it would only return no data
+	buckets, err := t.client.GetMetricSeries(ctx, metric+"_bucket", start, end, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up the buckets of '%s': %w", metric, err)
+	}
+	if len(buckets) == 0 {
+		return nil, nil, fmt.Errorf("metric '%s' has no %s_bucket series between %s and %s, so it is not a histogram or has no data. "+
+			"Find histograms with listMetrics and search '_bucket'; a summary exposes its quantiles in a 'quantile' label instead, query it with getMetrics",
+			metric, metric, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	}
+
+	rate := fmt.Sprintf("sum by (%s) (rate(%s[%s]))", strings.Join(by, ", "), selector, formatStep(window))
+	structured := &HistogramQuantilesResult{Metric: metric, Step: step, Window: formatStep(window)}
+	results := make([][]Series, len(quantiles))
+	for i, q := range quantiles {
+		query := fmt.Sprintf("histogram_quantile(%s, %s)", strconv.FormatFloat(q, 'g', -1, 64), rate)
+		// An empty timeout lets the client use its configured request timeout
+		res, err := t.client.QueryRangeMetric(ctx, query, start, end, step, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to query quantile %s: %w", formatValue(q), err)
+		}
+		results[i] = newSeries(res.Data.Result)
+		structured.Quantiles = append(structured.Quantiles, HistogramQuantile{
+			Quantile: q,
+			Query:    query,
+			Series:   structuredMetrics(results[i], query, step).Series,
+		})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(stepHeader(step, stepNote))
+	sb.WriteString(fmt.Sprintf("Quantiles of `%s` over %s rates of its buckets: `histogram_quantile(<quantile>, %s)`\n\n", metric, formatStep(window), rate))
+	sb.WriteString(formatHistogramQuantiles(quantiles, results, by[1:], t.limits.MetricMaxRows))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// formatHistogramQuantiles renders one row per timestamp and group of the by labels, with a
+// column per quantile. Above maxRows rows, zero meaning unlimited, the latest rows are kept.
+func formatHistogramQuantiles(quantiles []float64, results [][]Series, by []string, maxRows int) string {
+	type rowKey struct {
+		group     string
+		timestamp int64
+	}
+	values := map[rowKey][]string{}
+	groups := map[string]map[string]string{}
+	var keys []rowKey
+	for i, series := range results {
+		for _, s := range series {
+			group := seriesName(s.Labels)
+			groups[group] = s.Labels
+			for _, p := range s.Points {
+				key := rowKey{group: group, timestamp: p.Timestamp}
+				if _, ok := values[key]; !ok {
+					values[key] = make([]string, len(quantiles))
+					keys = append(keys, key)
+				}
+				values[key][i] = formatValue(p.Value)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return "No data: the buckets have no samples in the range, or their rate is zero everywhere.\n"
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].timestamp != keys[b].timestamp {
+			return keys[a].timestamp < keys[b].timestamp
+		}
+		return keys[a].group < keys[b].group
+	})
+	omitted := 0
+	if maxRows > 0 && len(keys) > maxRows {
+		omitted = len(keys) - maxRows
+		keys = keys[omitted:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Timestamp |")
+	for _, label := range by {
+		sb.WriteString(fmt.Sprintf(" %s |", label))
+	}
+	for _, q := range quantiles {
+		sb.WriteString(fmt.Sprintf(" p%s |", formatValue(q*100)))
+	}
+	sb.WriteString("\n|---|" + strings.Repeat("---|", len(by)+len(quantiles)) + "\n")
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("| %s |", formatUnix(key.timestamp)))
+		for _, label := range by {
+			sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(groups[key.group][label])))
+		}
+		for _, v := range values[key] {
+			sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(v)))
+		}
+		sb.WriteString("\n")
+	}
+	if omitted > 0 {
+		sb.WriteString(fmt.Sprintf("\nOutput truncated: the %d earliest rows are omitted. Use a coarser step, a shorter range or fewer by labels.\n", omitted))
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHistogramQuantiles(t *testing.T) {
+	ctx := context.Background()
+	bucket := []map[string]string{{"__name__": "latency_seconds_bucket", "le": "0.1"}}
+	quantileResponse := func(values ...float64) *suseobservability.MetricQueryResponse {
+		res := &suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{ResultType: "matrix"}}
+		for i, v := range values {
+			res.Data.Result = append(res.Data.Result, suseobservability.MetricResult{
+				Labels: map[string]string{"service": []string{"api", "web"}[i]},
+				Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: v}},
+			})
+		}
+		return res
+	}
+
+	t.Run("one column per quantile", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetMetricSeries", ctx, "latency_seconds_bucket", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 1).
+			Return(bucket, nil).Once()
+		rate := `sum by (le, service) (rate(latency_seconds_bucket{namespace="shop"}[5m]))`
+		mockClient.On("QueryRangeMetric", ctx, "histogram_quantile(0.5, "+rate+")", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(quantileResponse(0.05, 0.02), nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, "histogram_quantile(0.99, "+rate+")", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(quantileResponse(0.4), nil).Once()
+
+		result, structured, err := tools.GetHistogramQuantiles(ctx, nil, GetHistogramQuantilesParams{
+			Metric:    "latency_seconds_bucket",
+			Labels:    map[string]string{"namespace": "shop"},
+			Quantiles: []float64{0.5, 0.99},
+			By:        "service",
+		})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Quantiles of `latency_seconds` over 5m rates of its buckets: `histogram_quantile(<quantile>, "+rate+")`\n\n")
+		assert.Contains(t, output, "| Timestamp | service | p50 | p99 |\n|---|---|---|---|\n"+
+			"| "+formatUnix(1700000000)+" | api | 0.05 | 0.4 |\n"+
+			"| "+formatUnix(1700000000)+" | web | 0.02 | - |\n")
+		require.Len(t, structured.Quantiles, 2)
+		assert.Equal(t, 0.99, structured.Quantiles[1].Quantile)
+		assert.Equal(t, "histogram_quantile(0.99, "+rate+")", structured.Quantiles[1].Query)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("not a histogram", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetMetricSeries", ctx, "up_bucket", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), 1).
+			Return([]map[string]string{}, nil).Once()
+
+		_, _, err := tools.GetHistogramQuantiles(ctx, nil, GetHistogramQuantilesParams{Metric: "up"})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "metric 'up' has no up_bucket series between")
+		assert.Contains(t, err.Error(), "Find histograms with listMetrics and search '_bucket'")
+		mockClient.AssertNotCalled(t, "QueryRangeMetric", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+		tests := []struct {
+			name   string
+			params GetHistogramQuantilesParams
+			err    string
+		}{
+			{name: "missing metric", params: GetHistogramQuantilesParams{}, err: "metric is required"},
+			{name: "quantile above 1", params: GetHistogramQuantilesParams{Metric: "m", Quantiles: []float64{99}}, err: "invalid quantile 99, quantiles are between 0 and 1, e.g. 0.99 for p99"},
+			{name: "too many quantiles", params: GetHistogramQuantilesParams{Metric: "m", Quantiles: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}}, err: "at most 5 quantiles can be computed at once, got 6"},
+			{name: "invalid window", params: GetHistogramQuantilesParams{Metric: "m", Window: "5"}, err: "invalid window '5', expected a positive duration like '5m'"},
+			{name: "invalid by label", params: GetHistogramQuantilesParams{Metric: "m", By: "service-name"}, err: "invalid label 'service-name' in by: a label name consists of letters, digits and underscores and does not start with a digit"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := tools.GetHistogramQuantiles(ctx, nil, tt.params)
+
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+	})
+}
+
+func TestFormatHistogramQuantiles(t *testing.T) {
+	series := func(values ...float64) []Series {
+		return []Series{{Labels: map[string]string{}, Points: anomalyPoints(values...)}}
+	}
+
+	output := formatHistogramQuantiles([]float64{0.5, 0.999}, [][]Series{series(1, 2, 3), series(4, 5, 6)}, nil, 2)
+
+	assert.Equal(t, "| Timestamp | p50 | p99.9 |\n|---|---|---|\n"+
+		"| "+formatUnix(1700000060)+" | 2 | 5 |\n"+
+		"| "+formatUnix(1700000120)+" | 3 | 6 |\n"+
+		"\nOutput truncated: the 1 earliest rows are omitted. Use a coarser step, a shorter range or fewer by labels.\n", output)
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithLogging wraps a tool handler so that every call is logged at debug level with the tool
+// name, its duration and outcome. The arguments are left out, they may hold free text.
+func WithLogging[In, Out any](name string, h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, request *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+			return h(ctx, request, in)
+		}
+		start := time.Now()
+		result, out, err := h(ctx, request, in)
+		attrs := []any{"tool", name, "duration", time.Since(start)}
+		switch {
+		case err != nil:
+			attrs = append(attrs, "status", "error", "error", err)
+		case result != nil && result.IsError:
+			attrs = append(attrs, "status", "error")
+		case result != nil && IsPartial(result):
+			attrs = append(attrs, "status", "partial")
+		default:
+			attrs = append(attrs, "status", "ok")
+		}
+		slog.DebugContext(ctx, "Tool call", attrs...)
+		return result, out, err
+	}
+}
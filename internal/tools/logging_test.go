@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogging(t *testing.T) {
+	capture := func(t *testing.T, level slog.Level) *bytes.Buffer {
+		var buf bytes.Buffer
+		previous := slog.Default()
+		slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})))
+		t.Cleanup(func() { slog.SetDefault(previous) })
+		return &buf
+	}
+	handler := func(err error, partial bool) mcp.ToolHandlerFor[struct{}, any] {
+		return func(ctx context.Context, request *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+			if err != nil {
+				return nil, nil, err
+			}
+			result := &mcp.CallToolResult{}
+			if partial {
+				markPartial(result)
+			}
+			return result, nil, nil
+		}
+	}
+	call := func(h mcp.ToolHandlerFor[struct{}, any]) error {
+		_, _, err := WithLogging("getMetrics", h)(context.Background(), &mcp.CallToolRequest{}, struct{}{})
+		return err
+	}
+
+	t.Run("logs the tool, duration and status at debug level", func(t *testing.T) {
+		logs := capture(t, slog.LevelDebug)
+
+		require.NoError(t, call(handler(nil, true)))
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, "Tool call", entry["msg"])
+		assert.Equal(t, "DEBUG", entry["level"])
+		assert.Equal(t, "getMetrics", entry["tool"])
+		assert.Equal(t, "partial", entry["status"])
+		assert.Contains(t, entry, "duration")
+	})
+
+	t.Run("logs the error", func(t *testing.T) {
+		logs := capture(t, slog.LevelDebug)
+
+		assert.EqualError(t, call(handler(errors.New("boom"), false)), "boom")
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, "error", entry["status"])
+		assert.Equal(t, "boom", entry["error"])
+	})
+
+	t.Run("silent above debug level", func(t *testing.T) {
+		logs := capture(t, slog.LevelInfo)
+
+		require.NoError(t, call(handler(nil, false)))
+
+		assert.Empty(t, logs.String())
+	})
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetLogsParams struct {
+	ComponentID  int64  `json:"component_id,omitempty" jsonschema:"The ID of the component to get the logs of"`
+	ComponentURN string `json:"component_urn,omitempty" jsonschema:"Instead of component_id, the URN (identifier) of the component, e.g. 'urn:kubernetes:/prod:shop:pod/checkout-1'" examples:"urn:kubernetes:/prod:shop:pod/checkout-1"`
+	Lookback     string `json:"lookback,omitempty" jsonschema:"How far back to look for log lines, e.g. '15m' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"15m;2d"`
+	Filter       string `json:"filter,omitempty" jsonschema:"Only return log lines containing this text (case-insensitive)"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"Maximum number of log lines returned, the latest are kept (default: 100, max: 1000)" default:"100"`
+}
+
+const (
+	defaultLogLines = 100
+	maxLogLines     = 1000
+)
+
+// GetLogs returns the latest log lines of a component, oldest first, in a code block
+func (t tool) GetLogs(ctx context.Context, request *mcp.CallToolRequest, params GetLogsParams) (*mcp.CallToolResult, any, error) {
+	urn := strings.TrimSpace(params.ComponentURN)
+	if (params.ComponentID != 0) == (urn != "") {
+		return nil, nil, fmt.Errorf("exactly one of component_id or component_urn must be provided")
+	}
+	if params.ComponentID < 0 {
+		return nil, nil, fmt.Errorf("component_id must not be negative, got %d", params.ComponentID)
+	}
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit, err := displayLimit(params.Limit, defaultLogLines)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > maxLogLines {
+		return nil, nil, fmt.Errorf("limit must be at most %d, got %d", maxLogLines, limit)
+	}
+
+	var query, subject string
+	if params.ComponentID != 0 {
+		query = fmt.Sprintf("id = %d", params.ComponentID)
+		subject = fmt.Sprintf("component ID %d", params.ComponentID)
+	} else {
+		// STQL strings are escaped like PromQL strings
+		query = fmt.Sprintf("identifier = \"%s\"", promQLString(urn))
+		subject = fmt.Sprintf("component %s", urn)
+	}
+	filter := strings.TrimSpace(params.Filter)
+	if filter != "" {
+		subject += fmt.Sprintf(" containing '%s'", filter)
+	}
+
+	end := t.clock.Now()
+	res, err := t.client.GetLogs(ctx, &suseobservability.LogListRequest{
+		StartTimestampMs: end.Add(-lookback).UnixMilli(),
+		EndTimestampMs:   end.UnixMilli(),
+		TopologyQuery:    query,
+		Query:            filter,
+		Limit:            limit,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get logs of %s: %w", subject, err)
+	}
+
+	if len(res.Items) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No log lines found for %s in the last %s.", subject, formatLookback(lookback)),
+				},
+			},
+		}, nil, nil
+	}
+
+	// The server returns the latest lines, they are read oldest first
+	lines := slices.Clone(res.Items)
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Timestamp > lines[j].Timestamp
+	})
+	shown := lines[:min(limit, len(lines))]
+	slices.Reverse(shown)
+
+	rendered := make([]string, 0, len(shown))
+	for _, l := range shown {
+		rendered = append(rendered, fmt.Sprintf("%s %-5s %s",
+			time.UnixMilli(l.Timestamp).UTC().Format(time.RFC3339), valueOrDash(strings.ToUpper(l.Level)), strings.TrimRight(l.Message, " \t\r\n")))
+	}
+	body := strings.Join(rendered, "\n")
+	fence := codeFence(body)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Logs of %s in the last %s, oldest first (%s):\n\n", subject, formatLookback(lookback), totalSummary(len(shown), len(lines), int(res.Total))))
+	sb.WriteString(fence + "\n" + body + "\n" + fence + "\n")
+	if int(res.Total) > len(shown) {
+		sb.WriteString(fmt.Sprintf("\n%d earlier log lines matched, narrow the filter or lookback, or raise limit, to see them.\n", int(res.Total)-len(shown)))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, nil, nil
+}
+
+// codeFence returns a markdown code fence longer than any backtick run in text, so log
+// lines containing fences cannot end the block
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogs(t *testing.T) {
+	ctx := context.Background()
+	// Newest first, as returned by the server
+	lines := []suseobservability.LogLine{
+		{Timestamp: 1700000120000, Level: "error", Message: "upstream timeout after 30s\n"},
+		{Timestamp: 1700000060000, Level: "WARN", Message: "retrying upstream request"},
+		{Timestamp: 1700000000000, Message: "GET /cart 200"},
+	}
+
+	t.Run("log lines of a component oldest first", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		var req *suseobservability.LogListRequest
+		mockClient.On("GetLogs", ctx, mock.AnythingOfType("*suseobservability.LogListRequest")).
+			Run(func(args mock.Arguments) { req = args.Get(1).(*suseobservability.LogListRequest) }).
+			Return(&suseobservability.LogLinesWithTotal{Items: lines, Total: 3}, nil).Once()
+
+		result, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentID: 42, Lookback: "2h"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "id = 42", req.TopologyQuery)
+		assert.Empty(t, req.Query)
+		assert.Equal(t, defaultLogLines, req.Limit)
+		assert.Equal(t, 2*time.Hour, time.Duration(req.EndTimestampMs-req.StartTimestampMs)*time.Millisecond)
+		assert.Equal(t, "Logs of component ID 42 in the last 2h, oldest first (showing 3 of 3 fetched (3 total reported by server)):\n\n"+
+			"```\n"+
+			"2023-11-14T22:13:20Z -     GET /cart 200\n"+
+			"2023-11-14T22:14:20Z WARN  retrying upstream request\n"+
+			"2023-11-14T22:15:20Z ERROR upstream timeout after 30s\n"+
+			"```\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("text filter by component URN", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetLogs", ctx, mock.MatchedBy(func(req *suseobservability.LogListRequest) bool {
+			return req.TopologyQuery == `identifier = "urn:kubernetes:/prod:shop:pod/checkout-1"` && req.Query == "timeout"
+		})).Return(&suseobservability.LogLinesWithTotal{Items: lines[:1], Total: 1}, nil).Once()
+
+		result, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentURN: " urn:kubernetes:/prod:shop:pod/checkout-1 ", Filter: " timeout "})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Logs of component urn:kubernetes:/prod:shop:pod/checkout-1 containing 'timeout' in the last 1h, oldest first")
+		assert.Contains(t, output, "ERROR upstream timeout after 30s")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("limit keeps the latest lines", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		// A server ignoring the limit still only gets the latest lines rendered
+		mockClient.On("GetLogs", ctx, mock.MatchedBy(func(req *suseobservability.LogListRequest) bool {
+			return req.Limit == 2
+		})).Return(&suseobservability.LogLinesWithTotal{Items: lines, Total: 250}, nil).Once()
+
+		result, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentID: 42, Limit: 2})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "(showing 2 of 3 fetched (250 total reported by server))")
+		assert.Contains(t, output, "```\n2023-11-14T22:14:20Z WARN  retrying upstream request\n2023-11-14T22:15:20Z ERROR upstream timeout after 30s\n```\n")
+		assert.NotContains(t, output, "GET /cart")
+		assert.Contains(t, output, "248 earlier log lines matched, narrow the filter or lookback, or raise limit, to see them.")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("fence is longer than backtick runs in the lines", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetLogs", ctx, mock.Anything).Return(&suseobservability.LogLinesWithTotal{
+			Items: []suseobservability.LogLine{{Timestamp: 1700000000000, Level: "INFO", Message: "rendered ```yaml block"}},
+			Total: 1,
+		}, nil).Once()
+
+		result, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentID: 42})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "````\n2023-11-14T22:13:20Z INFO  rendered ```yaml block\n````\n")
+	})
+
+	t.Run("no log lines", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetLogs", ctx, mock.Anything).Return(&suseobservability.LogLinesWithTotal{}, nil).Once()
+
+		result, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentID: 42, Filter: "panic"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No log lines found for component ID 42 containing 'panic' in the last 1h.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetLogs", ctx, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+		_, _, err := tools.GetLogs(ctx, nil, GetLogsParams{ComponentID: 42})
+
+		assert.EqualError(t, err, "failed to get logs of component ID 42: boom")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params GetLogsParams
+			err    string
+		}{
+			{GetLogsParams{}, "exactly one of component_id or component_urn must be provided"},
+			{GetLogsParams{ComponentID: 42, ComponentURN: "urn:x"}, "exactly one of component_id or component_urn must be provided"},
+			{GetLogsParams{ComponentID: -1}, "component_id must not be negative, got -1"},
+			{GetLogsParams{ComponentID: 42, Limit: -1}, "limit must not be negative, got -1"},
+			{GetLogsParams{ComponentID: 42, Limit: 1001}, "limit must be at most 1000, got 1001"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.GetLogs(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
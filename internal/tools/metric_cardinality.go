@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMetricCardinalityParams struct {
+	MetricName string `json:"metric_name" jsonschema:"required,The exact name of the metric"`
+	Lookback   string `json:"lookback,omitempty" jsonschema:"How far back to count the series of the metric, e.g. '12h' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"12h;2d"`
+}
+
+// LabelCardinality is the number of distinct values of a label
+type LabelCardinality struct {
+	Label          string `json:"label"`
+	DistinctValues int    `json:"distinct_values"`
+}
+
+// MetricCardinalityResult is the structured content returned by getMetricCardinality
+type MetricCardinalityResult struct {
+	Metric   string `json:"metric"`
+	Lookback string `json:"lookback"`
+	Series   int    `json:"series"`
+	// Sampled is set when the metric has more series than the sample limit, the counts are
+	// then lower bounds
+	Sampled  bool               `json:"sampled,omitempty"`
+	TopLabel string             `json:"top_label,omitempty"`
+	Labels   []LabelCardinality `json:"labels"`
+}
+
+// GetMetricCardinality counts the series of a metric and the distinct values of each of its
+// labels, to find the labels driving the number of series
+func (t tool) GetMetricCardinality(ctx context.Context, request *mcp.CallToolRequest, params GetMetricCardinalityParams) (*mcp.CallToolResult, *MetricCardinalityResult, error) {
+	metric := strings.TrimSpace(params.MetricName)
+	if metric == "" {
+		return nil, nil, fmt.Errorf("metric_name is required")
+	}
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end := t.clock.Now()
+	samples := t.limits.MetricCardinalitySamples
+	// One series more than the sample tells whether the limit was hit
+	limit := 0
+	if samples > 0 {
+		limit = samples + 1
+	}
+	sets, err := t.client.GetMetricSeries(ctx, metric, end.Add(-lookback), end, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get series of metric '%s': %w", metric, err)
+	}
+	sampled := samples > 0 && len(sets) > samples
+	if sampled {
+		sets = sets[:samples]
+	}
+
+	window := formatLookback(lookback)
+	structured := &MetricCardinalityResult{
+		Metric:   metric,
+		Lookback: window,
+		Series:   len(sets),
+		Sampled:  sampled,
+		Labels:   labelCardinalities(sets),
+	}
+	if len(sets) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No series found for metric '%s' in the last %s, check the name with listMetrics or widen the lookback.", metric, window),
+				},
+			},
+		}, structured, nil
+	}
+
+	var sb strings.Builder
+	if sampled {
+		sb.WriteString(fmt.Sprintf("Warning: metric '%s' has more than %d series, only the first %d were counted and the numbers below are lower bounds. "+
+			"Shorten the lookback or raise -metric-cardinality-samples for exact counts.\n\n", metric, samples, samples))
+	}
+	sb.WriteString(fmt.Sprintf("Cardinality of metric '%s' in the last %s: %d series.\n\n", metric, window, len(sets)))
+	if len(structured.Labels) > 0 {
+		top := structured.Labels[0]
+		structured.TopLabel = top.Label
+		sb.WriteString(fmt.Sprintf("Top offending label: `%s` with %d distinct values.\n\n", top.Label, top.DistinctValues))
+		sb.WriteString("| Label | Distinct Values | Share of Series |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, l := range structured.Labels {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.1f%% |\n", l.Label, l.DistinctValues, 100*float64(l.DistinctValues)/float64(len(sets))))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// labelCardinalities counts the distinct values of every label across the series, sorted by
+// count descending and then by name. __name__ is left out.
+func labelCardinalities(series []map[string]string) []LabelCardinality {
+	labels := exampleLabelValues(series, 0)
+	counts := make([]LabelCardinality, 0, len(labels))
+	for _, l := range labels {
+		counts = append(counts, LabelCardinality{Label: l.name, DistinctValues: l.distinct})
+	}
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].DistinctValues > counts[j].DistinctValues
+	})
+	return counts
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelCardinalities(t *testing.T) {
+	counts := labelCardinalities([]map[string]string{
+		{"__name__": "http_requests_total", "job": "api", "pod": "a", "path": "/"},
+		{"__name__": "http_requests_total", "job": "api", "pod": "b", "path": "/"},
+		{"__name__": "http_requests_total", "job": "api", "pod": "c", "path": "/login"},
+	})
+
+	assert.Equal(t, []LabelCardinality{
+		{Label: "pod", DistinctValues: 3},
+		{Label: "path", DistinctValues: 2},
+		{Label: "job", DistinctValues: 1},
+	}, counts)
+}
+
+func TestGetMetricCardinality(t *testing.T) {
+	ctx := context.Background()
+	anyTime := mock.AnythingOfType("time.Time")
+
+	t.Run("counts series and distinct values per label", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricSeries", ctx, "up", anyTime, anyTime, 10001).Return([]map[string]string{
+			{"__name__": "up", "job": "node", "pod": "a"},
+			{"__name__": "up", "job": "node", "pod": "b"},
+			{"__name__": "up", "job": "node", "pod": "c"},
+			{"__name__": "up", "job": "api", "pod": "d"},
+		}, nil).Once()
+
+		result, structured, err := tools.GetMetricCardinality(ctx, nil, GetMetricCardinalityParams{MetricName: "up"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Cardinality of metric 'up' in the last 1h: 4 series.\n\n"+
+			"Top offending label: `pod` with 4 distinct values.\n\n"+
+			"| Label | Distinct Values | Share of Series |\n|---|---|---|\n| pod | 4 | 100.0% |\n| job | 2 | 50.0% |\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, "pod", structured.TopLabel)
+		assert.False(t, structured.Sampled)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("warns when the sample limit is hit", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		limits := DefaultLimits()
+		limits.MetricCardinalitySamples = 3
+		tools := NewBaseTool(mockClient).WithLimits(limits)
+
+		sets := make([]map[string]string, 4)
+		for i := range sets {
+			sets[i] = map[string]string{"pod": fmt.Sprintf("pod-%d", i)}
+		}
+		mockClient.On("GetMetricSeries", ctx, "up", anyTime, anyTime, 4).Return(sets, nil).Once()
+
+		result, structured, err := tools.GetMetricCardinality(ctx, nil, GetMetricCardinalityParams{MetricName: "up"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Warning: metric 'up' has more than 3 series, only the first 3 were counted and the numbers below are lower bounds.")
+		assert.Contains(t, output, "3 series.")
+		assert.True(t, structured.Sampled)
+		assert.Equal(t, 3, structured.Series)
+	})
+
+	t.Run("no series", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricSeries", ctx, "missing", anyTime, anyTime, 10001).Return([]map[string]string{}, nil).Once()
+
+		result, _, err := tools.GetMetricCardinality(ctx, nil, GetMetricCardinalityParams{MetricName: "missing", Lookback: "2d"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No series found for metric 'missing' in the last 2d, check the name with listMetrics or widen the lookback.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		_, _, err := tools.GetMetricCardinality(ctx, nil, GetMetricCardinalityParams{MetricName: " "})
+		assert.EqualError(t, err, "metric_name is required")
+
+		mockClient.On("GetMetricSeries", ctx, "up", anyTime, anyTime, 10001).Return(nil, errors.New("timeout")).Once()
+		_, _, err = tools.GetMetricCardinality(ctx, nil, GetMetricCardinalityParams{MetricName: "up"})
+		assert.EqualError(t, err, "failed to get series of metric 'up': timeout")
+	})
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type CompareMetricsParams struct {
+	Query     string  `json:"query" jsonschema:"The PromQL query to run over both windows" examples:"sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]))"`
+	Window    string  `json:"window,omitempty" jsonschema:"Length of the compared windows, the current one ending now, e.g. '1h' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"1h;6h"`
+	Offset    string  `json:"baseline_offset,omitempty" jsonschema:"How far back the baseline window is shifted from the current one, at least the window, e.g. '1d' for the same hour yesterday or '7d' for last week (default: 1d, max: 7d)" default:"1d" examples:"1d;7d"`
+	Step      string  `json:"step,omitempty" jsonschema:"Query resolution step width in duration format or float number of seconds (default: chosen from the window)" examples:"1m;5m"`
+	Threshold float64 `json:"threshold,omitempty" jsonschema:"Change of the mean in percent below which a series is reported unchanged (default: 10)" default:"10"`
+	Limit     int     `json:"limit,omitempty" jsonschema:"Maximum number of series listed (default: 20)"`
+}
+
+// defaultCompareOffset is the baseline offset of compareMetrics, the same window yesterday
+const defaultCompareOffset = 24 * time.Hour
+
+// defaultCompareThreshold is the change of the mean, in percent, compareMetrics reports as unchanged below
+const defaultCompareThreshold = 10
+
+// Verdicts of a compared series
+const (
+	verdictHigher          = "higher"
+	verdictLower           = "lower"
+	verdictUnchanged       = "unchanged"
+	verdictMissingBaseline = "missing in baseline"
+	verdictMissingCurrent  = "missing in current window"
+	verdictNoData          = "no data"
+)
+
+// StatDelta compares a statistic of a series over the baseline and the current window
+type StatDelta struct {
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`
+	// Percent is the delta in percent of the baseline, unset when the baseline is zero
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+// SeriesComparison is a series of compareMetrics. The deltas are only set when the series
+// has finite values in both windows.
+type SeriesComparison struct {
+	Labels  map[string]string `json:"labels"`
+	Verdict string            `json:"verdict"`
+	Mean    *StatDelta        `json:"mean,omitempty"`
+	Max     *StatDelta        `json:"max,omitempty"`
+	P95     *StatDelta        `json:"p95,omitempty"`
+}
+
+// MetricComparisonResult is the structured content returned by compareMetrics
+type MetricComparisonResult struct {
+	Query         string             `json:"query"`
+	Step          string             `json:"step"`
+	CurrentStart  time.Time          `json:"current_start"`
+	CurrentEnd    time.Time          `json:"current_end"`
+	BaselineStart time.Time          `json:"baseline_start"`
+	BaselineEnd   time.Time          `json:"baseline_end"`
+	Threshold     float64            `json:"threshold"`
+	Series        []SeriesComparison `json:"series"`
+}
+
+// CompareMetrics runs a range query over the current window and a baseline window shifted
+// back by the offset, and compares the mean, max and p95 of every series across them
+func (t tool) CompareMetrics(ctx context.Context, request *mcp.CallToolRequest, params CompareMetricsParams) (*mcp.CallToolResult, *MetricComparisonResult, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, nil, fmt.Errorf("query is required")
+	}
+	window, err := parseLookback(params.Window)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse window: %w", err)
+	}
+	offset := defaultCompareOffset
+	if params.Offset != "" {
+		if offset, err = parseLookback(params.Offset); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse baseline_offset: %w", err)
+		}
+	}
+	if offset < window {
+		return nil, nil, fmt.Errorf("baseline_offset %s is shorter than the window %s, the windows would overlap", formatLookback(offset), formatLookback(window))
+	}
+	threshold := params.Threshold
+	if threshold < 0 {
+		return nil, nil, fmt.Errorf("threshold must not be negative, got %g", threshold)
+	}
+	if threshold == 0 {
+		threshold = defaultCompareThreshold
+	}
+	limit, err := displayLimit(params.Limit, t.limits.MetricMaxSeries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end := t.clock.Now()
+	start := end.Add(-window)
+	step, stepNote, err := resolveStep(params.Step, start, end, t.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// An empty timeout lets the client use its configured request timeout
+	current, err := t.client.QueryRangeMetric(ctx, query, start, end, step, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query the current window: %w", err)
+	}
+	baseline, err := t.client.QueryRangeMetric(ctx, query, start.Add(-offset), end.Add(-offset), step, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query the baseline window: %w", err)
+	}
+
+	comparisons := compareSeries(newSeries(baseline.Data.Result), newSeries(current.Data.Result), threshold)
+	structured := &MetricComparisonResult{
+		Query:         query,
+		Step:          step,
+		CurrentStart:  start.UTC(),
+		CurrentEnd:    end.UTC(),
+		BaselineStart: start.Add(-offset).UTC(),
+		BaselineEnd:   end.Add(-offset).UTC(),
+		Threshold:     threshold,
+		Series:        comparisons,
+	}
+
+	var sb strings.Builder
+	sb.WriteString(stepHeader(step, stepNote))
+	sb.WriteString(fmt.Sprintf("Comparing `%s` over the last %s (%s to %s) with the baseline %s earlier (%s to %s).\n\n",
+		query, formatLookback(window), start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339),
+		formatLookback(offset), structured.BaselineStart.Format(time.RFC3339), structured.BaselineEnd.Format(time.RFC3339)))
+	if len(comparisons) == 0 {
+		sb.WriteString("No series returned in either window.")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: sb.String(),
+				},
+			},
+		}, structured, nil
+	}
+
+	shown := comparisons[:min(limit, len(comparisons))]
+	sb.WriteString(fmt.Sprintf("Compared series, %s. Series whose mean changed by less than %g%% are unchanged.\n\n",
+		countSummary(len(shown), len(comparisons)), threshold))
+	sb.WriteString("| Series | Verdict | Mean | Max | P95 |\n")
+	sb.WriteString("|---|---|---|---|---|\n")
+	for _, c := range shown {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", seriesName(c.Labels), c.Verdict, formatStatDelta(c.Mean), formatStatDelta(c.Max), formatStatDelta(c.P95)))
+	}
+	sb.WriteString("\nCells read baseline → current (delta, percent of the baseline).\n")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// compareSeries aligns the series of both windows on their labels and compares them. Series
+// of only one window are kept with a missing verdict. The result lists the missing series
+// first, then by the size of the change of their mean, and then by labels.
+func compareSeries(baseline, current []Series, threshold float64) []SeriesComparison {
+	baselineStats := make(map[string]SeriesStats, len(baseline))
+	for _, s := range baseline {
+		baselineStats[seriesName(s.Labels)] = s.Stats()
+	}
+
+	comparisons := make([]SeriesComparison, 0, len(current)+len(baseline))
+	seen := make(map[string]bool, len(current))
+	for _, s := range current {
+		name := seriesName(s.Labels)
+		seen[name] = true
+		before, ok := baselineStats[name]
+		if !ok {
+			comparisons = append(comparisons, SeriesComparison{Labels: s.Labels, Verdict: verdictMissingBaseline})
+			continue
+		}
+		comparisons = append(comparisons, compareStats(before, s.Stats(), threshold))
+	}
+	for _, s := range baseline {
+		if !seen[seriesName(s.Labels)] {
+			comparisons = append(comparisons, SeriesComparison{Labels: s.Labels, Verdict: verdictMissingCurrent})
+		}
+	}
+
+	rank := func(c SeriesComparison) float64 {
+		switch {
+		case c.Verdict == verdictMissingBaseline || c.Verdict == verdictMissingCurrent:
+			return math.Inf(1)
+		case c.Mean == nil:
+			return -1
+		case c.Mean.Percent == nil:
+			// A change from zero is larger than any relative change
+			if c.Mean.Delta != 0 {
+				return math.MaxFloat64
+			}
+			return 0
+		default:
+			return math.Abs(*c.Mean.Percent)
+		}
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		a, b := rank(comparisons[i]), rank(comparisons[j])
+		if a != b {
+			return a > b
+		}
+		return seriesName(comparisons[i].Labels) < seriesName(comparisons[j].Labels)
+	})
+	return comparisons
+}
+
+// compareStats compares the statistics of a series in both windows. The verdict follows the
+// change of the mean: unchanged below threshold percent, higher or lower otherwise.
+func compareStats(before, after SeriesStats, threshold float64) SeriesComparison {
+	c := SeriesComparison{Labels: after.Labels}
+	if !before.hasValues() || !after.hasValues() {
+		c.Verdict = verdictNoData
+		return c
+	}
+	c.Mean = statDelta(before.Mean, after.Mean)
+	c.Max = statDelta(before.Max, after.Max)
+	c.P95 = statDelta(before.P95, after.P95)
+
+	switch {
+	case c.Mean.Delta == 0 || (c.Mean.Percent != nil && math.Abs(*c.Mean.Percent) < threshold):
+		c.Verdict = verdictUnchanged
+	case c.Mean.Delta > 0:
+		c.Verdict = verdictHigher
+	default:
+		c.Verdict = verdictLower
+	}
+	return c
+}
+
+func statDelta(before, after float64) *StatDelta {
+	d := &StatDelta{Baseline: before, Current: after, Delta: after - before}
+	if before != 0 {
+		percent := 100 * d.Delta / math.Abs(before)
+		d.Percent = &percent
+	}
+	return d
+}
+
+// formatStatDelta renders a statistic like '10 → 12.5 (+2.5, +25.0%)'
+func formatStatDelta(d *StatDelta) string {
+	if d == nil {
+		return "-"
+	}
+	sign := ""
+	if d.Delta >= 0 {
+		sign = "+"
+	}
+	percent := "n/a"
+	if d.Percent != nil {
+		percent = fmt.Sprintf("%+.1f%%", *d.Percent)
+	}
+	return fmt.Sprintf("%s → %s (%s%s, %s)", formatValue(d.Baseline), formatValue(d.Current), sign, formatValue(d.Delta), percent)
+}
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareSeries(t *testing.T) {
+	series := func(pod string, values ...float64) Series {
+		s := Series{Labels: map[string]string{"pod": pod}}
+		for i, v := range values {
+			s.Points = append(s.Points, Point{Timestamp: int64(1700000000 + 60*i), Value: v})
+		}
+		return s
+	}
+	baseline := []Series{series("up", 10, 10), series("down", 10, 10), series("flat", 10, 10), series("gone", 1), series("zero", 0)}
+	current := []Series{series("flat", 10, 10.5), series("down", 5, 5), series("up", 20, 30), series("new", 1), series("zero", 2)}
+
+	comparisons := compareSeries(baseline, current, 10)
+
+	verdicts := map[string]string{}
+	var order []string
+	for _, c := range comparisons {
+		verdicts[c.Labels["pod"]] = c.Verdict
+		order = append(order, c.Labels["pod"])
+	}
+	assert.Equal(t, map[string]string{
+		"up":   "higher",
+		"down": "lower",
+		"flat": "unchanged",
+		"gone": "missing in current window",
+		"new":  "missing in baseline",
+		"zero": "higher",
+	}, verdicts)
+	assert.Equal(t, []string{"gone", "new", "zero", "up", "down", "flat"}, order)
+
+	up := comparisons[3]
+	percent := 150.0
+	assert.Equal(t, &StatDelta{Baseline: 10, Current: 25, Delta: 15, Percent: &percent}, up.Mean)
+	assert.Equal(t, 20.0, up.Max.Delta)
+	assert.Nil(t, comparisons[2].Mean.Percent, "a change from zero has no percentage")
+	assert.Nil(t, comparisons[0].Mean)
+}
+
+func TestCompareStatsNoData(t *testing.T) {
+	c := compareStats(SeriesStats{Samples: 1, NonFinite: 1}, SeriesStats{Samples: 1, Mean: 1}, 10)
+
+	assert.Equal(t, "no data", c.Verdict)
+	assert.Nil(t, c.Mean)
+}
+
+func TestFormatStatDelta(t *testing.T) {
+	assert.Equal(t, "10 → 12.5 (+2.5, +25.0%)", formatStatDelta(statDelta(10, 12.5)))
+	assert.Equal(t, "4 → 2 (-2, -50.0%)", formatStatDelta(statDelta(4, 2)))
+	assert.Equal(t, "0 → 3 (+3, n/a)", formatStatDelta(statDelta(0, 3)))
+	assert.Equal(t, "-", formatStatDelta(nil))
+}
+
+func TestCompareMetrics(t *testing.T) {
+	ctx := context.Background()
+	recent := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) < 2*time.Hour })
+	old := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) > 2*time.Hour })
+
+	t.Run("compares the windows", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", ctx, "cpu", recent, mock.AnythingOfType("time.Time"), "1m", "").
+			Return(metricResponse(map[string]string{"pod": "a"}, 2), nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, "cpu", old, mock.AnythingOfType("time.Time"), "1m", "").
+			Return(metricResponse(map[string]string{"pod": "a"}, 1), nil).Once()
+		tool := NewBaseTool(mockClient)
+
+		result, structured, err := tool.CompareMetrics(ctx, &mcp.CallToolRequest{}, CompareMetricsParams{Query: "cpu"})
+
+		require.NoError(t, err)
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, text, "over the last 1h")
+		assert.Contains(t, text, "with the baseline 1d earlier")
+		assert.Contains(t, text, "| {pod=\"a\"} | higher | 1 → 2 (+1, +100.0%) |")
+		require.Len(t, structured.Series, 1)
+		assert.Equal(t, 24*time.Hour, structured.CurrentEnd.Sub(structured.BaselineEnd))
+		assert.Equal(t, 10.0, structured.Threshold)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("flags series missing in a window", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", ctx, "cpu", recent, mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, "cpu", old, mock.AnythingOfType("time.Time"), "1m", "").
+			Return(metricResponse(map[string]string{"pod": "a"}, 1), nil).Once()
+		tool := NewBaseTool(mockClient)
+
+		result, _, err := tool.CompareMetrics(ctx, &mcp.CallToolRequest{}, CompareMetricsParams{Query: "cpu", Window: "1h", Offset: "2h"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| {pod=\"a\"} | missing in current window | - | - | - |")
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		tool := NewBaseTool(new(MockSuseObservabilityClient))
+
+		_, _, err := tool.CompareMetrics(ctx, &mcp.CallToolRequest{}, CompareMetricsParams{})
+		assert.EqualError(t, err, "query is required")
+
+		_, _, err = tool.CompareMetrics(ctx, &mcp.CallToolRequest{}, CompareMetricsParams{Query: "cpu", Window: "2h", Offset: "1h"})
+		assert.EqualError(t, err, "baseline_offset 1h is shorter than the window 2h, the windows would overlap")
+
+		_, _, err = tool.CompareMetrics(ctx, &mcp.CallToolRequest{}, CompareMetricsParams{Query: "cpu", Threshold: -1})
+		assert.EqualError(t, err, "threshold must not be negative, got -1")
+	})
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMetricLabelValuesParams struct {
+	MetricName string `json:"metric_name" jsonschema:"required,The exact name of the metric"`
+	Label      string `json:"label" jsonschema:"required,The name of the label to list the values of, e.g. 'namespace'"`
+	Lookback   string `json:"lookback,omitempty" jsonschema:"How far back to look for series of the metric, e.g. '12h' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"12h;2d"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"Maximum number of values listed (default: 100, max: 1000)" default:"100"`
+}
+
+const (
+	defaultLabelValueRows = 100
+	maxLabelValueRows     = 1000
+)
+
+// MetricLabelValuesResult is the structured content returned by getMetricLabelValues. Values
+// are sorted and not capped by limit.
+type MetricLabelValuesResult struct {
+	Metric   string   `json:"metric"`
+	Label    string   `json:"label"`
+	Lookback string   `json:"lookback"`
+	Values   []string `json:"values"`
+}
+
+// GetMetricLabelValues lists the distinct values of a label of a metric, to build PromQL selectors
+func (t tool) GetMetricLabelValues(ctx context.Context, request *mcp.CallToolRequest, params GetMetricLabelValuesParams) (*mcp.CallToolResult, *MetricLabelValuesResult, error) {
+	metric := strings.TrimSpace(params.MetricName)
+	if metric == "" {
+		return nil, nil, fmt.Errorf("metric_name is required")
+	}
+	label := strings.TrimSpace(params.Label)
+	if label == "" {
+		return nil, nil, fmt.Errorf("label is required")
+	}
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit, err := displayLimit(params.Limit, defaultLabelValueRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > maxLabelValueRows {
+		return nil, nil, fmt.Errorf("limit must be at most %d, got %d", maxLabelValueRows, limit)
+	}
+
+	end := t.clock.Now()
+	start := end.Add(-lookback)
+	values, err := t.client.GetMetricLabelValues(ctx, metric, label, start, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get values of label '%s' of metric '%s': %w", label, metric, err)
+	}
+
+	window := formatLookback(lookback)
+	sort.Strings(values)
+	structured := &MetricLabelValuesResult{Metric: metric, Label: label, Lookback: window, Values: values}
+	if structured.Values == nil {
+		structured.Values = []string{}
+	}
+	if len(values) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: t.noLabelValues(ctx, metric, label, window, start, end),
+				},
+			},
+		}, structured, nil
+	}
+
+	shown := values[:min(limit, len(values))]
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Values of label '%s' of metric '%s' in the last %s, %d distinct (showing %d):\n\n", label, metric, window, len(values), len(shown)))
+	sb.WriteString("| Value |\n")
+	sb.WriteString("|---|\n")
+	for _, v := range shown {
+		sb.WriteString(fmt.Sprintf("| %s |\n", v))
+	}
+	if more := len(values) - len(shown); more > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d more values, raise limit (max %d) or use a regex matcher such as %s=~\"prefix.*\" to see them.\n", more, maxLabelValueRows, label))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// noLabelValues explains an empty result: the metric has no series in the window, or its
+// series lack the label, then naming the labels they have. Without them it stays generic.
+func (t tool) noLabelValues(ctx context.Context, metric, label, window string, start, end time.Time) string {
+	generic := fmt.Sprintf("No values found for label '%s' of metric '%s' in the last %s.", label, metric, window)
+	names, err := t.client.GetMetricLabels(ctx, metric, start, end)
+	if err != nil {
+		return generic
+	}
+	names = slices.DeleteFunc(names, func(name string) bool { return name == "__name__" })
+	switch {
+	case len(names) == 0:
+		return fmt.Sprintf("No series found for metric '%s' in the last %s, check the name with listMetrics or widen the lookback.", metric, window)
+	case !slices.Contains(names, label):
+		sort.Strings(names)
+		return fmt.Sprintf("Metric '%s' has no label '%s' in the last %s. Its labels are: %s.", metric, label, window, strings.Join(names, ", "))
+	}
+	return generic
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricLabelValues(t *testing.T) {
+	ctx := context.Background()
+	anyTime := mock.AnythingOfType("time.Time")
+
+	t.Run("sorted values", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabelValues", ctx, "kube_pod_info", "namespace", anyTime, anyTime).
+			Return([]string{"shop", "default", "kube-system"}, nil).Once()
+
+		result, _, err := tools.GetMetricLabelValues(ctx, nil, GetMetricLabelValuesParams{MetricName: "kube_pod_info", Label: "namespace"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Values of label 'namespace' of metric 'kube_pod_info' in the last 1h, 3 distinct (showing 3):\n\n"+
+			"| Value |\n|---|\n| default |\n| kube-system |\n| shop |\n", result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("capped at the limit", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		twoDays := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) >= 48*time.Hour })
+		mockClient.On("GetMetricLabelValues", ctx, "up", "pod", twoDays, anyTime).
+			Return([]string{"c", "a", "d", "b"}, nil).Once()
+
+		result, _, err := tools.GetMetricLabelValues(ctx, nil, GetMetricLabelValuesParams{MetricName: "up", Label: "pod", Lookback: "2d", Limit: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Values of label 'pod' of metric 'up' in the last 2d, 4 distinct (showing 2):\n\n"+
+			"| Value |\n|---|\n| a |\n| b |\n\n"+
+			"2 more values, raise limit (max 1000) or use a regex matcher such as pod=~\"prefix.*\" to see them.\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown label", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabelValues", ctx, "up", "nope", anyTime, anyTime).Return([]string{}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "up", anyTime, anyTime).Return([]string{"pod", "__name__", "job"}, nil).Once()
+
+		result, _, err := tools.GetMetricLabelValues(ctx, nil, GetMetricLabelValuesParams{MetricName: "up", Label: "nope"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Metric 'up' has no label 'nope' in the last 1h. Its labels are: job, pod.",
+			result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabelValues", ctx, "nope", "job", anyTime, anyTime).Return([]string{}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "nope", anyTime, anyTime).Return([]string{}, nil).Once()
+
+		result, _, err := tools.GetMetricLabelValues(ctx, nil, GetMetricLabelValuesParams{MetricName: "nope", Label: "job"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "No series found for metric 'nope' in the last 1h")
+	})
+
+	t.Run("lookup errors", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabelValues", ctx, "up", "job", anyTime, anyTime).Return(nil, errors.New("timeout")).Once()
+
+		_, _, err := tools.GetMetricLabelValues(ctx, nil, GetMetricLabelValuesParams{MetricName: "up", Label: "job"})
+
+		assert.EqualError(t, err, "failed to get values of label 'job' of metric 'up': timeout")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params GetMetricLabelValuesParams
+			err    string
+		}{
+			{GetMetricLabelValuesParams{Label: "job"}, "metric_name is required"},
+			{GetMetricLabelValuesParams{MetricName: "up"}, "label is required"},
+			{GetMetricLabelValuesParams{MetricName: "up", Label: "job", Lookback: "0s"}, "invalid lookback '0s': must be positive, e.g. '1h'"},
+			{GetMetricLabelValuesParams{MetricName: "up", Label: "job", Limit: 2000}, "limit must be at most 1000, got 2000"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.GetMetricLabelValues(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
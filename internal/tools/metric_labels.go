@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMetricLabelsParams struct {
+	MetricName    string `json:"metric_name" jsonschema:"required,The exact name of the metric"`
+	Lookback      string `json:"lookback,omitempty" jsonschema:"How far back to look for series of the metric, e.g. '12h' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"12h;2d"`
+	IncludeValues bool   `json:"include_values,omitempty" jsonschema:"Also return example values of every label, looked up from the series of the metric"`
+	MaxValues     int    `json:"max_values,omitempty" jsonschema:"Maximum number of example values per label with include_values (default: 5, max: 50)" default:"5"`
+}
+
+const (
+	defaultLabelValues = 5
+	maxLabelValues     = 50
+)
+
+// MetricLabelsResult is the structured content returned by getMetricLabels. Series and the
+// values of the labels are only set with include_values.
+type MetricLabelsResult struct {
+	Metric   string          `json:"metric"`
+	Lookback string          `json:"lookback"`
+	Series   int             `json:"series,omitempty"`
+	Labels   []LabelExamples `json:"labels"`
+}
+
+// LabelExamples is a label of a metric with example values and its number of distinct values
+type LabelExamples struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values,omitempty"`
+	Distinct int      `json:"distinct,omitempty"`
+}
+
+// labelValues holds the example values of a label and the number of distinct values seen
+type labelValues struct {
+	name     string
+	values   []string
+	distinct int
+}
+
+// GetMetricLabels lists the label names of a metric, optionally with example values
+func (t tool) GetMetricLabels(ctx context.Context, request *mcp.CallToolRequest, params GetMetricLabelsParams) (*mcp.CallToolResult, *MetricLabelsResult, error) {
+	metric := strings.TrimSpace(params.MetricName)
+	if metric == "" {
+		return nil, nil, fmt.Errorf("metric_name is required")
+	}
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxValues, err := displayLimit(params.MaxValues, defaultLabelValues)
+	if err != nil {
+		return nil, nil, fmt.Errorf("max_values must not be negative, got %d", params.MaxValues)
+	}
+	if maxValues > maxLabelValues {
+		return nil, nil, fmt.Errorf("max_values must be at most %d, got %d", maxLabelValues, maxValues)
+	}
+
+	end := t.clock.Now()
+	start := end.Add(-lookback)
+
+	var (
+		labels []labelValues
+		series int
+	)
+	if params.IncludeValues {
+		sets, err := t.client.GetMetricSeries(ctx, metric, start, end, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get series of metric '%s': %w", metric, err)
+		}
+		series = len(sets)
+		labels = exampleLabelValues(sets, maxValues)
+	} else {
+		names, err := t.client.GetMetricLabels(ctx, metric, start, end)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get labels of metric '%s': %w", metric, err)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name != "__name__" {
+				labels = append(labels, labelValues{name: name})
+			}
+		}
+	}
+
+	window := formatLookback(lookback)
+	structured := &MetricLabelsResult{Metric: metric, Lookback: window, Series: series, Labels: make([]LabelExamples, 0, len(labels))}
+	for _, l := range labels {
+		structured.Labels = append(structured.Labels, LabelExamples{Name: l.name, Values: l.values, Distinct: l.distinct})
+	}
+	if len(labels) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No labels found for metric '%s' in the last %s, check the name with listMetrics or widen the lookback.", metric, window),
+				},
+			},
+		}, structured, nil
+	}
+
+	var sb strings.Builder
+	if !params.IncludeValues {
+		sb.WriteString(fmt.Sprintf("Labels of metric '%s' in the last %s, pass include_values for example values:\n\n", metric, window))
+		sb.WriteString("| Label |\n")
+		sb.WriteString("|---|\n")
+		for _, l := range labels {
+			sb.WriteString(fmt.Sprintf("| %s |\n", l.name))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("Labels of metric '%s' in the last %s, from %d series:\n\n", metric, window, series))
+		sb.WriteString("| Label | Distinct Values | Example Values |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, l := range labels {
+			examples := strings.Join(l.values, ", ")
+			if more := l.distinct - len(l.values); more > 0 {
+				examples += fmt.Sprintf(" (+%d more)", more)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", l.name, l.distinct, examples))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// exampleLabelValues collects the distinct values of every label across the series, keeping
+// the first maxValues in sorted order. Labels are sorted by name, __name__ is left out.
+func exampleLabelValues(series []map[string]string, maxValues int) []labelValues {
+	distinct := map[string]map[string]bool{}
+	for _, s := range series {
+		for name, value := range s {
+			if name == "__name__" {
+				continue
+			}
+			if distinct[name] == nil {
+				distinct[name] = map[string]bool{}
+			}
+			distinct[name][value] = true
+		}
+	}
+
+	labels := make([]labelValues, 0, len(distinct))
+	for name, values := range distinct {
+		sorted := make([]string, 0, len(values))
+		for v := range values {
+			sorted = append(sorted, v)
+		}
+		sort.Strings(sorted)
+		labels = append(labels, labelValues{name: name, values: sorted[:min(maxValues, len(sorted))], distinct: len(sorted)})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+	return labels
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExampleLabelValues(t *testing.T) {
+	labels := exampleLabelValues([]map[string]string{
+		{"__name__": "up", "job": "node", "pod": "c"},
+		{"__name__": "up", "job": "node", "pod": "a"},
+		{"__name__": "up", "job": "api", "pod": "b"},
+	}, 2)
+
+	assert.Equal(t, []labelValues{
+		{name: "job", values: []string{"api", "node"}, distinct: 2},
+		{name: "pod", values: []string{"a", "b"}, distinct: 3},
+	}, labels)
+}
+
+func TestGetMetricLabels(t *testing.T) {
+	ctx := context.Background()
+	anyTime := mock.AnythingOfType("time.Time")
+
+	t.Run("label names", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabels", ctx, "up", anyTime, anyTime).Return([]string{"pod", "__name__", "job"}, nil).Once()
+
+		result, _, err := tools.GetMetricLabels(ctx, nil, GetMetricLabelsParams{MetricName: "up"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Labels of metric 'up' in the last 1h, pass include_values for example values:\n\n| Label |\n|---|\n| job |\n| pod |\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("example values from the series", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		twoDays := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) >= 48*time.Hour })
+		mockClient.On("GetMetricSeries", ctx, "up", twoDays, anyTime, 0).Return([]map[string]string{
+			{"__name__": "up", "job": "node", "pod": "c"},
+			{"__name__": "up", "job": "node", "pod": "a"},
+			{"__name__": "up", "job": "api", "pod": "b"},
+		}, nil).Once()
+
+		result, _, err := tools.GetMetricLabels(ctx, nil, GetMetricLabelsParams{MetricName: "up", Lookback: "2d", IncludeValues: true, MaxValues: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Labels of metric 'up' in the last 2d, from 3 series:\n\n"+
+			"| Label | Distinct Values | Example Values |\n|---|---|---|\n"+
+			"| job | 2 | api, node |\n"+
+			"| pod | 3 | a, b (+1 more) |\n", result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricLabels", ctx, "nope", anyTime, anyTime).Return([]string{}, nil).Once()
+
+		result, _, err := tools.GetMetricLabels(ctx, nil, GetMetricLabelsParams{MetricName: "nope"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "No labels found for metric 'nope' in the last 1h")
+	})
+
+	t.Run("lookup errors", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMetricSeries", ctx, "up", anyTime, anyTime, 0).Return(nil, errors.New("timeout")).Once()
+
+		_, _, err := tools.GetMetricLabels(ctx, nil, GetMetricLabelsParams{MetricName: "up", IncludeValues: true})
+
+		assert.EqualError(t, err, "failed to get series of metric 'up': timeout")
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params GetMetricLabelsParams
+			err    string
+		}{
+			{GetMetricLabelsParams{}, "metric_name is required"},
+			{GetMetricLabelsParams{MetricName: "up", Lookback: "0s"}, "invalid lookback '0s': must be positive, e.g. '1h'"},
+			{GetMetricLabelsParams{MetricName: "up", MaxValues: 100}, "max_values must be at most 50, got 100"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.GetMetricLabels(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMetricValueParams struct {
+	Query string `json:"query" jsonschema:"The PromQL query to evaluate, e.g. a gauge like 'kube_deployment_status_replicas_available'" examples:"kube_deployment_status_replicas_available;sum(up)"`
+	Time  string `json:"time,omitempty" jsonschema:"Time to evaluate the query at: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d') (default: now)" default:"now" examples:"now;1h;now-1d/d"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of series listed (default: 20)"`
+}
+
+// MetricValueResult is the structured content returned by getMetricValue. Series hold a
+// single timestamp and value each, Text is only set for a string result.
+type MetricValueResult struct {
+	Query      string          `json:"query"`
+	Time       time.Time       `json:"time"`
+	ResultType string          `json:"result_type"`
+	Series     []MetricsSeries `json:"series"`
+	Text       string          `json:"text,omitempty"`
+}
+
+// GetMetricValue evaluates an instant query and renders the single value of every series,
+// for questions about the current value of a gauge
+func (t tool) GetMetricValue(ctx context.Context, request *mcp.CallToolRequest, params GetMetricValueParams) (*mcp.CallToolResult, *MetricValueResult, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, nil, fmt.Errorf("query is required")
+	}
+	at := t.clock.Now()
+	if params.Time != "" {
+		var err error
+		if at, err = parseTime(params.Time, at); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse time: %w", err)
+		}
+	}
+	limit, err := displayLimit(params.Limit, t.limits.MetricMaxSeries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// An empty timeout lets the client use its configured request timeout
+	res, err := t.client.QueryMetric(ctx, query, at, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query metric value: %w", err)
+	}
+
+	if res.Data.ResultType == "matrix" {
+		return nil, nil, fmt.Errorf("query '%s' returns a range vector, use getMetrics for series over time or reduce it with a function like last_over_time", query)
+	}
+
+	series := newSeries(res.Data.Result)
+	structured := &MetricValueResult{
+		Query:      query,
+		Time:       at.UTC(),
+		ResultType: res.Data.ResultType,
+		Series:     structuredMetrics(series, query, "").Series,
+	}
+	if res.Data.ResultType == "string" && len(series) > 0 {
+		structured.Text = series[0].Text
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatInstantValues(series, res.Data.ResultType, query, at, limit),
+			},
+		},
+	}, structured, nil
+}
+
+// formatInstantValues renders the single value of a scalar, or a table of the label sets
+// of a vector with their value
+func formatInstantValues(series []Series, resultType, query string, at time.Time, limit int) string {
+	var sb strings.Builder
+	evaluated := at.UTC().Format(time.RFC3339)
+	if resultType == "scalar" || resultType == "string" {
+		value := "-"
+		switch {
+		case len(series) > 0 && resultType == "string":
+			value = strconv.Quote(series[0].Text)
+		case len(series) > 0 && len(series[0].Points) > 0:
+			value = formatValue(series[0].Points[0].Value)
+		}
+		sb.WriteString(fmt.Sprintf("Value of `%s` at %s:\n\n", query, evaluated))
+		sb.WriteString("| Value |\n")
+		sb.WriteString("|---|\n")
+		sb.WriteString(fmt.Sprintf("| %s |\n", value))
+		return sb.String()
+	}
+	if len(series) == 0 {
+		return fmt.Sprintf("No series returned by `%s` at %s.", query, evaluated)
+	}
+
+	shown := series[:min(limit, len(series))]
+	nameColumn, nameHeader := metricNameColumn(shown)
+	keys := metricLabelKeys(shown)
+
+	sb.WriteString(fmt.Sprintf("Values of `%s` at %s (%d series):\n\n", query, evaluated, len(series)))
+	sb.WriteString(nameHeader)
+	header, divider := "|", "|"
+	if nameColumn {
+		header += " Metric |"
+		divider += "---|"
+	}
+	for _, k := range keys {
+		header += fmt.Sprintf(" %s |", k)
+		divider += "---|"
+	}
+	sb.WriteString(header + " Value |\n")
+	sb.WriteString(divider + "---|\n")
+	for _, s := range shown {
+		row := "|"
+		if nameColumn {
+			row += fmt.Sprintf(" %s |", valueOrDash(s.Labels["__name__"]))
+		}
+		for _, k := range keys {
+			row += fmt.Sprintf(" %s |", valueOrDash(s.Labels[k]))
+		}
+		value := "-"
+		if len(s.Points) > 0 {
+			value = formatValue(s.Points[len(s.Points)-1].Value)
+		}
+		sb.WriteString(fmt.Sprintf("%s %s |\n", row, value))
+	}
+	if len(shown) < len(series) {
+		sb.WriteString(fmt.Sprintf("\n%d more series not shown, raise limit or aggregate the query (e.g. topk) to see them.\n", len(series)-len(shown)))
+	}
+	return sb.String()
+}
@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricValue(t *testing.T) {
+	ctx := context.Background()
+	instant := func(resultType string, results ...suseobservability.MetricResult) *suseobservability.MetricQueryResponse {
+		return &suseobservability.MetricQueryResponse{Status: "success", Data: suseobservability.MetricData{ResultType: resultType, Result: results}}
+	}
+	sample := func(labels map[string]string, value float64) suseobservability.MetricResult {
+		return suseobservability.MetricResult{Labels: labels, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: value}}}
+	}
+
+	t.Run("vector of label sets and their value", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		query := "kube_deployment_status_replicas_available"
+		mockClient.On("QueryMetric", ctx, query, mock.MatchedBy(func(at time.Time) bool { return time.Since(at) < time.Minute }), "").
+			Return(instant("vector",
+				sample(map[string]string{"__name__": query, "deployment": "checkout", "namespace": "shop"}, 3),
+				sample(map[string]string{"__name__": query, "deployment": "cart", "namespace": "shop"}, 0.25),
+			), nil).Once()
+
+		result, structured, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: " " + query + " "})
+
+		require.NoError(t, err)
+		assert.Equal(t, query, structured.Query)
+		assert.Equal(t, "vector", structured.ResultType)
+		assert.Equal(t, []MetricsSeries{
+			{Labels: map[string]string{"__name__": query, "deployment": "checkout", "namespace": "shop"}, Timestamps: []int64{1700000000}, Values: []float64{3}},
+			{Labels: map[string]string{"__name__": query, "deployment": "cart", "namespace": "shop"}, Timestamps: []int64{1700000000}, Values: []float64{0.25}},
+		}, structured.Series)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Values of `kube_deployment_status_replicas_available` at ")
+		assert.Contains(t, output, "(2 series):\n\nMetric: `kube_deployment_status_replicas_available`\n\n"+
+			"| deployment | namespace | Value |\n"+
+			"|---|---|---|\n"+
+			"| checkout | shop | 3 |\n"+
+			"| cart | shop | 0.25 |\n")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("QueryMetric", ctx, "scalar(sum(up))", mock.MatchedBy(func(at time.Time) bool {
+			return time.Since(at) > 59*time.Minute && time.Since(at) < 61*time.Minute
+		}), "").Return(instant("scalar", sample(map[string]string{}, 42)), nil).Once()
+
+		result, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "scalar(sum(up))", Time: "1h"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Value of `scalar(sum(up))` at ")
+		assert.Contains(t, output, ":\n\n| Value |\n|---|\n| 42 |\n")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("string", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		text := suseobservability.MetricResult{Labels: map[string]string{}, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: math.NaN()}}, String: "ready"}
+		mockClient.On("QueryMetric", ctx, `"ready"`, mock.Anything, "").Return(instant("string", text), nil).Once()
+
+		result, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: `"ready"`})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, ":\n\n| Value |\n|---|\n| \"ready\" |\n")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("series of different metrics above the limit", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("QueryMetric", ctx, "up or node_load1", mock.Anything, "").
+			Return(instant("vector",
+				sample(map[string]string{"__name__": "up", "job": "node"}, 1),
+				sample(map[string]string{"__name__": "node_load1", "instance": "n1"}, 1.5),
+				sample(map[string]string{"__name__": "node_load1", "instance": "n2"}, 0.5),
+			), nil).Once()
+
+		result, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "up or node_load1", Limit: 2})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| Metric | instance | job | Value |\n|---|---|---|---|\n| up | - | node | 1 |\n| node_load1 | n1 | - | 1.5 |\n")
+		assert.Contains(t, output, "1 more series not shown, raise limit or aggregate the query (e.g. topk) to see them.")
+	})
+
+	t.Run("no series", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("QueryMetric", ctx, "up", mock.Anything, "").Return(instant("vector"), nil).Once()
+
+		result, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "up"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "No series returned by `up` at ")
+	})
+
+	t.Run("range vector is refused", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("QueryMetric", ctx, "up[5m]", mock.Anything, "").Return(instant("matrix"), nil).Once()
+
+		_, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "up[5m]"})
+
+		assert.EqualError(t, err, "query 'up[5m]' returns a range vector, use getMetrics for series over time or reduce it with a function like last_over_time")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("QueryMetric", ctx, "up", mock.Anything, "").Return(nil, errors.New("boom")).Once()
+
+		_, _, err := tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "up"})
+		assert.EqualError(t, err, "failed to query metric value: boom")
+
+		_, _, err = tools.GetMetricValue(ctx, nil, GetMetricValueParams{})
+		assert.EqualError(t, err, "query is required")
+
+		_, _, err = tools.GetMetricValue(ctx, nil, GetMetricValueParams{Query: "up", Time: "yesterday"})
+		assert.EqualError(t, err, "failed to parse time: invalid time format: yesterday (expected 'now', a duration like '1h' or 'now-1h', optionally followed by '/d' for the start of that day)")
+	})
+}
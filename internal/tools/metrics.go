@@ -2,9 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"suse-observability-mcp/client/suseobservability"
@@ -13,155 +19,863 @@ import (
 )
 
 type QueryMetricParams struct {
-	Query string `json:"query" jsonschema:"The PromQL query to execute"`
-	Start string `json:"start" jsonschema:"Start time: 'now' or duration (e.g. '1h')"`
-	End   string `json:"end" jsonschema:"End time: 'now' or duration (e.g. '1h')"`
-	Step  string `json:"step" jsonschema:"Query resolution step width in duration format or float number of seconds"`
+	Query              string            `json:"query,omitempty" jsonschema:"The PromQL query to execute" examples:"sum by (namespace) (rate(container_cpu_usage_seconds_total[5m]));up{job=\"api\"}"`
+	Queries            []MetricQuery     `json:"queries,omitempty" jsonschema:"Several PromQL queries to execute concurrently instead of query (at most 10)"`
+	Metric             string            `json:"metric,omitempty" jsonschema:"Instead of query, the name of the metric to select, the selector is built from metric and labels" examples:"container_cpu_usage_seconds_total"`
+	Labels             map[string]string `json:"labels,omitempty" jsonschema:"Label values the series of metric must have, keyed by label name. A value ending in * matches the values starting with the rest of it, e.g. {\"namespace\": \"shop\", \"pod\": \"checkout-*\"}"`
+	Start              string            `json:"start" jsonschema:"Start time: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d')" examples:"1h;24h;now-6h;now-1d/d"`
+	End                string            `json:"end,omitempty" jsonschema:"End time: 'now', duration back from now or 'now-1h' like start, must be after start (default: now)" examples:"now;30m;now/d"`
+	Step               string            `json:"step,omitempty" jsonschema:"Query resolution step width in duration format or float number of seconds" examples:"1m;5m;30"`
+	Format             string            `json:"format,omitempty" jsonschema:"Output format: 'markdown' (default), 'json' or 'csv'" enum:"markdown,json,csv" default:"markdown"`
+	Mode               string            `json:"mode,omitempty" jsonschema:"'raw' (default) for every point or 'summary' for min, max, mean, p50, p95 and last value per series" enum:"raw,summary" default:"raw"`
+	MaxSeries          int               `json:"max_series,omitempty" jsonschema:"Maximum number of series rendered in the markdown table (default: 20)"`
+	MaxRows            int               `json:"max_rows,omitempty" jsonschema:"Maximum number of rows rendered in the markdown table (default: 500)"`
+	Layout             string            `json:"layout,omitempty" jsonschema:"Markdown table layout in raw mode: 'flat' for one table of all series, 'grouped' for a table per series or 'pivot' for a value column per series aligned on timestamps (default: grouped above 3 series)" enum:"flat,grouped,pivot"`
+	Transpose          *bool             `json:"transpose,omitempty" jsonschema:"Render the markdown table in raw mode with a row per series, its labels and a value column per timestamp (at most 10), instead of a row per timestamp. Applied when the result has a single timestamp unless a layout is requested, set false to keep the row per timestamp"`
+	RankBy             string            `json:"rank_by,omitempty" jsonschema:"Order series by their 'max', 'last' or 'avg' value, highest first, so max_series keeps the top series (default: the order of the query result)" enum:"max,last,avg"`
+	Transform          string            `json:"transform,omitempty" jsonschema:"Wrap the metric selector in query with 'rate', 'increase' or 'irate' over a window of the step (at least 2m), for counters such as *_total. Only for a plain selector without functions" examples:"rate;increase;irate"`
+	Threshold          *float64          `json:"threshold,omitempty" jsonschema:"Value to flag breaches of, e.g. an SLO target. Breaching rows are marked in the markdown table, a footer counts the breaches per series with the first and last one, and summary mode reports the share of the window spent breaching" examples:"0.9;500"`
+	ThresholdDirection string            `json:"threshold_direction,omitempty" jsonschema:"Whether values 'above' (default) or 'below' threshold breach it" enum:"above,below" default:"above"`
+	DetectAnomalies    bool              `json:"detect_anomalies,omitempty" jsonschema:"Flag the points deviating from the 10 points before them, by their robust z-score (median and median absolute deviation). A footer counts the anomalous points per series with the first and last one (default: false)" default:"false"`
+	AnomalyZScore      float64           `json:"anomaly_z_score,omitempty" jsonschema:"Robust z-score above which detect_anomalies flags a point, lower flags more (default: 3.5)" examples:"3.5;5"`
+	LatestOnly         bool              `json:"latest_only,omitempty" jsonschema:"Render one row per series with only its latest sample and the age of it, for a quick look at current values. Series whose latest sample is older than stale_after are marked stale (default: false)" default:"false"`
+	StaleAfter         string            `json:"stale_after,omitempty" jsonschema:"Age above which the latest sample of a series is marked stale with latest_only (default: 5m)" default:"5m" examples:"5m;1h"`
+	Humanize           bool              `json:"humanize,omitempty" jsonschema:"Render byte and second values in the markdown table with units, e.g. '700 MiB' or '235 ms', detected from the metric metadata, name suffixes such as _bytes and _seconds and the unit label. JSON, CSV and the structured content keep the raw values (default: false)" default:"false"`
 }
 
 type ListMetricsParams struct {
-	ComponentID int64 `json:"component_id" jsonschema:"required,The ID of the component to list bound metrics for"`
+	ComponentID   int64  `json:"component_id,omitempty" jsonschema:"The ID of the component to list bound metrics for"`
+	Search        string `json:"search,omitempty" jsonschema:"Text contained in the names of the metrics to list, instead of listing the metrics bound to a component" examples:"container_cpu;http_requests"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"Maximum number of metrics listed (default: 50, max: 500)"`
+	Offset        int    `json:"offset,omitempty" jsonschema:"Number of metrics to skip, to list the next page (default: 0)"`
+	Lookback      string `json:"lookback,omitempty" jsonschema:"How far back to look for metrics with data, e.g. '12h' or '2d' (default: 1h, max: 7d)" default:"1h" examples:"12h;2d"`
+	MatchMode     string `json:"match_mode,omitempty" jsonschema:"How search matches metric names: 'substring' (case-insensitive, default), 'regex' or 'exact'" enum:"substring,regex,exact"`
+	CaseSensitive bool   `json:"case_sensitive,omitempty" jsonschema:"Match the regex case-sensitively, only with match_mode 'regex'"`
+}
+
+// MetricListResult is the structured content returned by listMetrics
+type MetricListResult struct {
+	ComponentID int64           `json:"component_id,omitempty"`
+	Search      string          `json:"search,omitempty"`
+	MatchMode   string          `json:"match_mode,omitempty"`
+	Lookback    string          `json:"lookback"`
+	Total       int             `json:"total"`
+	Offset      int             `json:"offset,omitempty"`
+	NextOffset  int             `json:"next_offset,omitempty"` // 0 on the last page
+	Metrics     []MetricSummary `json:"metrics"`
+	// CommonLabels are the label names every listed metric of a search has, empty when fewer
+	// than two metrics have known labels
+	CommonLabels []string `json:"common_labels,omitempty"`
+	// StaleMetrics are the matches of a search without samples in the lookback but with
+	// samples in the fallback window, only looked up when nothing matched
+	StaleMetrics []StaleMetric `json:"stale_metrics,omitempty"`
+}
+
+// MetricSummary is a listed metric. Bound metrics carry a unit and query expressions,
+// metrics found by name carry their type, unit and the label names of their series.
+// Guessed is set when the type or unit was derived from the metric name.
+type MetricSummary struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Guessed     bool     `json:"guessed,omitempty"`
+	Expressions []string `json:"expressions,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// MetricQuery is a query of a getMetrics batch
+type MetricQuery struct {
+	Query string `json:"query" jsonschema:"The PromQL query to execute"`
+	Alias string `json:"alias,omitempty" jsonschema:"Name of the query in the output (default: the query itself)" examples:"cpu;memory"`
+}
+
+// MetricsResult is the structured content returned by getMetrics
+type MetricsResult struct {
+	Query  string          `json:"query,omitempty"`
+	Step   string          `json:"step"`
+	Series []MetricsSeries `json:"series,omitempty"`
+	// Stats is only set in summary mode
+	Stats []SeriesStats `json:"stats,omitempty"`
+	// Breaches is only set with a threshold
+	Breaches []ThresholdBreach `json:"breaches,omitempty"`
+	// Latest is only set with latest_only
+	Latest []LatestSample `json:"latest,omitempty"`
+	// Anomalies is only set with detect_anomalies
+	Anomalies []SeriesAnomalies `json:"anomalies,omitempty"`
+	// Results holds one entry per query of a batch
+	Results []MetricsQueryResult `json:"results,omitempty"`
+	// Chunks is the number of requests each query was split into, unset when not split
+	Chunks int `json:"chunks,omitempty"`
+}
+
+// MetricsQueryResult is the result of a single query of a batch
+type MetricsQueryResult struct {
+	Alias     string            `json:"alias"`
+	Query     string            `json:"query"`
+	Error     string            `json:"error,omitempty"`
+	Series    []MetricsSeries   `json:"series,omitempty"`
+	Stats     []SeriesStats     `json:"stats,omitempty"`
+	Breaches  []ThresholdBreach `json:"breaches,omitempty"`
+	Latest    []LatestSample    `json:"latest,omitempty"`
+	Anomalies []SeriesAnomalies `json:"anomalies,omitempty"`
+}
+
+// MetricsSeries holds the points of a series as parallel timestamp and value arrays. NaN and
+// ±Inf points are left out, JSON cannot represent them.
+type MetricsSeries struct {
+	Labels     map[string]string `json:"labels"`
+	Timestamps []int64           `json:"timestamps"`
+	Values     []float64         `json:"values"`
 }
 
-// ListMetrics lists bound metrics for a specific component
-func (t tool) ListMetrics(ctx context.Context, request *mcp.CallToolRequest, params ListMetricsParams) (*mcp.CallToolResult, any, error) {
-	// Default time range: last 1 hour
-	end := time.Now()
-	start := end.Add(-1 * time.Hour)
+// maxListMetricsLimit is the highest limit accepted by listMetrics
+const maxListMetricsLimit = 500
+
+// listMetricsWindow is the default time range listMetrics looks for metrics in
+const listMetricsWindow = time.Hour
+
+// maxListMetricsLookback is the longest time range listMetrics looks for metrics in
+const maxListMetricsLookback = 7 * 24 * time.Hour
+
+// metricLabelWorkers is the number of label lookups listMetrics runs concurrently
+const metricLabelWorkers = 8
+
+// ListMetrics lists bound metrics for a specific component, or metrics by name
+func (t tool) ListMetrics(ctx context.Context, request *mcp.CallToolRequest, params ListMetricsParams) (*mcp.CallToolResult, *MetricListResult, error) {
+	if (params.ComponentID == 0) == (params.Search == "") {
+		return nil, nil, fmt.Errorf("exactly one of component_id or search must be provided")
+	}
+	limit, err := displayLimit(params.Limit, t.limits.MetricListRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit > maxListMetricsLimit {
+		limit = maxListMetricsLimit
+	}
+	if params.Offset < 0 {
+		return nil, nil, fmt.Errorf("offset must not be negative, got %d", params.Offset)
+	}
+	params.Limit = limit
+	lookback, err := parseLookback(params.Lookback)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	end := t.clock.Now()
+	start := end.Add(-lookback)
+
+	if params.Search != "" {
+		match, err := newMetricMatcher(params)
+		if err != nil {
+			return nil, nil, err
+		}
+		return t.searchMetrics(ctx, params, match, start, end)
+	}
+	if params.MatchMode != "" || params.CaseSensitive {
+		return nil, nil, fmt.Errorf("match_mode and case_sensitive only apply to search")
+	}
 
 	boundMetrics, err := t.client.GetBoundMetricsWithData(ctx, params.ComponentID, start, end)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to list bound metrics: %w", err)
 	}
 
+	// sorted so that pages do not shuffle between calls
+	sort.SliceStable(boundMetrics.BoundMetrics, func(i, j int) bool {
+		return boundMetrics.BoundMetrics[i].Name < boundMetrics.BoundMetrics[j].Name
+	})
+	from, to, err := metricsPage(params, len(boundMetrics.BoundMetrics))
+	if err != nil {
+		return nil, nil, err
+	}
+	metrics := boundMetrics.BoundMetrics[from:to]
+	structured := &MetricListResult{
+		ComponentID: params.ComponentID,
+		Lookback:    formatLookback(lookback),
+		Total:       len(boundMetrics.BoundMetrics),
+		Offset:      params.Offset,
+		NextOffset:  nextMetricsOffset(to, len(boundMetrics.BoundMetrics)),
+		Metrics:     summarizeBoundMetrics(metrics),
+	}
+
 	if len(boundMetrics.BoundMetrics) == 0 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{
-					Text: fmt.Sprintf("No bound metrics found for component ID %d.", params.ComponentID),
+					Text: fmt.Sprintf("No bound metrics found for component ID %d in the last %s.", params.ComponentID, formatLookback(lookback)),
 				},
 			},
-		}, nil, nil
+		}, structured, nil
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Found %d bound metrics for component ID %d:\n\n", len(boundMetrics.BoundMetrics), params.ComponentID))
+	sb.WriteString(fmt.Sprintf("Found %d bound metrics for component ID %d in the last %s:\n\n", len(boundMetrics.BoundMetrics), params.ComponentID, formatLookback(lookback)))
 	sb.WriteString("| Metric Name | Unit | Query Expression |\n")
 	sb.WriteString("|---|---|---|\n")
 
-	for _, bm := range boundMetrics.BoundMetrics {
+	for _, bm := range metrics {
 		for _, bq := range bm.BoundQueries {
 			sb.WriteString(fmt.Sprintf("| %s | %s | `%s` |\n", bm.Name, bm.Unit, bq.Expression))
 		}
 	}
 
+	sb.WriteString(metricsPageFooter(params, from, to, len(boundMetrics.BoundMetrics)))
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
 				Text: sb.String(),
 			},
 		},
-	}, nil, nil
+	}, structured, nil
 }
 
-// QueryMetric queries a metric over a range of time
-func (t tool) QueryMetric(ctx context.Context, request *mcp.CallToolRequest, params QueryMetricParams) (*mcp.CallToolResult, any, error) {
-	start, err := parseTime(params.Start)
+// searchMetrics lists the metrics whose name matches search, with the label names of their series
+func (t tool) searchMetrics(ctx context.Context, params ListMetricsParams, match metricMatcher, start, end time.Time) (*mcp.CallToolResult, *MetricListResult, error) {
+	search := params.Search
+	lookback := formatLookback(end.Sub(start))
+	names, err := t.client.ListMetrics(ctx, start, end)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse start time: %w", err)
+		return nil, nil, fmt.Errorf("failed to list metrics: %w", err)
 	}
 
-	end, err := parseTime(params.End)
+	var matching []string
+	for _, name := range names {
+		if match.matches(name) {
+			matching = append(matching, name)
+		}
+	}
+	sort.Strings(matching)
+
+	from, to, err := metricsPage(params, len(matching))
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse end time: %w", err)
+		return nil, nil, err
+	}
+	shown := matching[from:to]
+	labels, err := t.fetchMetricLabels(ctx, shown, start, end)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list metric labels: %w", err)
+	}
+	// metadata is optional, without it the type and unit are guessed from the metric names
+	var metadata map[string][]suseobservability.MetricMetadata
+	if len(shown) > 0 {
+		if metadata, err = t.client.GetMetricMetadata(ctx); err != nil {
+			slog.Warn("Metric metadata lookup failed", "error", err)
+		}
+	}
+	resolved := make([]metricMetadata, len(shown))
+	for i, name := range shown {
+		resolved[i] = resolveMetricMetadata(name, metadata)
 	}
 
-	step := params.Step
-	if step == "" {
-		step = "1m"
+	structured := &MetricListResult{
+		Search:     search,
+		MatchMode:  match.mode,
+		Lookback:   lookback,
+		Total:      len(matching),
+		Offset:     params.Offset,
+		NextOffset: nextMetricsOffset(to, len(matching)),
+		Metrics:    make([]MetricSummary, 0, len(shown)),
+	}
+	for i, name := range shown {
+		structured.Metrics = append(structured.Metrics, MetricSummary{
+			Name:    name,
+			Type:    resolved[i].Type,
+			Unit:    resolved[i].Unit,
+			Guessed: resolved[i].TypeGuessed || resolved[i].UnitGuessed,
+			Labels:  labels[i],
+		})
 	}
-	timeout := "30s"
 
-	result, err := t.client.QueryRangeMetric(ctx, params.Query, start, end, step, timeout)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to query range metri c: %w", err)
+	if len(matching) == 0 {
+		text := fmt.Sprintf("No metrics found matching '%s' (%s) in the last %s.", search, match, lookback)
+		// Only the empty result pays for the lookup over the longer fallback window
+		stale, total, err := t.staleMetrics(ctx, match, end.Sub(start), end)
+		if err != nil {
+			slog.Warn("Metric fallback lookup failed", "error", err)
+		}
+		if len(stale) > 0 {
+			structured.StaleMetrics = stale
+			text += formatStaleMetrics(stale, total, lookback, t.limits.MetricFallbackHours, end)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: text,
+				},
+			},
+		}, structured, nil
+	}
+
+	common := commonLabels(labels)
+	structured.CommonLabels = common
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d metrics matching '%s' (%s) in the last %s:\n\n", len(matching), search, match, lookback))
+	labelsHeader := "Labels"
+	if len(common) > 0 {
+		sb.WriteString(fmt.Sprintf("Common labels: %s\n\n", strings.Join(common, ", ")))
+		labelsHeader = "Other Labels"
+	}
+	sb.WriteString(fmt.Sprintf("| Metric Name | Type | Unit | %s |\n", labelsHeader))
+	sb.WriteString("|---|---|---|---|\n")
+	guessed := false
+	for i, name := range shown {
+		m := resolved[i]
+		guessed = guessed || m.TypeGuessed || m.UnitGuessed
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, markGuessed(m.Type, m.TypeGuessed), markGuessed(m.Unit, m.UnitGuessed),
+			otherLabels(labels[i], common)))
+	}
+	if guessed {
+		sb.WriteString("\nValues marked with * are guessed from the metric name, the backend has no metadata for them. Apply rate() or increase() to counters.\n")
 	}
 
-	output := formatMetrics(result.Data.Result, params.Query)
+	sb.WriteString(metricsPageFooter(params, from, to, len(matching)))
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
-				Text: output,
+				Text: sb.String(),
 			},
 		},
-	}, nil, nil
+	}, structured, nil
 }
 
-func formatMetrics(metricsResult []suseobservability.MetricResult, queryName string) string {
-	if len(metricsResult) == 0 {
-		return fmt.Sprintf("No data found for query: %s", queryName)
+// commonLabels returns the sorted label names all metrics with known labels share, so
+// listMetrics states them once instead of on every row. Metrics whose lookup failed are
+// left out, and fewer than two metrics with labels have nothing in common worth stating.
+func commonLabels(labels [][]string) []string {
+	var common []string
+	known := 0
+	for _, l := range labels {
+		if len(l) == 0 {
+			continue
+		}
+		if known == 0 {
+			common = slices.Clone(l)
+		} else {
+			common = slices.DeleteFunc(common, func(name string) bool { return !slices.Contains(l, name) })
+		}
+		known++
 	}
+	if known < 2 || len(common) == 0 {
+		return nil
+	}
+	sort.Strings(common)
+	return common
+}
+
+// otherLabels renders the labels of a metric without the common ones: a dash when the metric
+// has no known labels, "(common only)" when it has no others
+func otherLabels(labels, common []string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	var others []string
+	for _, l := range labels {
+		if !slices.Contains(common, l) {
+			others = append(others, l)
+		}
+	}
+	if len(others) == 0 {
+		return "(common only)"
+	}
+	return strings.Join(others, ", ")
+}
+
+// metric name match modes of listMetrics
+const (
+	matchSubstring = "substring"
+	matchRegex     = "regex"
+	matchExact     = "exact"
+)
+
+// metricMatcher matches metric names against the listMetrics search
+type metricMatcher struct {
+	mode          string
+	caseSensitive bool
+	matches       func(name string) bool
+}
+
+// String describes the applied match mode, e.g. "regex, case-insensitive"
+func (m metricMatcher) String() string {
+	if m.mode == matchExact {
+		return m.mode
+	}
+	if m.caseSensitive {
+		return m.mode + ", case-sensitive"
+	}
+	return m.mode + ", case-insensitive"
+}
 
-	// Collect all unique label keys across all series
-	labelKeys := make(map[string]bool)
-	for _, res := range metricsResult {
-		for k := range res.Labels {
-			if k != "__name__" {
-				labelKeys[k] = true
+// newMetricMatcher returns the matcher for the search of params. Substring matching ignores
+// case, exact matching does not, regex matching ignores case unless CaseSensitive is set.
+func newMetricMatcher(params ListMetricsParams) (metricMatcher, error) {
+	search := params.Search
+	mode := params.MatchMode
+	if mode == "" {
+		mode = matchSubstring
+	}
+	if params.CaseSensitive && mode != matchRegex {
+		return metricMatcher{}, fmt.Errorf("case_sensitive only applies to match_mode 'regex'")
+	}
+
+	switch mode {
+	case matchSubstring:
+		lower := strings.ToLower(search)
+		return metricMatcher{mode: mode, matches: func(name string) bool {
+			return strings.Contains(strings.ToLower(name), lower)
+		}}, nil
+	case matchExact:
+		return metricMatcher{mode: mode, caseSensitive: true, matches: func(name string) bool {
+			return name == search
+		}}, nil
+	case matchRegex:
+		expr := search
+		if !params.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			// report the error for the regex as given, without the flag
+			if _, rerr := regexp.Compile(search); rerr != nil {
+				err = rerr
 			}
+			return metricMatcher{}, fmt.Errorf("invalid search regex: %w", err)
 		}
+		return metricMatcher{mode: mode, caseSensitive: params.CaseSensitive, matches: re.MatchString}, nil
+	default:
+		return metricMatcher{}, fmt.Errorf("invalid match_mode '%s', must be one of substring, regex or exact", params.MatchMode)
 	}
+}
 
-	// Convert to sorted slice for consistent column order
-	var sortedKeys []string
-	for k := range labelKeys {
-		sortedKeys = append(sortedKeys, k)
+// parseLookback parses the listMetrics lookback, a duration like '12h' or a number of days
+// like '2d'. It defaults to listMetricsWindow and must be positive and at most 7 days.
+func parseLookback(s string) (time.Duration, error) {
+	if s == "" {
+		return listMetricsWindow, nil
+	}
+	d, err := parseTimeDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lookback '%s' (expected a duration like '12h' or a number of days like '2d')", s)
 	}
-	sort.Strings(sortedKeys)
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid lookback '%s': must be positive, e.g. '1h'", s)
+	}
+	if d > maxListMetricsLookback {
+		return 0, fmt.Errorf("invalid lookback '%s': must be at most %s", s, formatLookback(maxListMetricsLookback))
+	}
+	return d, nil
+}
 
-	var sb strings.Builder
+// formatLookback renders a lookback, in days when it is a whole number of them
+func formatLookback(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return formatStep(d)
+}
 
-	// Header
-	sb.WriteString("| Timestamp | Value |")
-	for _, k := range sortedKeys {
-		sb.WriteString(fmt.Sprintf(" %s |", k))
+// metricsPage returns the bounds of the requested page of total metrics. An offset past the
+// last metric is an error, except when there are no metrics at all.
+func metricsPage(params ListMetricsParams, total int) (from, to int, err error) {
+	if params.Offset > 0 && params.Offset >= total {
+		return 0, 0, fmt.Errorf("offset %d is past the last of the %d metrics", params.Offset, total)
 	}
-	sb.WriteString("\n")
+	return params.Offset, min(params.Offset+params.Limit, total), nil
+}
 
-	// Separator
-	sb.WriteString("|---|---|")
-	for range sortedKeys {
-		sb.WriteString("---|")
+// nextMetricsOffset returns the offset of the page after the one ending at to, 0 on the last page
+func nextMetricsOffset(to, total int) int {
+	if to >= total {
+		return 0
 	}
-	sb.WriteString("\n")
+	return to
+}
 
-	// Data rows
-	for _, res := range metricsResult {
-		for _, p := range res.Points {
-			ts := time.Unix(p.Timestamp, 0).Format(time.RFC3339)
-			sb.WriteString(fmt.Sprintf("| %s | %.4f |", ts, p.Value))
+// metricsPageFooter renders the range of a page and the parameters of the next page, nothing
+// when all metrics fit on the page
+func metricsPageFooter(params ListMetricsParams, from, to, total int) string {
+	if from == 0 && to == total {
+		return ""
+	}
+	footer := fmt.Sprintf("\nShowing %d–%d of %d metrics.", from+1, to, total)
+	if to < total {
+		next := params
+		next.Offset = to
+		// the parameters only hold strings and numbers, so marshaling cannot fail
+		nextParams, _ := json.Marshal(next)
+		footer += fmt.Sprintf(" Pass %s for the next page.", nextParams)
+	}
+	return footer + "\n"
+}
+
+// fetchMetricLabels looks up the sorted label names of every metric, without __name__,
+// running at most metricLabelWorkers lookups at a time. The labels are returned in the
+// order of names; a failed lookup leaves the labels of that metric empty.
+func (t tool) fetchMetricLabels(ctx context.Context, names []string, start, end time.Time) ([][]string, error) {
+	labels := make([][]string, len(names))
+	jobs := make(chan int)
 
-			for _, k := range sortedKeys {
-				val := res.Labels[k]
-				if val == "" {
-					val = "-"
+	var wg sync.WaitGroup
+	for w := 0; w < min(metricLabelWorkers, len(names)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				found, err := t.client.GetMetricLabels(ctx, names[i], start, end)
+				if err != nil {
+					slog.Warn("Metric label lookup failed", "metric", names[i], "error", err)
+					continue
+				}
+				kept := make([]string, 0, len(found))
+				for _, l := range found {
+					if l != "__name__" {
+						kept = append(kept, l)
+					}
 				}
-				sb.WriteString(fmt.Sprintf(" %s |", val))
+				sort.Strings(kept)
+				labels[i] = kept
 			}
-			sb.WriteString("\n")
+		}()
+	}
+
+feed:
+	for i := range names {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	return sb.String()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return labels, nil
 }
 
-func parseTime(s string) (time.Time, error) {
-	if s == "now" {
-		return time.Now(), nil
+// QueryMetric queries a metric over a range of time
+func (t tool) QueryMetric(ctx context.Context, request *mcp.CallToolRequest, params QueryMetricParams) (*mcp.CallToolResult, *MetricsResult, error) {
+	format := params.Format
+	if format == "" {
+		format = formatMarkdown
+	}
+	if format != formatMarkdown && format != formatJSON && format != formatCSV {
+		return nil, nil, fmt.Errorf("invalid format '%s'. Must be '%s', '%s' or '%s'", format, formatMarkdown, formatJSON, formatCSV)
 	}
-	if d, err := time.ParseDuration(s); err == nil {
-		return time.Now().Add(-d), nil
+
+	mode := params.Mode
+	if mode == "" {
+		mode = modeRaw
+	}
+	if mode != modeRaw && mode != modeSummary {
+		return nil, nil, fmt.Errorf("invalid mode '%s'. Must be '%s' or '%s'", mode, modeRaw, modeSummary)
+	}
+
+	selectorHeader := ""
+	if params.Metric != "" || len(params.Labels) > 0 {
+		if params.Query != "" || len(params.Queries) > 0 {
+			return nil, nil, fmt.Errorf("metric and labels build the query, pick either them or query and queries, not both")
+		}
+		selector, err := buildSelector(params.Metric, params.Labels)
+		if err != nil {
+			return nil, nil, err
+		}
+		params.Query = selector
+		selectorHeader = fmt.Sprintf("Query: `%s`\n\n", selector)
+	}
+	if (params.Query == "") == (len(params.Queries) == 0) {
+		return nil, nil, fmt.Errorf("exactly one of query, queries or metric must be provided")
+	}
+	if len(params.Queries) > maxBatchQueries {
+		return nil, nil, fmt.Errorf("at most %d queries can be batched, got %d", maxBatchQueries, len(params.Queries))
+	}
+	for i, q := range params.Queries {
+		if strings.TrimSpace(q.Query) == "" {
+			return nil, nil, fmt.Errorf("queries[%d]: query is required", i)
+		}
+	}
+
+	// Both times are relative to the same instant, so start and end 'now' are an empty range
+	now := t.clock.Now()
+	start, err := parseTime(params.Start, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse start time: %w", err)
+	}
+
+	end, endParam := now, "now"
+	if params.End != "" {
+		endParam = params.End
+		end, err = parseTime(params.End, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse end time: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return nil, nil, fmt.Errorf("start '%s' must be before end '%s'. Durations look back from now, so start needs the longer one, e.g. start '2h' and end '1h'",
+			params.Start, endParam)
+	}
+	maxRange := time.Duration(t.limits.MetricMaxRangeHours) * time.Hour
+	if mode == modeRaw && maxRange > 0 && end.Sub(start) > maxRange {
+		return nil, nil, fmt.Errorf("time range of %s is longer than the maximum of %s for raw points. Use mode 'summary' for the statistics of a longer range, or shorten it",
+			formatLookback(end.Sub(start)), formatLookback(maxRange))
+	}
+
+	if params.MaxSeries < 0 || params.MaxRows < 0 {
+		return nil, nil, fmt.Errorf("max_series and max_rows must not be negative")
+	}
+	opts := metricsFormat{
+		Format:          format,
+		Mode:            mode,
+		MaxSeries:       t.limits.MetricMaxSeries,
+		MaxRows:         t.limits.MetricMaxRows,
+		PivotMaxSeries:  t.limits.MetricPivotMaxSeries,
+		MaxLabelColumns: t.limits.MetricMaxLabelColumns,
+	}
+	if params.MaxSeries > 0 {
+		opts.MaxSeries = params.MaxSeries
+	}
+	if params.MaxRows > 0 {
+		opts.MaxRows = params.MaxRows
+	}
+	switch params.Layout {
+	case "":
+	case layoutFlat, layoutGrouped, layoutPivot:
+		if format != formatMarkdown || mode != modeRaw {
+			return nil, nil, fmt.Errorf("layout only applies to markdown output in raw mode")
+		}
+		opts.Layout = params.Layout
+	default:
+		return nil, nil, fmt.Errorf("invalid layout '%s'. Must be '%s', '%s' or '%s'", params.Layout, layoutFlat, layoutGrouped, layoutPivot)
+	}
+	if params.Transpose != nil {
+		if format != formatMarkdown || mode != modeRaw || params.LatestOnly {
+			return nil, nil, fmt.Errorf("transpose only applies to markdown output in raw mode, without latest_only")
+		}
+		if *params.Transpose && params.Layout != "" {
+			return nil, nil, fmt.Errorf("transpose renders its own table, it does not combine with layout '%s'", params.Layout)
+		}
+		opts.Transpose = params.Transpose
+	}
+	if params.RankBy != "" {
+		if _, ok := rankAggregations[params.RankBy]; !ok {
+			return nil, nil, fmt.Errorf("invalid rank_by '%s'. Must be 'max', 'last' or 'avg'", params.RankBy)
+		}
+		opts.RankBy = params.RankBy
+	}
+
+	if params.Humanize {
+		if format != formatMarkdown {
+			return nil, nil, fmt.Errorf("humanize only applies to markdown output, JSON and CSV keep the raw values")
+		}
+		opts.Humanize = true
+	}
+
+	if params.StaleAfter != "" && !params.LatestOnly {
+		return nil, nil, fmt.Errorf("stale_after only applies with latest_only")
+	}
+	if params.LatestOnly {
+		if mode != modeRaw || params.Layout != "" {
+			return nil, nil, fmt.Errorf("latest_only renders one row per series, it does not combine with mode 'summary' or a layout")
+		}
+		opts.Latest = &metricLatest{Now: now, StaleAfter: defaultStaleAfter}
+		if params.StaleAfter != "" {
+			if opts.Latest.StaleAfter, err = time.ParseDuration(params.StaleAfter); err != nil || opts.Latest.StaleAfter <= 0 {
+				return nil, nil, fmt.Errorf("invalid stale_after '%s', expected a positive duration like '5m'", params.StaleAfter)
+			}
+		}
+	}
+
+	if params.AnomalyZScore != 0 && !params.DetectAnomalies {
+		return nil, nil, fmt.Errorf("anomaly_z_score only applies with detect_anomalies")
+	}
+	if params.DetectAnomalies {
+		if params.LatestOnly {
+			return nil, nil, fmt.Errorf("detect_anomalies needs every point, it does not combine with latest_only")
+		}
+		if params.AnomalyZScore < 0 {
+			return nil, nil, fmt.Errorf("anomaly_z_score must be positive, got %s", formatValue(params.AnomalyZScore))
+		}
+		opts.Anomalies = &metricAnomalies{ZScore: defaultAnomalyZScore}
+		if params.AnomalyZScore > 0 {
+			opts.Anomalies.ZScore = params.AnomalyZScore
+		}
+	}
+
+	switch params.ThresholdDirection {
+	case "", thresholdAbove, thresholdBelow:
+	default:
+		return nil, nil, fmt.Errorf("invalid threshold_direction '%s'. Must be '%s' or '%s'", params.ThresholdDirection, thresholdAbove, thresholdBelow)
+	}
+
+	if params.Transform != "" && len(params.Queries) > 0 {
+		return nil, nil, fmt.Errorf("transform only applies to query, apply the counter function inside each of queries instead")
+	}
+
+	step, stepNote, err := resolveStep(params.Step, start, end, t.limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	// a step that does not parse is queried in one chunk and only loses the share of the
+	// window of a threshold
+	stepDuration, _ := parseStep(step)
+	chunks := chunkRange(start, end, stepDuration, t.limits.MetricBackendMaxPoints)
+	if params.Threshold != nil {
+		opts.Threshold = &metricThreshold{Value: *params.Threshold, Direction: params.ThresholdDirection, Step: stepDuration, Window: end.Sub(start)}
+		if opts.Threshold.Direction == "" {
+			opts.Threshold.Direction = thresholdAbove
+		}
+	}
+	if opts.Humanize {
+		// metadata is optional, without it the units come from the metric names
+		var metadataErr error
+		if opts.Metadata, metadataErr = t.client.GetMetricMetadata(ctx); metadataErr != nil {
+			slog.Warn("Metric metadata lookup failed", "error", metadataErr)
+		}
+	}
+	if len(params.Queries) > 0 {
+		return t.queryMetricBatch(ctx, params.Queries, chunks, step, stepNote, opts)
+	}
+
+	query, transformHeader := params.Query, ""
+	if params.Transform != "" {
+		var window time.Duration
+		query, window, err = applyTransform(params.Transform, params.Query, step)
+		if err != nil {
+			return nil, nil, err
+		}
+		transformHeader = fmt.Sprintf("Transform: %s over %s windows, querying `%s`\n\n", strings.ToLower(params.Transform), formatStep(window), query)
+	}
+
+	result, err := t.queryRangeChunks(ctx, query, chunks, step)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query range metri c: %w", err)
+	}
+
+	series := opts.series(result.Data.Result, query)
+	output, err := formatMetrics(series, query, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to format metrics: %w", err)
+	}
+	if format == formatMarkdown {
+		output = stepHeader(step, stepNote) + chunkNote(len(chunks), t.limits.MetricBackendMaxPoints) + selectorHeader + transformHeader + output
+	}
+
+	structured := structuredMetrics(series, query, step)
+	if len(chunks) > 1 {
+		structured.Chunks = len(chunks)
+	}
+	if mode == modeSummary {
+		structured.Stats = seriesStats(series)
+	}
+	if opts.Threshold != nil {
+		structured.Breaches = opts.Threshold.seriesBreaches(series)
+	}
+	if opts.Latest != nil {
+		structured.Latest = opts.Latest.samples(series)
+	}
+	if opts.Anomalies != nil {
+		structured.Anomalies = opts.Anomalies.seriesAnomalies(series)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: output,
+			},
+		},
+	}, structured, nil
+}
+
+// summarizeBoundMetrics converts bound metrics to their structured form
+func summarizeBoundMetrics(metrics []suseobservability.BoundMetric) []MetricSummary {
+	summaries := make([]MetricSummary, 0, len(metrics))
+	for _, bm := range metrics {
+		s := MetricSummary{
+			Name:        bm.Name,
+			Unit:        bm.Unit,
+			Expressions: make([]string, 0, len(bm.BoundQueries)),
+		}
+		for _, bq := range bm.BoundQueries {
+			s.Expressions = append(s.Expressions, bq.Expression)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+// structuredMetrics converts a query result to its structured form. Unlike the markdown
+// table it is never capped, so clients get every point of every series.
+func structuredMetrics(series []Series, query, step string) *MetricsResult {
+	structured := make([]MetricsSeries, 0, len(series))
+	for _, s := range series {
+		ms := MetricsSeries{
+			Labels:     s.Labels,
+			Timestamps: make([]int64, 0, len(s.Points)),
+			Values:     make([]float64, 0, len(s.Points)),
+		}
+		for _, p := range s.Points {
+			if !isFinite(p.Value) {
+				continue
+			}
+			ms.Timestamps = append(ms.Timestamps, p.Timestamp)
+			ms.Values = append(ms.Values, p.Value)
+		}
+		structured = append(structured, ms)
+	}
+	return &MetricsResult{Query: query, Step: step, Series: structured}
+}
+
+// stepHeader reports the resolution of a range query
+func stepHeader(step, note string) string {
+	if note == "" {
+		return fmt.Sprintf("Step: %s\n\n", step)
+	}
+	return fmt.Sprintf("Step: %s (%s)\n\n", step, note)
+}
+
+// timeFormatHint describes the times parseTime accepts, for its errors
+const timeFormatHint = "expected 'now', a duration like '1h' or 'now-1h', optionally followed by '/d' for the start of that day"
+
+// parseTime parses a time relative to now: 'now', a duration looking back from now like '1h'
+// or '2d', or the Grafana style 'now-1h'. A '/d' suffix aligns the time to the start of its
+// day in UTC, e.g. 'now/d' is today at midnight and 'now-1d/d' yesterday at midnight.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	expr, alignDay := strings.CutSuffix(s, "/d")
+	var t time.Time
+	switch {
+	case expr == "now":
+		t = now
+	case strings.HasPrefix(expr, "now+"):
+		return time.Time{}, fmt.Errorf("invalid time format: %s (times in the future are not supported, %s)", s, timeFormatHint)
+	case strings.HasPrefix(expr, "now-"):
+		d, err := parseTimeDuration(strings.TrimPrefix(expr, "now-"))
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid time format: %s (%s)", s, timeFormatHint)
+		}
+		t = now.Add(-d)
+	default:
+		d, err := parseTimeDuration(expr)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid time format: %s (%s)", s, timeFormatHint)
+		}
+		t = now.Add(-d)
+	}
+	if alignDay {
+		t = t.UTC().Truncate(24 * time.Hour)
+	}
+	return t, nil
+}
+
+// parseTimeDuration parses a Go duration or a whole number of days like '2d'
+func parseTimeDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok && err != nil {
+		n, derr := strconv.Atoi(days)
+		d, err = time.Duration(n)*24*time.Hour, derr
 	}
-	return time.Time{}, fmt.Errorf("invalid time format: %s (expected 'now' or duration like '1h')", s)
+	return d, err
 }
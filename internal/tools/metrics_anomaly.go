@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultAnomalyZScore is the robust z-score above which a point is anomalous, the usual
+	// cut-off for the modified z-score
+	defaultAnomalyZScore = 3.5
+	// anomalyWindow is the number of preceding finite points a point is compared with
+	anomalyWindow = 10
+	// minAnomalyWindow is the number of preceding finite points needed to judge a point, the
+	// first points of a series are never anomalous
+	minAnomalyWindow = 5
+	// madScale turns the median absolute deviation into an estimate of the standard deviation
+	// of normally distributed values, so the robust z-score reads like a z-score
+	madScale = 0.6745
+)
+
+// metricAnomalies flags the points of a getMetrics query deviating from the points before them
+type metricAnomalies struct {
+	// ZScore is the robust z-score above which a point is anomalous
+	ZScore float64
+}
+
+// SeriesAnomalies lists the anomalous points of a series. First and Last are the timestamps
+// of the first and last of them, unset without anomalies.
+type SeriesAnomalies struct {
+	Labels     map[string]string `json:"labels"`
+	Anomalies  int               `json:"anomalies"`
+	First      int64             `json:"first,omitempty"`
+	Last       int64             `json:"last,omitempty"`
+	Timestamps []int64           `json:"timestamps,omitempty"`
+}
+
+// detectAnomalies returns the timestamps of the points deviating from the median of the
+// anomalyWindow finite points before them by more than zScore times their median absolute
+// deviation, scaled by madScale. A point of a window without any deviation is anomalous
+// when it differs from the median at all, so a spike in a flat series is found. NaN and
+// ±Inf points are skipped.
+func detectAnomalies(points []Point, zScore float64) []int64 {
+	var anomalies []int64
+	window := make([]float64, 0, anomalyWindow)
+	for _, p := range points {
+		if !isFinite(p.Value) {
+			continue
+		}
+		if len(window) >= minAnomalyWindow {
+			median, mad := medianAbsoluteDeviation(window)
+			deviation := math.Abs(p.Value - median)
+			if (mad == 0 && deviation > 0) || (mad > 0 && madScale*deviation/mad > zScore) {
+				anomalies = append(anomalies, p.Timestamp)
+			}
+		}
+		if len(window) == anomalyWindow {
+			window = window[1:]
+		}
+		window = append(window, p.Value)
+	}
+	return anomalies
+}
+
+// medianAbsoluteDeviation returns the median of values and the median of their absolute
+// deviations from it
+func medianAbsoluteDeviation(values []float64) (median, mad float64) {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	median = quantile(sorted, 0.5)
+	deviations := make([]float64, len(sorted))
+	for i, v := range sorted {
+		deviations[i] = math.Abs(v - median)
+	}
+	sort.Float64s(deviations)
+	return median, quantile(deviations, 0.5)
+}
+
+// seriesAnomalies lists the anomalous points of every series
+func (a *metricAnomalies) seriesAnomalies(series []Series) []SeriesAnomalies {
+	anomalies := make([]SeriesAnomalies, 0, len(series))
+	for _, s := range series {
+		timestamps := detectAnomalies(s.sorted(), a.ZScore)
+		sa := SeriesAnomalies{Labels: s.Labels, Anomalies: len(timestamps), Timestamps: timestamps}
+		if len(timestamps) > 0 {
+			sa.First, sa.Last = timestamps[0], timestamps[len(timestamps)-1]
+		}
+		anomalies = append(anomalies, sa)
+	}
+	return anomalies
+}
+
+// note summarizes the anomalies of the rendered series below their table. Like the threshold
+// note, they are detected on the full series and the kept series are a prefix of all.
+func (a *metricAnomalies) note(kept, all []Series) string {
+	if a == nil {
+		return ""
+	}
+	anomalies := a.seriesAnomalies(all)
+	anomalous, hidden := 0, 0
+	for i, sa := range anomalies {
+		if sa.Anomalies == 0 {
+			continue
+		}
+		anomalous++
+		if i >= len(kept) {
+			hidden++
+		}
+	}
+
+	var sb strings.Builder
+	if anomalous == 0 {
+		sb.WriteString(fmt.Sprintf("\nAnomalies (robust z-score above %s): none found.\n", formatValue(a.ZScore)))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("\nAnomalies (robust z-score above %s against the %d points before): %d of %d series have anomalous points.\n",
+		formatValue(a.ZScore), anomalyWindow, anomalous, len(all)))
+	for _, sa := range anomalies[:len(kept)] {
+		if sa.Anomalies == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`: %d anomalous point(s) between %s and %s\n",
+			seriesName(sa.Labels), sa.Anomalies, formatUnix(sa.First), formatUnix(sa.Last)))
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("- %d more series with anomalous points are not shown, raise max_series or rank them with rank_by\n", hidden))
+	}
+	return sb.String()
+}
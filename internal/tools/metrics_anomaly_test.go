@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// anomalyPoints returns a point a minute from 1700000000 for every value
+func anomalyPoints(values ...float64) []Point {
+	points := make([]Point, len(values))
+	for i, v := range values {
+		points[i] = Point{Timestamp: 1700000000 + int64(i)*60, Value: v}
+	}
+	return points
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []int64
+	}{
+		{
+			name:   "flat series",
+			values: []float64{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5},
+		},
+		{
+			name:   "spike in a flat series",
+			values: []float64{5, 5, 5, 5, 5, 5, 50, 5, 5},
+			want:   []int64{1700000360},
+		},
+		{
+			name:   "spike in a noisy series",
+			values: []float64{10, 11, 9, 10, 12, 10, 9, 11, 40, 10, 11},
+			want:   []int64{1700000480},
+		},
+		{
+			name:   "noise is not anomalous",
+			values: []float64{10, 11, 9, 10, 12, 10, 9, 11, 12, 10, 11},
+		},
+		{
+			name:   "drop",
+			values: []float64{100, 98, 101, 99, 100, 102, 99, 0, 100},
+			want:   []int64{1700000420},
+		},
+		{
+			name:   "the first points are not judged",
+			values: []float64{1, 1000, 1, 1000, 1},
+		},
+		{
+			name:   "non-finite points are skipped",
+			values: []float64{5, 5, math.NaN(), 5, 5, 5, math.Inf(1), 5, 50},
+			want:   []int64{1700000480},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectAnomalies(anomalyPoints(tt.values...), defaultAnomalyZScore))
+		})
+	}
+
+	t.Run("a lower z-score flags more", func(t *testing.T) {
+		values := []float64{10, 11, 9, 10, 12, 10, 9, 11, 14, 10}
+
+		assert.Empty(t, detectAnomalies(anomalyPoints(values...), defaultAnomalyZScore))
+		assert.Equal(t, []int64{1700000480}, detectAnomalies(anomalyPoints(values...), 2))
+	})
+}
+
+func TestFormatMetricsAnomalies(t *testing.T) {
+	series := []Series{
+		{Labels: map[string]string{"pod": "api-1"}, Points: anomalyPoints(5, 5, 5, 5, 5, 5, 50, 5, 60)},
+		{Labels: map[string]string{"pod": "api-2"}, Points: anomalyPoints(5, 5, 5, 5, 5, 5, 5, 5, 5)},
+	}
+	opts := metricsFormat{Format: formatMarkdown, Layout: layoutGrouped, Anomalies: &metricAnomalies{ZScore: defaultAnomalyZScore}}
+
+	t.Run("footer per series", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", opts)
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "\nAnomalies (robust z-score above 3.5 against the 10 points before): 1 of 2 series have anomalous points.\n"+
+			"- `{pod=\"api-1\"}`: 2 anomalous point(s) between "+formatUnix(1700000360)+" and "+formatUnix(1700000480)+"\n")
+	})
+
+	t.Run("hidden series", func(t *testing.T) {
+		opts := opts
+		opts.MaxSeries = 1
+
+		output, err := formatMetrics([]Series{series[1], series[0]}, "q", opts)
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "- 1 more series with anomalous points are not shown, raise max_series or rank them with rank_by\n")
+	})
+
+	t.Run("none found", func(t *testing.T) {
+		output, err := formatMetrics(series[1:], "q", opts)
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "\nAnomalies (robust z-score above 3.5): none found.\n")
+	})
+
+	t.Run("summary mode", func(t *testing.T) {
+		opts := opts
+		opts.Mode, opts.Layout = modeSummary, ""
+
+		output, err := formatMetrics(series, "q", opts)
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "2 anomalous point(s)")
+	})
+}
+
+func TestQueryMetricDetectAnomalies(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+
+	t.Run("anomalies in the structured content", func(t *testing.T) {
+		var points []suseobservability.MetricPoint
+		for _, p := range anomalyPoints(5, 5, 5, 5, 5, 5, 50, 5) {
+			points = append(points, suseobservability.MetricPoint{Timestamp: p.Timestamp, Value: p.Value})
+		}
+		mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{
+				Data: suseobservability.MetricData{Result: []suseobservability.MetricResult{{Labels: map[string]string{"pod": "a"}, Points: points}}},
+			}, nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h", DetectAnomalies: true})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "1 anomalous point(s) between")
+		assert.Equal(t, []SeriesAnomalies{{
+			Labels:     map[string]string{"pod": "a"},
+			Anomalies:  1,
+			First:      1700000360,
+			Last:       1700000360,
+			Timestamps: []int64{1700000360},
+		}}, structured.Anomalies)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params QueryMetricParams
+			err    string
+		}{
+			{
+				name:   "z-score without detect_anomalies",
+				params: QueryMetricParams{Query: "up", Start: "1h", AnomalyZScore: 3},
+				err:    "anomaly_z_score only applies with detect_anomalies",
+			},
+			{
+				name:   "negative z-score",
+				params: QueryMetricParams{Query: "up", Start: "1h", DetectAnomalies: true, AnomalyZScore: -1},
+				err:    "anomaly_z_score must be positive, got -1",
+			},
+			{
+				name:   "latest_only",
+				params: QueryMetricParams{Query: "up", Start: "1h", DetectAnomalies: true, LatestOnly: true},
+				err:    "detect_anomalies needs every point, it does not combine with latest_only",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := tools.QueryMetric(ctx, nil, tt.params)
+
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBatchQueries is the number of queries getMetrics accepts in one call
+const maxBatchQueries = 10
+
+// queryMetricBatch runs the queries concurrently and renders one section per query.
+// A failing query is reported in its section; the call only fails when all queries do.
+// Each query runs over the chunks in turn.
+func (t tool) queryMetricBatch(ctx context.Context, queries []MetricQuery, chunks []timeRange, step, stepNote string, opts metricsFormat) (*mcp.CallToolResult, *MetricsResult, error) {
+	results := make([][]Series, len(queries))
+	errs := make([]error, len(queries))
+
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := t.queryRangeChunks(ctx, q.Query, chunks, step)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = opts.series(res.Data.Result, q.Query)
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(queries) {
+		return nil, nil, fmt.Errorf("failed to query range metrics: %w", errors.Join(errs...))
+	}
+
+	var sb strings.Builder
+	if opts.Format == formatMarkdown {
+		sb.WriteString(stepHeader(step, stepNote))
+		sb.WriteString(chunkNote(len(chunks), t.limits.MetricBackendMaxPoints))
+	}
+	structured := &MetricsResult{Step: step, Results: make([]MetricsQueryResult, 0, len(queries))}
+	if len(chunks) > 1 {
+		structured.Chunks = len(chunks)
+	}
+	for i, q := range queries {
+		alias := q.Alias
+		if alias == "" {
+			alias = q.Query
+		}
+		entry := MetricsQueryResult{Alias: alias, Query: q.Query}
+
+		sb.WriteString(fmt.Sprintf("### %s\n\n", alias))
+		if errs[i] != nil {
+			entry.Error = errs[i].Error()
+			sb.WriteString(fmt.Sprintf("Query failed: %s\n\n", errs[i]))
+			structured.Results = append(structured.Results, entry)
+			continue
+		}
+
+		output, err := formatMetrics(results[i], q.Query, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to format metrics of '%s': %w", alias, err)
+		}
+		sb.WriteString(strings.TrimRight(output, "\n"))
+		sb.WriteString("\n\n")
+
+		entry.Series = structuredMetrics(results[i], q.Query, step).Series
+		if opts.Mode == modeSummary {
+			entry.Stats = seriesStats(results[i])
+		}
+		if opts.Threshold != nil {
+			entry.Breaches = opts.Threshold.seriesBreaches(results[i])
+		}
+		if opts.Latest != nil {
+			entry.Latest = opts.Latest.samples(results[i])
+		}
+		if opts.Anomalies != nil {
+			entry.Anomalies = opts.Anomalies.seriesAnomalies(results[i])
+		}
+		structured.Results = append(structured.Results, entry)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: strings.TrimRight(sb.String(), "\n") + "\n",
+			},
+		},
+	}
+	if failed > 0 {
+		markPartial(result)
+	}
+	return result, structured, nil
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func metricResponse(labels map[string]string, value float64) *suseobservability.MetricQueryResponse {
+	return &suseobservability.MetricQueryResponse{
+		Data: suseobservability.MetricData{
+			Result: []suseobservability.MetricResult{
+				{Labels: labels, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: value}}},
+			},
+		},
+	}
+}
+
+func TestQueryMetricBatch(t *testing.T) {
+	ctx := context.Background()
+	queryRange := func(m *MockSuseObservabilityClient, query string) *mock.Call {
+		return m.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "")
+	}
+
+	t.Run("one section per query in request order", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		queryRange(mockClient, "cpu").Return(metricResponse(map[string]string{"pod": "a"}, 0.25), nil).Once()
+		queryRange(mockClient, "memory").Return(metricResponse(map[string]string{"pod": "a"}, 512), nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{
+			Queries: []MetricQuery{{Query: "cpu", Alias: "CPU"}, {Query: "memory"}},
+			Start:   "1h",
+			End:     "now",
+		})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.True(t, strings.HasPrefix(output, "Step: 1m"))
+		cpu, memory := strings.Index(output, "### CPU\n"), strings.Index(output, "### memory\n")
+		require.NotEqual(t, -1, cpu)
+		require.NotEqual(t, -1, memory)
+		assert.Less(t, cpu, memory)
+		assert.Contains(t, output, "| a | 0.25 |")
+		assert.Contains(t, output, "| a | 512 |")
+
+		require.Len(t, structured.Results, 2)
+		assert.Equal(t, "CPU", structured.Results[0].Alias)
+		assert.Equal(t, []float64{512}, structured.Results[1].Series[0].Values)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("partial failure is reported per query", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		queryRange(mockClient, "cpu").Return(metricResponse(nil, 1), nil).Once()
+		queryRange(mockClient, "bad(").Return(nil, errors.New("parse error")).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{
+			Queries: []MetricQuery{{Query: "cpu"}, {Query: "bad(", Alias: "broken"}},
+			Start:   "1h",
+			End:     "now",
+			Mode:    "summary",
+		})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "### broken\n\nQuery failed: parse error")
+		assert.Contains(t, output, "| Samples |")
+		assert.Equal(t, "parse error", structured.Results[1].Error)
+		assert.Len(t, structured.Results[0].Stats, 1)
+		assert.True(t, IsPartial(result))
+	})
+
+	t.Run("all queries failing fails the call", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		queryRange(mockClient, "a").Return(nil, errors.New("first")).Once()
+		queryRange(mockClient, "b").Return(nil, errors.New("second")).Once()
+
+		result, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{
+			Queries: []MetricQuery{{Query: "a"}, {Query: "b"}},
+			Start:   "1h",
+			End:     "now",
+		})
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "first")
+		assert.ErrorContains(t, err, "second")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+		tooMany := make([]MetricQuery, 11)
+		for i := range tooMany {
+			tooMany[i] = MetricQuery{Query: "up"}
+		}
+
+		tests := []struct {
+			params QueryMetricParams
+			err    string
+		}{
+			{params: QueryMetricParams{}, err: "exactly one of query, queries or metric must be provided"},
+			{params: QueryMetricParams{Query: "up", Queries: []MetricQuery{{Query: "up"}}}, err: "exactly one of query, queries or metric must be provided"},
+			{params: QueryMetricParams{Queries: tooMany}, err: "at most 10 queries can be batched, got 11"},
+			{params: QueryMetricParams{Queries: []MetricQuery{{Query: "up"}, {Alias: "empty"}}}, err: "queries[1]: query is required"},
+		}
+
+		for _, tt := range tests {
+			tt.params.Start, tt.params.End = "1h", "now"
+			result, _, err := tools.QueryMetric(ctx, nil, tt.params)
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+// timeRange is the time range of one range query
+type timeRange struct {
+	Start, End time.Time
+}
+
+// chunkRange splits [start, end] into consecutive ranges of at most maxPoints points per
+// series at the step, each starting a step after the end of the previous one. The range is
+// returned whole when it fits, or when maxPoints or the step are not positive.
+func chunkRange(start, end time.Time, step time.Duration, maxPoints int) []timeRange {
+	if maxPoints <= 0 || step <= 0 || int64(end.Sub(start)/step)+1 <= int64(maxPoints) {
+		return []timeRange{{Start: start, End: end}}
+	}
+	span := time.Duration(maxPoints-1) * step
+	var chunks []timeRange
+	for s := start; !s.After(end); s = s.Add(span + step) {
+		e := s.Add(span)
+		if e.After(end) {
+			e = end
+		}
+		chunks = append(chunks, timeRange{Start: s, End: e})
+	}
+	return chunks
+}
+
+// chunkNote tells that a query was split, rendered below the step header
+func chunkNote(chunks, maxPoints int) string {
+	if chunks <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("Queried in %d chunks: the backend returns at most %d points per series per request, the results are stitched together.\n\n", chunks, maxPoints)
+}
+
+// queryRangeChunks runs a range query over each chunk in turn and stitches the series back
+// together, dropping the samples a chunk repeats at its boundary. It stops at the first
+// failing chunk or when the context is cancelled between chunks.
+func (t tool) queryRangeChunks(ctx context.Context, query string, chunks []timeRange, step string) (*suseobservability.MetricQueryResponse, error) {
+	var merged *suseobservability.MetricQueryResponse
+	index := map[string]int{}
+	for i, c := range chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// An empty timeout lets the client use its configured request timeout
+		res, err := t.client.QueryRangeMetric(ctx, query, c.Start, c.End, step, "")
+		if err != nil {
+			if len(chunks) > 1 {
+				return nil, fmt.Errorf("chunk %d of %d (%s to %s): %w", i+1, len(chunks), formatUnix(c.Start.Unix()), formatUnix(c.End.Unix()), err)
+			}
+			return nil, err
+		}
+		if merged == nil {
+			merged = &suseobservability.MetricQueryResponse{Status: res.Status, Data: suseobservability.MetricData{ResultType: res.Data.ResultType}}
+		}
+		merged.Errors = append(merged.Errors, res.Errors...)
+		for _, r := range res.Data.Result {
+			key := labelsKey(r.Labels)
+			j, ok := index[key]
+			if !ok {
+				j = len(merged.Data.Result)
+				index[key] = j
+				merged.Data.Result = append(merged.Data.Result, suseobservability.MetricResult{Labels: r.Labels})
+			}
+			merged.Data.Result[j].Points = appendAfter(merged.Data.Result[j].Points, r.Points)
+		}
+	}
+	return merged, nil
+}
+
+// appendAfter appends the points later than the last of points, as chunks are queried in
+// time order
+func appendAfter(points, next []suseobservability.MetricPoint) []suseobservability.MetricPoint {
+	for _, p := range next {
+		if len(points) > 0 && p.Timestamp <= points[len(points)-1].Timestamp {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// labelsKey identifies a label set independently of the map order
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte(0)
+		sb.WriteString(labels[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkRange(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("a range within the limit is one chunk", func(t *testing.T) {
+		chunks := chunkRange(start, start.Add(9*time.Minute), time.Minute, 10)
+
+		assert.Equal(t, []timeRange{{Start: start, End: start.Add(9 * time.Minute)}}, chunks)
+	})
+
+	t.Run("chunks start a step after the previous one ends", func(t *testing.T) {
+		chunks := chunkRange(start, start.Add(25*time.Minute), time.Minute, 10)
+
+		assert.Equal(t, []timeRange{
+			{Start: start, End: start.Add(9 * time.Minute)},
+			{Start: start.Add(10 * time.Minute), End: start.Add(19 * time.Minute)},
+			{Start: start.Add(20 * time.Minute), End: start.Add(25 * time.Minute)},
+		}, chunks)
+	})
+
+	t.Run("a 30 day range at 1m", func(t *testing.T) {
+		chunks := chunkRange(start, start.Add(30*24*time.Hour), time.Minute, 11000)
+
+		assert.Len(t, chunks, 4)
+		assert.Equal(t, start.Add(30*24*time.Hour), chunks[3].End)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		assert.Len(t, chunkRange(start, start.Add(30*24*time.Hour), time.Minute, 0), 1)
+	})
+}
+
+func TestQueryRangeChunks(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+	chunks := []timeRange{{Start: start, End: start.Add(time.Minute)}, {Start: start.Add(time.Minute), End: start.Add(2 * time.Minute)}}
+	response := func(results ...suseobservability.MetricResult) *suseobservability.MetricQueryResponse {
+		return &suseobservability.MetricQueryResponse{Status: "success", Data: suseobservability.MetricData{ResultType: "matrix", Result: results}}
+	}
+	points := func(timestamps ...int64) []suseobservability.MetricPoint {
+		p := make([]suseobservability.MetricPoint, len(timestamps))
+		for i, ts := range timestamps {
+			p[i] = suseobservability.MetricPoint{Timestamp: ts, Value: float64(ts - 1700000000)}
+		}
+		return p
+	}
+
+	t.Run("series are stitched without the repeated boundary samples", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", ctx, "up", chunks[0].Start, chunks[0].End, "1m", "").Return(response(
+			suseobservability.MetricResult{Labels: map[string]string{"pod": "a"}, Points: points(1700000000, 1700000060)},
+		), nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, "up", chunks[1].Start, chunks[1].End, "1m", "").Return(response(
+			suseobservability.MetricResult{Labels: map[string]string{"pod": "b"}, Points: points(1700000120)},
+			suseobservability.MetricResult{Labels: map[string]string{"pod": "a"}, Points: points(1700000060, 1700000120)},
+		), nil).Once()
+
+		res, err := NewBaseTool(mockClient).queryRangeChunks(ctx, "up", chunks, "1m")
+
+		require.NoError(t, err)
+		assert.Equal(t, "matrix", res.Data.ResultType)
+		assert.Equal(t, []suseobservability.MetricResult{
+			{Labels: map[string]string{"pod": "a"}, Points: points(1700000000, 1700000060, 1700000120)},
+			{Labels: map[string]string{"pod": "b"}, Points: points(1700000120)},
+		}, res.Data.Result)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("a failing chunk fails the query", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", ctx, "up", chunks[0].Start, chunks[0].End, "1m", "").Return(response(), nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, "up", chunks[1].Start, chunks[1].End, "1m", "").Return(nil, errors.New("timeout")).Once()
+
+		_, err := NewBaseTool(mockClient).queryRangeChunks(ctx, "up", chunks, "1m")
+
+		assert.EqualError(t, err, "chunk 2 of 2 (2023-11-14T22:14:20Z to 2023-11-14T22:15:20Z): timeout")
+	})
+
+	t.Run("cancellation stops before the next chunk", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", cancelled, "up", chunks[0].Start, chunks[0].End, "1m", "").
+			Run(func(mock.Arguments) { cancel() }).Return(response(), nil).Once()
+
+		_, err := NewBaseTool(mockClient).queryRangeChunks(cancelled, "up", chunks, "1m")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestQueryMetricChunked(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockSuseObservabilityClient)
+	mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+		Return(&suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: []suseobservability.MetricResult{{
+			Labels: map[string]string{"job": "api"},
+			Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1}},
+		}}}}, nil).Times(3)
+	tools := NewBaseTool(mockClient)
+	tools.limits.MetricBackendMaxPoints = 25
+
+	result, structured, err := tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "up", Start: "1h", Step: "1m"})
+
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text,
+		"Step: 1m\n\nQueried in 3 chunks: the backend returns at most 25 points per series per request, the results are stitched together.\n\n")
+	assert.Equal(t, 3, structured.Chunks)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// otherLabelsColumn is the markdown column holding the labels collapsed out of their own columns
+const otherLabelsColumn = "Other labels"
+
+// labelColumns is the split of the label keys of a markdown table between their own columns
+// and the otherLabelsColumn
+type labelColumns struct {
+	// Keys have their own column, in sorted order
+	Keys []string
+	// Collapsed are rendered as key=value pairs in the otherLabelsColumn, in sorted order
+	Collapsed []string
+}
+
+// splitLabelColumns gives every label key of the series its own column while there are at
+// most maxColumns of them, zero meaning unlimited. Above that, the maxColumns-1 keys telling
+// the series apart best keep their column and the others are collapsed into one column.
+func splitLabelColumns(series []Series, maxColumns int) labelColumns {
+	keys := metricLabelKeys(series)
+	if maxColumns <= 0 || len(keys) <= maxColumns {
+		return labelColumns{Keys: keys}
+	}
+	ranked := rankLabelKeys(series, keys)
+	kept := append([]string(nil), ranked[:maxColumns-1]...)
+	collapsed := append([]string(nil), ranked[maxColumns-1:]...)
+	sort.Strings(kept)
+	sort.Strings(collapsed)
+	return labelColumns{Keys: kept, Collapsed: collapsed}
+}
+
+// rankLabelKeys orders label keys by the number of distinct values they take across the
+// series, highest first, so keys like pod come before keys shared by every series like job.
+// A series without the label counts as the empty value. Ties keep the keys sorted.
+func rankLabelKeys(series []Series, keys []string) []string {
+	distinct := make(map[string]int, len(keys))
+	for _, k := range keys {
+		values := map[string]bool{}
+		for _, s := range series {
+			values[s.Labels[k]] = true
+		}
+		distinct[k] = len(values)
+	}
+
+	ranked := append([]string(nil), keys...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if distinct[ranked[i]] != distinct[ranked[j]] {
+			return distinct[ranked[i]] > distinct[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+	return ranked
+}
+
+// header renders the markdown header cells of the label columns
+func (c labelColumns) header() string {
+	var sb strings.Builder
+	for _, k := range c.Keys {
+		sb.WriteString(fmt.Sprintf(" %s |", k))
+	}
+	if len(c.Collapsed) > 0 {
+		sb.WriteString(fmt.Sprintf(" %s |", otherLabelsColumn))
+	}
+	return sb.String()
+}
+
+// separator renders the markdown separator cells of the label columns
+func (c labelColumns) separator() string {
+	n := len(c.Keys)
+	if len(c.Collapsed) > 0 {
+		n++
+	}
+	return strings.Repeat("---|", n)
+}
+
+// cells renders the markdown label cells of a series
+func (c labelColumns) cells(labels map[string]string) string {
+	var sb strings.Builder
+	for _, k := range c.Keys {
+		sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(labels[k])))
+	}
+	if len(c.Collapsed) > 0 {
+		var pairs []string
+		for _, k := range c.Collapsed {
+			if v, ok := labels[k]; ok {
+				pairs = append(pairs, k+"="+v)
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(strings.Join(pairs, ", "))))
+	}
+	return sb.String()
+}
+
+// note names the collapsed label keys above the table, empty when every key has a column
+func (c labelColumns) note() string {
+	if len(c.Collapsed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d label keys have their own column, chosen by how many distinct values they take; %s are shown as key=value in '%s'.\n\n",
+		len(c.Keys), len(c.Keys)+len(c.Collapsed), strings.Join(c.Collapsed, ", "), otherLabelsColumn)
+}
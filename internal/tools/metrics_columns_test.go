@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wideSeries returns series of a cAdvisor like metric with 12 labels: pod and container differ
+// per series, node takes 2 values, instance is missing on one series and the rest is shared
+func wideSeries() []Series {
+	var series []Series
+	for i := 0; i < 3; i++ {
+		labels := map[string]string{
+			"__name__":  "container_memory_working_set_bytes",
+			"pod":       fmt.Sprintf("api-%d", i),
+			"container": fmt.Sprintf("c-%d", i),
+			"node":      fmt.Sprintf("node-%d", i%2),
+			"instance":  "10.0.0.1:10250",
+			"cluster":   "prod",
+			"namespace": "shop",
+			"job":       "kubelet",
+			"endpoint":  "https-metrics",
+			"service":   "kubelet",
+			"metrics":   "cadvisor",
+			"image":     "registry/api:1.0",
+			"id":        "/kubepods",
+		}
+		if i == 2 {
+			delete(labels, "instance")
+		}
+		series = append(series, Series{Labels: labels, Points: []Point{{Timestamp: 1700000000, Value: float64(i)}}})
+	}
+	return series
+}
+
+func TestRankLabelKeys(t *testing.T) {
+	t.Run("distinct values first, ties sorted", func(t *testing.T) {
+		series := wideSeries()
+
+		ranked := rankLabelKeys(series, metricLabelKeys(series))
+
+		assert.Equal(t, []string{"container", "pod", "instance", "node", "cluster", "endpoint", "id", "image", "job", "metrics", "namespace", "service"}, ranked)
+	})
+
+	t.Run("does not reorder the keys passed", func(t *testing.T) {
+		keys := []string{"b", "a"}
+
+		ranked := rankLabelKeys([]Series{{Labels: map[string]string{"a": "1", "b": "1"}}, {Labels: map[string]string{"a": "2", "b": "1"}}}, keys)
+
+		assert.Equal(t, []string{"a", "b"}, ranked)
+		assert.Equal(t, []string{"b", "a"}, keys)
+	})
+
+	t.Run("no series", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b"}, rankLabelKeys(nil, []string{"a", "b"}))
+	})
+}
+
+func TestSplitLabelColumns(t *testing.T) {
+	series := wideSeries()
+
+	t.Run("at the maximum every key keeps its column", func(t *testing.T) {
+		columns := splitLabelColumns(series, 12)
+
+		assert.Len(t, columns.Keys, 12)
+		assert.Empty(t, columns.Collapsed)
+		assert.Empty(t, columns.note())
+	})
+
+	t.Run("zero is unlimited", func(t *testing.T) {
+		assert.Empty(t, splitLabelColumns(series, 0).Collapsed)
+	})
+
+	t.Run("above the maximum the least distinctive keys are collapsed", func(t *testing.T) {
+		columns := splitLabelColumns(series, 4)
+
+		assert.Equal(t, []string{"container", "instance", "pod"}, columns.Keys)
+		assert.Equal(t, []string{"cluster", "endpoint", "id", "image", "job", "metrics", "namespace", "node", "service"}, columns.Collapsed)
+		assert.Equal(t, " container | instance | pod | Other labels |", columns.header())
+		assert.Equal(t, "---|---|---|---|", columns.separator())
+		assert.Equal(t, " c-2 | - | api-2 | cluster=prod, endpoint=https-metrics, id=/kubepods, image=registry/api:1.0, job=kubelet, metrics=cadvisor, namespace=shop, node=node-0, service=kubelet |",
+			columns.cells(series[2].Labels))
+	})
+}
+
+func TestFormatMetricsLabelColumns(t *testing.T) {
+	series := wideSeries()
+
+	t.Run("flat table", func(t *testing.T) {
+		untransposed := false
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatMarkdown, MaxLabelColumns: 4, Transpose: &untransposed})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "3 of 12 label keys have their own column, chosen by how many distinct values they take; cluster, endpoint, id, image, job, metrics, namespace, node, service are shown as key=value in 'Other labels'.")
+		assert.Contains(t, output, "| Timestamp | Value | container | instance | pod | Other labels |\n|---|---|---|---|---|---|\n")
+		assert.Contains(t, output, "| 0 | c-0 | 10.0.0.1:10250 | api-0 | cluster=prod,")
+	})
+
+	t.Run("summary table", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatMarkdown, Mode: modeSummary, MaxLabelColumns: 4})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| Last | container | instance | pod | Other labels |\n")
+	})
+
+	t.Run("CSV keeps every label", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatCSV, MaxLabelColumns: 4})
+		require.NoError(t, err)
+
+		header, _, _ := strings.Cut(output, "\n")
+		assert.Equal(t, "timestamp,value,cluster,container,endpoint,id,image,instance,job,metrics,namespace,node,pod,service", header)
+	})
+
+	t.Run("below the maximum the table is unchanged", func(t *testing.T) {
+		narrow, err := formatMetrics(series, "q", metricsFormat{Format: formatMarkdown, MaxLabelColumns: 12})
+		require.NoError(t, err)
+		unlimited, err := formatMetrics(series, "q", metricsFormat{Format: formatMarkdown})
+		require.NoError(t, err)
+
+		assert.Equal(t, unlimited, narrow)
+		assert.NotContains(t, narrow, otherLabelsColumn)
+	})
+}
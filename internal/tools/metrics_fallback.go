@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxStaleMetrics is the number of metrics found only in the fallback window whose last
+// sample listMetrics looks up
+const maxStaleMetrics = 5
+
+// staleMetricPoints is the number of points of the range query locating the last sample of a
+// stale metric, the precision of its age
+const staleMetricPoints = 96
+
+// StaleMetric is a metric matching a listMetrics search without samples in the lookback but
+// with samples in the fallback window. LastSeen is unset when its last sample was not found.
+type StaleMetric struct {
+	Name     string     `json:"name"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// staleMetrics lists the metrics matching a search that have samples in the fallback window
+// ending at end but none in the lookback, the last sample of the first maxStaleMetrics
+// located. It is only run when the lookback has no match, and returns nothing when the
+// fallback window is not longer than the lookback.
+func (t tool) staleMetrics(ctx context.Context, match metricMatcher, lookback time.Duration, end time.Time) ([]StaleMetric, int, error) {
+	fallback := time.Duration(t.limits.MetricFallbackHours) * time.Hour
+	if fallback <= lookback {
+		return nil, 0, nil
+	}
+	names, err := t.client.ListMetrics(ctx, end.Add(-fallback), end)
+	if err != nil {
+		return nil, 0, err
+	}
+	var matching []string
+	for _, name := range names {
+		if match.matches(name) {
+			matching = append(matching, name)
+		}
+	}
+	sort.Strings(matching)
+
+	stale := make([]StaleMetric, 0, min(len(matching), maxStaleMetrics))
+	step := max(niceStep(fallback/staleMetricPoints), minStep)
+	for _, name := range matching[:min(len(matching), maxStaleMetrics)] {
+		m := StaleMetric{Name: name}
+		if lastSeen, ok := t.lastSample(ctx, name, end.Add(-fallback), end, step); ok {
+			m.LastSeen = &lastSeen
+		}
+		stale = append(stale, m)
+	}
+	return stale, len(matching), nil
+}
+
+// lastSample returns the time of the last point of any series of a metric in [start, end],
+// at the precision of step. A failing query only loses the age.
+func (t tool) lastSample(ctx context.Context, name string, start, end time.Time, step time.Duration) (time.Time, bool) {
+	res, err := t.client.QueryRangeMetric(ctx, fmt.Sprintf(`count({__name__=%q})`, name), start, end, formatStep(step), "")
+	if err != nil {
+		return time.Time{}, false
+	}
+	var last int64
+	for _, s := range newSeries(res.Data.Result) {
+		for _, p := range s.Points {
+			if isFinite(p.Value) {
+				last = max(last, p.Timestamp)
+			}
+		}
+	}
+	if last == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(last, 0), true
+}
+
+// formatStaleMetrics reports the metrics found only in the fallback window
+func formatStaleMetrics(stale []StaleMetric, total int, lookback string, fallbackHours int, now time.Time) string {
+	var sb strings.Builder
+	for _, m := range stale {
+		lastSeen := "no sample located"
+		if m.LastSeen != nil {
+			lastSeen = fmt.Sprintf("last seen ~%s ago", formatRemaining(now.Sub(*m.LastSeen)))
+		}
+		sb.WriteString(fmt.Sprintf("\n- Metric '%s' exists but has no samples in the last %s (%s).", m.Name, lookback, lastSeen))
+	}
+	if total > len(stale) {
+		sb.WriteString(fmt.Sprintf("\n- %d more matching metrics have samples in the last %dh only.", total-len(stale), fallbackHours))
+	}
+	return sb.String()
+}
@@ -0,0 +1,688 @@
+package tools
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+	formatCSV      = "csv"
+)
+
+const (
+	// layoutFlat renders the points of all series in one table, with their labels on every row
+	layoutFlat = "flat"
+	// layoutGrouped renders a table per series under a header with its labels
+	layoutGrouped = "grouped"
+	// layoutPivot renders one row per timestamp with a value column per series
+	layoutPivot = "pivot"
+	// layoutTransposed renders one row per series with a value column per timestamp, chosen
+	// with transpose rather than as a layout
+	layoutTransposed = "transposed"
+	// groupedLayoutSeries is the number of rendered series above which the markdown table
+	// is grouped per series unless a layout is requested
+	groupedLayoutSeries = 3
+)
+
+// metricSeries is the JSON representation of a single series
+type metricSeries struct {
+	Labels map[string]string `json:"labels"`
+	Points [][2]any          `json:"points"`
+}
+
+// metricsFormat configures how formatMetrics renders a query result
+type metricsFormat struct {
+	Format string
+	// Mode is modeRaw to render every point or modeSummary to render statistics per series
+	Mode string
+	// MaxSeries and MaxRows cap the markdown table, zero means unlimited
+	MaxSeries int
+	MaxRows   int
+	// Layout is layoutFlat, layoutGrouped or layoutPivot for the raw markdown table, empty to
+	// choose between flat and grouped by the number of series, or transposed at a single timestamp
+	Layout string
+	// PivotMaxSeries is the number of rendered series above which layoutPivot is refused, zero means unlimited
+	PivotMaxSeries int
+	// RankBy is the rank_by statistic the series are ordered by, empty to keep the order of the query result
+	RankBy string
+	// MaxLabelColumns is the number of label columns of the flat and summary markdown tables
+	// above which the least distinctive labels are collapsed into one column, zero means unlimited
+	MaxLabelColumns int
+	// Threshold flags the breaching values in the markdown tables, nil for none
+	Threshold *metricThreshold
+	// Anomalies summarizes the anomalous points of every series below the markdown tables, nil for none
+	Anomalies *metricAnomalies
+	// Humanize renders byte and second values of the markdown tables with units, resolved
+	// from Metadata, which may be nil, and the metric names
+	Humanize bool
+	Metadata map[string][]suseobservability.MetricMetadata
+	// Latest renders only the latest sample of every series, nil to render every point
+	Latest *metricLatest
+	// Transpose renders the raw markdown table with a row per series and a column per
+	// timestamp when true, nil to transpose a result with a single timestamp unless a
+	// layout is requested
+	Transpose *bool
+}
+
+// series converts the result of a query, ranked when RankBy is set and with the unit of
+// every series when humanizing
+func (opts metricsFormat) series(results []suseobservability.MetricResult, query string) []Series {
+	series := newSeries(results)
+	if opts.Humanize {
+		for i := range series {
+			series[i].Unit = valueUnit(series[i].Labels, query, opts.Metadata)
+		}
+	}
+	if opts.RankBy == "" {
+		return series
+	}
+	return rankSeries(series, rankAggregations[opts.RankBy])
+}
+
+// truncationNote explains which series were omitted from a table. Ranked series are the
+// top ones, so the omitted series are those with the lowest values.
+func (opts metricsFormat) truncationNote(kept, total int) string {
+	if opts.RankBy == "" {
+		return fmt.Sprintf("showing %d of %d series", kept, total)
+	}
+	return fmt.Sprintf("showing the top %d of %d series by %s value, the %d series with lower values are dropped", kept, total, opts.RankBy, total-kept)
+}
+
+// Magnitudes outside [smallValue, largeValue) are rendered in scientific notation, as fixed
+// precision would either round them to zero or pad them with meaningless digits
+const (
+	smallValue = 1e-2
+	largeValue = 1e9
+	// maxExactInteger is the magnitude up to which integers, such as counters, are rendered exactly
+	maxExactInteger = 1e12
+)
+
+// formatValue renders a metric value for the markdown tables: integers exactly, mid-range
+// values with 4 decimals and very small or large values in scientific notation, all without
+// trailing zeros. NaN, e.g. of a division by zero, is rendered as "no data" and ±Inf as ±∞.
+func formatValue(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case math.IsNaN(v):
+		return "no data"
+	case math.IsInf(v, 1):
+		return "∞"
+	case math.IsInf(v, -1):
+		return "-∞"
+	case v == math.Trunc(v) && abs < maxExactInteger:
+		return strconv.FormatFloat(v+0, 'f', 0, 64)
+	case abs < smallValue || abs >= largeValue:
+		mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(v, 'e', 4, 64), "e")
+		return trimZeros(mantissa) + "e" + exponent
+	default:
+		return trimZeros(strconv.FormatFloat(v, 'f', 4, 64))
+	}
+}
+
+// trimZeros removes the trailing zeros of a decimal number, and the point when nothing follows it
+func trimZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+func formatMetrics(series []Series, queryName string, opts metricsFormat) (string, error) {
+	if opts.Format == formatMarkdown && len(series) == 1 && series[0].Text != "" && len(series[0].Points) > 0 {
+		return fmt.Sprintf("String result at %s: %q\n", formatUnix(series[0].Points[0].Timestamp), series[0].Text), nil
+	}
+	if opts.Mode == modeSummary {
+		return formatMetricsSummary(series, queryName, opts)
+	}
+	if opts.Latest != nil {
+		return formatMetricsLatest(series, queryName, opts)
+	}
+
+	switch opts.Format {
+	case formatJSON:
+		return formatMetricsJSON(series)
+	case formatCSV:
+		return formatMetricsCSV(series)
+	}
+
+	kept, omittedSeries, omittedPoints := capMetrics(series, opts.MaxSeries, opts.MaxRows)
+	layout := opts.Layout
+	if layout == "" {
+		layout = layoutFlat
+		if len(kept) > groupedLayoutSeries {
+			layout = layoutGrouped
+		}
+		// Series at a single timestamp read best as a list of label sets with their value
+		if opts.Transpose == nil && len(metricTimestamps(kept)) == 1 {
+			layout = layoutTransposed
+		}
+	}
+	if opts.Transpose != nil && *opts.Transpose {
+		layout = layoutTransposed
+	}
+	var output string
+	switch layout {
+	case layoutTransposed:
+		var err error
+		if output, err = formatMetricsTransposed(kept, queryName, counterResets(kept, series), opts.Threshold, opts.MaxLabelColumns); err != nil {
+			return "", err
+		}
+	case layoutGrouped:
+		output = formatMetricsGrouped(kept, queryName, counterResets(kept, series), opts.Threshold)
+	case layoutPivot:
+		if opts.PivotMaxSeries > 0 && len(kept) > opts.PivotMaxSeries {
+			return "", fmt.Errorf("layout 'pivot' aligns at most %d series, the query returned %d. "+
+				"Lower max_series (with rank_by to keep the top series), aggregate the query or use layout 'grouped'", opts.PivotMaxSeries, len(kept))
+		}
+		output = formatMetricsPivot(kept, queryName, counterResets(kept, series), opts.Threshold)
+	default:
+		output = formatMetricsMarkdown(kept, queryName, counterResets(kept, series), opts.Threshold, opts.MaxLabelColumns)
+	}
+	if len(kept) > 0 {
+		output += opts.Threshold.note(kept, series)
+		output += opts.Anomalies.note(kept, series)
+	}
+	if omittedSeries > 0 || omittedPoints > 0 {
+		output += fmt.Sprintf("\nOutput truncated: %s, %d series and %d points omitted (the latest points of each series are kept). "+
+			"Aggregate the query (e.g. sum by (namespace) (...)), narrow the label filter, use a coarser step or mode 'summary' for one row per series.\n",
+			opts.truncationNote(len(kept), len(series)), omittedSeries, omittedPoints)
+	}
+	return output, nil
+}
+
+// capMetrics keeps at most maxSeries series and maxRows points overall.
+// Every kept series keeps at least its latest point, so no more than maxRows
+// series are kept; the row budget is shared fairly, so short series leave room
+// for longer ones. Zero limits mean unlimited.
+func capMetrics(series []Series, maxSeries, maxRows int) (kept []Series, omittedSeries int, omittedPoints int) {
+	kept = series
+	if maxRows > 0 && (maxSeries <= 0 || maxRows < maxSeries) {
+		maxSeries = maxRows
+	}
+	if maxSeries > 0 && len(kept) > maxSeries {
+		for _, res := range kept[maxSeries:] {
+			omittedPoints += len(res.Points)
+		}
+		omittedSeries = len(kept) - maxSeries
+		kept = kept[:maxSeries]
+	}
+
+	total := 0
+	for _, res := range kept {
+		total += len(res.Points)
+	}
+	if maxRows <= 0 || total <= maxRows {
+		return kept, omittedSeries, omittedPoints
+	}
+
+	// Hand out the budget from the shortest series to the longest
+	order := make([]int, len(kept))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(kept[order[a]].Points) < len(kept[order[b]].Points)
+	})
+
+	capped := make([]Series, len(kept))
+	budget := maxRows
+	for n, idx := range order {
+		share := budget / (len(order) - n)
+		if share < 1 {
+			share = 1
+		}
+		points := kept[idx].Points
+		if len(points) > share {
+			omittedPoints += len(points) - share
+			points = points[len(points)-share:]
+		}
+		budget -= len(points)
+		capped[idx] = Series{Labels: kept[idx].Labels, Points: points, Unit: kept[idx].Unit}
+	}
+	return capped, omittedSeries, omittedPoints
+}
+
+// formatMetricsJSON renders the series as an array of labels and [timestamp, value] pairs.
+// JSON has no literals for NaN and ±Inf, their values are null.
+func formatMetricsJSON(series []Series) (string, error) {
+	rendered := make([]metricSeries, 0, len(series))
+	for _, s := range series {
+		r := metricSeries{
+			Labels: s.Labels,
+			Points: make([][2]any, 0, len(s.Points)),
+		}
+		for _, p := range s.Points {
+			var value any
+			if isFinite(p.Value) {
+				value = p.Value
+			}
+			r.Points = append(r.Points, [2]any{p.Timestamp, value})
+		}
+		rendered = append(rendered, r)
+	}
+
+	b, err := json.Marshal(rendered)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// formatMetricsMarkdown renders one row per point. resets holds the counter reset timestamps
+// of every series, rows at a reset are annotated in a Note column and rows breaching th, if
+// any, in a Breach column. Labels beyond maxLabelColumns are collapsed into one column.
+func formatMetricsMarkdown(series []Series, queryName string, resets []map[int64]bool, th *metricThreshold, maxLabelColumns int) string {
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName)
+	}
+
+	columns := splitLabelColumns(series, maxLabelColumns)
+	nameColumn, nameHeader := metricNameColumn(series)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	sb.WriteString(columns.note())
+
+	hasResets := false
+	for i, r := range resets {
+		if len(r) == 0 {
+			continue
+		}
+		if !hasResets {
+			hasResets = true
+			sb.WriteString(counterResetsNote)
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`: %d counter reset(s)\n", seriesName(series[i].Labels), len(r)))
+	}
+	if hasResets {
+		sb.WriteString("\n")
+	}
+
+	// Header
+	if nameColumn {
+		sb.WriteString("| Metric ")
+	}
+	sb.WriteString("| Timestamp | Value |")
+	if th != nil {
+		sb.WriteString(" Breach |")
+	}
+	sb.WriteString(columns.header())
+	if hasResets {
+		sb.WriteString(" Note |")
+	}
+	sb.WriteString("\n")
+
+	// Separator
+	if nameColumn {
+		sb.WriteString("|---")
+	}
+	sb.WriteString("|---|---|")
+	if th != nil {
+		sb.WriteString("---|")
+	}
+	sb.WriteString(columns.separator())
+	if hasResets {
+		sb.WriteString("---|")
+	}
+	sb.WriteString("\n")
+
+	// Data rows
+	cells := make([]string, len(series))
+	for i, s := range series {
+		cells[i] = columns.cells(s.Labels)
+	}
+	forEachMetricRow(series, nil, func(i int, p Point, _ []string) {
+		ts := formatUnix(p.Timestamp)
+		if nameColumn {
+			sb.WriteString(fmt.Sprintf("| %s ", valueOrDash(series[i].Labels["__name__"])))
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |", ts, series[i].format(p.Value)))
+		if th != nil {
+			sb.WriteString(fmt.Sprintf(" %s |", th.marker(p.Value)))
+		}
+		sb.WriteString(cells[i])
+		if hasResets {
+			note := "-"
+			if resets[i][p.Timestamp] {
+				note = "counter reset"
+			}
+			sb.WriteString(fmt.Sprintf(" %s |", note))
+		}
+		sb.WriteString("\n")
+	})
+
+	sb.WriteString(nonFiniteNote(series))
+
+	return sb.String()
+}
+
+// counterResetsNote introduces the counter resets marked in the markdown tables
+const counterResetsNote = "Counter resets detected: these drops are restarts of the counter (e.g. a pod restart), not real decreases. Use rate() or increase() across them.\n\n"
+
+// nonFiniteNote counts the NaN and ±Inf samples of the rendered series, empty when there are none
+func nonFiniteNote(series []Series) string {
+	nonFinite := 0
+	for _, s := range series {
+		nonFinite += s.NonFinite()
+	}
+	if nonFinite == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n%d samples are not finite, shown as 'no data' for NaN (e.g. a division by zero) and ∞ for ±Inf.\n", nonFinite)
+}
+
+// formatMetricsGrouped renders a timestamp/value table per series, under a header with the
+// labels of the series. Rows at a counter reset are annotated in a Note column of their table,
+// rows breaching th, if any, in a Breach column.
+func formatMetricsGrouped(series []Series, queryName string, resets []map[int64]bool, th *metricThreshold) string {
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName)
+	}
+
+	_, nameHeader := metricNameColumn(series)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	for _, r := range resets {
+		if len(r) > 0 {
+			sb.WriteString(counterResetsNote)
+			break
+		}
+	}
+
+	for i, s := range series {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("Series `%s` (%d point(s)", seriesName(s.Labels), len(s.Points)))
+		hasResets := len(resets[i]) > 0
+		if hasResets {
+			sb.WriteString(fmt.Sprintf(", %d counter reset(s)", len(resets[i])))
+		}
+		sb.WriteString("):\n\n")
+
+		header, separator := "| Timestamp | Value |", "|---|---|"
+		if th != nil {
+			header, separator = header+" Breach |", separator+"---|"
+		}
+		if hasResets {
+			header, separator = header+" Note |", separator+"---|"
+		}
+		sb.WriteString(header + "\n" + separator + "\n")
+		for _, p := range s.Points {
+			sb.WriteString(fmt.Sprintf("| %s | %s |", formatUnix(p.Timestamp), s.format(p.Value)))
+			if th != nil {
+				sb.WriteString(fmt.Sprintf(" %s |", th.marker(p.Value)))
+			}
+			if hasResets {
+				note := "-"
+				if resets[i][p.Timestamp] {
+					note = "counter reset"
+				}
+				sb.WriteString(fmt.Sprintf(" %s |", note))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString(nonFiniteNote(series))
+
+	return sb.String()
+}
+
+// formatMetricsPivot renders one row per timestamp with a value column per series, named by
+// the labels telling the series apart. Series without a sample at a timestamp show "-", and
+// values at a counter reset or breaching th, if any, are annotated.
+func formatMetricsPivot(series []Series, queryName string, resets []map[int64]bool, th *metricThreshold) string {
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName)
+	}
+
+	columns, keys := pivotColumns(series)
+	_, nameHeader := metricNameColumn(series)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	if len(keys) > 0 {
+		sb.WriteString(fmt.Sprintf("Columns are named by %s.\n\n", strings.Join(keys, ", ")))
+	}
+
+	hasResets := false
+	for i, r := range resets {
+		if len(r) == 0 {
+			continue
+		}
+		if !hasResets {
+			hasResets = true
+			sb.WriteString(counterResetsNote)
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`: %d counter reset(s)\n", columns[i], len(r)))
+	}
+	if hasResets {
+		sb.WriteString("\n")
+	}
+
+	values := make([]map[int64]float64, len(series))
+	for i, s := range series {
+		values[i] = make(map[int64]float64, len(s.Points))
+		for _, p := range s.Points {
+			values[i][p.Timestamp] = p.Value
+		}
+	}
+	timestamps := metricTimestamps(series)
+
+	sb.WriteString("| Timestamp |")
+	for _, c := range columns {
+		sb.WriteString(fmt.Sprintf(" %s |", c))
+	}
+	sb.WriteString("\n|---|")
+	for range columns {
+		sb.WriteString("---|")
+	}
+	sb.WriteString("\n")
+
+	for _, ts := range timestamps {
+		sb.WriteString(fmt.Sprintf("| %s |", formatUnix(ts)))
+		for i := range series {
+			v, ok := values[i][ts]
+			if !ok {
+				sb.WriteString(" - |")
+				continue
+			}
+			var notes []string
+			if resets[i][ts] {
+				notes = append(notes, "counter reset")
+			}
+			if th.breached(v) {
+				notes = append(notes, "breach")
+			}
+			if len(notes) > 0 {
+				sb.WriteString(fmt.Sprintf(" %s (%s) |", series[i].format(v), strings.Join(notes, ", ")))
+			} else {
+				sb.WriteString(fmt.Sprintf(" %s |", series[i].format(v)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(nonFiniteNote(series))
+
+	return sb.String()
+}
+
+// pivotColumns names the pivot column of every series by the values of the labels that
+// differ between the series, e.g. the pod, and returns those label keys with __name__ shown
+// as "metric". A single series is named "Value"; clashing names get a "#n" suffix.
+func pivotColumns(series []Series) (columns []string, keys []string) {
+	var varying []string
+	for _, k := range append([]string{"__name__"}, metricLabelKeys(series)...) {
+		for _, s := range series[1:] {
+			if s.Labels[k] != series[0].Labels[k] {
+				varying = append(varying, k)
+				break
+			}
+		}
+	}
+
+	columns = make([]string, len(series))
+	used := map[string]int{}
+	for i, s := range series {
+		values := make([]string, len(varying))
+		for j, k := range varying {
+			values[j] = valueOrDash(s.Labels[k])
+		}
+		name := strings.Join(values, "/")
+		if name == "" {
+			name = "Value"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s #%d", name, used[name])
+		}
+		columns[i] = name
+	}
+
+	for _, k := range varying {
+		if k == "__name__" {
+			k = "metric"
+		}
+		keys = append(keys, k)
+	}
+	return columns, keys
+}
+
+// counterResets returns the counter reset timestamps of every kept series, detected on the
+// full series so that a reset right before the first kept point is not missed. The kept
+// series are a prefix of all, as returned by capMetrics.
+func counterResets(kept, all []Series) []map[int64]bool {
+	resets := make([]map[int64]bool, len(kept))
+	for i := range kept {
+		for _, ts := range all[i].CounterResets() {
+			if resets[i] == nil {
+				resets[i] = map[int64]bool{}
+			}
+			resets[i][ts] = true
+		}
+	}
+	return resets
+}
+
+// seriesName renders a series like name{label="value"}, with sorted labels
+func seriesName(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if k != "__name__" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, labels[k])
+	}
+	return labels["__name__"] + "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// formatMetricsCSV renders one row per point with the timestamp, the value and the sorted label values.
+// NaN and ±Inf values are left empty.
+func formatMetricsCSV(series []Series) (string, error) {
+	sortedKeys := metricLabelKeys(series)
+	nameColumn, _ := metricNameColumn(series)
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	header := append([]string{"timestamp", "value"}, sortedKeys...)
+	if nameColumn {
+		header = append([]string{"metric"}, header...)
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	var err error
+	forEachMetricRow(series, sortedKeys, func(i int, p Point, labels []string) {
+		if err != nil {
+			return
+		}
+		ts := formatUnix(p.Timestamp)
+		value := ""
+		if isFinite(p.Value) {
+			value = strconv.FormatFloat(p.Value, 'g', -1, 64)
+		}
+		row := append([]string{ts, value}, labels...)
+		if nameColumn {
+			row = append([]string{series[i].Labels["__name__"]}, row...)
+		}
+		err = w.Write(row)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// metricNameColumn tells whether the series carry more than one metric name, in which case
+// __name__ is rendered as a Metric column. Otherwise header states the single name, if any.
+// A series without a name counts as a distinct name when others have one.
+func metricNameColumn(series []Series) (column bool, header string) {
+	names := map[string]bool{}
+	for _, s := range series {
+		names[s.Labels["__name__"]] = true
+	}
+	if len(names) > 1 {
+		return true, ""
+	}
+	for name := range names {
+		if name != "" {
+			header = fmt.Sprintf("Metric: `%s`\n\n", name)
+		}
+	}
+	return false, header
+}
+
+// metricLabelKeys returns the sorted label keys used across all series, without __name__
+func metricLabelKeys(series []Series) []string {
+	labelKeys := make(map[string]bool)
+	for _, s := range series {
+		for k := range s.Labels {
+			if k != "__name__" {
+				labelKeys[k] = true
+			}
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(labelKeys))
+	for k := range labelKeys {
+		sortedKeys = append(sortedKeys, k)
+	}
+	sort.Strings(sortedKeys)
+	return sortedKeys
+}
+
+// forEachMetricRow calls fn for every point of every series, with the index of the series
+// and its label values in keys order
+func forEachMetricRow(series []Series, keys []string, fn func(i int, p Point, labels []string)) {
+	for i, s := range series {
+		labels := make([]string, len(keys))
+		for i, k := range keys {
+			labels[i] = s.Labels[k]
+		}
+		for _, p := range s.Points {
+			fn(i, p, labels)
+		}
+	}
+}
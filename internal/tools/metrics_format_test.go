@@ -0,0 +1,435 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMetrics(t *testing.T) {
+	series := []suseobservability.MetricResult{
+		{
+			Labels: map[string]string{"__name__": "up", "job": "node_exporter"},
+			Points: []suseobservability.MetricPoint{
+				{Timestamp: 1700000000, Value: 1},
+				{Timestamp: 1700000060, Value: 0.5},
+			},
+		},
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "up", metricsFormat{Format: formatMarkdown})
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "| Timestamp | Value | job |")
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | 1 | node_exporter |")
+		assert.Contains(t, output, "| 2023-11-14T22:14:20Z | 0.5 | node_exporter |")
+	})
+
+	t.Run("markdown states a single metric name once", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "up", metricsFormat{Format: formatMarkdown})
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(output, "Metric: `up`\n\n| Timestamp | Value | job |\n"))
+	})
+
+	t.Run("markdown shows the metric of every row when they differ", func(t *testing.T) {
+		multi := []Series{
+			{Labels: map[string]string{"__name__": "up", "job": "x"}, Points: []Point{{1700000000, 1}}},
+			{Labels: map[string]string{"__name__": "scrape_duration_seconds", "job": "x"}, Points: []Point{{1700000000, 0.2}}},
+		}
+
+		untransposed := false
+		output, err := formatMetrics(multi, `{job="x"}`, metricsFormat{Format: formatMarkdown, Transpose: &untransposed})
+
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "Metric: ")
+		assert.Contains(t, output, "| Metric | Timestamp | Value | job |\n|---|---|---|---|\n")
+		assert.Contains(t, output, "| up | 2023-11-14T22:13:20Z | 1 | x |")
+		assert.Contains(t, output, "| scrape_duration_seconds | 2023-11-14T22:13:20Z | 0.2 | x |")
+	})
+
+	t.Run("csv shows the metric of every row when they differ", func(t *testing.T) {
+		multi := []Series{
+			{Labels: map[string]string{"__name__": "up"}, Points: []Point{{1700000000, 1}}},
+			{Labels: map[string]string{}, Points: []Point{{1700000000, 1}}},
+		}
+
+		output, err := formatMetrics(multi, "up or vector(1)", metricsFormat{Format: formatCSV})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "metric,timestamp,value\nup,2023-11-14T22:13:20Z,1\n,2023-11-14T22:13:20Z,1\n", output)
+	})
+
+	t.Run("markdown annotates counter resets", func(t *testing.T) {
+		counters := []Series{
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "a"}, Points: []Point{{1700000000, 10}, {1700000060, 2}, {1700000120, 4}}},
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "b"}, Points: []Point{{1700000000, 1}, {1700000060, 2}}},
+		}
+
+		output, err := formatMetrics(counters, "http_requests_total", metricsFormat{Format: formatMarkdown})
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "- `http_requests_total{pod=\"a\"}`: 1 counter reset(s)\n")
+		assert.NotContains(t, output, `pod="b"`)
+		assert.Contains(t, output, "| Timestamp | Value | pod | Note |\n|---|---|---|---|\n")
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | 10 | a | - |")
+		assert.Contains(t, output, "| 2023-11-14T22:14:20Z | 2 | a | counter reset |")
+		assert.Contains(t, output, "| 2023-11-14T22:14:20Z | 2 | b | - |")
+	})
+
+	t.Run("markdown does not annotate gauges", func(t *testing.T) {
+		gauges := []Series{
+			{Labels: map[string]string{"__name__": "node_memory_free_bytes"}, Points: []Point{{1700000000, 10}, {1700000060, 2}}},
+		}
+
+		output, err := formatMetrics(gauges, "node_memory_free_bytes", metricsFormat{Format: formatMarkdown})
+
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "Note")
+		assert.NotContains(t, output, "counter reset")
+	})
+
+	t.Run("reset before the first kept point", func(t *testing.T) {
+		counters := []Series{
+			{Labels: map[string]string{"__name__": "restarts_total"}, Points: []Point{{1700000000, 5}, {1700000060, 1}, {1700000120, 2}}},
+		}
+
+		output, err := formatMetrics(counters, "restarts_total", metricsFormat{Format: formatMarkdown, MaxRows: 2})
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "| 2023-11-14T22:14:20Z | 1 | counter reset |")
+	})
+
+	t.Run("markdown is grouped per series above 3 series", func(t *testing.T) {
+		var pods []Series
+		for _, pod := range []string{"a", "b", "c", "d"} {
+			pods = append(pods, Series{Labels: map[string]string{"__name__": "up", "pod": pod}, Points: []Point{{1700000000, 1}}})
+		}
+		untransposed := false
+
+		output, err := formatMetrics(pods, "up", metricsFormat{Format: formatMarkdown, Transpose: &untransposed})
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Series `up{pod=\"d\"}` (1 point(s)):\n\n| Timestamp | Value |\n|---|---|\n| 2023-11-14T22:13:20Z | 1 |\n")
+
+		output, err = formatMetrics(pods[:3], "up", metricsFormat{Format: formatMarkdown, Transpose: &untransposed})
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "Series `")
+
+		output, err = formatMetrics(pods, "up", metricsFormat{Format: formatMarkdown, MaxSeries: 3, Transpose: &untransposed})
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "Series `", "the layout is chosen by the rendered series")
+
+		output, err = formatMetrics(pods, "up", metricsFormat{Format: formatMarkdown, Layout: layoutFlat})
+		assert.NoError(t, err)
+		assert.Contains(t, output, "| Timestamp | Value | pod |\n")
+	})
+
+	t.Run("grouped markdown annotates counter resets per series", func(t *testing.T) {
+		counters := []Series{
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "a"}, Points: []Point{{1700000000, 10}, {1700000060, 2}}},
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "b"}, Points: []Point{{1700000000, 1}, {1700000060, math.NaN()}}},
+		}
+
+		output, err := formatMetrics(counters, "http_requests_total", metricsFormat{Format: formatMarkdown, Layout: layoutGrouped})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Metric: `http_requests_total`\n\n"+
+			counterResetsNote+
+			"Series `http_requests_total{pod=\"a\"}` (2 point(s), 1 counter reset(s)):\n\n"+
+			"| Timestamp | Value | Note |\n|---|---|---|\n"+
+			"| 2023-11-14T22:13:20Z | 10 | - |\n"+
+			"| 2023-11-14T22:14:20Z | 2 | counter reset |\n"+
+			"\n"+
+			"Series `http_requests_total{pod=\"b\"}` (2 point(s)):\n\n"+
+			"| Timestamp | Value |\n|---|---|\n"+
+			"| 2023-11-14T22:13:20Z | 1 |\n"+
+			"| 2023-11-14T22:14:20Z | no data |\n"+
+			"\n1 samples are not finite, shown as 'no data' for NaN (e.g. a division by zero) and ∞ for ±Inf.\n", output)
+	})
+
+	t.Run("pivot aligns series on timestamps", func(t *testing.T) {
+		counters := []Series{
+			{Labels: map[string]string{"__name__": "http_requests_total", "job": "shop", "pod": "a"}, Points: []Point{{1700000000, 10}, {1700000060, 2}}},
+			{Labels: map[string]string{"__name__": "http_requests_total", "job": "shop", "pod": "b"}, Points: []Point{{1700000060, 1}, {1700000120, math.Inf(1)}}},
+		}
+
+		output, err := formatMetrics(counters, "http_requests_total", metricsFormat{Format: formatMarkdown, Layout: layoutPivot, PivotMaxSeries: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Metric: `http_requests_total`\n\n"+
+			"Columns are named by pod.\n\n"+
+			counterResetsNote+
+			"- `a`: 1 counter reset(s)\n\n"+
+			"| Timestamp | a | b |\n|---|---|---|\n"+
+			"| 2023-11-14T22:13:20Z | 10 | - |\n"+
+			"| 2023-11-14T22:14:20Z | 2 (counter reset) | 1 |\n"+
+			"| 2023-11-14T22:15:20Z | - | ∞ |\n"+
+			"\n1 samples are not finite, shown as 'no data' for NaN (e.g. a division by zero) and ∞ for ±Inf.\n", output)
+	})
+
+	t.Run("pivot columns", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			labels  []map[string]string
+			columns []string
+			keys    []string
+		}{
+			{
+				name:    "single series",
+				labels:  []map[string]string{{"__name__": "up", "pod": "a"}},
+				columns: []string{"Value"},
+			},
+			{
+				name:    "several differing labels and metric names",
+				labels:  []map[string]string{{"__name__": "cpu", "pod": "a"}, {"__name__": "memory", "pod": "a", "container": "app"}},
+				columns: []string{"cpu/-", "memory/app"},
+				keys:    []string{"metric", "container"},
+			},
+			{
+				name:    "clashing names",
+				labels:  []map[string]string{{"pod": "a/b"}, {"pod": "a/b", "zone": ""}, {"pod": "c"}},
+				columns: []string{"a/b", "a/b #2", "c"},
+				keys:    []string{"pod"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				series := make([]Series, 0, len(tt.labels))
+				for _, l := range tt.labels {
+					series = append(series, Series{Labels: l})
+				}
+
+				columns, keys := pivotColumns(series)
+
+				assert.Equal(t, tt.columns, columns)
+				assert.Equal(t, tt.keys, keys)
+			})
+		}
+	})
+
+	t.Run("pivot is refused above the maximum series", func(t *testing.T) {
+		var pods []Series
+		for _, pod := range []string{"a", "b", "c", "d"} {
+			pods = append(pods, Series{Labels: map[string]string{"__name__": "up", "pod": pod}, Points: []Point{{1700000000, 1}}})
+		}
+
+		_, err := formatMetrics(pods, "up", metricsFormat{Format: formatMarkdown, Layout: layoutPivot, PivotMaxSeries: 3})
+		assert.EqualError(t, err, "layout 'pivot' aligns at most 3 series, the query returned 4. "+
+			"Lower max_series (with rank_by to keep the top series), aggregate the query or use layout 'grouped'")
+
+		output, err := formatMetrics(pods, "up", metricsFormat{Format: formatMarkdown, Layout: layoutPivot, PivotMaxSeries: 3, MaxSeries: 3})
+		assert.NoError(t, err, "the maximum applies to the rendered series")
+		assert.Contains(t, output, "| Timestamp | a | b | c |\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "up", metricsFormat{Format: formatJSON})
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"labels":{"__name__":"up","job":"node_exporter"},"points":[[1700000000,1],[1700000060,0.5]]}]`, output)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "up", metricsFormat{Format: formatCSV})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "timestamp,value,job\n2023-11-14T22:13:20Z,1,node_exporter\n2023-11-14T22:14:20Z,0.5,node_exporter\n", output)
+	})
+
+	t.Run("csv quotes values containing commas", func(t *testing.T) {
+		input := []suseobservability.MetricResult{
+			{
+				Labels: map[string]string{"route": "/a,b", "code": ""},
+				Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 2}},
+			},
+		}
+
+		output, err := formatMetrics(newSeries(input), "up", metricsFormat{Format: formatCSV})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "timestamp,value,code,route\n2023-11-14T22:13:20Z,2,,\"/a,b\"\n", output)
+	})
+
+	t.Run("json without data", func(t *testing.T) {
+		output, err := formatMetrics(nil, "up", metricsFormat{Format: formatJSON})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", output)
+	})
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		want  string
+	}{
+		{"exact integer", 42, "42"},
+		{"zero", 0, "0"},
+		{"negative zero", math.Copysign(0, -1), "0"},
+		{"negative integer", -3, "-3"},
+		{"counter in the billions", 3200000000, "3200000000"},
+		{"huge counter", 4.5e15, "4.5e+15"},
+		{"large fraction", 3.2e9 + 0.5, "3.2e+09"},
+		{"mid-range", 12.345678, "12.3457"},
+		{"trailing zeros", 0.25, "0.25"},
+		{"sub-millisecond latency", 0.000012, "1.2e-05"},
+		{"small latency", 0.00123456, "1.2346e-03"},
+		{"negative small", -0.005, "-5e-03"},
+		{"NaN", math.NaN(), "no data"},
+		{"infinity", math.Inf(1), "∞"},
+		{"negative infinity", math.Inf(-1), "-∞"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatValue(tt.value))
+		})
+	}
+}
+
+func TestFormatMetricsNonFinite(t *testing.T) {
+	series := []Series{{
+		Labels: map[string]string{"__name__": "error_ratio"},
+		Points: []Point{{Timestamp: 1700000000, Value: math.NaN()}, {Timestamp: 1700000060, Value: 0.5}, {Timestamp: 1700000120, Value: math.Inf(1)}},
+	}}
+
+	t.Run("markdown marks the values and counts them", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatMarkdown, Mode: modeRaw})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | no data |")
+		assert.Contains(t, output, "| 2023-11-14T22:15:20Z | ∞ |")
+		assert.Contains(t, output, "2 samples are not finite")
+	})
+
+	t.Run("json uses null", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatJSON, Mode: modeRaw})
+		require.NoError(t, err)
+
+		assert.True(t, json.Valid([]byte(output)))
+		assert.Contains(t, output, `[[1700000000,null],[1700000060,0.5],[1700000120,null]]`)
+	})
+
+	t.Run("csv leaves the value empty", func(t *testing.T) {
+		output, err := formatMetrics(series, "q", metricsFormat{Format: formatCSV, Mode: modeRaw})
+		require.NoError(t, err)
+
+		assert.Equal(t, "timestamp,value\n2023-11-14T22:13:20Z,\n2023-11-14T22:14:20Z,0.5\n2023-11-14T22:15:20Z,\n", output)
+	})
+
+	t.Run("summary leaves them out of the statistics", func(t *testing.T) {
+		output, err := formatMetrics(append(series, Series{Labels: map[string]string{"__name__": "error_ratio", "pod": "b"}, Points: []Point{{Timestamp: 1700000000, Value: math.NaN()}}}),
+			"q", metricsFormat{Format: formatMarkdown, Mode: modeSummary})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | 2023-11-14T22:15:20Z | 3 | 0.5 | 0.5 | 0.5 | 0.5 | 0.5 | 0.5 | - |")
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | 2023-11-14T22:13:20Z | 1 | - | - | - | - | - | - | b |")
+		assert.Contains(t, output, "3 samples are not finite")
+	})
+
+	t.Run("structured output drops them", func(t *testing.T) {
+		structured := structuredMetrics(series, "q", "1m")
+
+		assert.Equal(t, []int64{1700000060}, structured.Series[0].Timestamps)
+		assert.Equal(t, []float64{0.5}, structured.Series[0].Values)
+	})
+}
+
+func TestFormatMetricsString(t *testing.T) {
+	series := []Series{{Labels: map[string]string{}, Points: []Point{{Timestamp: 1700000000, Value: math.NaN()}}, Text: "ready"}}
+
+	output, err := formatMetrics(series, `"ready"`, metricsFormat{Format: formatMarkdown})
+
+	require.NoError(t, err)
+	assert.Equal(t, "String result at 2023-11-14T22:13:20Z: \"ready\"\n", output)
+}
+
+func TestCapMetrics(t *testing.T) {
+	series := func(name string, points int) Series {
+		res := Series{Labels: map[string]string{"pod": name}}
+		for i := 0; i < points; i++ {
+			res.Points = append(res.Points, Point{Timestamp: int64(1700000000 + i*60), Value: float64(i)})
+		}
+		return res
+	}
+
+	t.Run("under the limits", func(t *testing.T) {
+		input := []Series{series("a", 3), series("b", 3)}
+
+		kept, omittedSeries, omittedPoints := capMetrics(input, 10, 10)
+
+		assert.Equal(t, input, kept)
+		assert.Zero(t, omittedSeries)
+		assert.Zero(t, omittedPoints)
+	})
+
+	t.Run("series cap", func(t *testing.T) {
+		input := []Series{series("a", 2), series("b", 2), series("c", 5)}
+
+		kept, omittedSeries, omittedPoints := capMetrics(input, 2, 0)
+
+		assert.Len(t, kept, 2)
+		assert.Equal(t, 1, omittedSeries)
+		assert.Equal(t, 5, omittedPoints)
+	})
+
+	t.Run("row budget keeps every series and the latest points", func(t *testing.T) {
+		input := []Series{series("a", 100), series("b", 2), series("c", 100)}
+
+		kept, omittedSeries, omittedPoints := capMetrics(input, 0, 10)
+
+		assert.Len(t, kept, 3)
+		assert.Zero(t, omittedSeries)
+		assert.Len(t, kept[1].Points, 2)
+		assert.Len(t, kept[0].Points, 4)
+		assert.Len(t, kept[2].Points, 4)
+		assert.Equal(t, float64(99), kept[0].Points[3].Value)
+		assert.Equal(t, 192, omittedPoints)
+	})
+
+	t.Run("series beyond the row budget are dropped", func(t *testing.T) {
+		input := []Series{series("a", 5), series("b", 5), series("c", 5)}
+
+		kept, omittedSeries, omittedPoints := capMetrics(input, 0, 2)
+
+		assert.Len(t, kept, 2)
+		for _, res := range kept {
+			assert.Len(t, res.Points, 1)
+		}
+		assert.Equal(t, 1, omittedSeries)
+		assert.Equal(t, 13, omittedPoints)
+	})
+
+	t.Run("truncation note", func(t *testing.T) {
+		input := []Series{series("a", 10), series("b", 10), series("c", 10)}
+
+		output, err := formatMetrics(input, "up", metricsFormat{Format: formatMarkdown, MaxSeries: 2, MaxRows: 4})
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "showing 2 of 3 series, 1 series and 26 points omitted")
+		assert.Contains(t, output, "Aggregate the query")
+		assert.Equal(t, 4, strings.Count(output, "| 2023-"))
+	})
+
+	t.Run("large result is truncated to the row cap", func(t *testing.T) {
+		input := make([]Series, 0, 1000)
+		for i := 0; i < 1000; i++ {
+			input = append(input, series(fmt.Sprintf("pod-%d", i), 100))
+		}
+
+		output, err := formatMetrics(input, "up", metricsFormat{Format: formatMarkdown, Layout: layoutFlat, MaxRows: 300})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 300, strings.Count(output, "| 2023-"))
+		assert.Contains(t, output, "Output truncated: showing 300 of 1000 series, 700 series and 99700 points omitted (the latest points of each series are kept). "+
+			"Aggregate the query (e.g. sum by (namespace) (...)), narrow the label filter, use a coarser step or mode 'summary' for one row per series.")
+	})
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/require"
+)
+
+// goldenMetrics is a query result with series of different lengths, labels and a missing label
+func goldenMetrics() []suseobservability.MetricResult {
+	var result []suseobservability.MetricResult
+	for i, pod := range []string{"api-1", "api-2", "worker-1"} {
+		series := suseobservability.MetricResult{Labels: map[string]string{"__name__": "cpu", "namespace": "prod", "pod": pod}}
+		if pod == "worker-1" {
+			delete(series.Labels, "namespace")
+		}
+		for j := 0; j < 4+i*3; j++ {
+			series.Points = append(series.Points, suseobservability.MetricPoint{Timestamp: 1700000000 + int64(j)*60, Value: float64(i+1)*0.25 + float64(j%5)*0.1})
+		}
+		result = append(result, series)
+	}
+	return result
+}
+
+func TestFormatMetricsGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts metricsFormat
+	}{
+		{name: "raw.md", opts: metricsFormat{Format: formatMarkdown}},
+		{name: "raw_capped.md", opts: metricsFormat{Format: formatMarkdown, MaxSeries: 2, MaxRows: 6}},
+		{name: "raw_grouped.md", opts: metricsFormat{Format: formatMarkdown, Layout: layoutGrouped}},
+		{name: "raw_grouped_capped.md", opts: metricsFormat{Format: formatMarkdown, Layout: layoutGrouped, MaxSeries: 2, MaxRows: 6}},
+		{name: "raw_pivot.md", opts: metricsFormat{Format: formatMarkdown, Layout: layoutPivot}},
+		{name: "raw.json", opts: metricsFormat{Format: formatJSON}},
+		{name: "raw.csv", opts: metricsFormat{Format: formatCSV}},
+		{name: "summary.md", opts: metricsFormat{Format: formatMarkdown, Mode: modeSummary}},
+		{name: "summary_capped.md", opts: metricsFormat{Format: formatMarkdown, Mode: modeSummary, MaxSeries: 1}},
+		{name: "summary.json", opts: metricsFormat{Format: formatJSON, Mode: modeSummary}},
+		{name: "summary.csv", opts: metricsFormat{Format: formatCSV, Mode: modeSummary}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := formatMetrics(newSeries(goldenMetrics()), "cpu", tt.opts)
+			require.NoError(t, err)
+
+			assertGolden(t, filepath.Join("metrics", tt.name), output)
+		})
+	}
+}
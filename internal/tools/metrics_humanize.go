@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+// Units values are humanized in
+const (
+	unitBytes          = "bytes"
+	unitSeconds        = "seconds"
+	unitBytesPerSecond = "bytes/s"
+)
+
+var (
+	// perSecondFunctions turn a counter into its change per second
+	perSecondFunctions = regexp.MustCompile(`\b(rate|irate|deriv)\s*\(`)
+	// unitlessFunctions return counts whatever the unit of their argument
+	unitlessFunctions = regexp.MustCompile(`\b(count|count_over_time|count_values|changes|resets|absent|absent_over_time)\s*\(`)
+	// quantileFunctions return values in the unit of the observed histogram
+	quantileFunctions = regexp.MustCompile(`\bhistogram_quantile\s*\(`)
+)
+
+// valueUnit returns the unit the values of a series are humanized in, empty when they are
+// plain numbers. It follows, in order, the unit label of kube-state-metrics (e.g.
+// unit="byte"), the metadata of the metric and the unit suffix of its name, taking the
+// metric name from the series or else from the single metric the query selects. Rates of
+// bytes are bytes per second, rates of seconds are ratios and left plain, and so are counts
+// and timestamps such as node_boot_time_seconds.
+func valueUnit(labels map[string]string, query string, metadata map[string][]suseobservability.MetricMetadata) string {
+	if unitlessFunctions.MatchString(query) {
+		return ""
+	}
+	name := labels["__name__"]
+	if name == "" {
+		name = queryMetric(query)
+	}
+
+	unit := normalizeUnit(labels["unit"])
+	if unit == "" {
+		if strings.HasSuffix(name, "_time_seconds") || strings.HasSuffix(name, "_timestamp_seconds") {
+			return ""
+		}
+		unit = normalizeUnit(resolveMetricMetadata(name, metadata).Unit)
+	}
+	if unit == "" || quantileFunctions.MatchString(query) {
+		return unit
+	}
+	if perSecondFunctions.MatchString(query) {
+		if unit == unitBytes {
+			return unitBytesPerSecond
+		}
+		return ""
+	}
+	return unit
+}
+
+// normalizeUnit maps the unit names of metric metadata, name suffixes and unit labels to
+// the humanized units, empty for other units
+func normalizeUnit(unit string) string {
+	switch strings.ToLower(unit) {
+	case "bytes", "byte", "by":
+		return unitBytes
+	case "seconds", "second", "s":
+		return unitSeconds
+	default:
+		return ""
+	}
+}
+
+// queryMetric returns the metric name selected by every selector of a query, empty when the
+// query selects several metrics or cannot be parsed
+func queryMetric(query string) string {
+	_, selectors, err := parsePromQL(query)
+	if err != nil {
+		return ""
+	}
+	name := ""
+	for _, s := range selectors {
+		metric := s.Metric
+		for _, m := range s.Matchers {
+			if m.Label == "__name__" && m.Op == "=" {
+				metric = m.Value
+			}
+		}
+		if metric == "" || (name != "" && metric != name) {
+			return ""
+		}
+		name = metric
+	}
+	return name
+}
+
+// byteUnits are the binary prefixes of humanized bytes
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// humanizeValue renders a finite value in a unit with 3 significant digits, e.g. 734003200
+// bytes as "700 MiB" and 0.235 seconds as "235 ms"
+func humanizeValue(v float64, unit string) string {
+	switch unit {
+	case unitBytes:
+		return humanizeBytes(v)
+	case unitBytesPerSecond:
+		return humanizeBytes(v) + "/s"
+	case unitSeconds:
+		return humanizeSeconds(v)
+	default:
+		return formatValue(v)
+	}
+}
+
+func humanizeBytes(v float64) string {
+	abs, i := math.Abs(v), 0
+	for ; abs >= 1024 && i < len(byteUnits)-1; i++ {
+		abs /= 1024
+	}
+	return humanizeNumber(math.Copysign(abs, v)) + " " + byteUnits[i]
+}
+
+// durationUnits are the units of humanized seconds, from the largest
+var durationUnits = []struct {
+	name    string
+	seconds float64
+}{
+	{"d", 86400},
+	{"h", 3600},
+	{"min", 60},
+	{"s", 1},
+	{"ms", 1e-3},
+	{"µs", 1e-6},
+	{"ns", 1e-9},
+}
+
+func humanizeSeconds(v float64) string {
+	abs := math.Abs(v)
+	if abs == 0 {
+		return "0 s"
+	}
+	for _, u := range durationUnits {
+		if abs >= u.seconds {
+			return humanizeNumber(v/u.seconds) + " " + u.name
+		}
+	}
+	last := durationUnits[len(durationUnits)-1]
+	return humanizeNumber(v/last.seconds) + " " + last.name
+}
+
+// humanizeNumber renders a number with 3 significant digits, without trailing zeros
+func humanizeNumber(v float64) string {
+	abs := math.Abs(v)
+	decimals := 2
+	switch {
+	case abs >= 100:
+		decimals = 0
+	case abs >= 10:
+		decimals = 1
+	}
+	return trimZeros(strconv.FormatFloat(v, 'f', decimals, 64))
+}
+
+// format renders a value of the series for the markdown tables, humanized in its unit when
+// it has one
+func (s Series) format(v float64) string {
+	if s.Unit == "" || !isFinite(v) {
+		return formatValue(v)
+	}
+	return humanizeValue(v, s.Unit)
+}
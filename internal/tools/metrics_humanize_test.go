@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueUnit(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		query    string
+		metadata map[string][]suseobservability.MetricMetadata
+		expected string
+	}{
+		// node-exporter
+		{name: "memory gauge", labels: map[string]string{"__name__": "node_memory_MemAvailable_bytes"}, query: "node_memory_MemAvailable_bytes", expected: unitBytes},
+		{name: "filesystem gauge without name label", query: `node_filesystem_avail_bytes{mountpoint="/"}`, expected: unitBytes},
+		{name: "aggregated gauge", query: "sum by (instance) (node_filesystem_size_bytes)", expected: unitBytes},
+		{name: "network counter", query: "node_network_receive_bytes_total", expected: unitBytes},
+		{name: "network counter rate", query: "rate(node_network_receive_bytes_total[5m])", expected: unitBytesPerSecond},
+		{name: "cpu counter", query: "node_cpu_seconds_total", expected: unitSeconds},
+		{name: "cpu counter rate is a ratio", query: `sum(rate(node_cpu_seconds_total{mode!="idle"}[5m]))`, expected: ""},
+		{name: "boot timestamp", labels: map[string]string{"__name__": "node_boot_time_seconds"}, query: "node_boot_time_seconds", expected: ""},
+		{name: "load average", query: "node_load1", expected: ""},
+		// cAdvisor and the API server
+		{name: "container memory", query: "container_memory_working_set_bytes", expected: unitBytes},
+		{name: "container cpu rate", query: "rate(container_cpu_usage_seconds_total[5m])", expected: ""},
+		{name: "latency quantile", query: "histogram_quantile(0.99, sum by (le) (rate(apiserver_request_duration_seconds_bucket[5m])))", expected: unitSeconds},
+		// kube-state-metrics
+		{name: "resource requests in bytes", labels: map[string]string{"resource": "memory", "unit": "byte"}, query: "kube_pod_container_resource_requests", expected: unitBytes},
+		{name: "resource requests in cores", labels: map[string]string{"resource": "cpu", "unit": "core"}, query: "kube_pod_container_resource_requests", expected: ""},
+		{name: "pod start time", query: "kube_pod_start_time", expected: ""},
+		{name: "counts are plain", query: "count(container_memory_working_set_bytes)", expected: ""},
+		{name: "several metrics", query: "node_memory_MemTotal_bytes - node_memory_MemAvailable_seconds", expected: ""},
+		// metadata
+		{name: "metadata unit", query: "process_resident_memory", metadata: map[string][]suseobservability.MetricMetadata{"process_resident_memory": {{Unit: "By"}}}, expected: unitBytes},
+		{name: "name selector", query: `{__name__="go_memstats_heap_alloc_bytes"}`, expected: unitBytes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, valueUnit(tt.labels, tt.query, tt.metadata))
+		})
+	}
+}
+
+func TestHumanizeValue(t *testing.T) {
+	tests := []struct {
+		value    float64
+		unit     string
+		expected string
+	}{
+		{value: 734003200, unit: unitBytes, expected: "700 MiB"},
+		{value: 512, unit: unitBytes, expected: "512 B"},
+		{value: 1536, unit: unitBytes, expected: "1.5 KiB"},
+		{value: 5.5 * 1024 * 1024 * 1024 * 1024, unit: unitBytes, expected: "5.5 TiB"},
+		{value: -2048, unit: unitBytes, expected: "-2 KiB"},
+		{value: 12.5 * 1024 * 1024, unit: unitBytesPerSecond, expected: "12.5 MiB/s"},
+		{value: 0.235, unit: unitSeconds, expected: "235 ms"},
+		{value: 0.0000042, unit: unitSeconds, expected: "4.2 µs"},
+		{value: 90, unit: unitSeconds, expected: "1.5 min"},
+		{value: 7200, unit: unitSeconds, expected: "2 h"},
+		{value: 3 * 86400, unit: unitSeconds, expected: "3 d"},
+		{value: 0, unit: unitSeconds, expected: "0 s"},
+		{value: 1.23456, unit: "", expected: "1.2346"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, humanizeValue(tt.value, tt.unit))
+		})
+	}
+
+	t.Run("non-finite values stay plain", func(t *testing.T) {
+		s := Series{Unit: unitBytes}
+		assert.Equal(t, "no data", s.format(math.NaN()))
+		assert.Equal(t, "∞", s.format(math.Inf(1)))
+	})
+}
+
+func TestQueryMetricHumanize(t *testing.T) {
+	ctx := context.Background()
+	response := &suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: []suseobservability.MetricResult{{
+		Labels: map[string]string{"pod": "a"},
+		Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 734003200}},
+	}}}}
+
+	t.Run("markdown is humanized, structured content stays raw", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("GetMetricMetadata", ctx).Return(nil, assert.AnError).Once()
+		mockClient.On("QueryRangeMetric", ctx, "container_memory_working_set_bytes", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(response, nil).Twice()
+		tools := NewBaseTool(mockClient)
+
+		result, structured, err := tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "container_memory_working_set_bytes", Start: "1h", Humanize: true})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| a | 700 MiB |")
+		assert.Equal(t, []float64{734003200}, structured.Series[0].Values)
+
+		result, _, err = tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "container_memory_working_set_bytes", Start: "1h"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| a | 734003200 |")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("only for markdown", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		_, _, err := tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "up", Start: "1h", Format: "csv", Humanize: true})
+
+		assert.EqualError(t, err, "humanize only applies to markdown output, JSON and CSV keep the raw values")
+	})
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStaleAfter is the age above which the latest sample of a series is marked stale,
+// matching the lookback Prometheus gives instant queries
+const defaultStaleAfter = 5 * time.Minute
+
+// metricLatest renders only the latest sample of every series of a getMetrics query
+type metricLatest struct {
+	// Now is the instant the age of the samples is measured from
+	Now time.Time
+	// StaleAfter is the age above which a sample is marked stale
+	StaleAfter time.Duration
+}
+
+// LatestSample is the latest sample of a series in latest_only mode. Timestamp, Value and
+// Age are unset for a series without samples, which is always stale. Value is nil for NaN
+// and ±Inf, JSON cannot represent them.
+type LatestSample struct {
+	Labels    map[string]string `json:"labels"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Value     *float64          `json:"value,omitempty"`
+	Age       float64           `json:"age_seconds,omitempty"`
+	Stale     bool              `json:"stale,omitempty"`
+}
+
+// samples returns the latest sample of every series
+func (l *metricLatest) samples(series []Series) []LatestSample {
+	latest := make([]LatestSample, 0, len(series))
+	for _, s := range series {
+		sample := LatestSample{Labels: s.Labels, Stale: true}
+		if age, ok := s.LastAge(l.Now); ok {
+			points := s.sorted()
+			last := points[len(points)-1]
+			sample.Timestamp = last.Timestamp
+			if isFinite(last.Value) {
+				sample.Value = &last.Value
+			}
+			sample.Age = age.Seconds()
+			sample.Stale = age > l.StaleAfter
+		}
+		latest = append(latest, sample)
+	}
+	return latest
+}
+
+// formatMetricsLatest renders one row per series with its latest sample and its age
+func formatMetricsLatest(series []Series, queryName string, opts metricsFormat) (string, error) {
+	switch opts.Format {
+	case formatJSON:
+		b, err := json.Marshal(opts.Latest.samples(series))
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case formatCSV:
+		return formatLatestCSV(series, opts.Latest)
+	}
+
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName), nil
+	}
+
+	kept, omittedSeries, _ := capMetrics(series, opts.MaxSeries, 0)
+	columns := splitLabelColumns(kept, opts.MaxLabelColumns)
+	nameColumn, nameHeader := metricNameColumn(kept)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	sb.WriteString(columns.note())
+	if nameColumn {
+		sb.WriteString("| Metric ")
+	}
+	sb.WriteString("| Timestamp | Value | Age | Stale |")
+	sb.WriteString(columns.header())
+	sb.WriteString("\n")
+	if nameColumn {
+		sb.WriteString("|---")
+	}
+	sb.WriteString("|---|---|---|---|")
+	sb.WriteString(columns.separator())
+	sb.WriteString("\n")
+
+	stale := 0
+	for i, sample := range opts.Latest.samples(kept) {
+		if nameColumn {
+			sb.WriteString(fmt.Sprintf("| %s ", valueOrDash(sample.Labels["__name__"])))
+		}
+		marker := "-"
+		if sample.Stale {
+			marker = "stale"
+			stale++
+		}
+		if sample.Timestamp == 0 {
+			sb.WriteString(fmt.Sprintf("| - | - | - | %s |", marker))
+		} else {
+			points := kept[i].sorted()
+			value := kept[i].format(points[len(points)-1].Value)
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |",
+				formatUnix(sample.Timestamp), value, formatAge(time.Duration(sample.Age*float64(time.Second))), marker))
+		}
+		sb.WriteString(columns.cells(sample.Labels))
+		sb.WriteString("\n")
+	}
+	if stale > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d of %d series are stale, their latest sample is older than %s. The target may be gone or no longer scraped.\n",
+			stale, len(kept), formatStep(opts.Latest.StaleAfter)))
+	}
+
+	if omittedSeries > 0 {
+		sb.WriteString(fmt.Sprintf("\nOutput truncated: %s. Aggregate the query (e.g. sum by (namespace) (...)) or narrow the label filter.\n",
+			opts.truncationNote(len(kept), len(series))))
+	}
+	return sb.String(), nil
+}
+
+// formatLatestCSV renders one row per series with its latest sample and the sorted label values
+func formatLatestCSV(series []Series, latest *metricLatest) (string, error) {
+	sortedKeys := metricLabelKeys(series)
+	nameColumn, _ := metricNameColumn(series)
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	header := append([]string{"timestamp", "value", "age_seconds", "stale"}, sortedKeys...)
+	if nameColumn {
+		header = append([]string{"metric"}, header...)
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, sample := range latest.samples(series) {
+		row := make([]string, 0, len(header))
+		if nameColumn {
+			row = append(row, sample.Labels["__name__"])
+		}
+		timestamp, value, age := "", "", ""
+		if sample.Timestamp != 0 {
+			timestamp = formatUnix(sample.Timestamp)
+			age = strconv.FormatFloat(sample.Age, 'f', 0, 64)
+		}
+		if sample.Value != nil {
+			value = strconv.FormatFloat(*sample.Value, 'g', -1, 64)
+		}
+		row = append(row, timestamp, value, age, strconv.FormatBool(sample.Stale))
+		for _, k := range sortedKeys {
+			row = append(row, sample.Labels[k])
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// formatAge renders the age of a sample in seconds below a minute, coarser above
+func formatAge(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", max(0, d/time.Second))
+	}
+	return formatRemaining(d)
+}
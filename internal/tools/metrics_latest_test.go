@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// latestSeries returns three pods at 1700000600: api-1 sampled 30s ago, api-2 last sampled
+// 10 minutes ago and api-3 without samples
+func latestSeries() []Series {
+	return []Series{
+		{Labels: map[string]string{"pod": "api-1"}, Points: []Point{{Timestamp: 1700000510, Value: 2}, {Timestamp: 1700000570, Value: 3}}},
+		{Labels: map[string]string{"pod": "api-2"}, Points: []Point{{Timestamp: 1700000000, Value: math.NaN()}}},
+		{Labels: map[string]string{"pod": "api-3"}},
+	}
+}
+
+func TestMetricLatest(t *testing.T) {
+	latest := &metricLatest{Now: time.Unix(1700000600, 0), StaleAfter: 5 * time.Minute}
+	three := 3.0
+
+	assert.Equal(t, []LatestSample{
+		{Labels: map[string]string{"pod": "api-1"}, Timestamp: 1700000570, Value: &three, Age: 30},
+		{Labels: map[string]string{"pod": "api-2"}, Timestamp: 1700000000, Age: 600, Stale: true},
+		{Labels: map[string]string{"pod": "api-3"}, Stale: true},
+	}, latest.samples(latestSeries()))
+}
+
+func TestFormatMetricsLatest(t *testing.T) {
+	opts := metricsFormat{Format: formatMarkdown, Latest: &metricLatest{Now: time.Unix(1700000600, 0), StaleAfter: 5 * time.Minute}}
+
+	t.Run("markdown", func(t *testing.T) {
+		output, err := formatMetrics(latestSeries(), "q", opts)
+
+		require.NoError(t, err)
+		assert.Equal(t, "| Timestamp | Value | Age | Stale | pod |\n"+
+			"|---|---|---|---|---|\n"+
+			"| "+formatUnix(1700000570)+" | 3 | 30s | - | api-1 |\n"+
+			"| "+formatUnix(1700000000)+" | no data | 10m | stale | api-2 |\n"+
+			"| - | - | - | stale | api-3 |\n"+
+			"\n2 of 3 series are stale, their latest sample is older than 5m. The target may be gone or no longer scraped.\n", output)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		opts := opts
+		opts.Format = formatCSV
+
+		output, err := formatMetrics(latestSeries(), "q", opts)
+
+		require.NoError(t, err)
+		assert.Equal(t, "timestamp,value,age_seconds,stale,pod\n"+
+			formatUnix(1700000570)+",3,30,false,api-1\n"+
+			formatUnix(1700000000)+",,600,true,api-2\n"+
+			",,,true,api-3\n", output)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		opts := opts
+		opts.Format = formatJSON
+
+		output, err := formatMetrics(latestSeries()[:1], "q", opts)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"labels":{"pod":"api-1"},"timestamp":1700000570,"value":3,"age_seconds":30}]`, output)
+	})
+}
+
+func TestQueryMetricLatestOnly(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	clock := newFakeClock()
+	tools.clock = clock
+	now := clock.Now().Unix()
+
+	t.Run("latest sample per series", func(t *testing.T) {
+		mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{
+				Data: suseobservability.MetricData{
+					Result: []suseobservability.MetricResult{
+						{Labels: map[string]string{"pod": "a"}, Points: []suseobservability.MetricPoint{{Timestamp: now - 120, Value: 0}, {Timestamp: now - 60, Value: 1}}},
+						{Labels: map[string]string{"pod": "b"}, Points: []suseobservability.MetricPoint{{Timestamp: now - 240, Value: 1}}},
+					},
+				},
+			}, nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "5m", LatestOnly: true, StaleAfter: "3m"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| "+formatUnix(now-60)+" | 1 | 1m | - | a |\n")
+		assert.Contains(t, output, "| "+formatUnix(now-240)+" | 1 | 4m | stale | b |\n")
+		assert.Contains(t, output, "1 of 2 series are stale, their latest sample is older than 3m.")
+		require.Len(t, structured.Latest, 2)
+		assert.False(t, structured.Latest[0].Stale)
+		assert.True(t, structured.Latest[1].Stale)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid combinations", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params QueryMetricParams
+			err    string
+		}{
+			{
+				name:   "summary mode",
+				params: QueryMetricParams{Query: "up", Start: "5m", LatestOnly: true, Mode: modeSummary},
+				err:    "latest_only renders one row per series, it does not combine with mode 'summary' or a layout",
+			},
+			{
+				name:   "stale_after without latest_only",
+				params: QueryMetricParams{Query: "up", Start: "5m", StaleAfter: "5m"},
+				err:    "stale_after only applies with latest_only",
+			},
+			{
+				name:   "invalid stale_after",
+				params: QueryMetricParams{Query: "up", Start: "5m", LatestOnly: true, StaleAfter: "-1m"},
+				err:    "invalid stale_after '-1m', expected a positive duration like '5m'",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := tools.QueryMetric(ctx, nil, tt.params)
+
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+	})
+}
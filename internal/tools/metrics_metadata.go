@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+// metricBaseUnits are the Prometheus base units recognized as metric name suffixes
+var metricBaseUnits = []string{"seconds", "bytes", "ratio", "percent", "celsius", "meters", "volts", "amperes", "joules", "grams", "hertz"}
+
+// metricMetadata is the type and unit of a metric. TypeGuessed and UnitGuessed are set when
+// the value was derived from the metric name instead of reported by the backend.
+type metricMetadata struct {
+	Type        string
+	Unit        string
+	TypeGuessed bool
+	UnitGuessed bool
+}
+
+// resolveMetricMetadata returns the type and unit of a metric, preferring the backend
+// metadata and filling the gaps from the Prometheus naming conventions
+func resolveMetricMetadata(name string, metadata map[string][]suseobservability.MetricMetadata) metricMetadata {
+	var m metricMetadata
+	for _, md := range metadata[name] {
+		if m.Type == "" && md.Type != "" && md.Type != "unknown" {
+			m.Type = md.Type
+		}
+		if m.Unit == "" && md.Unit != "" {
+			m.Unit = md.Unit
+		}
+	}
+
+	guessedType, guessedUnit := guessMetricMetadata(name)
+	if m.Type == "" && guessedType != "" {
+		m.Type, m.TypeGuessed = guessedType, true
+	}
+	if m.Unit == "" && guessedUnit != "" {
+		m.Unit, m.UnitGuessed = guessedUnit, true
+	}
+	return m
+}
+
+// guessMetricMetadata derives the type and unit of a metric from its name, e.g.
+// http_request_duration_seconds_bucket is a histogram in seconds. Series with a counter
+// suffix are counters, other series with a unit suffix are taken as gauges.
+func guessMetricMetadata(name string) (metricType, unit string) {
+	base := name
+	switch {
+	case strings.HasSuffix(name, "_bucket"):
+		metricType, base = "histogram", strings.TrimSuffix(name, "_bucket")
+	case strings.HasSuffix(name, "_total"):
+		metricType, base = "counter", strings.TrimSuffix(name, "_total")
+	case strings.HasSuffix(name, "_count"):
+		metricType, base = "counter", strings.TrimSuffix(name, "_count")
+	case strings.HasSuffix(name, "_sum"):
+		metricType, base = "counter", strings.TrimSuffix(name, "_sum")
+	}
+
+	for _, u := range metricBaseUnits {
+		if strings.HasSuffix(base, "_"+u) {
+			unit = u
+			break
+		}
+	}
+	if metricType == "" && unit != "" {
+		metricType = "gauge"
+	}
+	return metricType, unit
+}
+
+// markGuessed renders a metadata value, marking values derived from the metric name with an asterisk
+func markGuessed(value string, guessed bool) string {
+	if value == "" {
+		return "-"
+	}
+	if guessed {
+		return value + "*"
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuessMetricMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		metricType string
+		unit       string
+	}{
+		{name: "http_requests_total", metricType: "counter"},
+		{name: "process_cpu_seconds_total", metricType: "counter", unit: "seconds"},
+		{name: "http_request_duration_seconds_bucket", metricType: "histogram", unit: "seconds"},
+		{name: "http_request_duration_seconds_count", metricType: "counter", unit: "seconds"},
+		{name: "http_request_size_bytes_sum", metricType: "counter", unit: "bytes"},
+		{name: "container_memory_working_set_bytes", metricType: "gauge", unit: "bytes"},
+		{name: "node_cpu_utilisation_ratio", metricType: "gauge", unit: "ratio"},
+		{name: "up"},
+		{name: "kube_pod_status_phase"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricType, unit := guessMetricMetadata(tt.name)
+
+			assert.Equal(t, tt.metricType, metricType)
+			assert.Equal(t, tt.unit, unit)
+		})
+	}
+}
+
+func TestResolveMetricMetadata(t *testing.T) {
+	metadata := map[string][]suseobservability.MetricMetadata{
+		"http_requests_total":    {{Type: "counter", Unit: "requests"}},
+		"node_load1":             {{Type: "gauge"}},
+		"process_cpu_seconds":    {{Type: "unknown"}, {Type: "counter"}},
+		"queue_latency_seconds":  {{Type: "unknown"}},
+		"custom_duration":        {{Unit: "milliseconds"}},
+		"not_shown_metric_bytes": {{Type: "gauge", Unit: "bytes"}},
+	}
+
+	tests := []struct {
+		name     string
+		expected metricMetadata
+	}{
+		{name: "http_requests_total", expected: metricMetadata{Type: "counter", Unit: "requests"}},
+		{name: "node_load1", expected: metricMetadata{Type: "gauge"}},
+		{name: "process_cpu_seconds", expected: metricMetadata{Type: "counter", Unit: "seconds", UnitGuessed: true}},
+		{name: "queue_latency_seconds", expected: metricMetadata{Type: "gauge", Unit: "seconds", TypeGuessed: true, UnitGuessed: true}},
+		{name: "custom_duration", expected: metricMetadata{Unit: "milliseconds"}},
+		{name: "missing_total", expected: metricMetadata{Type: "counter", TypeGuessed: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolveMetricMetadata(tt.name, metadata))
+		})
+	}
+}
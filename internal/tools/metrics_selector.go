@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// buildSelector builds a vector selector from a metric name and label values, so agents can
+// query a metric without writing PromQL. A value ending in * matches the labels starting with
+// the rest of it, every other value matches exactly. Values are quoted, so they may hold any
+// character, and matchers are sorted by label for a stable query.
+func buildSelector(metric string, labels map[string]string) (string, error) {
+	metric = strings.TrimSpace(metric)
+	if metric == "" {
+		return "", fmt.Errorf("labels require metric, the name of the metric to select")
+	}
+	if !metricNamePattern.MatchString(metric) {
+		return "", fmt.Errorf("invalid metric '%s': a metric name consists of letters, digits, underscores and colons and does not start with a digit. Use query for expressions", metric)
+	}
+	if len(labels) == 0 {
+		return metric, nil
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if !labelNamePattern.MatchString(name) {
+			return "", fmt.Errorf("invalid label '%s': a label name consists of letters, digits and underscores and does not start with a digit", name)
+		}
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matcher := PromQLMatcher{Label: name, Op: "=", Value: labels[name]}
+		if prefix, ok := strings.CutSuffix(matcher.Value, "*"); ok {
+			matcher.Op, matcher.Value = "=~", regexp.QuoteMeta(prefix)+".*"
+		}
+		matchers = append(matchers, matcher.String())
+	}
+	return fmt.Sprintf("%s{%s}", metric, strings.Join(matchers, ", ")), nil
+}
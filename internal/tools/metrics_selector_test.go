@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   string
+		labels   map[string]string
+		expected string
+	}{
+		{"metric only", "up", nil, "up"},
+		{"exact values sorted by label", "kube_pod_info", map[string]string{"pod": "api-1", "namespace": "shop"}, `kube_pod_info{namespace="shop", pod="api-1"}`},
+		{"trailing star as prefix regex", "up", map[string]string{"pod": "checkout-*"}, `up{pod=~"checkout-.*"}`},
+		{"regex characters escaped", "up", map[string]string{"path": "/api/v1.0*"}, `up{path=~"/api/v1\\.0.*"}`},
+		{"quotes and backslashes escaped", "up", map[string]string{"job": `say "hi" \ bye`}, `up{job="say \"hi\" \\ bye"}`},
+		{"empty value", "up", map[string]string{"job": ""}, `up{job=""}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := buildSelector(tt.metric, tt.labels)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selector)
+			_, _, err = parsePromQL(selector)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBuildSelectorRejections(t *testing.T) {
+	tests := []struct {
+		metric string
+		labels map[string]string
+		err    string
+	}{
+		{"", map[string]string{"job": "api"}, "labels require metric, the name of the metric to select"},
+		{"rate(up[5m])", nil, "invalid metric 'rate(up[5m])': a metric name consists of letters, digits, underscores and colons and does not start with a digit. Use query for expressions"},
+		{"up", map[string]string{"1job": "api"}, "invalid label '1job': a label name consists of letters, digits and underscores and does not start with a digit"},
+	}
+
+	for _, tt := range tests {
+		_, err := buildSelector(tt.metric, tt.labels)
+
+		assert.EqualError(t, err, tt.err)
+	}
+}
@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	modeRaw     = "raw"
+	modeSummary = "summary"
+)
+
+// SeriesStats summarizes the points of a series. From and To are the timestamps of the
+// first and last sample, so gaps in the requested range are visible. The statistics are
+// zero when every sample is NonFinite.
+type SeriesStats struct {
+	Labels  map[string]string `json:"labels"`
+	Samples int               `json:"samples"`
+	// NonFinite is the number of NaN and ±Inf samples, left out of the statistics
+	NonFinite int     `json:"non_finite,omitempty"`
+	From      int64   `json:"from,omitempty"`
+	To        int64   `json:"to,omitempty"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Mean      float64 `json:"mean"`
+	P50       float64 `json:"p50"`
+	P95       float64 `json:"p95"`
+	Last      float64 `json:"last"`
+}
+
+// hasValues tells whether the statistics were computed from at least one finite sample
+func (s SeriesStats) hasValues() bool {
+	return s.Samples > s.NonFinite
+}
+
+// seriesStats computes the statistics of every series
+func seriesStats(series []Series) []SeriesStats {
+	stats := make([]SeriesStats, 0, len(series))
+	for _, s := range series {
+		stats = append(stats, s.Stats())
+	}
+	return stats
+}
+
+// formatMetricsSummary renders one row of statistics per series, with the share of the
+// window breaching the threshold when one is set
+func formatMetricsSummary(series []Series, queryName string, opts metricsFormat) (string, error) {
+	switch opts.Format {
+	case formatJSON:
+		b, err := json.Marshal(seriesStats(series))
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case formatCSV:
+		return formatSummaryCSV(series)
+	}
+
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName), nil
+	}
+
+	kept, omittedSeries, _ := capMetrics(series, opts.MaxSeries, 0)
+	columns := splitLabelColumns(kept, opts.MaxLabelColumns)
+	nameColumn, nameHeader := metricNameColumn(kept)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	sb.WriteString(columns.note())
+	if nameColumn {
+		sb.WriteString("| Metric ")
+	}
+	sb.WriteString("| From | To | Samples | Min | Max | Mean | P50 | P95 | Last |")
+	if opts.Threshold != nil {
+		sb.WriteString(fmt.Sprintf(" Time %s |", opts.Threshold))
+	}
+	sb.WriteString(columns.header())
+	sb.WriteString("\n")
+	if nameColumn {
+		sb.WriteString("|---")
+	}
+	sb.WriteString("|---|---|---|---|---|---|---|---|---|")
+	if opts.Threshold != nil {
+		sb.WriteString("---|")
+	}
+	sb.WriteString(columns.separator())
+	sb.WriteString("\n")
+
+	var breaches []ThresholdBreach
+	if opts.Threshold != nil {
+		breaches = opts.Threshold.seriesBreaches(kept)
+	}
+	for i, s := range seriesStats(kept) {
+		if nameColumn {
+			sb.WriteString(fmt.Sprintf("| %s ", valueOrDash(s.Labels["__name__"])))
+		}
+		switch {
+		case s.Samples == 0:
+			sb.WriteString("| - | - | 0 | - | - | - | - | - | - |")
+		case !s.hasValues():
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | - | - | - | - | - | - |",
+				formatUnix(s.From), formatUnix(s.To), s.Samples))
+		default:
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s | %s | %s | %s | %s |",
+				formatUnix(s.From), formatUnix(s.To), s.Samples,
+				kept[i].format(s.Min), kept[i].format(s.Max), kept[i].format(s.Mean), kept[i].format(s.P50), kept[i].format(s.P95), kept[i].format(s.Last)))
+		}
+		if breaches != nil {
+			sb.WriteString(fmt.Sprintf(" %.1f%% |", breaches[i].Percent))
+		}
+		sb.WriteString(columns.cells(s.Labels))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(opts.Threshold.note(kept, series))
+	sb.WriteString(opts.Anomalies.note(kept, series))
+
+	nonFinite := 0
+	for _, s := range kept {
+		nonFinite += s.NonFinite()
+	}
+	if nonFinite > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d samples are not finite (NaN, e.g. of a division by zero, or ±Inf) and are left out of the statistics.\n", nonFinite))
+	}
+
+	if omittedSeries > 0 {
+		sb.WriteString(fmt.Sprintf("\nOutput truncated: %s. Aggregate the query (e.g. sum by (namespace) (...)) or narrow the label filter.\n",
+			opts.truncationNote(len(kept), len(series))))
+	}
+	return sb.String(), nil
+}
+
+// formatSummaryCSV renders one row of statistics per series with the sorted label values
+func formatSummaryCSV(series []Series) (string, error) {
+	sortedKeys := metricLabelKeys(series)
+	nameColumn, _ := metricNameColumn(series)
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	header := append([]string{"from", "to", "samples", "min", "max", "mean", "p50", "p95", "last"}, sortedKeys...)
+	if nameColumn {
+		header = append([]string{"metric"}, header...)
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, s := range seriesStats(series) {
+		row := make([]string, 0, len(header))
+		if nameColumn {
+			row = append(row, s.Labels["__name__"])
+		}
+		switch {
+		case s.Samples == 0:
+			row = append(row, "", "", "0", "", "", "", "", "", "")
+		case !s.hasValues():
+			row = append(row, formatUnix(s.From), formatUnix(s.To), strconv.Itoa(s.Samples), "", "", "", "", "", "")
+		default:
+			row = append(row, formatUnix(s.From), formatUnix(s.To), strconv.Itoa(s.Samples))
+			for _, v := range []float64{s.Min, s.Max, s.Mean, s.P50, s.P95, s.Last} {
+				row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+		for _, k := range sortedKeys {
+			row = append(row, s.Labels[k])
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
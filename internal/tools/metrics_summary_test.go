@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesStats(t *testing.T) {
+	t.Run("statistics and covered range", func(t *testing.T) {
+		var points []suseobservability.MetricPoint
+		for i := 1; i <= 20; i++ {
+			points = append(points, suseobservability.MetricPoint{Timestamp: 1700000000 + int64(i)*60, Value: float64(21 - i)})
+		}
+
+		stats := seriesStats(newSeries([]suseobservability.MetricResult{{Labels: map[string]string{"pod": "a"}, Points: points}}))
+
+		assert.Equal(t, []SeriesStats{{
+			Labels:  map[string]string{"pod": "a"},
+			Samples: 20,
+			From:    1700000060,
+			To:      1700001200,
+			Min:     1,
+			Max:     20,
+			Mean:    10.5,
+			P50:     10.5,
+			P95:     19.05,
+			Last:    1,
+		}}, stats)
+	})
+
+	t.Run("single point and empty series", func(t *testing.T) {
+		stats := seriesStats(newSeries([]suseobservability.MetricResult{
+			{Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 3}}},
+			{},
+		}))
+
+		assert.Equal(t, []SeriesStats{
+			{Labels: map[string]string{}, Samples: 1, From: 1700000000, To: 1700000000, Min: 3, Max: 3, Mean: 3, P50: 3, P95: 3, Last: 3},
+			{Labels: map[string]string{}},
+		}, stats)
+	})
+}
+
+func TestFormatMetricsSummary(t *testing.T) {
+	series := []suseobservability.MetricResult{
+		{
+			Labels: map[string]string{"__name__": "cpu", "pod": "a"},
+			Points: []suseobservability.MetricPoint{
+				{Timestamp: 1700000000, Value: 0.2},
+				{Timestamp: 1700000060, Value: 0.9},
+				{Timestamp: 1700000120, Value: 0.4},
+			},
+		},
+		{
+			Labels: map[string]string{"pod": "b"},
+		},
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "cpu", metricsFormat{Format: formatMarkdown, Mode: modeSummary})
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "| From | To | Samples | Min | Max | Mean | P50 | P95 | Last | pod |")
+		assert.Contains(t, output, "| 2023-11-14T22:13:20Z | 2023-11-14T22:15:20Z | 3 | 0.2 | 0.9 | 0.5 | 0.4 | 0.85 | 0.4 | a |")
+		assert.Contains(t, output, "| - | - | 0 | - | - | - | - | - | - | b |")
+	})
+
+	t.Run("collapses the points listed in raw mode", func(t *testing.T) {
+		multiPoint := newSeries(series[:1])
+
+		full, err := formatMetrics(multiPoint, "cpu", metricsFormat{Format: formatMarkdown, Mode: modeRaw})
+		require.NoError(t, err)
+		summarized, err := formatMetrics(multiPoint, "cpu", metricsFormat{Format: formatMarkdown, Mode: modeSummary})
+		require.NoError(t, err)
+
+		// Raw mode has a row per point, summary mode a single row with the statistics of all of them
+		for _, ts := range []string{"2023-11-14T22:13:20Z", "2023-11-14T22:14:20Z", "2023-11-14T22:15:20Z"} {
+			assert.Contains(t, full, "| "+ts+" |")
+		}
+		assert.Equal(t, 1, strings.Count(summarized, "| a |"))
+		assert.Equal(t, 3, strings.Count(full, "| a |"))
+		assert.Contains(t, summarized, "| 3 | 0.2 | 0.9 | 0.5 | 0.4 | 0.85 | 0.4 | a |")
+		assert.NotContains(t, summarized, "| 2023-11-14T22:14:20Z |")
+	})
+
+	t.Run("markdown caps the series", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "cpu", metricsFormat{Format: formatMarkdown, Mode: modeSummary, MaxSeries: 1})
+
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "| b |")
+		assert.Contains(t, output, "Output truncated: showing 1 of 2 series")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series), "cpu", metricsFormat{Format: formatCSV, Mode: modeSummary})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "metric,from,to,samples,min,max,mean,p50,p95,last,pod\n"+
+			"cpu,2023-11-14T22:13:20Z,2023-11-14T22:15:20Z,3,0.2,0.9,0.5,0.4,0.85,0.4,a\n"+
+			",,,0,,,,,,,b\n", output)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		output, err := formatMetrics(newSeries(series[1:]), "cpu", metricsFormat{Format: formatJSON, Mode: modeSummary})
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"labels":{"pod":"b"},"samples":0,"min":0,"max":0,"mean":0,"p50":0,"p95":0,"last":0}]`, output)
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		output, err := formatMetrics(nil, "cpu", metricsFormat{Format: formatMarkdown, Mode: modeSummary})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "No data found for query: cpu", output)
+	})
+}
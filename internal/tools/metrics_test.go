@@ -2,7 +2,9 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListMetrics(t *testing.T) {
@@ -37,13 +40,48 @@ func TestListMetrics(t *testing.T) {
 		mockClient.On("GetBoundMetricsWithData", ctx, componentID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
 			Return(expectedResponse, nil).Once()
 
-		result, _, err := tools.ListMetrics(ctx, nil, params)
+		result, structured, err := tools.ListMetrics(ctx, nil, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "cpu_usage")
 		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "percent")
 		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "avg(cpu_usage)")
+		assert.Equal(t, &MetricListResult{
+			ComponentID: componentID,
+			Lookback:    "1h",
+			Total:       1,
+			Metrics: []MetricSummary{
+				{Name: "cpu_usage", Unit: "percent", Expressions: []string{"avg(cpu_usage)"}},
+			},
+		}, structured)
+	})
+
+	t.Run("limit truncates the list", func(t *testing.T) {
+		componentID := int64(124)
+		params := ListMetricsParams{ComponentID: componentID, Limit: 10}
+
+		expectedResponse := &suseobservability.BoundMetricsResponse{}
+		for i := 0; i < 25; i++ {
+			expectedResponse.BoundMetrics = append(expectedResponse.BoundMetrics, suseobservability.BoundMetric{
+				Name:         fmt.Sprintf("metric_%02d", i),
+				BoundQueries: []suseobservability.BoundQuery{{Expression: fmt.Sprintf("metric_%02d", i)}},
+			})
+		}
+
+		mockClient.On("GetBoundMetricsWithData", ctx, componentID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return(expectedResponse, nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "metric_09")
+		assert.NotContains(t, output, "metric_10")
+		assert.Contains(t, output, `Showing 1–10 of 25 metrics. Pass {"component_id":124,"limit":10,"offset":10} for the next page.`)
+		assert.Equal(t, 10, structured.NextOffset)
+		assert.Equal(t, 25, structured.Total)
+		assert.Len(t, structured.Metrics, 10)
 	})
 
 	t.Run("success no metrics", func(t *testing.T) {
@@ -57,11 +95,13 @@ func TestListMetrics(t *testing.T) {
 		mockClient.On("GetBoundMetricsWithData", ctx, componentID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
 			Return(expectedResponse, nil).Once()
 
-		result, _, err := tools.ListMetrics(ctx, nil, params)
+		result, structured, err := tools.ListMetrics(ctx, nil, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "No bound metrics found")
+		assert.NotNil(t, structured.Metrics)
+		assert.Empty(t, structured.Metrics)
 	})
 
 	t.Run("client error", func(t *testing.T) {
@@ -77,6 +117,392 @@ func TestListMetrics(t *testing.T) {
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "client error")
 	})
+
+	t.Run("component_id or search is required", func(t *testing.T) {
+		for _, params := range []ListMetricsParams{{}, {ComponentID: 1, Search: "cpu"}} {
+			result, _, err := tools.ListMetrics(ctx, nil, params)
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, "exactly one of component_id or search must be provided")
+		}
+	})
+}
+
+func TestCommonLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   [][]string
+		expected []string
+	}{
+		{"shared labels", [][]string{{"pod", "namespace", "container"}, {"namespace", "pod"}, {"pod", "node", "namespace"}}, []string{"namespace", "pod"}},
+		{"identical labels", [][]string{{"pod", "namespace"}, {"pod", "namespace"}}, []string{"namespace", "pod"}},
+		{"nothing shared", [][]string{{"pod"}, {"node"}}, nil},
+		{"failed lookups left out", [][]string{{"pod", "job"}, nil, {"job", "pod"}}, []string{"job", "pod"}},
+		{"a single metric", [][]string{{"pod"}, nil}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, commonLabels(tt.labels))
+		})
+	}
+}
+
+func TestListMetricsSearch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("labels keep the metric order and a failed lookup degrades to a dash", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		var names []string
+		for i := 29; i >= 0; i-- {
+			names = append(names, fmt.Sprintf("cpu_metric_%02d", i))
+		}
+		names = append(names, "memory_usage")
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(names, nil).Once()
+		for i := 0; i < 30; i++ {
+			name := fmt.Sprintf("cpu_metric_%02d", i)
+			call := mockClient.On("GetMetricLabels", ctx, name, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"))
+			if i == 3 {
+				call.Return(nil, errors.New("lookup failed")).Once()
+			} else {
+				call.Return([]string{"pod", "__name__", fmt.Sprintf("label_%02d", i)}, nil).Once()
+			}
+		}
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{
+			"cpu_metric_02": {{Type: "gauge", Unit: "cores"}},
+		}, nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "CPU"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Found 30 metrics matching 'CPU' (substring, case-insensitive) in the last 1h:")
+		assert.Contains(t, output, "Common labels: pod\n\n| Metric Name | Type | Unit | Other Labels |")
+		assert.Contains(t, output, "| cpu_metric_02 | gauge | cores | label_02 |\n| cpu_metric_03 | - | - | - |\n| cpu_metric_04 | - | - | label_04 |")
+		assert.NotContains(t, output, "Values marked with *")
+		assert.NotContains(t, output, "memory_usage")
+		require.Len(t, structured.Metrics, 30)
+		for i, m := range structured.Metrics {
+			assert.Equal(t, fmt.Sprintf("cpu_metric_%02d", i), m.Name)
+		}
+		assert.Nil(t, structured.Metrics[3].Labels)
+		assert.Equal(t, MetricSummary{Name: "cpu_metric_02", Type: "gauge", Unit: "cores", Labels: []string{"label_02", "pod"}}, structured.Metrics[2])
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("limit bounds the lookups", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"cpu_c", "cpu_b", "cpu_a"}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "cpu_a", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"pod"}, nil).Once()
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{}, nil).Once()
+
+		result, _, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Limit: 1})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, `Showing 1–1 of 3 metrics. Pass {"search":"cpu","limit":1,"offset":1} for the next page.`)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("offset pages through the sorted metrics", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"cpu_e", "cpu_c", "cpu_a", "cpu_d", "cpu_b"}, nil)
+		mockClient.On("GetMetricLabels", ctx, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{}, nil)
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{}, nil)
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Limit: 2, Offset: 2})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| cpu_c |")
+		assert.Contains(t, output, "| cpu_d |")
+		assert.NotContains(t, output, "cpu_b")
+		assert.Contains(t, output, `Showing 3–4 of 5 metrics. Pass {"search":"cpu","limit":2,"offset":4} for the next page.`)
+		assert.Equal(t, 2, structured.Offset)
+		assert.Equal(t, 4, structured.NextOffset)
+
+		result, structured, err = tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Limit: 2, Offset: 4})
+
+		require.NoError(t, err)
+		output = result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| cpu_e |")
+		assert.Contains(t, output, "Showing 5–5 of 5 metrics.\n")
+		assert.NotContains(t, output, "next page")
+		assert.Zero(t, structured.NextOffset)
+	})
+
+	t.Run("lookback widens the window", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		twoDays := mock.MatchedBy(func(start time.Time) bool {
+			return time.Since(start) >= 48*time.Hour && time.Since(start) < 49*time.Hour
+		})
+		mockClient.On("ListMetrics", ctx, twoDays, mock.AnythingOfType("time.Time")).Return([]string{"batch_jobs_total"}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "batch_jobs_total", twoDays, mock.AnythingOfType("time.Time")).Return([]string{"job"}, nil).Once()
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{}, nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "batch", Lookback: "2d"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Found 1 metrics matching 'batch' (substring, case-insensitive) in the last 2d:")
+		assert.Equal(t, "2d", structured.Lookback)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid lookbacks", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := map[string]string{
+			"yesterday": "invalid lookback 'yesterday' (expected a duration like '12h' or a number of days like '2d')",
+			"0s":        "invalid lookback '0s': must be positive, e.g. '1h'",
+			"-1h":       "invalid lookback '-1h': must be positive, e.g. '1h'",
+			"8d":        "invalid lookback '8d': must be at most 7d",
+		}
+		for lookback, expected := range tests {
+			_, _, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Lookback: lookback})
+			assert.EqualError(t, err, expected)
+		}
+	})
+
+	t.Run("invalid offsets", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"cpu_a", "cpu_b"}, nil).Once()
+
+		_, _, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Offset: -1})
+		assert.EqualError(t, err, "offset must not be negative, got -1")
+
+		_, _, err = tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu", Offset: 2})
+		assert.EqualError(t, err, "offset 2 is past the last of the 2 metrics")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("labels without a common one", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"cpu_a", "cpu_b"}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "cpu_a", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"pod"}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "cpu_b", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"node"}, nil).Once()
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{}, nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.NotContains(t, output, "Common labels")
+		assert.Contains(t, output, "| Metric Name | Type | Unit | Labels |\n|---|---|---|---|\n| cpu_a | - | - | pod |\n| cpu_b | - | - | node |\n")
+		assert.Nil(t, structured.CommonLabels)
+	})
+
+	t.Run("type and unit are guessed from the name without metadata", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		names := []string{"http_requests_total", "http_request_duration_seconds_bucket", "node_memory_bytes", "up"}
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(names, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"job"}, nil)
+		mockClient.On("GetMetricMetadata", ctx).Return(map[string][]suseobservability.MetricMetadata{
+			"http_request_duration_seconds_bucket": {{Type: "histogram"}},
+		}, nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "_"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Common labels: job\n\n")
+		assert.Contains(t, output, "| http_request_duration_seconds_bucket | histogram | seconds* | (common only) |")
+		assert.Contains(t, output, "| http_requests_total | counter* | - | (common only) |")
+		assert.Contains(t, output, "| node_memory_bytes | gauge* | bytes* | (common only) |")
+		assert.Equal(t, []string{"job"}, structured.CommonLabels)
+		assert.Equal(t, []string{"job"}, structured.Metrics[0].Labels)
+		assert.Contains(t, output, "Values marked with * are guessed from the metric name")
+		assert.True(t, structured.Metrics[0].Guessed)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("failing metadata lookup falls back to the name", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"cpu_seconds_total"}, nil).Once()
+		mockClient.On("GetMetricLabels", ctx, "cpu_seconds_total", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{"pod"}, nil).Once()
+		mockClient.On("GetMetricMetadata", ctx).Return(nil, errors.New("not found")).Once()
+
+		result, _, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| cpu_seconds_total | counter* | seconds* | pod |")
+	})
+
+	t.Run("cancellation stops the lookups", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		mockClient.On("ListMetrics", cancelled, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"cpu_a", "cpu_b"}, nil).Once()
+		mockClient.On("GetMetricLabels", cancelled, mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return(nil, context.Canceled).Maybe()
+
+		result, _, err := tools.ListMetrics(cancelled, nil, ListMetricsParams{Search: "cpu"})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		// The lookback and the fallback window
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"memory_usage"}, nil).Twice()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No metrics found matching 'cpu' (substring, case-insensitive) in the last 1h.", result.Content[0].(*mcp.TextContent).Text)
+		assert.Empty(t, structured.Metrics)
+		assert.Empty(t, structured.StaleMetrics)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("metrics with samples in the fallback window only", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		inLookback := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) < 2*time.Hour })
+		inFallback := mock.MatchedBy(func(start time.Time) bool { return time.Since(start) > 23*time.Hour })
+
+		mockClient.On("ListMetrics", ctx, inLookback, mock.AnythingOfType("time.Time")).
+			Return([]string{"memory_usage"}, nil).Once()
+		mockClient.On("ListMetrics", ctx, inFallback, mock.AnythingOfType("time.Time")).
+			Return([]string{"memory_usage", "cpu_throttled", "cpu_seconds_total"}, nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, `count({__name__="cpu_seconds_total"})`, inFallback, mock.AnythingOfType("time.Time"), "15m", "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+		mockClient.On("QueryRangeMetric", ctx, `count({__name__="cpu_throttled"})`, inFallback, mock.AnythingOfType("time.Time"), "15m", "").
+			Return(func() *suseobservability.MetricQueryResponse {
+				now := time.Now()
+				return &suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: []suseobservability.MetricResult{{
+					Points: []suseobservability.MetricPoint{
+						{Timestamp: now.Add(-6 * time.Hour).Unix(), Value: 2},
+						{Timestamp: now.Add(-5*time.Hour - 10*time.Minute).Unix(), Value: 1},
+					},
+				}}}}
+			}(), nil).Once()
+
+		result, structured, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No metrics found matching 'cpu' (substring, case-insensitive) in the last 1h.\n"+
+			"- Metric 'cpu_seconds_total' exists but has no samples in the last 1h (no sample located).\n"+
+			"- Metric 'cpu_throttled' exists but has no samples in the last 1h (last seen ~5h ago).",
+			result.Content[0].(*mcp.TextContent).Text)
+		require.Len(t, structured.StaleMetrics, 2)
+		assert.Nil(t, structured.StaleMetrics[0].LastSeen)
+		assert.Equal(t, "cpu_throttled", structured.StaleMetrics[1].Name)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("disabled fallback", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		limits := DefaultLimits()
+		limits.MetricFallbackHours = 0
+		tools := NewBaseTool(mockClient).WithLimits(limits)
+
+		mockClient.On("ListMetrics", ctx, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return([]string{"memory_usage"}, nil).Once()
+
+		_, _, err := tools.ListMetrics(ctx, nil, ListMetricsParams{Search: "cpu"})
+
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+}
+
+func TestFormatStaleMetrics(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	lastSeen := now.Add(-26 * time.Hour)
+
+	text := formatStaleMetrics([]StaleMetric{{Name: "cpu", LastSeen: &lastSeen}}, 8, "1h", 48, now)
+
+	assert.Equal(t, "\n- Metric 'cpu' exists but has no samples in the last 1h (last seen ~1d ago).\n- 7 more matching metrics have samples in the last 48h only.", text)
+}
+
+func TestMetricMatcher(t *testing.T) {
+	names := []string{"container_cpu_usage", "CPU_seconds_total", "node_cpu", "cpu"}
+
+	tests := []struct {
+		name     string
+		params   ListMetricsParams
+		expected []string
+		mode     string
+		err      string
+	}{
+		{name: "substring ignores case", params: ListMetricsParams{Search: "CPU"}, expected: names, mode: "substring, case-insensitive"},
+		{name: "regex ignores case by default", params: ListMetricsParams{Search: "^cpu", MatchMode: "regex"}, expected: []string{"CPU_seconds_total", "cpu"}, mode: "regex, case-insensitive"},
+		{name: "case-sensitive regex", params: ListMetricsParams{Search: "^cpu", MatchMode: "regex", CaseSensitive: true}, expected: []string{"cpu"}, mode: "regex, case-sensitive"},
+		{name: "exact", params: ListMetricsParams{Search: "cpu", MatchMode: "exact"}, expected: []string{"cpu"}, mode: "exact"},
+		{name: "invalid regex", params: ListMetricsParams{Search: "cpu(", MatchMode: "regex"}, err: "invalid search regex: error parsing regexp: missing closing ): `cpu(`"},
+		{name: "invalid mode", params: ListMetricsParams{Search: "cpu", MatchMode: "glob"}, err: "invalid match_mode 'glob', must be one of substring, regex or exact"},
+		{name: "case_sensitive outside regex mode", params: ListMetricsParams{Search: "cpu", CaseSensitive: true}, err: "case_sensitive only applies to match_mode 'regex'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := newMetricMatcher(tt.params)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			var matched []string
+			for _, name := range names {
+				if match.matches(name) {
+					matched = append(matched, name)
+				}
+			}
+			assert.Equal(t, tt.expected, matched)
+			assert.Equal(t, tt.mode, match.String())
+		})
+	}
+
+	t.Run("listMetrics states the mode applied", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("ListMetrics", context.Background(), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(names, nil).Once()
+		mockClient.On("GetMetricLabels", context.Background(), "cpu", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]string{}, nil).Once()
+		mockClient.On("GetMetricMetadata", context.Background()).Return(map[string][]suseobservability.MetricMetadata{}, nil).Once()
+
+		result, structured, err := tools.ListMetrics(context.Background(), nil, ListMetricsParams{Search: "cpu", MatchMode: "exact"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Found 1 metrics matching 'cpu' (exact) in the last 1h:")
+		assert.Equal(t, "exact", structured.MatchMode)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("match mode without search", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		_, _, err := tools.ListMetrics(context.Background(), nil, ListMetricsParams{ComponentID: 1, MatchMode: "regex"})
+
+		assert.EqualError(t, err, "match_mode and case_sensitive only apply to search")
+	})
 }
 
 func TestQueryMetric(t *testing.T) {
@@ -108,16 +534,127 @@ func TestQueryMetric(t *testing.T) {
 			},
 		}
 
-		mockClient.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "30s").
+		mockClient.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
 			Return(expectedResponse, nil).Once()
 
-		result, _, err := tools.QueryMetric(ctx, nil, params)
+		result, structured, err := tools.QueryMetric(ctx, nil, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Step: 1m (auto-selected")
 		assert.Contains(t, output, "node_exporter")
-		assert.Contains(t, output, "1.0000")
+		assert.Contains(t, output, "| node_exporter | 1 |")
+		assert.Equal(t, &MetricsResult{
+			Query: query,
+			Step:  "1m",
+			Series: []MetricsSeries{
+				{
+					Labels:     map[string]string{"job": "node_exporter"},
+					Timestamps: []int64{timestamp},
+					Values:     []float64{1.0},
+				},
+			},
+		}, structured)
+	})
+
+	t.Run("long range coarsens the default step", func(t *testing.T) {
+		query := "up"
+		params := QueryMetricParams{
+			Query: query,
+			Start: "168h",
+			End:   "now",
+		}
+
+		mockClient.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1h", "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+
+		result, _, err := tools.QueryMetric(ctx, nil, params)
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Step: 1h")
+	})
+
+	t.Run("transform wraps a counter", func(t *testing.T) {
+		mockClient.On("QueryRangeMetric", ctx, "rate(http_requests_total[2m])", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "http_requests_total", Start: "1h", Transform: "rate"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Step: 1m (auto-selected for at most 200 points per series)\n\nTransform: rate over 2m windows, querying `rate(http_requests_total[2m])`\n\n")
+		assert.Equal(t, "rate(http_requests_total[2m])", structured.Query)
+	})
+
+	t.Run("transform is refused for batched queries", func(t *testing.T) {
+		_, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Queries: []MetricQuery{{Query: "a_total"}}, Start: "1h", Transform: "rate"})
+
+		assert.EqualError(t, err, "transform only applies to query, apply the counter function inside each of queries instead")
+	})
+
+	t.Run("query built from metric and labels", func(t *testing.T) {
+		query := `container_cpu_usage_seconds_total{namespace="shop", pod=~"checkout-.*"}`
+		mockClient.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{
+			Metric: "container_cpu_usage_seconds_total",
+			Labels: map[string]string{"pod": "checkout-*", "namespace": "shop"},
+			Start:  "1h",
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Query: `"+query+"`\n\n")
+		assert.Equal(t, query, structured.Query)
+	})
+
+	t.Run("metric is refused with query", func(t *testing.T) {
+		_, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Metric: "up", Start: "1h"})
+
+		assert.EqualError(t, err, "metric and labels build the query, pick either them or query and queries, not both")
+	})
+
+	t.Run("end defaults to now", func(t *testing.T) {
+		mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.MatchedBy(func(end time.Time) bool {
+			return time.Since(end) < time.Minute
+		}), "1m", "").Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+
+		_, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid time ranges", func(t *testing.T) {
+		tests := []struct {
+			params QueryMetricParams
+			err    string
+		}{
+			{
+				QueryMetricParams{Query: "up", Start: "1h", End: "2h"},
+				"start '1h' must be before end '2h'. Durations look back from now, so start needs the longer one, e.g. start '2h' and end '1h'",
+			},
+			{
+				QueryMetricParams{Query: "up", Start: "now"},
+				"start 'now' must be before end 'now'. Durations look back from now, so start needs the longer one, e.g. start '2h' and end '1h'",
+			},
+			{
+				QueryMetricParams{Query: "up", Start: "720h"},
+				"time range of 30d is longer than the maximum of 7d for raw points. Use mode 'summary' for the statistics of a longer range, or shorten it",
+			},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.QueryMetric(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+
+	t.Run("summary mode is not limited to the maximum range", func(t *testing.T) {
+		mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), mock.AnythingOfType("string"), "").
+			Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+
+		_, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "720h", Mode: "summary"})
+
+		assert.NoError(t, err)
 	})
 
 	t.Run("parsing error", func(t *testing.T) {
@@ -132,4 +669,281 @@ func TestQueryMetric(t *testing.T) {
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "failed to parse start time")
 	})
+
+	t.Run("json format", func(t *testing.T) {
+		query := "up"
+		params := QueryMetricParams{
+			Query:  query,
+			Start:  "1h",
+			End:    "now",
+			Format: "json",
+		}
+
+		expectedResponse := &suseobservability.MetricQueryResponse{
+			Data: suseobservability.MetricData{
+				Result: []suseobservability.MetricResult{
+					{
+						Labels: map[string]string{"job": "node_exporter"},
+						Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1.0}},
+					},
+				},
+			},
+		}
+
+		mockClient.On("QueryRangeMetric", ctx, query, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(expectedResponse, nil).Once()
+
+		result, _, err := tools.QueryMetric(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.JSONEq(t, `[{"labels":{"job":"node_exporter"},"points":[[1700000000,1]]}]`, output)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		params := QueryMetricParams{
+			Query:  "up",
+			Start:  "1h",
+			End:    "now",
+			Format: "xml",
+		}
+
+		result, _, err := tools.QueryMetric(ctx, nil, params)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "invalid format 'xml'")
+	})
+
+	t.Run("summary mode", func(t *testing.T) {
+		params := QueryMetricParams{
+			Query: "cpu",
+			Start: "1h",
+			End:   "now",
+			Mode:  "summary",
+		}
+
+		expectedResponse := &suseobservability.MetricQueryResponse{
+			Data: suseobservability.MetricData{
+				Result: []suseobservability.MetricResult{
+					{
+						Labels: map[string]string{"pod": "a"},
+						Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 0.5}, {Timestamp: 1700000060, Value: 1.5}},
+					},
+				},
+			},
+		}
+
+		mockClient.On("QueryRangeMetric", ctx, "cpu", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(expectedResponse, nil).Once()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Step: 1m")
+		assert.Contains(t, output, "| 2 | 0.5 | 1.5 | 1 |")
+		assert.Len(t, structured.Series, 1)
+		assert.Len(t, structured.Stats, 1)
+		assert.Equal(t, 1.5, structured.Stats[0].Last)
+	})
+
+	t.Run("rank_by keeps the top series", func(t *testing.T) {
+		var results []suseobservability.MetricResult
+		for i, pod := range []string{"a", "b", "c", "d"} {
+			results = append(results, suseobservability.MetricResult{
+				Labels: map[string]string{"pod": pod},
+				Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: float64(10 * (i % 3))}, {Timestamp: 1700000060, Value: float64(i)}},
+			})
+		}
+		mockClient.On("QueryRangeMetric", ctx, "restarts", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: results}}, nil).Twice()
+
+		result, structured, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "restarts", Start: "1h", End: "now", Mode: "summary", MaxSeries: 2, RankBy: "max"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| 2 | 2 | 20 | 11 | 11 | 19.1 | 2 | c |\n")
+		assert.Contains(t, output, "| 2 | 1 | 10 | 5.5 | 5.5 | 9.55 | 1 | b |\n")
+		assert.NotContains(t, output, "| a |")
+		assert.Contains(t, output, "Output truncated: showing the top 2 of 4 series by max value, the 2 series with lower values are dropped.")
+		require.Len(t, structured.Stats, 4)
+		assert.Equal(t, "c", structured.Stats[0].Labels["pod"])
+		assert.Equal(t, "a", structured.Series[3].Labels["pod"])
+
+		result, _, err = tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "restarts", Start: "1h", End: "now", MaxSeries: 1, RankBy: "last"})
+
+		require.NoError(t, err)
+		output = result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| d |")
+		assert.NotContains(t, output, "| c |")
+		assert.Contains(t, output, "Output truncated: showing the top 1 of 4 series by last value, the 3 series with lower values are dropped, 3 series and 6 points omitted")
+	})
+
+	t.Run("pivot layout is capped by the configured series limit", func(t *testing.T) {
+		var results []suseobservability.MetricResult
+		for _, pod := range []string{"a", "b", "c"} {
+			results = append(results, suseobservability.MetricResult{
+				Labels: map[string]string{"pod": pod},
+				Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1}},
+			})
+		}
+		mockClient.On("QueryRangeMetric", ctx, "pod_cpu", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: results}}, nil).Twice()
+		limits := DefaultLimits()
+		limits.MetricPivotMaxSeries = 2
+		pivotTools := NewBaseTool(mockClient).WithLimits(limits)
+
+		_, _, err := pivotTools.QueryMetric(ctx, nil, QueryMetricParams{Query: "pod_cpu", Start: "1h", End: "now", Layout: "pivot"})
+		assert.ErrorContains(t, err, "layout 'pivot' aligns at most 2 series, the query returned 3.")
+
+		result, _, err := pivotTools.QueryMetric(ctx, nil, QueryMetricParams{Query: "pod_cpu", Start: "1h", End: "now", Layout: "pivot", MaxSeries: 2})
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| Timestamp | a | b |\n|---|---|---|\n| 2023-11-14T22:13:20Z | 1 | 1 |\n")
+	})
+
+	t.Run("invalid layout", func(t *testing.T) {
+		_, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h", End: "now", Layout: "table"})
+		assert.EqualError(t, err, "invalid layout 'table'. Must be 'flat', 'grouped' or 'pivot'")
+
+		_, _, err = tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h", End: "now", Layout: "grouped", Format: "csv"})
+		assert.EqualError(t, err, "layout only applies to markdown output in raw mode")
+
+		_, _, err = tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h", End: "now", Layout: "flat", Mode: "summary"})
+		assert.EqualError(t, err, "layout only applies to markdown output in raw mode")
+	})
+
+	t.Run("invalid rank_by", func(t *testing.T) {
+		result, _, err := tools.QueryMetric(ctx, nil, QueryMetricParams{Query: "up", Start: "1h", End: "now", RankBy: "min"})
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "invalid rank_by 'min'. Must be 'max', 'last' or 'avg'")
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		params := QueryMetricParams{
+			Query: "up",
+			Start: "1h",
+			End:   "now",
+			Mode:  "stats",
+		}
+
+		result, _, err := tools.QueryMetric(ctx, nil, params)
+
+		assert.Nil(t, result)
+		assert.EqualError(t, err, "invalid mode 'stats'. Must be 'raw' or 'summary'")
+	})
+}
+
+func TestMetricToolsStructuredContent(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "listMetrics"}, tools.ListMetrics)
+	mcp.AddTool(server, &mcp.Tool{Name: "getMetrics"}, tools.QueryMetric)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	t.Run("tools declare an output schema", func(t *testing.T) {
+		listed, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, listed.Tools, 2)
+		for _, tool := range listed.Tools {
+			assert.NotNil(t, tool.OutputSchema, tool.Name)
+		}
+	})
+
+	t.Run("getMetrics returns series as structured content", func(t *testing.T) {
+		mockClient.On("QueryRangeMetric", mock.Anything, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{
+				Data: suseobservability.MetricData{
+					Result: []suseobservability.MetricResult{
+						{Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 0.5}}},
+					},
+				},
+			}, nil).Once()
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "getMetrics",
+			Arguments: map[string]any{"query": "up", "start": "1h", "end": "now"},
+		})
+
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| 0.5 |")
+		structured, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"query":"up","step":"1m","series":[{"labels":{},"timestamps":[1700000000],"values":[0.5]}]}`, string(structured))
+	})
+
+	t.Run("listMetrics returns an empty list as structured content", func(t *testing.T) {
+		mockClient.On("GetBoundMetricsWithData", mock.Anything, int64(1), mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+			Return(&suseobservability.BoundMetricsResponse{}, nil).Once()
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "listMetrics",
+			Arguments: map[string]any{"component_id": 1},
+		})
+
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		structured, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"component_id":1,"lookback":"1h","total":0,"metrics":[]}`, string(structured))
+	})
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+		err   string
+	}{
+		{input: "now", want: now},
+		{input: "1h", want: now.Add(-time.Hour)},
+		{input: "90m", want: now.Add(-90 * time.Minute)},
+		{input: "2d", want: now.Add(-48 * time.Hour)},
+		{input: "now-6h", want: now.Add(-6 * time.Hour)},
+		{input: "now-1h30m", want: now.Add(-90 * time.Minute)},
+		{input: "now-7d", want: now.Add(-7 * 24 * time.Hour)},
+		{input: "now/d", want: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{input: "now-1d/d", want: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{input: "now-13h/d", want: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{input: "36h/d", want: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{input: "now+1h", err: "invalid time format: now+1h (times in the future are not supported"},
+		{input: "now+1h/d", err: "times in the future are not supported"},
+		{input: "now-", err: "invalid time format: now- (expected 'now', a duration like '1h' or 'now-1h'"},
+		{input: "now-0s", err: "invalid time format: now-0s"},
+		{input: "now--1h", err: "invalid time format: now--1h"},
+		{input: "now-1h/h", err: "invalid time format: now-1h/h"},
+		{input: "now-1h-30m", err: "invalid time format: now-1h-30m"},
+		{input: "now1h", err: "invalid time format: now1h"},
+		{input: "-1h", err: "invalid time format: -1h"},
+		{input: "0s", err: "invalid time format: 0s"},
+		{input: "yesterday", err: "invalid time format: yesterday"},
+		{input: "", err: "invalid time format: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseTime(tt.input, now)
+
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
 }
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Directions of a getMetrics threshold
+const (
+	thresholdAbove = "above"
+	thresholdBelow = "below"
+)
+
+// metricThreshold flags the values of a getMetrics query breaching a threshold, e.g. of an SLO
+type metricThreshold struct {
+	Value float64
+	// Direction is thresholdAbove when values greater than Value breach it, thresholdBelow
+	// when smaller values do
+	Direction string
+	// Step and Window are the resolution and time range of the query, each breaching sample
+	// counts for a step of the window
+	Step   time.Duration
+	Window time.Duration
+}
+
+// ThresholdBreach counts the samples of a series breaching the threshold of a getMetrics call.
+// First and Last are the timestamps of the first and last breaching sample, unset without
+// breaches. Percent is the share of the window spent breaching, each sample counting for a step.
+type ThresholdBreach struct {
+	Labels   map[string]string `json:"labels"`
+	Breaches int               `json:"breaches"`
+	First    int64             `json:"first,omitempty"`
+	Last     int64             `json:"last,omitempty"`
+	Percent  float64           `json:"percent_of_window"`
+}
+
+// breached tells whether a value breaches the threshold. NaN and ±Inf never do, and nothing
+// does without a threshold.
+func (th *metricThreshold) breached(v float64) bool {
+	if th == nil || !isFinite(v) {
+		return false
+	}
+	if th.Direction == thresholdBelow {
+		return v < th.Value
+	}
+	return v > th.Value
+}
+
+// String renders the threshold like '> 0.9'
+func (th *metricThreshold) String() string {
+	op := ">"
+	if th.Direction == thresholdBelow {
+		op = "<"
+	}
+	return op + " " + formatValue(th.Value)
+}
+
+// seriesBreaches counts the breaching samples of every series
+func (th *metricThreshold) seriesBreaches(series []Series) []ThresholdBreach {
+	breaches := make([]ThresholdBreach, 0, len(series))
+	for _, s := range series {
+		b := ThresholdBreach{Labels: s.Labels}
+		for _, p := range s.sorted() {
+			if !th.breached(p.Value) {
+				continue
+			}
+			if b.Breaches == 0 {
+				b.First = p.Timestamp
+			}
+			b.Breaches++
+			b.Last = p.Timestamp
+		}
+		b.Percent = th.percent(b.Breaches, s)
+		breaches = append(breaches, b)
+	}
+	return breaches
+}
+
+// percent returns the share of the window spent breaching, at most 100. Without a window
+// the breaching samples are counted against all samples of the series.
+func (th *metricThreshold) percent(breaches int, s Series) float64 {
+	if breaches == 0 {
+		return 0
+	}
+	if th.Step <= 0 || th.Window <= 0 {
+		return 100 * float64(breaches) / float64(len(s.Points))
+	}
+	return min(100, 100*float64(breaches)*th.Step.Seconds()/th.Window.Seconds())
+}
+
+// note summarizes the breaches of the rendered series below their table. They are counted on
+// the full series, so points dropped by the row cap still count; the kept series are a prefix
+// of all, as returned by capMetrics.
+func (th *metricThreshold) note(kept, all []Series) string {
+	if th == nil {
+		return ""
+	}
+	breaches := th.seriesBreaches(all)
+	breaching, hidden := 0, 0
+	for i, b := range breaches {
+		if b.Breaches == 0 {
+			continue
+		}
+		breaching++
+		if i >= len(kept) {
+			hidden++
+		}
+	}
+
+	var sb strings.Builder
+	if breaching == 0 {
+		sb.WriteString(fmt.Sprintf("\nThreshold %s: no series breaches it.\n", th))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("\nThreshold %s: %d of %d series breach it.\n", th, breaching, len(all)))
+	for _, b := range breaches[:len(kept)] {
+		if b.Breaches == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`: %d breaching sample(s), %.1f%% of the window, first at %s, last at %s\n",
+			seriesName(b.Labels), b.Breaches, b.Percent,
+			formatUnix(b.First), formatUnix(b.Last)))
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("- %d more breaching series are not shown, raise max_series or rank them with rank_by\n", hidden))
+	}
+	return sb.String()
+}
+
+// marker renders the Breach cell of a value
+func (th *metricThreshold) marker(v float64) string {
+	if th.breached(v) {
+		return "breach"
+	}
+	return "-"
+}
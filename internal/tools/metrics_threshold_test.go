@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// thresholdSeries returns an error ratio of two pods over 4 minutes, api-1 breaching 0.05
+// twice and api-2 never
+func thresholdSeries() []Series {
+	points := func(values ...float64) []Point {
+		p := make([]Point, len(values))
+		for i, v := range values {
+			p[i] = Point{Timestamp: 1700000000 + int64(i)*60, Value: v}
+		}
+		return p
+	}
+	return []Series{
+		{Labels: map[string]string{"pod": "api-1"}, Points: points(0.01, 0.08, math.NaN(), 0.2)},
+		{Labels: map[string]string{"pod": "api-2"}, Points: points(0.01, 0.02, 0.03, 0.04)},
+	}
+}
+
+func TestMetricThreshold(t *testing.T) {
+	t.Run("breached", func(t *testing.T) {
+		above := &metricThreshold{Value: 1, Direction: thresholdAbove}
+		below := &metricThreshold{Value: 1, Direction: thresholdBelow}
+		var none *metricThreshold
+
+		assert.True(t, above.breached(1.5))
+		assert.False(t, above.breached(1), "the threshold itself is not a breach")
+		assert.False(t, above.breached(math.Inf(1)))
+		assert.True(t, below.breached(0.5))
+		assert.False(t, below.breached(math.NaN()))
+		assert.False(t, none.breached(5))
+		assert.Equal(t, "> 1", above.String())
+		assert.Equal(t, "< 1", below.String())
+	})
+
+	t.Run("breaches per series", func(t *testing.T) {
+		th := &metricThreshold{Value: 0.05, Direction: thresholdAbove, Step: time.Minute, Window: 10 * time.Minute}
+
+		breaches := th.seriesBreaches(thresholdSeries())
+
+		require.Len(t, breaches, 2)
+		assert.Equal(t, ThresholdBreach{Labels: map[string]string{"pod": "api-1"}, Breaches: 2, First: 1700000060, Last: 1700000180, Percent: 20}, breaches[0])
+		assert.Equal(t, ThresholdBreach{Labels: map[string]string{"pod": "api-2"}}, breaches[1])
+	})
+
+	t.Run("without a window breaches count against the samples", func(t *testing.T) {
+		th := &metricThreshold{Value: 0.05, Direction: thresholdAbove}
+
+		assert.Equal(t, 50.0, th.seriesBreaches(thresholdSeries())[0].Percent)
+	})
+
+	t.Run("the share of the window is at most 100", func(t *testing.T) {
+		th := &metricThreshold{Value: 0, Direction: thresholdAbove, Step: time.Minute, Window: time.Minute}
+
+		assert.Equal(t, 100.0, th.seriesBreaches(thresholdSeries())[1].Percent)
+	})
+}
+
+func TestFormatMetricsThreshold(t *testing.T) {
+	th := &metricThreshold{Value: 0.05, Direction: thresholdAbove, Step: time.Minute, Window: 10 * time.Minute}
+	ts := func(i int64) string { return formatUnix(1700000000 + i*60) }
+	footer := "\nThreshold > 0.05: 1 of 2 series breach it.\n" +
+		"- `{pod=\"api-1\"}`: 2 breaching sample(s), 20.0% of the window, first at " + ts(1) + ", last at " + ts(3) + "\n"
+
+	t.Run("flat", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, Layout: layoutFlat, Threshold: th})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| Timestamp | Value | Breach | pod |\n|---|---|---|---|\n")
+		assert.Contains(t, output, "| "+ts(0)+" | 0.01 | - | api-1 |\n")
+		assert.Contains(t, output, "| "+ts(1)+" | 0.08 | breach | api-1 |\n")
+		assert.Contains(t, output, "| "+ts(2)+" | no data | - | api-1 |\n")
+		assert.Contains(t, output, footer)
+	})
+
+	t.Run("grouped", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, Layout: layoutGrouped, Threshold: th})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| Timestamp | Value | Breach |\n|---|---|---|\n| "+ts(0)+" | 0.01 | - |\n| "+ts(1)+" | 0.08 | breach |\n")
+		assert.Contains(t, output, footer)
+	})
+
+	t.Run("pivot", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, Layout: layoutPivot, Threshold: th})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| "+ts(3)+" | 0.2 (breach) | 0.04 |\n")
+		assert.Contains(t, output, footer)
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, Mode: modeSummary, Threshold: th})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "| Last | Time > 0.05 | pod |\n")
+		assert.Contains(t, output, "| 0.2 | 20.0% | api-1 |\n")
+		assert.Contains(t, output, "| 0.04 | 0.0% | api-2 |\n")
+		assert.Contains(t, output, footer)
+	})
+
+	t.Run("no breach", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, Threshold: &metricThreshold{Value: 1, Direction: thresholdAbove}})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "\nThreshold > 1: no series breaches it.\n")
+	})
+
+	t.Run("breaching series beyond max_series are counted", func(t *testing.T) {
+		below := &metricThreshold{Value: 0.05, Direction: thresholdBelow}
+
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown, MaxSeries: 1, Threshold: below})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "\nThreshold < 0.05: 2 of 2 series breach it.\n- `{pod=\"api-1\"}`: 1 breaching sample(s)")
+		assert.Contains(t, output, "- 1 more breaching series are not shown, raise max_series or rank them with rank_by\n")
+	})
+
+	t.Run("without threshold the tables are unchanged", func(t *testing.T) {
+		output, err := formatMetrics(thresholdSeries(), "q", metricsFormat{Format: formatMarkdown})
+		require.NoError(t, err)
+
+		assert.NotContains(t, output, "Breach")
+		assert.NotContains(t, output, "Threshold")
+	})
+}
+
+func TestQueryMetricThreshold(t *testing.T) {
+	ctx := context.Background()
+	threshold := 0.5
+
+	t.Run("breaches in the structured content", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("QueryRangeMetric", ctx, "up", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1m", "").
+			Return(&suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{Result: []suseobservability.MetricResult{{
+				Labels: map[string]string{"job": "api"},
+				Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1}, {Timestamp: 1700000060, Value: 0}},
+			}}}}, nil)
+		tools := NewBaseTool(mockClient)
+
+		result, structured, err := tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "up", Start: "1h", Threshold: &threshold, ThresholdDirection: thresholdBelow})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Threshold < 0.5: 1 of 1 series breach it.")
+		require.Len(t, structured.Breaches, 1)
+		assert.Equal(t, 1, structured.Breaches[0].Breaches)
+		assert.Equal(t, int64(1700000060), structured.Breaches[0].First)
+		assert.InDelta(t, 100.0/60, structured.Breaches[0].Percent, 0.01)
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		_, _, err := tools.QueryMetric(ctx, &mcp.CallToolRequest{}, QueryMetricParams{Query: "up", Start: "1h", Threshold: &threshold, ThresholdDirection: "over"})
+
+		assert.EqualError(t, err, "invalid threshold_direction 'over'. Must be 'above' or 'below'")
+	})
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minTransformWindow is the shortest window a transform is computed over, so that the window
+// holds several samples at common scrape intervals even for fine steps
+const minTransformWindow = 2 * time.Minute
+
+// metricTransforms are the counter functions getMetrics can wrap a metric selector with
+var metricTransforms = []string{"rate", "increase", "irate"}
+
+// applyTransform wraps a plain metric selector with the transform function over a window of
+// the step, at least minTransformWindow, so that counters are rendered as their rate or
+// increase per step instead of their ever growing value. Queries that are not a plain
+// selector, in particular queries already calling a function, are rejected.
+func applyTransform(transform, query, step string) (string, time.Duration, error) {
+	if !containsFold(metricTransforms, transform) {
+		return "", 0, fmt.Errorf("invalid transform '%s'. Must be 'rate', 'increase' or 'irate'", transform)
+	}
+	transform = strings.ToLower(transform)
+	query = strings.TrimSpace(query)
+	if err := plainSelector(transform, query); err != nil {
+		return "", 0, err
+	}
+
+	window, err := parseStep(step)
+	if err != nil {
+		return "", 0, err
+	}
+	window = max(window, minTransformWindow)
+	return fmt.Sprintf("%s(%s[%s])", transform, query, formatStep(window)), window, nil
+}
+
+// plainSelector checks that query is a single vector selector such as
+// 'http_requests_total{job="api"}', without function calls, operators or modifiers
+func plainSelector(transform, query string) error {
+	tokens, err := lexPromQL(query)
+	if err != nil {
+		return fmt.Errorf("transform '%s' needs a valid metric selector: %w", transform, err)
+	}
+	for i, t := range tokens[:len(tokens)-1] {
+		next := tokens[i+1]
+		if t.kind == tokenIdent && (next.isOp("(") || next.isKeyword("by") || next.isKeyword("without")) {
+			return fmt.Errorf("transform '%s' only wraps a plain metric selector, but the query already calls %s(). "+
+				"Remove transform, or apply the counter function inside the query instead, e.g. sum(rate(metric[5m]))", transform, t.text)
+		}
+	}
+
+	result, selectors, err := parsePromQL(query)
+	if err != nil {
+		return fmt.Errorf("transform '%s' needs a valid metric selector: %w", transform, err)
+	}
+	// A lone selector is a metric name and its matchers, nothing follows the closing brace
+	end := 1
+	if tokens[0].isOp("{") {
+		end = 0
+	}
+	if end < len(tokens) && tokens[end].isOp("{") {
+		for end < len(tokens) && !tokens[end].isOp("}") {
+			end++
+		}
+		end++
+	}
+	if result != promqlVector || len(selectors) != 1 || end >= len(tokens) || tokens[end].kind != tokenEOF {
+		return fmt.Errorf("transform '%s' only wraps a plain metric selector such as 'http_requests_total{job=\"api\"}', got '%s'", transform, query)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform string
+		query     string
+		step      string
+		expected  string
+		window    time.Duration
+	}{
+		{"rate of a metric name", "rate", "http_requests_total", "5m", "rate(http_requests_total[5m])", 5 * time.Minute},
+		{"increase with matchers", "increase", ` kube_pod_container_status_restarts_total{namespace="shop", pod=~"api-.*"} `, "1h",
+			`increase(kube_pod_container_status_restarts_total{namespace="shop", pod=~"api-.*"}[1h])`, time.Hour},
+		{"fine steps use the minimum window", "irate", "node_cpu_seconds_total", "15s", "irate(node_cpu_seconds_total[2m])", 2 * time.Minute},
+		{"case-insensitive transform and a selector without name", "Rate", `{__name__="up"}`, "90", `rate({__name__="up"}[2m])`, 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, window, err := applyTransform(tt.transform, tt.query, tt.step)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+			assert.Equal(t, tt.window, window)
+		})
+	}
+}
+
+func TestApplyTransformRejections(t *testing.T) {
+	tests := []struct {
+		transform string
+		query     string
+		err       string
+	}{
+		{"delta", "up", "invalid transform 'delta'. Must be 'rate', 'increase' or 'irate'"},
+		{"rate", "rate(http_requests_total[5m])",
+			"transform 'rate' only wraps a plain metric selector, but the query already calls rate(). Remove transform, or apply the counter function inside the query instead, e.g. sum(rate(metric[5m]))"},
+		{"increase", "sum by (pod) (restarts_total)",
+			"transform 'increase' only wraps a plain metric selector, but the query already calls sum(). Remove transform, or apply the counter function inside the query instead, e.g. sum(rate(metric[5m]))"},
+		{"rate", "http_requests_total[5m]", `transform 'rate' only wraps a plain metric selector such as 'http_requests_total{job="api"}', got 'http_requests_total[5m]'`},
+		{"rate", "errors_total / requests_total", `transform 'rate' only wraps a plain metric selector such as 'http_requests_total{job="api"}', got 'errors_total / requests_total'`},
+		{"rate", "requests_total offset 1h", `transform 'rate' only wraps a plain metric selector such as 'http_requests_total{job="api"}', got 'requests_total offset 1h'`},
+		{"rate", `requests_total{job="api"`, `transform 'rate' needs a valid metric selector: unexpected end of input in label matching, expected "," or "}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, _, err := applyTransform(tt.transform, tt.query, "1m")
+
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
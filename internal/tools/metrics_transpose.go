@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxTransposedTimestamps is the number of timestamps above which the transposed table is
+// refused, each is a column
+const maxTransposedTimestamps = 10
+
+// metricTimestamps returns the distinct timestamps of the points of series, in order
+func metricTimestamps(series []Series) []int64 {
+	var timestamps []int64
+	seen := map[int64]bool{}
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !seen[p.Timestamp] {
+				seen[p.Timestamp] = true
+				timestamps = append(timestamps, p.Timestamp)
+			}
+		}
+	}
+	sort.Slice(timestamps, func(a, b int) bool { return timestamps[a] < timestamps[b] })
+	return timestamps
+}
+
+// formatMetricsTransposed renders one row per series with its labels and a value column per
+// timestamp, the transpose of the pivot layout. With a single timestamp, e.g. of a query
+// over a range shorter than its step, the table lists the label sets with their value and
+// the timestamp is stated above it. Values at a counter reset or breaching th, if any, are
+// annotated like in the pivot layout.
+func formatMetricsTransposed(series []Series, queryName string, resets []map[int64]bool, th *metricThreshold, maxLabelColumns int) (string, error) {
+	if len(series) == 0 {
+		return fmt.Sprintf("No data found for query: %s", queryName), nil
+	}
+	timestamps := metricTimestamps(series)
+	if len(timestamps) > maxTransposedTimestamps {
+		return "", fmt.Errorf("transpose renders a column per timestamp, at most %d, the result has %d. "+
+			"Use a coarser step or a shorter range, or leave transpose unset", maxTransposedTimestamps, len(timestamps))
+	}
+
+	columns := splitLabelColumns(series, maxLabelColumns)
+	nameColumn, nameHeader := metricNameColumn(series)
+
+	var sb strings.Builder
+	sb.WriteString(nameHeader)
+	sb.WriteString(columns.note())
+	if len(timestamps) == 1 {
+		sb.WriteString(fmt.Sprintf("Values at %s:\n\n", formatUnix(timestamps[0])))
+	}
+
+	sb.WriteString("|")
+	if nameColumn {
+		sb.WriteString(" Metric |")
+	}
+	sb.WriteString(columns.header())
+	if len(timestamps) == 1 {
+		sb.WriteString(" Value |")
+	} else {
+		for _, ts := range timestamps {
+			sb.WriteString(fmt.Sprintf(" %s |", formatUnix(ts)))
+		}
+	}
+	sb.WriteString("\n|")
+	if nameColumn {
+		sb.WriteString("---|")
+	}
+	sb.WriteString(columns.separator())
+	sb.WriteString(strings.Repeat("---|", max(1, len(timestamps))))
+	sb.WriteString("\n")
+
+	for i, s := range series {
+		values := make(map[int64]float64, len(s.Points))
+		for _, p := range s.Points {
+			values[p.Timestamp] = p.Value
+		}
+		sb.WriteString("|")
+		if nameColumn {
+			sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(s.Labels["__name__"])))
+		}
+		sb.WriteString(columns.cells(s.Labels))
+		for _, ts := range timestamps {
+			v, ok := values[ts]
+			if !ok {
+				sb.WriteString(" - |")
+				continue
+			}
+			var notes []string
+			if resets[i][ts] {
+				notes = append(notes, "counter reset")
+			}
+			if th.breached(v) {
+				notes = append(notes, "breach")
+			}
+			if len(notes) > 0 {
+				sb.WriteString(fmt.Sprintf(" %s (%s) |", s.format(v), strings.Join(notes, ", ")))
+			} else {
+				sb.WriteString(fmt.Sprintf(" %s |", s.format(v)))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(nonFiniteNote(series))
+
+	return sb.String(), nil
+}
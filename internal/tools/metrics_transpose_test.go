@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// instantSeries returns n pods of up, all sampled at 1700000000
+func instantSeries(n int) []Series {
+	var series []Series
+	for i := range n {
+		series = append(series, Series{
+			Labels: map[string]string{"__name__": "up", "pod": fmt.Sprintf("api-%d", i)},
+			Points: []Point{{Timestamp: 1700000000, Value: float64(i % 2)}},
+		})
+	}
+	return series
+}
+
+func TestFormatMetricsTransposed(t *testing.T) {
+	t.Run("a single timestamp is transposed unless a layout is requested", func(t *testing.T) {
+		for _, n := range []int{2, 5} {
+			output, err := formatMetrics(instantSeries(n), "up", metricsFormat{Format: formatMarkdown})
+
+			require.NoError(t, err)
+			assert.Contains(t, output, "Metric: `up`\n\nValues at "+formatUnix(1700000000)+":\n\n| pod | Value |\n|---|---|\n| api-0 | 0 |\n| api-1 | 1 |\n")
+			assert.NotContains(t, output, "Series `")
+		}
+
+		output, err := formatMetrics(instantSeries(5), "up", metricsFormat{Format: formatMarkdown, Layout: layoutFlat})
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "| Timestamp | Value | pod |\n")
+	})
+
+	t.Run("false keeps the row per timestamp", func(t *testing.T) {
+		untransposed := false
+
+		output, err := formatMetrics(instantSeries(2), "up", metricsFormat{Format: formatMarkdown, Transpose: &untransposed})
+		require.NoError(t, err)
+		assert.Contains(t, output, "| Timestamp | Value | pod |\n")
+
+		output, err = formatMetrics(instantSeries(5), "up", metricsFormat{Format: formatMarkdown, Transpose: &untransposed})
+		require.NoError(t, err)
+		assert.Contains(t, output, "Series `up{pod=\"api-4\"}` (1 point(s))")
+	})
+
+	t.Run("several timestamps are transposed only on request", func(t *testing.T) {
+		counters := []Series{
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "a"}, Points: []Point{{1700000000, 10}, {1700000060, 2}}},
+			{Labels: map[string]string{"__name__": "http_requests_total", "pod": "b"}, Points: []Point{{1700000060, 3}}},
+		}
+
+		output, err := formatMetrics(counters, "http_requests_total", metricsFormat{Format: formatMarkdown})
+		require.NoError(t, err)
+		assert.Contains(t, output, "| Timestamp | Value | pod |")
+
+		transposed := true
+		output, err = formatMetrics(counters, "http_requests_total", metricsFormat{Format: formatMarkdown, Transpose: &transposed})
+		require.NoError(t, err)
+		assert.Contains(t, output, "| pod | "+formatUnix(1700000000)+" | "+formatUnix(1700000060)+" |\n|---|---|---|\n"+
+			"| a | 10 | 2 (counter reset) |\n"+
+			"| b | - | 3 |\n")
+		assert.NotContains(t, output, "Values at")
+	})
+
+	t.Run("metric name and breaches", func(t *testing.T) {
+		multi := []Series{
+			{Labels: map[string]string{"__name__": "up", "job": "x"}, Points: []Point{{1700000000, 1}}},
+			{Labels: map[string]string{"__name__": "scrape_duration_seconds", "job": "x"}, Points: []Point{{1700000000, 0.2}}},
+		}
+
+		output, err := formatMetrics(multi, `{job="x"}`, metricsFormat{Format: formatMarkdown, Threshold: &metricThreshold{Value: 0.5, Direction: thresholdBelow}})
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "| Metric | job | Value |\n|---|---|---|\n| up | x | 1 |\n| scrape_duration_seconds | x | 0.2 (breach) |\n")
+	})
+
+	t.Run("too many timestamps", func(t *testing.T) {
+		var points []Point
+		for i := range maxTransposedTimestamps + 1 {
+			points = append(points, Point{Timestamp: 1700000000 + int64(i)*60, Value: 1})
+		}
+		transposed := true
+
+		_, err := formatMetrics([]Series{{Labels: map[string]string{"pod": "a"}, Points: points}}, "up", metricsFormat{Format: formatMarkdown, Transpose: &transposed})
+
+		assert.EqualError(t, err, "transpose renders a column per timestamp, at most 10, the result has 11. Use a coarser step or a shorter range, or leave transpose unset")
+	})
+}
+
+func TestQueryMetricTranspose(t *testing.T) {
+	ctx := context.Background()
+	transposed, untransposed := true, false
+	tests := []struct {
+		name   string
+		params QueryMetricParams
+		err    string
+	}{
+		{"csv", QueryMetricParams{Format: "csv", Transpose: &untransposed}, "transpose only applies to markdown output in raw mode, without latest_only"},
+		{"summary", QueryMetricParams{Mode: "summary", Transpose: &transposed}, "transpose only applies to markdown output in raw mode, without latest_only"},
+		{"latest only", QueryMetricParams{LatestOnly: true, Transpose: &transposed}, "transpose only applies to markdown output in raw mode, without latest_only"},
+		{"with a layout", QueryMetricParams{Layout: layoutPivot, Transpose: &transposed}, "transpose renders its own table, it does not combine with layout 'pivot'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.params.Query, tt.params.Start = "up", "1h"
+
+			_, _, err := NewBaseTool(new(MockSuseObservabilityClient)).QueryMetric(ctx, &mcp.CallToolRequest{}, tt.params)
+
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
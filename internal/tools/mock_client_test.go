@@ -11,6 +11,8 @@ import (
 
 type MockSuseObservabilityClient struct {
 	mock.Mock
+	// fetchedPages counts the check state pages handed out by EachMonitorCheckStatePage
+	fetchedPages int
 }
 
 func (m *MockSuseObservabilityClient) GetBoundMetricsWithData(ctx context.Context, componentID int64, start, end time.Time) (*suseobservability.BoundMetricsResponse, error) {
@@ -21,6 +23,46 @@ func (m *MockSuseObservabilityClient) GetBoundMetricsWithData(ctx context.Contex
 	return args.Get(0).(*suseobservability.BoundMetricsResponse), args.Error(1)
 }
 
+func (m *MockSuseObservabilityClient) ListMetrics(ctx context.Context, start, end time.Time) ([]string, error) {
+	args := m.Called(ctx, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMetricMetadata(ctx context.Context) (map[string][]suseobservability.MetricMetadata, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]suseobservability.MetricMetadata), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMetricLabels(ctx context.Context, metric string, start, end time.Time) ([]string, error) {
+	args := m.Called(ctx, metric, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMetricLabelValues(ctx context.Context, metric, label string, start, end time.Time) ([]string, error) {
+	args := m.Called(ctx, metric, label, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) QueryMetric(ctx context.Context, query string, at time.Time, timeout string) (*suseobservability.MetricQueryResponse, error) {
+	args := m.Called(ctx, query, at, timeout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.MetricQueryResponse), args.Error(1)
+}
+
 func (m *MockSuseObservabilityClient) QueryRangeMetric(ctx context.Context, query string, start time.Time, end time.Time, step, timeout string) (*suseobservability.MetricQueryResponse, error) {
 	args := m.Called(ctx, query, start, end, step, timeout)
 	if args.Get(0) == nil {
@@ -44,3 +86,123 @@ func (m *MockSuseObservabilityClient) SnapShotTopologyQuery(ctx context.Context,
 	}
 	return args.Get(0).([]suseobservability.ViewComponent), args.Error(1)
 }
+
+func (m *MockSuseObservabilityClient) SnapShotTopologyQueryAt(ctx context.Context, query string, at time.Time) ([]suseobservability.ViewComponent, error) {
+	args := m.Called(ctx, query, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]suseobservability.ViewComponent), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) ValidateSTQL(ctx context.Context, query string) (*suseobservability.STQLValidation, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.STQLValidation), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) ListViews(ctx context.Context) ([]suseobservability.QueryView, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]suseobservability.QueryView), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMonitors(ctx context.Context) (*suseobservability.MonitorList, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.MonitorList), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMonitor(ctx context.Context, monitorIdOrUrn string) (*suseobservability.Monitor, error) {
+	args := m.Called(ctx, monitorIdOrUrn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.Monitor), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMonitorsOverview(ctx context.Context) (*suseobservability.MonitorOverviewList, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.MonitorOverviewList), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) PostEvent(ctx context.Context, event suseobservability.IntakeEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// QueryTraces also accepts a function as return value, to answer depending on the request
+func (m *MockSuseObservabilityClient) QueryTraces(ctx context.Context, req *suseobservability.TraceQueryRequest) (*suseobservability.TraceQueryResponse, error) {
+	args := m.Called(ctx, req)
+	if fn, ok := args.Get(0).(func(context.Context, *suseobservability.TraceQueryRequest) (*suseobservability.TraceQueryResponse, error)); ok {
+		return fn(ctx, req)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.TraceQueryResponse), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetEvents(ctx context.Context, req *suseobservability.EventListRequest) (*suseobservability.EventItemsWithTotal, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.EventItemsWithTotal), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) Ping(ctx context.Context) (*suseobservability.PingResult, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.PingResult), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetLogs(ctx context.Context, req *suseobservability.LogListRequest) (*suseobservability.LogLinesWithTotal, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.LogLinesWithTotal), args.Error(1)
+}
+
+// EachMonitorCheckStatePage hands out the pages returned by the expectation one at a time,
+// stopping like the client does
+func (m *MockSuseObservabilityClient) EachMonitorCheckStatePage(ctx context.Context, monitorIdOrUrn string, healthState string, pageSize int, timestamp int64, fn func(states []suseobservability.ViewCheckState) (bool, error)) error {
+	args := m.Called(ctx, monitorIdOrUrn, healthState, pageSize, timestamp)
+	pages, _ := args.Get(0).([][]suseobservability.ViewCheckState)
+	for _, page := range pages {
+		m.fetchedPages++
+		more, err := fn(page)
+		if err != nil || !more || len(page) < pageSize {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetTrace(ctx context.Context, id string) (*suseobservability.Trace, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*suseobservability.Trace), args.Error(1)
+}
+
+func (m *MockSuseObservabilityClient) GetMetricSeries(ctx context.Context, metric string, start, end time.Time, limit int) ([]map[string]string, error) {
+	args := m.Called(ctx, metric, start, end, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]string), args.Error(1)
+}
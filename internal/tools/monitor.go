@@ -0,0 +1,325 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMonitorParams struct {
+	ID string `json:"id" jsonschema:"required,The ID or URN of the monitor"`
+}
+
+const (
+	// monitorCheckStatePageSize is the number of check states getMonitor fetches per request,
+	// larger than for getMonitorCheckStates as only affected states are fetched
+	monitorCheckStatePageSize = 1000
+	// maxMonitorCheckStates bounds the affected check states getMonitor lists
+	maxMonitorCheckStates = maxCheckStateLimit
+	// maxMonitorValueRows bounds the affected components whose current value getMonitor shows
+	maxMonitorValueRows = 20
+)
+
+// monitorThreshold is a numeric argument of a monitor named like a threshold
+type monitorThreshold struct {
+	Name  string
+	Value float64
+}
+
+// comparatorSymbols renders the comparators of the threshold monitor function
+var comparatorSymbols = map[string]string{"GT": ">", "GTE": ">=", "LT": "<", "LTE": "<="}
+
+// affectedStates are the health states of the check states getMonitor lists, worst first
+var affectedStates = []string{"CRITICAL", "DEVIATING", "UNKNOWN"}
+
+// GetMonitor renders the definition and runtime metrics of a single monitor, with every
+// check state it reports as not clear
+func (t tool) GetMonitor(ctx context.Context, request *mcp.CallToolRequest, params GetMonitorParams) (*mcp.CallToolResult, any, error) {
+	id := strings.TrimSpace(params.ID)
+	if id == "" {
+		return nil, nil, fmt.Errorf("id is required")
+	}
+
+	monitor, err := t.client.GetMonitor(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get monitor '%s': %w", id, err)
+	}
+
+	// The runtime metrics are only reported by the overview of all monitors
+	var overview *suseobservability.MonitorOverview
+	overviews, overviewErr := t.client.GetMonitorsOverview(ctx)
+	if overviewErr == nil {
+		for i, m := range overviews.Monitors {
+			if m.Monitor.Id == monitor.Id {
+				overview = &overviews.Monitors[i]
+				break
+			}
+		}
+	}
+
+	var (
+		rows      strings.Builder
+		counts    = map[string]int{}
+		seen      = map[string]bool{}
+		listed    int
+		truncated bool
+		// failing are the first affected check states, worst first, whose current value is shown
+		failing []suseobservability.ViewCheckState
+	)
+	for _, state := range affectedStates {
+		err := t.client.EachMonitorCheckStatePage(ctx, id, state, monitorCheckStatePageSize, 0, func(states []suseobservability.ViewCheckState) (bool, error) {
+			for _, s := range states {
+				if seen[s.CheckStateId] {
+					continue
+				}
+				if listed == maxMonitorCheckStates {
+					truncated = true
+					return false, nil
+				}
+				seen[s.CheckStateId] = true
+				listed++
+				if len(failing) < maxMonitorValueRows {
+					failing = append(failing, s)
+				}
+				counts[valueOrDash(s.Health)]++
+				// Messages explain why a check fails, so they are kept whole on a single line
+				message := strings.Join(strings.Fields(s.Message), " ")
+				rows.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n", s.TopologyElementId, valueOrDash(s.Name), valueOrDash(s.Health),
+					valueOrDash(s.CheckStateId), valueOrDash(message)))
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get %s check states of monitor '%s': %w", state, id, err)
+		}
+		if truncated {
+			break
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Monitor '%s' (ID %d):\n\n", monitor.Name, monitor.Id))
+	sb.WriteString("| Field | Value |\n")
+	sb.WriteString("|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| ID | %d |\n", monitor.Id))
+	sb.WriteString(fmt.Sprintf("| URN | %s |\n", valueOrDash(monitor.Identifier)))
+	sb.WriteString(fmt.Sprintf("| Description | %s |\n", valueOrDash(strings.Join(strings.Fields(monitor.Description), " "))))
+	function := strconv.FormatInt(monitor.FunctionId, 10)
+	if overview != nil && overview.Function.Name != "" {
+		function = fmt.Sprintf("%s (%s)", overview.Function.Name, valueOrDash(overview.Function.Identifier))
+	}
+	sb.WriteString(fmt.Sprintf("| Function | %s |\n", function))
+	sb.WriteString(fmt.Sprintf("| Status | %s |\n", valueOrDash(string(monitor.Status))))
+	sb.WriteString(fmt.Sprintf("| Runtime status | %s |\n", valueOrDash(string(monitor.RuntimeStatus))))
+	sb.WriteString(fmt.Sprintf("| Interval | %s |\n", time.Duration(monitor.IntervalSeconds)*time.Second))
+	sb.WriteString(fmt.Sprintf("| Tags | %s |\n", valueOrDash(strings.Join(monitor.Tags, ", "))))
+	sb.WriteString(fmt.Sprintf("| Source | %s |\n", valueOrDash(monitor.Source)))
+	sb.WriteString(fmt.Sprintf("| Last updated | %s |\n", formatMillis(monitor.LastUpdateTimestamp)))
+
+	sb.WriteString("\nRuntime metrics:\n\n")
+	if overview == nil {
+		reason := "the monitor is missing from the monitors overview"
+		if overviewErr != nil {
+			reason = overviewErr.Error()
+		}
+		sb.WriteString(fmt.Sprintf("Unavailable: %s\n", reason))
+	} else {
+		rm := overview.RuntimeMetrics
+		sb.WriteString("| Metric | Value |\n")
+		sb.WriteString("|---|---|\n")
+		for _, state := range healthStateOrder {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", state, stateCount(*overview, state)))
+		}
+		sb.WriteString(fmt.Sprintf("| Health states | %d |\n", rm.HealthStatesCount))
+		sb.WriteString(fmt.Sprintf("| Unmapped health states | %d |\n", rm.UnmappedHealthStatesCount))
+		sb.WriteString(fmt.Sprintf("| Groups | %d |\n", rm.GroupCount))
+		sb.WriteString(fmt.Sprintf("| Last run | %s |\n", formatMillis(rm.LastRunTimestamp)))
+		sb.WriteString(fmt.Sprintf("| Last successful run | %s |\n", formatMillis(rm.LastSuccessfulRunTimestamp)))
+		sb.WriteString(fmt.Sprintf("| Last failed run | %s |\n", formatMillis(rm.LastFailedRunTimestamp)))
+		for _, e := range overview.Errors {
+			sb.WriteString(fmt.Sprintf("\nRun error (%s, %d time(s)): %s\n", valueOrDash(e.Level), e.Count, e.Error))
+		}
+	}
+
+	thresholds, comparator := monitorThresholdArguments(monitor.Arguments)
+	query := monitorQuery(monitor.Arguments)
+	if len(thresholds) > 0 {
+		sb.WriteString("\nThresholds:\n\n")
+		for _, th := range thresholds {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", th.Name, formatThreshold(comparator, th.Value)))
+		}
+	}
+	if query != "" {
+		sb.WriteString(fmt.Sprintf("\nQuery:\n```\n%s\n```\n", Truncate(query, maxMonitorQueryLength)))
+	}
+	valuesErr := t.writeCurrentValues(ctx, &sb, query, thresholds, comparator, failing)
+
+	if hint := strings.TrimSpace(monitor.RemediationHint); hint != "" {
+		sb.WriteString(fmt.Sprintf("\nRemediation hint:\n\n%s\n", Truncate(hint, maxMonitorQueryLength)))
+	}
+
+	if listed == 0 {
+		sb.WriteString("\nNo affected components, every check state is CLEAR.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\nAffected components (%d):\n\n", listed))
+		writeStateCounts(&sb, counts, affectedStates)
+		sb.WriteString("| Component ID | Name | Health | Check State ID | Message |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
+		sb.WriteString(rows.String())
+		if truncated {
+			sb.WriteString(fmt.Sprintf("\nStopped after %d check states, the monitor affects more. Use getMonitorCheckStates with a state to page through them.\n", maxMonitorCheckStates))
+		}
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}
+	if overviewErr != nil || valuesErr != nil {
+		markPartial(result)
+	}
+	return result, nil, nil
+}
+
+// writeCurrentValues runs the query of a metric monitor and writes, per failing component,
+// the value of the series labelled with its name next to the threshold of its health state.
+// Monitors without a query are described as such; a failing query is reported and returned.
+func (t tool) writeCurrentValues(ctx context.Context, sb *strings.Builder, query string, thresholds []monitorThreshold, comparator string, failing []suseobservability.ViewCheckState) error {
+	if len(failing) == 0 {
+		return nil
+	}
+	sb.WriteString("\nCurrent values:\n\n")
+	if query == "" {
+		sb.WriteString("Unavailable: the monitor definition does not expose a metric query.\n")
+		return nil
+	}
+	// An empty timeout lets the client use its configured request timeout
+	res, err := t.client.QueryMetric(ctx, query, t.clock.Now(), "")
+	if err == nil && res.Data.ResultType != "vector" {
+		err = fmt.Errorf("the query returns a %s instead of a series per component", res.Data.ResultType)
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Unavailable: %s\n", err))
+		return err
+	}
+
+	series := newSeries(res.Data.Result)
+	sb.WriteString("| Component | Health | Series | Value | Threshold | Difference |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+	for _, state := range failing {
+		seriesLabel, value, difference := "-", "-", "-"
+		threshold, hasThreshold := thresholdFor(thresholds, state.Health)
+		if s, ok := seriesOfComponent(series, state.Name); ok {
+			seriesLabel = fmt.Sprintf("`%s`", seriesName(s.Labels))
+			v := s.Points[len(s.Points)-1].Value
+			value = formatValue(v)
+			if hasThreshold {
+				difference = formatValue(v - threshold.Value)
+				if v > threshold.Value {
+					difference = "+" + difference
+				}
+			}
+		}
+		thresholdCell := "-"
+		if hasThreshold {
+			thresholdCell = formatThreshold(comparator, threshold.Value)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n", valueOrDash(state.Name), valueOrDash(state.Health), seriesLabel, value, thresholdCell, difference))
+	}
+	sb.WriteString("\nSeries are matched to components by a label value equal to the component name.\n")
+	return nil
+}
+
+// seriesOfComponent returns the first series with a point and a label value equal to name
+func seriesOfComponent(series []Series, name string) (Series, bool) {
+	if name == "" {
+		return Series{}, false
+	}
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		for k, v := range s.Labels {
+			if k != "__name__" && v == name {
+				return s, true
+			}
+		}
+	}
+	return Series{}, false
+}
+
+// thresholdFor returns the threshold applying to a health state: the one named after the
+// state, such as criticalThreshold, otherwise the first one
+func thresholdFor(thresholds []monitorThreshold, health string) (monitorThreshold, bool) {
+	if len(thresholds) == 0 {
+		return monitorThreshold{}, false
+	}
+	for _, th := range thresholds {
+		if health != "" && strings.HasPrefix(strings.ToLower(th.Name), strings.ToLower(health)) {
+			return th, true
+		}
+	}
+	return thresholds[0], true
+}
+
+// monitorThresholdArguments decodes the numeric threshold arguments of a monitor and the
+// comparator they are applied with, empty when the definition has none
+func monitorThresholdArguments(arguments []map[string]any) (thresholds []monitorThreshold, comparator string) {
+	for _, arg := range arguments {
+		name, _ := arg["parameter"].(string)
+		switch {
+		case strings.EqualFold(name, "comparator"):
+			comparator, _ = arg["value"].(string)
+		case strings.Contains(strings.ToLower(name), "threshold"):
+			if v, ok := arg["value"].(float64); ok {
+				thresholds = append(thresholds, monitorThreshold{Name: name, Value: v})
+			}
+		}
+	}
+	return thresholds, comparator
+}
+
+// monitorQuery returns the PromQL query argument of a monitor, either a 'query' parameter or
+// the query of a metric argument, empty when the definition does not expose one
+func monitorQuery(arguments []map[string]any) string {
+	for _, arg := range arguments {
+		name, _ := arg["parameter"].(string)
+		switch v := arg["value"].(type) {
+		case string:
+			if strings.EqualFold(name, "query") {
+				return strings.TrimSpace(v)
+			}
+		case map[string]any:
+			if q, ok := v["query"].(string); ok && strings.TrimSpace(q) != "" {
+				return strings.TrimSpace(q)
+			}
+		}
+	}
+	return ""
+}
+
+// formatThreshold renders a threshold with the symbol of its comparator, such as "> 3",
+// an unknown comparator as is
+func formatThreshold(comparator string, value float64) string {
+	if symbol, ok := comparatorSymbols[strings.ToUpper(comparator)]; ok {
+		comparator = symbol
+	}
+	return strings.TrimSpace(comparator + " " + formatValue(value))
+}
+
+// formatMillis renders a millisecond timestamp in UTC, "-" when unset
+func formatMillis(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
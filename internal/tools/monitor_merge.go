@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+// clusterTagPrefixes are the prefixes of the monitor tags naming the cluster a monitor checks
+var clusterTagPrefixes = []string{"cluster-name:", "cluster:"}
+
+// clusterSuffixFormats are the ways a cluster is appended to the name of a monitor installed
+// per cluster, %s standing for the cluster
+var clusterSuffixFormats = []string{" (%s)", " [%s]", " - %s", " – %s", " on %s"}
+
+// MonitorGroup is a set of monitors with the same name up to the cluster they check
+type MonitorGroup struct {
+	Name       string         `json:"name"`
+	Clusters   []string       `json:"clusters,omitempty"`
+	MonitorIDs []int64        `json:"monitor_ids"`
+	Counts     map[string]int `json:"counts"`
+}
+
+// monitorCluster returns the cluster a monitor checks, taken from a cluster tag or a cluster
+// segment of its identifier, e.g. 'urn:...:cluster:prod:...'. It is empty when neither names one.
+func monitorCluster(m suseobservability.Monitor) string {
+	for _, tag := range m.Tags {
+		for _, prefix := range clusterTagPrefixes {
+			if value, ok := strings.CutPrefix(tag, prefix); ok && value != "" {
+				return value
+			}
+		}
+	}
+	segments := strings.Split(m.Identifier, ":")
+	for i, s := range segments[:max(len(segments)-1, 0)] {
+		if s == "cluster" && segments[i+1] != "" {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// normalizeMonitorName strips the cluster from the name of a monitor installed per cluster.
+// With a known cluster any of clusterSuffixFormats is removed, otherwise a bracketed suffix
+// such as ' (prod)' is taken as the cluster. It returns the name and the cluster stripped.
+func normalizeMonitorName(name, cluster string) (string, string) {
+	name = strings.TrimSpace(name)
+	if cluster != "" {
+		lower := strings.ToLower(name)
+		for _, format := range clusterSuffixFormats {
+			suffix := strings.ToLower(strings.Replace(format, "%s", cluster, 1))
+			if strings.HasSuffix(lower, suffix) && len(name) > len(suffix) {
+				return strings.TrimSpace(name[:len(name)-len(suffix)]), cluster
+			}
+		}
+		return name, cluster
+	}
+	for _, brackets := range []string{"()", "[]"} {
+		if !strings.HasSuffix(name, brackets[1:]) {
+			continue
+		}
+		open := strings.LastIndex(name, " "+brackets[:1])
+		if open <= 0 {
+			continue
+		}
+		if suffix := strings.TrimSpace(name[open+2 : len(name)-1]); suffix != "" {
+			return strings.TrimSpace(name[:open]), suffix
+		}
+	}
+	return name, ""
+}
+
+// groupMonitors merges the monitors whose names are equal, ignoring case, once their cluster
+// is stripped, summing their counts per state. The groups are sorted by their counts in state
+// order, worst first, and then by name.
+func groupMonitors(monitors []suseobservability.MonitorOverview, states []string) []MonitorGroup {
+	var groups []MonitorGroup
+	index := map[string]int{}
+	for _, m := range monitors {
+		name, cluster := normalizeMonitorName(m.Monitor.Name, monitorCluster(m.Monitor))
+		key := strings.ToLower(name)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, MonitorGroup{Name: name, MonitorIDs: []int64{}, Counts: map[string]int{}})
+		}
+		g := &groups[i]
+		g.MonitorIDs = append(g.MonitorIDs, m.Monitor.Id)
+		if cluster != "" && !containsFold(g.Clusters, cluster) {
+			g.Clusters = append(g.Clusters, cluster)
+		}
+		for _, state := range states {
+			g.Counts[state] += stateCount(m, state)
+		}
+	}
+	for i := range groups {
+		sort.Strings(groups[i].Clusters)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		for _, state := range states {
+			a, b := groups[i].Counts[state], groups[j].Counts[state]
+			if a != b {
+				return a > b
+			}
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
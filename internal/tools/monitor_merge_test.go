@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMonitorName(t *testing.T) {
+	tests := []struct {
+		name            string
+		monitor         string
+		cluster         string
+		expectedName    string
+		expectedCluster string
+	}{
+		{name: "parenthesized cluster", monitor: "Pod restarts (prod)", cluster: "prod", expectedName: "Pod restarts", expectedCluster: "prod"},
+		{name: "dash cluster ignoring case", monitor: "Pod restarts - PROD", cluster: "prod", expectedName: "Pod restarts", expectedCluster: "prod"},
+		{name: "on cluster", monitor: "Pod restarts on prod", cluster: "prod", expectedName: "Pod restarts", expectedCluster: "prod"},
+		{name: "known cluster not in name", monitor: "Pod restarts", cluster: "prod", expectedName: "Pod restarts", expectedCluster: "prod"},
+		{name: "name equal to cluster kept", monitor: "(prod)", cluster: "prod", expectedName: "(prod)", expectedCluster: "prod"},
+		{name: "bracketed suffix taken as cluster", monitor: "Pod restarts [staging]", expectedName: "Pod restarts", expectedCluster: "staging"},
+		{name: "no suffix", monitor: " Pod restarts ", expectedName: "Pod restarts"},
+		{name: "dash without known cluster kept", monitor: "CPU - throttling", expectedName: "CPU - throttling"},
+		{name: "empty brackets kept", monitor: "Pod restarts ()", expectedName: "Pod restarts ()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, cluster := normalizeMonitorName(tt.monitor, tt.cluster)
+
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedCluster, cluster)
+		})
+	}
+}
+
+func TestMonitorCluster(t *testing.T) {
+	t.Run("cluster-name tag", func(t *testing.T) {
+		assert.Equal(t, "prod", monitorCluster(suseobservability.Monitor{Tags: []string{"team:a", "cluster-name:prod"}}))
+	})
+
+	t.Run("identifier segment", func(t *testing.T) {
+		assert.Equal(t, "prod", monitorCluster(suseobservability.Monitor{Identifier: "urn:custom:cluster:prod:monitor:restarts"}))
+	})
+
+	t.Run("none", func(t *testing.T) {
+		assert.Empty(t, monitorCluster(suseobservability.Monitor{Identifier: "urn:custom:monitor:cluster", Tags: []string{"cluster:"}}))
+	})
+}
+
+func TestGroupMonitors(t *testing.T) {
+	withID := func(m suseobservability.MonitorOverview, id int64, identifier string) suseobservability.MonitorOverview {
+		m.Monitor.Id = id
+		m.Monitor.Identifier = identifier
+		return m
+	}
+	monitors := []suseobservability.MonitorOverview{
+		withID(monitorOverview("Pod restarts (prod)", 1, 2, "cluster-name:prod"), 1, ""),
+		withID(monitorOverview("pod restarts", 4, 0), 2, "urn:custom:cluster:staging:monitor:restarts"),
+		withID(monitorOverview("Disk full [dev]", 2, 0), 3, ""),
+		withID(monitorOverview("Pod restarts (prod)", 0, 1, "cluster:prod"), 4, ""),
+	}
+
+	groups := groupMonitors(monitors, []string{"CRITICAL", "DEVIATING"})
+
+	assert.Equal(t, []MonitorGroup{
+		{Name: "Pod restarts", Clusters: []string{"prod", "staging"}, MonitorIDs: []int64{1, 2, 4}, Counts: map[string]int{"CRITICAL": 5, "DEVIATING": 3}},
+		{Name: "Disk full", Clusters: []string{"dev"}, MonitorIDs: []int64{3}, Counts: map[string]int{"CRITICAL": 2, "DEVIATING": 0}},
+	}, groups)
+}
+
+func TestGetMonitorsMergeDuplicates(t *testing.T) {
+	overview := &suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{
+		monitorOverview("Pod restarts (prod)", 1, 0),
+		monitorOverview("Pod restarts (staging)", 2, 0),
+		monitorOverview("Node down", 1, 0),
+	}}
+	overview.Monitors[1].Monitor.Id = 7
+	mockClient := new(MockSuseObservabilityClient)
+	mockClient.On("GetMonitorsOverview", context.Background()).Return(overview, nil)
+	tool := NewBaseTool(mockClient)
+
+	result, structured, err := tool.GetMonitors(context.Background(), &mcp.CallToolRequest{}, GetMonitorsParams{MergeDuplicates: true})
+
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "Found 3 monitor(s) with CRITICAL results merged into groups, showing 2 of 2 fetched")
+	assert.Contains(t, text, "| Monitor | Clusters | IDs | CRITICAL |\n|---|---|---|---|\n| Pod restarts | prod, staging | 7, 0 | 3 |\n| Node down | - | 0 | 1 |\n")
+	groups := structured.(*MonitorGroupsResult)
+	assert.Equal(t, []string{"CRITICAL"}, groups.States)
+	require.Len(t, groups.Groups, 2)
+	assert.Equal(t, []int64{7, 0}, groups.Groups[0].MonitorIDs)
+}
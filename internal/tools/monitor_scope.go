@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ListMonitorsForTypeParams struct {
+	Type  string `json:"type,omitempty" jsonschema:"Component type the monitors should target (e.g. 'pod')" examples:"pod;deployment"`
+	Layer string `json:"layer,omitempty" jsonschema:"Component layer the monitors should target (e.g. 'Pods')" examples:"Pods;Services"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of monitors listed (default: 50)"`
+}
+
+// scopeConfidence rates how certain a monitor targets a component type or layer
+type scopeConfidence int
+
+const (
+	confidenceLow scopeConfidence = iota + 1
+	confidenceMedium
+	confidenceHigh
+)
+
+func (c scopeConfidence) String() string {
+	switch c {
+	case confidenceHigh:
+		return "high"
+	case confidenceMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// scopeMatch is a monitor whose definition appears to target the requested type or layer
+type scopeMatch struct {
+	Monitor    suseobservability.Monitor
+	Confidence scopeConfidence
+	Reason     string
+}
+
+// stqlFilter matches STQL 'field = "x"' and 'field in ("x", "y")' filters
+var stqlFilter = regexp.MustCompile(`(?i)\b(type|layer)\s*(?:=\s*"([^"]*)"|in\s*\(([^)]*)\))`)
+
+// ListMonitorsForType lists the monitors whose definition targets a component type or layer
+func (t tool) ListMonitorsForType(ctx context.Context, request *mcp.CallToolRequest, params ListMonitorsForTypeParams) (*mcp.CallToolResult, any, error) {
+	field, value := "type", strings.TrimSpace(params.Type)
+	if layer := strings.TrimSpace(params.Layer); layer != "" {
+		if value != "" {
+			return nil, nil, fmt.Errorf("exactly one of type or layer must be provided")
+		}
+		field, value = "layer", layer
+	}
+	if value == "" {
+		return nil, nil, fmt.Errorf("exactly one of type or layer must be provided")
+	}
+	limit, err := displayLimit(params.Limit, t.limits.MonitorRows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	definitions, err := t.client.GetMonitors(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get monitor definitions: %w", err)
+	}
+
+	matches := matchMonitorScope(definitions.Monitors, field, value)
+	if len(matches) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No monitors found targeting %s '%s' among %d monitor definitions.", field, value, len(definitions.Monitors)),
+				},
+			},
+		}, nil, nil
+	}
+
+	shown := matches
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Monitors targeting %s '%s', %s:\n\n", field, value, countSummary(len(shown), len(matches))))
+	sb.WriteString("| Monitor Name | ID | Interval | Threshold | Confidence | Matched On |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+	for _, m := range shown {
+		interval := "-"
+		if m.Monitor.IntervalSeconds > 0 {
+			interval = fmt.Sprintf("%ds", m.Monitor.IntervalSeconds)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s |\n", m.Monitor.Name, m.Monitor.Id, interval,
+			valueOrDash(Truncate(strings.Join(monitorThresholds(m.Monitor.Arguments), ", "), 40)), m.Confidence, m.Reason))
+	}
+	sb.WriteString("\nMatching is a best-effort textual analysis of the monitor definitions: high confidence comes from STQL filters " +
+		"or URN templates, medium from query labels or metric names and low from the monitor name or description only.\n")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, nil, nil
+}
+
+// matchMonitorScope returns the monitors whose definition mentions the type or layer, most confident first
+func matchMonitorScope(monitors []suseobservability.Monitor, field, value string) []scopeMatch {
+	terms := scopeTerms(value)
+
+	var matches []scopeMatch
+	for _, m := range monitors {
+		if match, ok := matchMonitor(m, field, terms); ok {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].Monitor.Name < matches[j].Monitor.Name
+	})
+	return matches
+}
+
+func matchMonitor(m suseobservability.Monitor, field string, terms []string) (scopeMatch, bool) {
+	var texts []string
+	for _, arg := range m.Arguments {
+		texts = collectStrings(arg, texts)
+	}
+
+	for _, text := range texts {
+		for _, f := range stqlFilter.FindAllStringSubmatch(text, -1) {
+			if !strings.EqualFold(f[1], field) {
+				continue
+			}
+			for _, v := range strings.Split(f[2]+","+f[3], ",") {
+				v = strings.Trim(strings.TrimSpace(v), `"'`)
+				if containsFold(terms, v) {
+					return scopeMatch{Monitor: m, Confidence: confidenceHigh, Reason: fmt.Sprintf("STQL filter %s '%s'", field, v)}, true
+				}
+			}
+		}
+	}
+
+	if field == "type" {
+		for _, text := range texts {
+			if !strings.Contains(text, "urn:") {
+				continue
+			}
+			for _, term := range terms {
+				if strings.Contains(strings.ToLower(text), ":"+term+"/") {
+					return scopeMatch{Monitor: m, Confidence: confidenceHigh, Reason: fmt.Sprintf("URN template with '%s'", term)}, true
+				}
+			}
+		}
+		for _, text := range texts {
+			if term, ok := mentionsTerm(text, terms); ok {
+				return scopeMatch{Monitor: m, Confidence: confidenceMedium, Reason: fmt.Sprintf("query mentions '%s'", term)}, true
+			}
+		}
+	}
+
+	if term, ok := mentionsTerm(m.Name+" "+m.Description, terms); ok {
+		return scopeMatch{Monitor: m, Confidence: confidenceLow, Reason: fmt.Sprintf("name or description mentions '%s'", term)}, true
+	}
+	return scopeMatch{}, false
+}
+
+// scopeTerms returns the lower-cased value with its singular and plural forms
+func scopeTerms(value string) []string {
+	value = strings.ToLower(value)
+	terms := []string{value}
+	if singular, ok := strings.CutSuffix(value, "s"); ok && singular != "" {
+		terms = append(terms, singular)
+	} else {
+		terms = append(terms, value+"s")
+	}
+	return terms
+}
+
+// mentionsTerm reports the first term appearing as a whole word in text, underscores separating words
+func mentionsTerm(text string, terms []string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	for _, w := range words {
+		if containsFold(terms, w) {
+			return w, true
+		}
+	}
+	return "", false
+}
+
+func containsFold(terms []string, v string) bool {
+	for _, term := range terms {
+		if strings.EqualFold(term, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectStrings appends every string nested in v
+func collectStrings(v any, texts []string) []string {
+	switch v := v.(type) {
+	case string:
+		texts = append(texts, v)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			texts = collectStrings(v[k], texts)
+		}
+	case []any:
+		for _, item := range v {
+			texts = collectStrings(item, texts)
+		}
+	}
+	return texts
+}
+
+// monitorThresholds returns the values of the monitor arguments named like a threshold
+func monitorThresholds(arguments []map[string]any) []string {
+	var thresholds []string
+	for _, arg := range arguments {
+		if name, ok := arg["parameter"].(string); ok && strings.Contains(strings.ToLower(name), "threshold") {
+			if v, ok := arg["value"]; ok {
+				thresholds = append(thresholds, fmt.Sprintf("%v", v))
+			}
+			continue
+		}
+		keys := make([]string, 0, len(arg))
+		for k := range arg {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if strings.Contains(strings.ToLower(k), "threshold") {
+				thresholds = append(thresholds, fmt.Sprintf("%v", arg[k]))
+			}
+		}
+	}
+	return thresholds
+}
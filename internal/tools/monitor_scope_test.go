@@ -0,0 +1,176 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monitorDefinitions mirrors the shape of the monitors endpoint for the common monitor functions
+const monitorDefinitions = `{"monitors": [
+	{
+		"id": 1, "name": "Pod span error ratio", "intervalSeconds": 30,
+		"arguments": [
+			{"_type": "ArgumentStringVal", "parameter": "query", "value": "sum(rate(otel_span_errors[5m])) by (cluster_name, namespace, pod_name)"},
+			{"_type": "ArgumentDoubleVal", "parameter": "deviatingThreshold", "value": 0.05},
+			{"_type": "ArgumentStringVal", "parameter": "urnTemplate", "value": "urn:kubernetes:/${cluster_name}:${namespace}:pod/${pod_name}"}
+		]
+	},
+	{
+		"id": 2, "name": "Derived health of deployments", "intervalSeconds": 60,
+		"arguments": [
+			{"_type": "ArgumentStringVal", "parameter": "topologyQuery", "value": "type in (\"deployment\", \"pod\") AND label = \"stackpack:kubernetes\""}
+		]
+	},
+	{
+		"id": 3, "name": "Container restarts", "intervalSeconds": 300,
+		"arguments": [
+			{"_type": "ArgumentStringVal", "parameter": "query", "value": "increase(kube_pod_container_status_restarts_total[10m])"},
+			{"_type": "ArgumentLongVal", "parameter": "criticalThreshold", "value": 3}
+		]
+	},
+	{
+		"id": 4, "name": "Pods stuck pending", "description": "Detects pods that cannot be scheduled", "intervalSeconds": 60,
+		"arguments": [
+			{"_type": "ArgumentStringVal", "parameter": "query", "value": "kube_scheduler_pending_workloads"}
+		]
+	},
+	{
+		"id": 5, "name": "Service latency", "intervalSeconds": 30,
+		"arguments": [
+			{"_type": "ArgumentStringVal", "parameter": "topologyQuery", "value": "layer = \"Services\""},
+			{"_type": "ArgumentDoubleVal", "parameter": "threshold", "value": 0.5}
+		]
+	}
+]}`
+
+func loadMonitorDefinitions(t *testing.T) *suseobservability.MonitorList {
+	var list suseobservability.MonitorList
+	require.NoError(t, json.Unmarshal([]byte(monitorDefinitions), &list))
+	return &list
+}
+
+func TestMatchMonitorScope(t *testing.T) {
+	definitions := loadMonitorDefinitions(t)
+
+	tests := []struct {
+		name     string
+		field    string
+		value    string
+		expected map[string]scopeConfidence
+	}{
+		{
+			name:  "pod type from filters, URN templates, labels and names",
+			field: "type",
+			value: "pod",
+			expected: map[string]scopeConfidence{
+				"Derived health of deployments": confidenceHigh,
+				"Pod span error ratio":          confidenceHigh,
+				"Container restarts":            confidenceMedium,
+				"Pods stuck pending":            confidenceLow,
+			},
+		},
+		{
+			name:     "plural type matches the singular filter",
+			field:    "type",
+			value:    "Deployments",
+			expected: map[string]scopeConfidence{"Derived health of deployments": confidenceHigh},
+		},
+		{
+			name:     "layer filter",
+			field:    "layer",
+			value:    "services",
+			expected: map[string]scopeConfidence{"Service latency": confidenceHigh},
+		},
+		{
+			name:     "layer does not match type filters",
+			field:    "layer",
+			value:    "deployment",
+			expected: map[string]scopeConfidence{"Derived health of deployments": confidenceLow},
+		},
+		{
+			name:     "no match",
+			field:    "type",
+			value:    "node",
+			expected: map[string]scopeConfidence{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := matchMonitorScope(definitions.Monitors, tt.field, tt.value)
+
+			got := make(map[string]scopeConfidence, len(matches))
+			for i, m := range matches {
+				got[m.Monitor.Name] = m.Confidence
+				if i > 0 {
+					assert.GreaterOrEqual(t, matches[i-1].Confidence, m.Confidence)
+				}
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestMonitorThresholds(t *testing.T) {
+	definitions := loadMonitorDefinitions(t)
+
+	assert.Equal(t, []string{"0.05"}, monitorThresholds(definitions.Monitors[0].Arguments))
+	assert.Nil(t, monitorThresholds(definitions.Monitors[1].Arguments))
+	assert.Equal(t, []string{"map[alert:0.9]"}, monitorThresholds([]map[string]any{{"thresholds": map[string]any{"alert": 0.9}}}))
+}
+
+func TestListMonitorsForType(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	t.Run("lists matching monitors with interval and threshold", func(t *testing.T) {
+		mockClient.On("GetMonitors", ctx).Return(loadMonitorDefinitions(t), nil).Once()
+
+		result, _, err := tools.ListMonitorsForType(ctx, nil, ListMonitorsForTypeParams{Type: "pod", Limit: 3})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Monitors targeting type 'pod', showing 3 of 4 fetched:")
+		assert.Contains(t, output, "| Derived health of deployments | 2 | 60s | - | high | STQL filter type 'pod' |")
+		assert.Contains(t, output, "| Pod span error ratio | 1 | 30s | 0.05 | high | URN template with 'pod' |")
+		assert.Contains(t, output, "| Container restarts | 3 | 300s | 3 | medium | query mentions 'pod' |")
+		assert.NotContains(t, output, "Pods stuck pending")
+		assert.Contains(t, output, "best-effort")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		mockClient.On("GetMonitors", ctx).Return(loadMonitorDefinitions(t), nil).Once()
+
+		result, _, err := tools.ListMonitorsForType(ctx, nil, ListMonitorsForTypeParams{Layer: "Nodes"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No monitors found targeting layer 'Nodes' among 5 monitor definitions.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("type or layer is required", func(t *testing.T) {
+		for _, params := range []ListMonitorsForTypeParams{{}, {Type: "pod", Layer: "Pods"}} {
+			result, _, err := tools.ListMonitorsForType(ctx, nil, params)
+
+			assert.Nil(t, result)
+			assert.EqualError(t, err, "exactly one of type or layer must be provided")
+		}
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mockClient.On("GetMonitors", ctx).Return(nil, errors.New("client error")).Once()
+
+		result, _, err := tools.ListMonitorsForType(ctx, nil, ListMonitorsForTypeParams{Type: "pod"})
+
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "client error")
+	})
+}
@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetMonitorsParams struct {
+	State string `json:"state,omitempty" jsonschema:"Comma-separated health states to list monitors for, e.g. 'CRITICAL,DEVIATING' (default: CRITICAL)" default:"CRITICAL" examples:"CRITICAL;CRITICAL,DEVIATING"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of monitors listed (default: 50)"`
+	// MergeDuplicates groups the monitors installed once per cluster under a single row
+	MergeDuplicates bool `json:"merge_duplicates,omitempty" jsonschema:"Merge the monitors with the same name up to a cluster suffix into one row, summing their counts and listing their clusters (default: false)" default:"false"`
+}
+
+// MonitorGroupsResult is the structured content returned by getMonitors when merging
+// duplicates, keeping the IDs of the monitors of every group
+type MonitorGroupsResult struct {
+	States []string       `json:"states"`
+	Groups []MonitorGroup `json:"groups"`
+}
+
+// MonitorStatesResult is the structured content returned by getMonitors without merging
+// duplicates. Unlike the markdown table it is not capped by limit.
+type MonitorStatesResult struct {
+	States   []string       `json:"states"`
+	Monitors []MonitorState `json:"monitors"`
+}
+
+// MonitorState is a monitor with the number of components it reports in each requested state
+type MonitorState struct {
+	ID     int64          `json:"id"`
+	Name   string         `json:"name"`
+	Counts map[string]int `json:"counts"`
+}
+
+// defaultMonitorState is the state getMonitors lists when none is requested
+const defaultMonitorState = "CRITICAL"
+
+// GetMonitors lists the monitors with results in any of the requested health states, with
+// the number of affected components per state. With merge_duplicates the monitors installed
+// once per cluster are merged into a row per group.
+func (t tool) GetMonitors(ctx context.Context, request *mcp.CallToolRequest, params GetMonitorsParams) (*mcp.CallToolResult, any, error) {
+	states, err := parseMonitorStates(params.State)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit, err := displayLimit(params.Limit, t.limits.MonitorRows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	overview, err := t.client.GetMonitorsOverview(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get monitors: %w", err)
+	}
+	monitors := monitorsInStates(overview.Monitors, states)
+
+	totals := make(map[string]int, len(states))
+	for _, m := range monitors {
+		for _, state := range states {
+			totals[state] += stateCount(m, state)
+		}
+	}
+
+	stateList := strings.Join(states, ", ")
+	if len(monitors) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No monitors with %s results found.", stateList),
+				},
+			},
+		}, &MonitorStatesResult{States: states, Monitors: []MonitorState{}}, nil
+	}
+
+	shown := monitors
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	if params.MergeDuplicates {
+		return monitorGroupsResult(monitors, states, totals, limit)
+	}
+
+	structured := &MonitorStatesResult{States: states, Monitors: make([]MonitorState, 0, len(monitors))}
+	for _, m := range monitors {
+		counts := make(map[string]int, len(states))
+		for _, state := range states {
+			counts[state] = stateCount(m, state)
+		}
+		structured.Monitors = append(structured.Monitors, MonitorState{ID: m.Monitor.Id, Name: m.Monitor.Name, Counts: counts})
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Monitors with %s results, %s.\n\n", stateList, countSummary(len(shown), len(monitors))))
+	sb.WriteString("Affected components per state, summed over the monitors:\n\n")
+	writeStateCounts(&sb, totals, states)
+
+	sb.WriteString("| Monitor | ID |")
+	for _, state := range states {
+		sb.WriteString(fmt.Sprintf(" %s |", state))
+	}
+	sb.WriteString("\n|---|---|" + strings.Repeat("---|", len(states)) + "\n")
+	for _, m := range shown {
+		sb.WriteString(fmt.Sprintf("| %s | %d |", m.Monitor.Name, m.Monitor.Id))
+		for _, state := range states {
+			sb.WriteString(fmt.Sprintf(" %d |", stateCount(m, state)))
+		}
+		sb.WriteString("\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, structured, nil
+}
+
+// monitorGroupsResult renders the monitors merged by groupMonitors, limit groups at most
+func monitorGroupsResult(monitors []suseobservability.MonitorOverview, states []string, totals map[string]int, limit int) (*mcp.CallToolResult, any, error) {
+	groups := groupMonitors(monitors, states)
+	shown := groups
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Found %d monitor(s) with %s results merged into groups, %s.\n\n",
+		len(monitors), strings.Join(states, ", "), countSummary(len(shown), len(groups))))
+	sb.WriteString("Affected components per state, summed over the monitors:\n\n")
+	writeStateCounts(&sb, totals, states)
+
+	sb.WriteString("| Monitor | Clusters | IDs |")
+	for _, state := range states {
+		sb.WriteString(fmt.Sprintf(" %s |", state))
+	}
+	sb.WriteString("\n|---|---|---|" + strings.Repeat("---|", len(states)) + "\n")
+	for _, g := range shown {
+		clusters := "-"
+		if len(g.Clusters) > 0 {
+			clusters = strings.Join(g.Clusters, ", ")
+		}
+		ids := make([]string, len(g.MonitorIDs))
+		for i, id := range g.MonitorIDs {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |", g.Name, clusters, strings.Join(ids, ", ")))
+		for _, state := range states {
+			sb.WriteString(fmt.Sprintf(" %d |", g.Counts[state]))
+		}
+		sb.WriteString("\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, &MonitorGroupsResult{States: states, Groups: groups}, nil
+}
+
+// parseMonitorStates parses a comma-separated list of health states, case-insensitively and
+// without duplicates. The states are returned in healthStateOrder, CRITICAL when empty.
+func parseMonitorStates(s string) ([]string, error) {
+	requested := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		state := strings.ToUpper(strings.TrimSpace(part))
+		if state == "" {
+			continue
+		}
+		if !slices.Contains(healthStateOrder, state) {
+			return nil, fmt.Errorf("invalid state %q, must be one of %s", strings.TrimSpace(part), strings.Join(healthStateOrder, ", "))
+		}
+		requested[state] = true
+	}
+	if len(requested) == 0 {
+		return []string{defaultMonitorState}, nil
+	}
+
+	var states []string
+	for _, state := range healthStateOrder {
+		if requested[state] {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// stateCount returns the number of components a monitor reports in a health state
+func stateCount(m suseobservability.MonitorOverview, state string) int {
+	switch state {
+	case "CRITICAL":
+		return m.RuntimeMetrics.CriticalCount
+	case "DEVIATING":
+		return m.RuntimeMetrics.DeviatingCount
+	case "UNKNOWN":
+		return m.RuntimeMetrics.UnknownCount
+	case "CLEAR":
+		return m.RuntimeMetrics.ClearCount
+	default:
+		return 0
+	}
+}
+
+// monitorsInStates keeps the monitors with results in any of the states, sorted by their
+// counts in state order, worst first, and then by name
+func monitorsInStates(monitors []suseobservability.MonitorOverview, states []string) []suseobservability.MonitorOverview {
+	var matching []suseobservability.MonitorOverview
+	for _, m := range monitors {
+		for _, state := range states {
+			if stateCount(m, state) > 0 {
+				matching = append(matching, m)
+				break
+			}
+		}
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		for _, state := range states {
+			a, b := stateCount(matching[i], state), stateCount(matching[j], state)
+			if a != b {
+				return a > b
+			}
+		}
+		return matching[i].Monitor.Name < matching[j].Monitor.Name
+	})
+	return matching
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMonitorStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []string
+		err      string
+	}{
+		{name: "empty defaults to critical", value: "", expected: []string{"CRITICAL"}},
+		{name: "single state", value: "DEVIATING", expected: []string{"DEVIATING"}},
+		{name: "multiple states in severity order", value: "deviating, CRITICAL,deviating", expected: []string{"CRITICAL", "DEVIATING"}},
+		{name: "invalid state", value: "CRITICAL,FIRING", err: `invalid state "FIRING", must be one of CRITICAL, DEVIATING, UNKNOWN, CLEAR`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states, err := parseMonitorStates(tt.value)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, states)
+		})
+	}
+}
+
+func TestGetMonitors(t *testing.T) {
+	overview := &suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{
+		monitorOverview("pod restarts", 0, 4),
+		monitorOverview("disk full", 2, 1),
+		monitorOverview("quiet", 0, 0),
+		monitorOverview("node down", 3, 0),
+	}}
+
+	run := func(t *testing.T, params GetMonitorsParams) (string, error) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("GetMonitorsOverview", context.Background()).Return(overview, nil)
+		tool := NewBaseTool(mockClient)
+
+		result, _, err := tool.GetMonitors(context.Background(), &mcp.CallToolRequest{}, params)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(*mcp.TextContent).Text, nil
+	}
+
+	t.Run("single state", func(t *testing.T) {
+		text, err := run(t, GetMonitorsParams{State: "CRITICAL"})
+
+		require.NoError(t, err)
+		assert.Contains(t, text, "Monitors with CRITICAL results, showing 2 of 2 fetched")
+		assert.Contains(t, text, "| CRITICAL | 5 |")
+		assert.Contains(t, text, "| Monitor | ID | CRITICAL |\n|---|---|---|\n| node down | 0 | 3 |\n| disk full | 0 | 2 |\n")
+		assert.NotContains(t, text, "pod restarts")
+	})
+
+	t.Run("structured content", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("GetMonitorsOverview", context.Background()).Return(overview, nil)
+
+		_, structured, err := NewBaseTool(mockClient).GetMonitors(context.Background(), &mcp.CallToolRequest{}, GetMonitorsParams{State: "CRITICAL,DEVIATING", Limit: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, &MonitorStatesResult{
+			States: []string{"CRITICAL", "DEVIATING"},
+			Monitors: []MonitorState{
+				{Name: "node down", Counts: map[string]int{"CRITICAL": 3, "DEVIATING": 0}},
+				{Name: "disk full", Counts: map[string]int{"CRITICAL": 2, "DEVIATING": 1}},
+				{Name: "pod restarts", Counts: map[string]int{"CRITICAL": 0, "DEVIATING": 4}},
+			},
+		}, structured)
+	})
+
+	t.Run("default state", func(t *testing.T) {
+		text, err := run(t, GetMonitorsParams{})
+
+		require.NoError(t, err)
+		assert.Contains(t, text, "Monitors with CRITICAL results, showing 2 of 2 fetched")
+	})
+
+	t.Run("multiple states", func(t *testing.T) {
+		text, err := run(t, GetMonitorsParams{State: "DEVIATING,CRITICAL"})
+
+		require.NoError(t, err)
+		assert.Contains(t, text, "Monitors with CRITICAL, DEVIATING results, showing 3 of 3 fetched")
+		assert.Contains(t, text, "| CRITICAL | 5 |\n| DEVIATING | 5 |\n")
+		assert.Contains(t, text, "| node down | 0 | 3 | 0 |\n| disk full | 0 | 2 | 1 |\n| pod restarts | 0 | 0 | 4 |\n")
+		assert.NotContains(t, text, "quiet")
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		_, err := run(t, GetMonitorsParams{State: "CRITICAL,BROKEN"})
+
+		assert.EqualError(t, err, `invalid state "BROKEN", must be one of CRITICAL, DEVIATING, UNKNOWN, CLEAR`)
+	})
+
+	t.Run("no matching monitors", func(t *testing.T) {
+		text, err := run(t, GetMonitorsParams{State: "UNKNOWN"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No monitors with UNKNOWN results found.", text)
+	})
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMonitor(t *testing.T) {
+	ctx := context.Background()
+	monitor := &suseobservability.Monitor{
+		Id:                  42,
+		Name:                "Pod restarts",
+		Identifier:          "urn:stackpack:kubernetes:monitor:pod-restarts",
+		Description:         "Pods restarting\ntoo often",
+		FunctionId:          7,
+		RemediationHint:     "Check the pod logs.",
+		IntervalSeconds:     30,
+		Tags:                []string{"pods"},
+		Source:              "StackPack",
+		Status:              suseobservability.MonitorStatusEnabled,
+		RuntimeStatus:       suseobservability.MonitorRuntimeStatusEnabled,
+		LastUpdateTimestamp: 1700000000000,
+	}
+	overview := &suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{
+		{Monitor: suseobservability.Monitor{Id: 1, Name: "Other"}},
+		{
+			Monitor:  *monitor,
+			Function: suseobservability.MonitorFunction{Name: "Threshold", Identifier: "urn:system:default:monitor-function:threshold"},
+			Errors:   []suseobservability.MonitorError{{Error: "query timed out", Count: 2, Level: "WARNING"}},
+			RuntimeMetrics: suseobservability.MonitorRuntimeMetrics{
+				CriticalCount: 7, DeviatingCount: 1, ClearCount: 300, HealthStatesCount: 308, GroupCount: 2,
+				LastRunTimestamp: 1700000060000, LastSuccessfulRunTimestamp: 1700000060000,
+			},
+		},
+	}}
+
+	t.Run("lists every affected component of the monitor", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		// A full page of critical states is followed by a partial one
+		critical := append(checkStatePages(0, 1, monitorCheckStatePageSize, "CRITICAL"), checkStatePages(monitorCheckStatePageSize, 1, 2, "CRITICAL")...)
+		critical[1][1].Message = "Restarted 12 times\nin the last 10 minutes | OOMKilled"
+		affected := monitorCheckStatePageSize + 3
+		mockClient.On("GetMonitor", ctx, "42").Return(monitor, nil).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(overview, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "CRITICAL", monitorCheckStatePageSize, int64(0)).
+			Return(critical, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "DEVIATING", monitorCheckStatePageSize, int64(0)).
+			Return(checkStatePages(affected-1, 1, 1, "DEVIATING"), nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "UNKNOWN", monitorCheckStatePageSize, int64(0)).
+			Return(nil, nil).Once()
+
+		result, _, err := tools.GetMonitor(ctx, nil, GetMonitorParams{ID: " 42 "})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Monitor 'Pod restarts' (ID 42):")
+		assert.Contains(t, output, "| URN | urn:stackpack:kubernetes:monitor:pod-restarts |\n")
+		assert.Contains(t, output, "| Description | Pods restarting too often |\n")
+		assert.Contains(t, output, "| Function | Threshold (urn:system:default:monitor-function:threshold) |\n")
+		assert.Contains(t, output, "| Interval | 30s |\n")
+		assert.Contains(t, output, "| CRITICAL | 7 |\n| DEVIATING | 1 |\n| UNKNOWN | 0 |\n| CLEAR | 300 |\n")
+		assert.Contains(t, output, "| Last failed run | - |\n")
+		assert.Contains(t, output, "Run error (WARNING, 2 time(s)): query timed out")
+		assert.Contains(t, output, "Remediation hint:\n\nCheck the pod logs.\n")
+		assert.Contains(t, output, fmt.Sprintf("Affected components (%d):", affected))
+		assert.Contains(t, output, "| CRITICAL | 1002 |\n| DEVIATING | 1 |\n| UNKNOWN | 0 |\n")
+		for id := 0; id < affected; id++ {
+			assert.Contains(t, output, fmt.Sprintf("| %d | pod-%d |", id, id))
+		}
+		assert.Contains(t, output, "| 1001 | pod-1001 | CRITICAL | 1001 | Restarted 12 times in the last 10 minutes | OOMKilled |\n")
+		assert.Contains(t, output, "| 1002 | pod-1002 | DEVIATING | 1002 | - |\n")
+		assert.NotContains(t, output, "Stopped after")
+		assert.Contains(t, output, "Current values:\n\nUnavailable: the monitor definition does not expose a metric query.\n")
+		assert.False(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("current values of failing components against the threshold", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		metricMonitor := *monitor
+		metricMonitor.Arguments = []map[string]any{
+			{"_type": "ArgumentPromQLMetricVal", "parameter": "metric", "value": map[string]any{"query": "increase(restarts_total[10m])", "unit": "short"}},
+			{"_type": "ArgumentComparatorWithoutEqualityVal", "parameter": "comparator", "value": "GT"},
+			{"_type": "ArgumentDoubleVal", "parameter": "criticalThreshold", "value": 5.0},
+			{"_type": "ArgumentDoubleVal", "parameter": "deviatingThreshold", "value": 2.0},
+		}
+		states := checkStatePages(0, 1, 3, "CRITICAL")
+		states[0][2].Health = "DEVIATING"
+		mockClient.On("GetMonitor", ctx, "42").Return(&metricMonitor, nil).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(overview, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "CRITICAL", monitorCheckStatePageSize, int64(0)).Return(states, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "DEVIATING", monitorCheckStatePageSize, int64(0)).Return(nil, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "UNKNOWN", monitorCheckStatePageSize, int64(0)).Return(nil, nil).Once()
+		mockClient.On("QueryMetric", ctx, "increase(restarts_total[10m])", mock.AnythingOfType("time.Time"), "").
+			Return(&suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{ResultType: "vector", Result: []suseobservability.MetricResult{
+				{Labels: map[string]string{"pod": "pod-0", "namespace": "shop"}, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 12}}},
+				{Labels: map[string]string{"pod": "pod-2", "namespace": "shop"}, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1.5}}},
+			}}}, nil).Once()
+
+		result, _, err := tools.GetMonitor(ctx, nil, GetMonitorParams{ID: "42"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Thresholds:\n\n- criticalThreshold: > 5\n- deviatingThreshold: > 2\n")
+		assert.Contains(t, output, "Query:\n```\nincrease(restarts_total[10m])\n```\n")
+		assert.Contains(t, output, "| Component | Health | Series | Value | Threshold | Difference |\n|---|---|---|---|---|---|\n"+
+			"| pod-0 | CRITICAL | `{namespace=\"shop\", pod=\"pod-0\"}` | 12 | > 5 | +7 |\n"+
+			"| pod-1 | CRITICAL | - | - | > 5 | - |\n"+
+			"| pod-2 | DEVIATING | `{namespace=\"shop\", pod=\"pod-2\"}` | 1.5 | > 2 | -0.5 |\n")
+		assert.False(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("failing monitor query degrades to a partial result", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		metricMonitor := *monitor
+		metricMonitor.Arguments = []map[string]any{{"parameter": "query", "value": "restarts"}, {"parameter": "threshold", "value": 3.0}}
+		mockClient.On("GetMonitor", ctx, "42").Return(&metricMonitor, nil).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(overview, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "CRITICAL", monitorCheckStatePageSize, int64(0)).Return(checkStatePages(0, 1, 1, "CRITICAL"), nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "DEVIATING", monitorCheckStatePageSize, int64(0)).Return(nil, nil).Once()
+		mockClient.On("EachMonitorCheckStatePage", ctx, "42", "UNKNOWN", monitorCheckStatePageSize, int64(0)).Return(nil, nil).Once()
+		mockClient.On("QueryMetric", ctx, "restarts", mock.AnythingOfType("time.Time"), "").Return(nil, errors.New("query timed out")).Once()
+
+		result, _, err := tools.GetMonitor(ctx, nil, GetMonitorParams{ID: "42"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Thresholds:\n\n- threshold: 3\n")
+		assert.Contains(t, output, "Current values:\n\nUnavailable: query timed out\n")
+		assert.Contains(t, output, "Affected components (1):")
+		assert.True(t, IsPartial(result))
+	})
+
+	t.Run("monitor without affected components", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("GetMonitor", ctx, "urn:stackpack:kubernetes:monitor:pod-restarts").Return(monitor, nil).Once()
+		mockClient.On("GetMonitorsOverview", ctx).Return(nil, errors.New("overview unavailable")).Once()
+		for _, state := range affectedStates {
+			mockClient.On("EachMonitorCheckStatePage", ctx, "urn:stackpack:kubernetes:monitor:pod-restarts", state, monitorCheckStatePageSize, int64(0)).Return(nil, nil).Once()
+		}
+
+		result, _, err := tools.GetMonitor(ctx, nil, GetMonitorParams{ID: "urn:stackpack:kubernetes:monitor:pod-restarts"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| Function | 7 |\n")
+		assert.Contains(t, output, "Runtime metrics:\n\nUnavailable: overview unavailable\n")
+		assert.Contains(t, output, "No affected components, every check state is CLEAR.")
+		assert.True(t, IsPartial(result), "the runtime metrics are missing")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown monitor", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("GetMonitor", ctx, "404").Return(nil, errors.New("not found")).Once()
+
+		_, _, err := tools.GetMonitor(ctx, nil, GetMonitorParams{ID: "404"})
+
+		assert.EqualError(t, err, "failed to get monitor '404': not found")
+	})
+
+	t.Run("id is required", func(t *testing.T) {
+		_, _, err := NewBaseTool(new(MockSuseObservabilityClient)).GetMonitor(ctx, nil, GetMonitorParams{ID: " "})
+
+		assert.EqualError(t, err, "id is required")
+	})
+}
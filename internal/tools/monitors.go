@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -10,16 +11,63 @@ import (
 
 type ListMonitorsParams struct {
 	ComponentID int64 `json:"component_id" jsonschema:"required,The ID of the component to list monitors for"`
+	Limit       int   `json:"limit,omitempty" jsonschema:"Maximum number of monitors listed (default: 50)"`
+}
+
+// ComponentMonitorsResult is the structured content returned by listMonitors. Unlike the
+// markdown table it is not capped by limit and holds the full queries and remediation hints.
+type ComponentMonitorsResult struct {
+	ComponentID   int64              `json:"component_id"`
+	ComponentName string             `json:"component_name"`
+	Monitors      []ComponentMonitor `json:"monitors"`
+}
+
+// ComponentMonitor is the check state of a monitor on a component
+type ComponentMonitor struct {
+	Name            string   `json:"name"`
+	Health          string   `json:"health"`
+	RemediationHint string   `json:"remediation_hint,omitempty"`
+	Queries         []string `json:"queries,omitempty"`
+}
+
+// maxMonitorQueryLength is the maximum number of characters of a monitor query in the detail block
+const maxMonitorQueryLength = 1000
+
+// monitorQueries holds the queries of a monitor
+type monitorQueries struct {
+	name    string
+	queries []string
 }
 
 // ListMonitors lists monitors for a specific component using the Component API
-func (t tool) ListMonitors(ctx context.Context, request *mcp.CallToolRequest, params ListMonitorsParams) (*mcp.CallToolResult, any, error) {
+func (t tool) ListMonitors(ctx context.Context, request *mcp.CallToolRequest, params ListMonitorsParams) (*mcp.CallToolResult, *ComponentMonitorsResult, error) {
+	limit, err := displayLimit(params.Limit, t.limits.MonitorRows)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Get component with synced check states
 	res, err := t.client.GetComponent(ctx, params.ComponentID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get component: %w", err)
 	}
 
+	structured := &ComponentMonitorsResult{
+		ComponentID:   params.ComponentID,
+		ComponentName: res.Node.Name,
+		Monitors:      make([]ComponentMonitor, 0, len(res.Node.SyncedCheckStates)),
+	}
+	for _, checkStateData := range res.Node.SyncedCheckStates {
+		var m ComponentMonitor
+		m.Name, _ = checkStateData["name"].(string)
+		m.Health, _ = checkStateData["health"].(string)
+		if dataField, ok := checkStateData["data"].(map[string]interface{}); ok {
+			m.RemediationHint, _ = dataField["remediationHint"].(string)
+			m.Queries = displayQueries(dataField)
+		}
+		structured.Monitors = append(structured.Monitors, m)
+	}
+
 	// Check if component has synced check states
 	if len(res.Node.SyncedCheckStates) == 0 {
 		return &mcp.CallToolResult{
@@ -28,16 +76,25 @@ func (t tool) ListMonitors(ctx context.Context, request *mcp.CallToolRequest, pa
 					Text: fmt.Sprintf("No monitors found for component '%s' (ID: %d)", res.Node.Name, params.ComponentID),
 				},
 			},
-		}, nil, nil
+		}, structured, nil
 	}
 
 	// Build output table
+	checkStates := res.Node.SyncedCheckStates
+	if len(checkStates) > limit {
+		checkStates = checkStates[:limit]
+	}
+
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Found %d monitor(s) for component '%s' (ID: %d):\n\n", len(res.Node.SyncedCheckStates), res.Node.Name, params.ComponentID))
+	sb.WriteString(fmt.Sprintf("Monitors of component '%s' (ID: %d), %s:\n\n", res.Node.Name, params.ComponentID,
+		countSummary(len(checkStates), len(res.Node.SyncedCheckStates))))
 	sb.WriteString("| Monitor Name | Health | Query | Remediation Hint |\n")
 	sb.WriteString("|---|---|---|---|\n")
 
-	for _, checkStateData := range res.Node.SyncedCheckStates {
+	// queries of every distinct monitor, rendered below the table as they can be long
+	var details []monitorQueries
+	seen := map[string]bool{}
+	for _, checkStateData := range checkStates {
 		// Extract monitor name from check state data
 		name := ""
 		if nameField, ok := checkStateData["name"].(string); ok {
@@ -56,25 +113,15 @@ func (t tool) ListMonitors(ctx context.Context, request *mcp.CallToolRequest, pa
 		if dataField, ok := checkStateData["data"].(map[string]interface{}); ok {
 			// Extract remediation hint
 			if remediationHint, ok := dataField["remediationHint"].(string); ok {
-				hint = remediationHint
-				if len(hint) > 100 {
-					hint = hint[:97] + "..."
-				}
+				hint = Truncate(remediationHint, 100)
 			}
 
-			// Extract query from displayTimeSeries
-			if displayTimeSeries, ok := dataField["displayTimeSeries"].([]interface{}); ok && len(displayTimeSeries) > 0 {
-				if series, ok := displayTimeSeries[0].(map[string]interface{}); ok {
-					if queries, ok := series["queries"].([]interface{}); ok && len(queries) > 0 {
-						if queryData, ok := queries[0].(map[string]interface{}); ok {
-							if q, ok := queryData["query"].(string); ok {
-								query = fmt.Sprintf("`%s`", q)
-								if len(query) > 80 {
-									query = query[:77] + "...`"
-								}
-							}
-						}
-					}
+			queries := displayQueries(dataField)
+			if len(queries) > 0 {
+				query = fmt.Sprintf("`%s`", Truncate(queries[0], 78))
+				if !seen[name] {
+					seen[name] = true
+					details = append(details, monitorQueries{name: name, queries: queries})
 				}
 			}
 		}
@@ -82,11 +129,38 @@ func (t tool) ListMonitors(ctx context.Context, request *mcp.CallToolRequest, pa
 		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, health, query, hint))
 	}
 
+	if len(details) > 0 {
+		sb.WriteString("\nMonitor queries:\n")
+		for _, d := range details {
+			sb.WriteString(fmt.Sprintf("\n%s:\n", valueOrDash(d.name)))
+			for _, q := range d.queries {
+				sb.WriteString(fmt.Sprintf("```\n%s\n```\n", Truncate(q, maxMonitorQueryLength)))
+			}
+		}
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{
 				Text: sb.String(),
 			},
 		},
-	}, nil, nil
+	}, structured, nil
+}
+
+// displayQueries returns the distinct queries of every series in data.displayTimeSeries of a check state
+func displayQueries(data map[string]interface{}) []string {
+	var queries []string
+	displayTimeSeries, _ := data["displayTimeSeries"].([]interface{})
+	for _, s := range displayTimeSeries {
+		series, _ := s.(map[string]interface{})
+		seriesQueries, _ := series["queries"].([]interface{})
+		for _, qd := range seriesQueries {
+			queryData, _ := qd.(map[string]interface{})
+			if q, ok := queryData["query"].(string); ok && q != "" && !slices.Contains(queries, q) {
+				queries = append(queries, q)
+			}
+		}
+	}
+	return queries
 }
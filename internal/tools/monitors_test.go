@@ -3,12 +3,14 @@ package tools
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"suse-observability-mcp/client/suseobservability"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListMonitors(t *testing.T) {
@@ -48,10 +50,15 @@ func TestListMonitors(t *testing.T) {
 		mockClient.On("GetComponent", ctx, componentID).
 			Return(expectedResponse, nil).Once()
 
-		result, _, err := tools.ListMonitors(ctx, nil, params)
+		result, structured, err := tools.ListMonitors(ctx, nil, params)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
+		assert.Equal(t, &ComponentMonitorsResult{
+			ComponentID:   componentID,
+			ComponentName: "test-component",
+			Monitors:      []ComponentMonitor{{Name: "High CPU", Health: "CRITICAL", RemediationHint: "Check logs", Queries: []string{"avg(cpu)"}}},
+		}, structured)
 		output := result.Content[0].(*mcp.TextContent).Text
 		assert.Contains(t, output, "High CPU")
 		assert.Contains(t, output, "CRITICAL")
@@ -59,6 +66,82 @@ func TestListMonitors(t *testing.T) {
 		assert.Contains(t, output, "avg(cpu)")
 	})
 
+	t.Run("limit separates shown from fetched", func(t *testing.T) {
+		componentID := int64(321)
+		params := ListMonitorsParams{ComponentID: componentID, Limit: 1}
+
+		expectedResponse := &suseobservability.ComponentResponse{
+			Node: suseobservability.ComponentNode{
+				ID:   componentID,
+				Name: "test-component",
+				SyncedCheckStates: []map[string]interface{}{
+					{"name": "High CPU", "health": "CRITICAL"},
+					{"name": "High Memory", "health": "DEVIATING"},
+				},
+			},
+		}
+
+		mockClient.On("GetComponent", ctx, componentID).
+			Return(expectedResponse, nil).Once()
+
+		result, _, err := tools.ListMonitors(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Monitors of component 'test-component' (ID: 321), showing 1 of 2 fetched:")
+		assert.Contains(t, output, "High CPU")
+		assert.NotContains(t, output, "High Memory")
+	})
+
+	t.Run("queries are detailed below the table", func(t *testing.T) {
+		componentID := int64(654)
+		params := ListMonitorsParams{ComponentID: componentID}
+		checkState := func(name string, queries ...string) map[string]interface{} {
+			var qs []interface{}
+			for _, q := range queries {
+				qs = append(qs, map[string]interface{}{"query": q})
+			}
+			return map[string]interface{}{
+				"name":   name,
+				"health": "CRITICAL",
+				"data": map[string]interface{}{
+					"displayTimeSeries": []interface{}{map[string]interface{}{"queries": qs}},
+				},
+			}
+		}
+		longQuery := `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="default", container!=""}[5m])) / sum by (pod) (kube_pod_container_resource_limits{resource="cpu"}) > 0.9`
+		hugeQuery := strings.Repeat("x", 1500)
+
+		expectedResponse := &suseobservability.ComponentResponse{
+			Node: suseobservability.ComponentNode{
+				ID:   componentID,
+				Name: "test-component",
+				SyncedCheckStates: []map[string]interface{}{
+					checkState("CPU throttling", longQuery, "avg(cpu)"),
+					checkState("CPU throttling", longQuery),
+					checkState("Huge", hugeQuery),
+					{"name": "No query", "health": "CLEAR"},
+				},
+			},
+		}
+
+		mockClient.On("GetComponent", ctx, componentID).
+			Return(expectedResponse, nil).Once()
+
+		result, _, err := tools.ListMonitors(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		table, details, found := strings.Cut(output, "\nMonitor queries:\n")
+		require.True(t, found)
+		assert.NotContains(t, table, longQuery)
+		assert.Equal(t, 1, strings.Count(details, "\nCPU throttling:\n"))
+		assert.Equal(t, 1, strings.Count(details, longQuery))
+		assert.Contains(t, details, "```\navg(cpu)\n```")
+		assert.Contains(t, details, "\nHuge:\n```\n"+strings.Repeat("x", 999)+"…\n```")
+		assert.NotContains(t, details, "No query")
+	})
+
 	t.Run("success no monitors", func(t *testing.T) {
 		componentID := int64(456)
 		params := ListMonitorsParams{ComponentID: componentID}
@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetNamespaceResourceUsageParams struct {
+	Cluster string `json:"cluster" jsonschema:"required,The cluster name to summarize"`
+	Window  string `json:"window,omitempty" jsonschema:"The window usage is averaged over, e.g. '15m' or '1d' (default: 1h, max: 7d)" default:"1h" examples:"15m;1d"`
+	SortBy  string `json:"sort_by,omitempty" jsonschema:"The resource namespaces are ranked by: cpu, memory or pods (default: cpu)" default:"cpu" examples:"cpu;memory;pods"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Number of top namespaces to show (default: 10)" default:"10"`
+}
+
+// defaultNamespaceRows is the number of namespaces getNamespaceResourceUsage shows by default
+const defaultNamespaceRows = 10
+
+// namespaceResource is a column of the namespace usage table
+type namespaceResource struct {
+	name   string
+	header string
+	query  string
+	format func(float64) string
+}
+
+// namespaceUsage holds the value of every resource of a namespace, resources without data are absent
+type namespaceUsage struct {
+	namespace string
+	values    map[string]float64
+}
+
+// resourceResult is the outcome of the query of a resource
+type resourceResult struct {
+	series []Series
+	err    error
+}
+
+// namespaceResources returns the resources with a configured query, in column order
+func (t tool) namespaceResources() []namespaceResource {
+	all := []namespaceResource{
+		{name: "cpu", header: "CPU (cores)", query: t.namespaceQueries.CPU, format: func(v float64) string { return fmt.Sprintf("%.3f", v) }},
+		{name: "memory", header: "Memory (working set)", query: t.namespaceQueries.Memory, format: formatBytes},
+		{name: "pods", header: "Pods", query: t.namespaceQueries.Pods, format: func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+	}
+	resources := make([]namespaceResource, 0, len(all))
+	for _, r := range all {
+		if r.query != "" {
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// GetNamespaceResourceUsage ranks the namespaces of a cluster by their CPU, memory or pod usage
+func (t tool) GetNamespaceResourceUsage(ctx context.Context, request *mcp.CallToolRequest, params GetNamespaceResourceUsageParams) (*mcp.CallToolResult, any, error) {
+	cluster := strings.TrimSpace(params.Cluster)
+	if cluster == "" {
+		return nil, nil, fmt.Errorf("cluster is required")
+	}
+	window, err := parseLookback(params.Window)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit, err := displayLimit(params.Limit, defaultNamespaceRows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources := t.namespaceResources()
+	if len(resources) == 0 {
+		return nil, nil, fmt.Errorf("no namespace resource queries are configured")
+	}
+	sortBy := strings.ToLower(strings.TrimSpace(params.SortBy))
+	if sortBy == "" {
+		sortBy = "cpu"
+	}
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.name)
+	}
+	if !slices.Contains(names, sortBy) {
+		return nil, nil, fmt.Errorf("invalid sort_by %q, must be one of %s", params.SortBy, strings.Join(names, ", "))
+	}
+
+	windowText := formatLookback(window)
+	queries := make([]string, len(resources))
+	replacer := strings.NewReplacer("$cluster", promQLString(cluster), "$window", windowText)
+	for i, r := range resources {
+		queries[i] = replacer.Replace(r.query)
+	}
+
+	// The window is covered by the range selectors of the queries, they are evaluated as
+	// instant queries at the end of it
+	end := t.clock.Now()
+	results := make([]resourceResult, len(resources))
+	var wg sync.WaitGroup
+	for i, q := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := t.client.QueryMetric(ctx, q, end, "")
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].series = newSeries(res.Data.Result)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for i, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", resources[i].name, res.err))
+		}
+	}
+	if len(errs) == len(resources) {
+		return nil, nil, fmt.Errorf("failed to get resource usage of cluster '%s': %w", cluster, errors.Join(errs...))
+	}
+
+	usage := mergeNamespaceUsage(resources, results)
+	if len(usage) == 0 {
+		result := &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("No namespace resource usage found for cluster '%s' in the last %s, check the cluster name with getComponents.\n\n%s", cluster, windowText, formatResourceQueries(resources, queries, results)),
+				},
+			},
+		}
+		if len(errs) > 0 {
+			markPartial(result)
+		}
+		return result, nil, nil
+	}
+	sortNamespaceUsage(usage, sortBy)
+	shown := min(limit, len(usage))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Resource usage of namespaces in cluster '%s' over the last %s, sorted by %s (%s):\n\n", cluster, windowText, sortBy, countSummary(shown, len(usage))))
+	sb.WriteString("| Namespace |")
+	for _, r := range resources {
+		sb.WriteString(fmt.Sprintf(" %s |", r.header))
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", len(resources)))
+	sb.WriteString("\n")
+	for _, u := range usage[:shown] {
+		sb.WriteString(fmt.Sprintf("| %s |", u.namespace))
+		for _, r := range resources {
+			cell := ""
+			if v, ok := u.values[r.name]; ok {
+				cell = r.format(v)
+			}
+			sb.WriteString(fmt.Sprintf(" %s |", valueOrDash(cell)))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(formatResourceQueries(resources, queries, results))
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}
+	if len(errs) > 0 {
+		markPartial(result)
+	}
+	return result, nil, nil
+}
+
+// mergeNamespaceUsage joins the series of the resources on their namespace label, taking the
+// latest value of every series. Series without a namespace or values are skipped, so a
+// namespace lacks the resources it has no data for.
+func mergeNamespaceUsage(resources []namespaceResource, results []resourceResult) []namespaceUsage {
+	byNamespace := map[string]map[string]float64{}
+	for i, res := range results {
+		for _, s := range res.series {
+			namespace := s.Labels["namespace"]
+			if namespace == "" {
+				continue
+			}
+			value, ok := s.Aggregate(aggregateLast)
+			if !ok {
+				continue
+			}
+			if byNamespace[namespace] == nil {
+				byNamespace[namespace] = map[string]float64{}
+			}
+			byNamespace[namespace][resources[i].name] += value
+		}
+	}
+
+	usage := make([]namespaceUsage, 0, len(byNamespace))
+	for namespace, values := range byNamespace {
+		usage = append(usage, namespaceUsage{namespace: namespace, values: values})
+	}
+	return usage
+}
+
+// sortNamespaceUsage orders the namespaces by the resource, highest first. Namespaces without
+// data for it come last, ties are ordered by name.
+func sortNamespaceUsage(usage []namespaceUsage, resource string) {
+	sort.Slice(usage, func(i, j int) bool {
+		vi, okI := usage[i].values[resource]
+		vj, okJ := usage[j].values[resource]
+		if okI != okJ {
+			return okI
+		}
+		if vi != vj {
+			return vi > vj
+		}
+		return usage[i].namespace < usage[j].namespace
+	})
+}
+
+// formatResourceQueries lists the query of every resource, so the underlying metrics can be
+// drilled into with getMetrics, and why a resource has no data
+func formatResourceQueries(resources []namespaceResource, queries []string, results []resourceResult) string {
+	var sb strings.Builder
+	sb.WriteString("Queries used:\n")
+	for i, r := range resources {
+		sb.WriteString(fmt.Sprintf("- %s: `%s`", r.name, queries[i]))
+		switch {
+		case results[i].err != nil:
+			sb.WriteString(fmt.Sprintf(" (failed: %s)", results[i].err))
+		case len(results[i].series) == 0:
+			sb.WriteString(" (no data, the metric may not exist in this cluster)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// promQLString escapes s for use inside a double-quoted PromQL string
+func promQLString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "1.5 GiB"
+func formatBytes(v float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for math.Abs(v) >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", v, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
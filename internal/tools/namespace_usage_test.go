@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func namespaceResponse(values map[string]float64) *suseobservability.MetricQueryResponse {
+	res := &suseobservability.MetricQueryResponse{Data: suseobservability.MetricData{ResultType: "vector"}}
+	for namespace, v := range values {
+		res.Data.Result = append(res.Data.Result, suseobservability.MetricResult{
+			Labels: map[string]string{"namespace": namespace},
+			Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: v}},
+		})
+	}
+	return res
+}
+
+func TestMergeNamespaceUsage(t *testing.T) {
+	resources := []namespaceResource{{name: "cpu"}, {name: "memory"}}
+
+	t.Run("joins resources on the namespace", func(t *testing.T) {
+		usage := mergeNamespaceUsage(resources, []resourceResult{
+			{series: []Series{
+				{Labels: map[string]string{"namespace": "a"}, Points: []Point{{Timestamp: 1, Value: 0.5}, {Timestamp: 2, Value: 1.5}}},
+				{Labels: map[string]string{"namespace": "b"}, Points: []Point{{Timestamp: 2, Value: 2}}},
+				{Labels: map[string]string{}, Points: []Point{{Timestamp: 2, Value: 9}}},
+			}},
+			{series: []Series{
+				{Labels: map[string]string{"namespace": "a"}, Points: []Point{{Timestamp: 2, Value: 1024}}},
+				{Labels: map[string]string{"namespace": "c"}, Points: []Point{{Timestamp: 2, Value: 2048}}},
+			}},
+		})
+		sortNamespaceUsage(usage, "cpu")
+
+		assert.Equal(t, []namespaceUsage{
+			{namespace: "b", values: map[string]float64{"cpu": 2}},
+			{namespace: "a", values: map[string]float64{"cpu": 1.5, "memory": 1024}},
+			{namespace: "c", values: map[string]float64{"memory": 2048}},
+		}, usage)
+	})
+
+	t.Run("failed resource leaves namespaces without it", func(t *testing.T) {
+		usage := mergeNamespaceUsage(resources, []resourceResult{
+			{err: errors.New("boom")},
+			{series: []Series{{Labels: map[string]string{"namespace": "a"}, Points: []Point{{Timestamp: 2, Value: 1}}}}},
+		})
+
+		assert.Equal(t, []namespaceUsage{{namespace: "a", values: map[string]float64{"memory": 1}}}, usage)
+	})
+
+	t.Run("ties are ordered by name", func(t *testing.T) {
+		usage := []namespaceUsage{
+			{namespace: "b", values: map[string]float64{"pods": 3}},
+			{namespace: "a", values: map[string]float64{"pods": 3}},
+		}
+		sortNamespaceUsage(usage, "pods")
+
+		assert.Equal(t, "a", usage[0].namespace)
+	})
+}
+
+func TestGetNamespaceResourceUsage(t *testing.T) {
+	ctx := context.Background()
+	queries := NamespaceQueries{
+		CPU:    `cpu{cluster="$cluster"}[$window]`,
+		Memory: `memory{cluster="$cluster"}`,
+		Pods:   `pods{cluster="$cluster"}`,
+	}
+	queryInstant := func(m *MockSuseObservabilityClient, query string) *mock.Call {
+		return m.On("QueryMetric", ctx, query, mock.AnythingOfType("time.Time"), "")
+	}
+
+	t.Run("merges the queries into one table", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient).WithNamespaceQueries(queries)
+		queryInstant(mockClient, `cpu{cluster="prod"}[1h]`).Return(namespaceResponse(map[string]float64{"web": 1.25, "db": 0.5}), nil).Once()
+		queryInstant(mockClient, `memory{cluster="prod"}`).Return(namespaceResponse(map[string]float64{"web": 512 * 1024 * 1024, "db": 3 * 1024 * 1024 * 1024}), nil).Once()
+		queryInstant(mockClient, `pods{cluster="prod"}`).Return(namespaceResponse(map[string]float64{"web": 4, "db": 1}), nil).Once()
+
+		result, _, err := tools.GetNamespaceResourceUsage(ctx, nil, GetNamespaceResourceUsageParams{Cluster: "prod", SortBy: "memory"})
+		require.NoError(t, err)
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, text, "Resource usage of namespaces in cluster 'prod' over the last 1h, sorted by memory (showing 2 of 2 fetched):")
+		assert.Contains(t, text, "| Namespace | CPU (cores) | Memory (working set) | Pods |\n|---|---|---|---|\n"+
+			"| db | 0.500 | 3.0 GiB | 1 |\n"+
+			"| web | 1.250 | 512.0 MiB | 4 |\n")
+		assert.Contains(t, text, "- cpu: `cpu{cluster=\"prod\"}[1h]`\n")
+		assert.False(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("missing metric degrades to a dash column", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient).WithNamespaceQueries(queries)
+		queryInstant(mockClient, `cpu{cluster="prod"}[1h]`).Return(namespaceResponse(map[string]float64{"web": 1}), nil).Once()
+		queryInstant(mockClient, `memory{cluster="prod"}`).Return(&suseobservability.MetricQueryResponse{}, nil).Once()
+		queryInstant(mockClient, `pods{cluster="prod"}`).Return(nil, errors.New("boom")).Once()
+
+		result, _, err := tools.GetNamespaceResourceUsage(ctx, nil, GetNamespaceResourceUsageParams{Cluster: "prod"})
+		require.NoError(t, err)
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, text, "| web | 1.000 | - | - |\n")
+		assert.Contains(t, text, "- memory: `memory{cluster=\"prod\"}` (no data, the metric may not exist in this cluster)\n")
+		assert.Contains(t, text, "- pods: `pods{cluster=\"prod\"}` (failed: boom)\n")
+		assert.True(t, IsPartial(result))
+	})
+
+	t.Run("all queries failing is an error", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient).WithNamespaceQueries(NamespaceQueries{CPU: queries.CPU})
+		queryInstant(mockClient, `cpu{cluster="prod"}[1h]`).Return(nil, errors.New("boom")).Once()
+
+		_, _, err := tools.GetNamespaceResourceUsage(ctx, nil, GetNamespaceResourceUsageParams{Cluster: "prod"})
+		assert.ErrorContains(t, err, "failed to get resource usage of cluster 'prod': cpu: boom")
+	})
+
+	t.Run("sort_by must have a query", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient)).WithNamespaceQueries(NamespaceQueries{CPU: queries.CPU})
+
+		_, _, err := tools.GetNamespaceResourceUsage(ctx, nil, GetNamespaceResourceUsageParams{Cluster: "prod", SortBy: "pods"})
+		assert.EqualError(t, err, `invalid sort_by "pods", must be one of cpu`)
+	})
+
+	t.Run("cluster is escaped", func(t *testing.T) {
+		assert.Equal(t, `a\"b\\c`, promQLString(`a"b\c`))
+	})
+}
@@ -0,0 +1,21 @@
+package tools
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// partialMetaKey is the result metadata key marking results that lack a failed part, e.g. a
+// query of a batch. The result is still useful, the text reports what failed.
+const partialMetaKey = "partial"
+
+// markPartial marks a result as lacking a failed part
+func markPartial(result *mcp.CallToolResult) {
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[partialMetaKey] = true
+}
+
+// IsPartial reports whether a result lacks a failed part
+func IsPartial(result *mcp.CallToolResult) bool {
+	partial, _ := result.Meta[partialMetaKey].(bool)
+	return partial
+}
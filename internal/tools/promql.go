@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ValidatePromQLParams struct {
+	Query string `json:"query" jsonschema:"required,The PromQL query to validate"`
+}
+
+// PromQLValidation is the outcome of validatePromQL
+type PromQLValidation struct {
+	Valid      bool             `json:"valid"`
+	Error      string           `json:"error,omitempty"`
+	Line       int              `json:"line,omitempty"`
+	Column     int              `json:"column,omitempty"`
+	ResultType string           `json:"result_type,omitempty"`
+	Selectors  []PromQLSelector `json:"selectors,omitempty"`
+}
+
+// ValidatePromQL parses a PromQL query without executing it
+func (t tool) ValidatePromQL(ctx context.Context, request *mcp.CallToolRequest, params ValidatePromQLParams) (*mcp.CallToolResult, *PromQLValidation, error) {
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, nil, fmt.Errorf("query is required")
+	}
+
+	validation := validatePromQL(params.Query)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatPromQLValidation(params.Query, validation),
+			},
+		},
+	}, validation, nil
+}
+
+// validatePromQL parses query and reports either its selectors or the parse error and its position
+func validatePromQL(query string) *PromQLValidation {
+	resultType, selectors, err := parsePromQL(query)
+	if err != nil {
+		validation := &PromQLValidation{Error: err.Error()}
+		if perr, ok := err.(*promqlError); ok {
+			validation.Line, validation.Column = perr.position(query)
+		}
+		return validation
+	}
+	return &PromQLValidation{Valid: true, ResultType: string(resultType), Selectors: selectors}
+}
+
+func formatPromQLValidation(query string, v *PromQLValidation) string {
+	var sb strings.Builder
+	if !v.Valid {
+		sb.WriteString(fmt.Sprintf("Invalid PromQL at line %d, column %d: %s\n\n", v.Line, v.Column, v.Error))
+		// point at the error in its line
+		line := strings.Split(query, "\n")[v.Line-1]
+		sb.WriteString("```\n")
+		sb.WriteString(line + "\n")
+		sb.WriteString(strings.Repeat(" ", v.Column-1) + "^\n")
+		sb.WriteString("```")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("Valid PromQL, result type: %s.\n\n", v.ResultType))
+	if len(v.Selectors) == 0 {
+		sb.WriteString("The query references no metric selectors.")
+		return sb.String()
+	}
+	sb.WriteString("| Metric | Label Matchers |\n")
+	sb.WriteString("|---|---|\n")
+	for _, s := range v.Selectors {
+		matchers := make([]string, 0, len(s.Matchers))
+		for _, m := range s.Matchers {
+			matchers = append(matchers, m.String())
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", valueOrDash(s.Metric), valueOrDash(strings.Join(matchers, ", "))))
+	}
+	return sb.String()
+}
@@ -0,0 +1,821 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// This file holds a lightweight PromQL parser used by validatePromQL. It checks the syntax and
+// the value types of an expression the way Prometheus does, without evaluating anything, and
+// collects the vector selectors the expression references.
+
+// promqlType is the type of the value an expression evaluates to, named as in Prometheus errors
+type promqlType string
+
+const (
+	promqlScalar promqlType = "scalar"
+	promqlVector promqlType = "instant vector"
+	promqlMatrix promqlType = "range vector"
+	promqlString promqlType = "string"
+)
+
+// PromQLSelector is a vector selector referenced by a query
+type PromQLSelector struct {
+	Metric   string          `json:"metric,omitempty"`
+	Matchers []PromQLMatcher `json:"matchers"`
+}
+
+// PromQLMatcher is a label matcher of a vector selector
+type PromQLMatcher struct {
+	Label string `json:"label"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// String renders the matcher as in a query, quoting label names that are not valid unquoted
+func (m PromQLMatcher) String() string {
+	label := m.Label
+	if !labelNamePattern.MatchString(label) {
+		label = strconv.Quote(label)
+	}
+	return fmt.Sprintf("%s%s%q", label, m.Op, m.Value)
+}
+
+// promqlError is a parse error at a byte offset of the query
+type promqlError struct {
+	pos int
+	msg string
+}
+
+func (e *promqlError) Error() string {
+	return e.msg
+}
+
+// position returns the 1-based line and column of the error in query
+func (e *promqlError) position(query string) (line, column int) {
+	pos := min(e.pos, len(query))
+	before := query[:pos]
+	line = strings.Count(before, "\n") + 1
+	column = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
+
+type promqlTokenKind int
+
+const (
+	tokenEOF promqlTokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenOp
+)
+
+type promqlToken struct {
+	kind promqlTokenKind
+	text string
+	pos  int
+}
+
+func (t promqlToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenString:
+		return "string " + t.text
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+// promqlOperators lists the operator and punctuation tokens, two character operators first
+var promqlOperators = []string{"==", "!=", "<=", ">=", "=~", "!~", "+", "-", "*", "/", "%", "^", "<", ">", "=", "(", ")", "{", "}", "[", "]", ",", ":", "@"}
+
+// lexPromQL splits a query into tokens
+func lexPromQL(query string) ([]promqlToken, error) {
+	var tokens []promqlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(query) && (isIdentStart(query[i]) || isDigit(query[i]) || query[i] == ':') {
+				i++
+			}
+			tokens = append(tokens, promqlToken{kind: tokenIdent, text: query[start:i], pos: start})
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			token, err := lexNumber(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i += len(token.text)
+		case c == '"' || c == '\'' || c == '`':
+			token, err := lexString(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i += len(token.text)
+		default:
+			op := ""
+			for _, o := range promqlOperators {
+				if strings.HasPrefix(query[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(query[i:])
+				return nil, &promqlError{pos: i, msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, promqlToken{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, promqlToken{kind: tokenEOF, pos: len(query)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lexNumber scans a number or, when an integer is directly followed by a unit, a duration such as 1h30m
+func lexNumber(query string, start int) (promqlToken, error) {
+	i := start
+	if strings.HasPrefix(query[i:], "0x") || strings.HasPrefix(query[i:], "0X") {
+		i += 2
+		for i < len(query) && strings.IndexByte("0123456789abcdefABCDEF", query[i]) >= 0 {
+			i++
+		}
+		return promqlToken{kind: tokenNumber, text: query[start:i], pos: start}, nil
+	}
+
+	for i < len(query) && isDigit(query[i]) {
+		i++
+	}
+	if i < len(query) && durationUnitLength(query[i:]) > 0 {
+		for i < len(query) {
+			n := durationUnitLength(query[i:])
+			if n == 0 {
+				break
+			}
+			i += n
+			digits := i
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			if i == digits {
+				break
+			}
+			if durationUnitLength(query[i:]) == 0 {
+				return promqlToken{}, &promqlError{pos: start, msg: fmt.Sprintf("bad duration %q: missing unit after %s", query[start:i], query[digits:i])}
+			}
+		}
+		return promqlToken{kind: tokenDuration, text: query[start:i], pos: start}, nil
+	}
+
+	if i < len(query) && query[i] == '.' {
+		i++
+		for i < len(query) && isDigit(query[i]) {
+			i++
+		}
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < len(query) && isDigit(query[j]) {
+			for i = j; i < len(query) && isDigit(query[i]); i++ {
+			}
+		}
+	}
+	return promqlToken{kind: tokenNumber, text: query[start:i], pos: start}, nil
+}
+
+// durationUnitLength returns the length of the duration unit s starts with, or 0
+func durationUnitLength(s string) int {
+	if strings.HasPrefix(s, "ms") {
+		return 2
+	}
+	if s != "" && strings.IndexByte("smhdwy", s[0]) >= 0 {
+		// a unit must not be the start of an identifier, e.g. the 'm' of 'min'
+		if len(s) > 1 && isIdentStart(s[1]) {
+			return 0
+		}
+		return 1
+	}
+	return 0
+}
+
+// lexString scans a double, single or back quoted string
+func lexString(query string, start int) (promqlToken, error) {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case '\n':
+			if quote != '`' {
+				return promqlToken{}, &promqlError{pos: start, msg: "unterminated quoted string"}
+			}
+		case quote:
+			return promqlToken{kind: tokenString, text: query[start : i+1], pos: start}, nil
+		}
+	}
+	return promqlToken{}, &promqlError{pos: start, msg: "unterminated quoted string"}
+}
+
+// unquotePromQL returns the value of a string token
+func unquotePromQL(t promqlToken) (string, error) {
+	text := t.text
+	if text[0] == '\'' {
+		// turn a single quoted string into a double quoted one, strconv only unquotes runes in single quotes
+		var sb strings.Builder
+		sb.WriteByte('"')
+		inner := text[1 : len(text)-1]
+		for i := 0; i < len(inner); i++ {
+			switch {
+			case inner[i] == '\\' && i+1 < len(inner) && inner[i+1] == '\'':
+				sb.WriteByte('\'')
+				i++
+			case inner[i] == '\\' && i+1 < len(inner):
+				sb.WriteString(inner[i : i+2])
+				i++
+			case inner[i] == '"':
+				sb.WriteString(`\"`)
+			default:
+				sb.WriteByte(inner[i])
+			}
+		}
+		sb.WriteByte('"')
+		text = sb.String()
+	}
+	s, err := strconv.Unquote(text)
+	if err != nil {
+		return "", &promqlError{pos: t.pos, msg: fmt.Sprintf("invalid string %s: %v", t.text, err)}
+	}
+	return s, nil
+}
+
+// promqlFunction is the signature of a PromQL function. The last optional arguments may be
+// omitted and a variadic function repeats its last argument.
+type promqlFunction struct {
+	args     []promqlType
+	optional int
+	variadic bool
+	returns  promqlType
+}
+
+var promqlFunctions = func() map[string]promqlFunction {
+	v, m, s, str := promqlVector, promqlMatrix, promqlScalar, promqlString
+	functions := map[string]promqlFunction{
+		"abs":                          {args: []promqlType{v}, returns: v},
+		"absent":                       {args: []promqlType{v}, returns: v},
+		"absent_over_time":             {args: []promqlType{m}, returns: v},
+		"avg_over_time":                {args: []promqlType{m}, returns: v},
+		"ceil":                         {args: []promqlType{v}, returns: v},
+		"changes":                      {args: []promqlType{m}, returns: v},
+		"clamp":                        {args: []promqlType{v, s, s}, returns: v},
+		"clamp_max":                    {args: []promqlType{v, s}, returns: v},
+		"clamp_min":                    {args: []promqlType{v, s}, returns: v},
+		"count_over_time":              {args: []promqlType{m}, returns: v},
+		"deg":                          {args: []promqlType{v}, returns: v},
+		"delta":                        {args: []promqlType{m}, returns: v},
+		"deriv":                        {args: []promqlType{m}, returns: v},
+		"double_exponential_smoothing": {args: []promqlType{m, s, s}, returns: v},
+		"exp":                          {args: []promqlType{v}, returns: v},
+		"floor":                        {args: []promqlType{v}, returns: v},
+		"histogram_avg":                {args: []promqlType{v}, returns: v},
+		"histogram_count":              {args: []promqlType{v}, returns: v},
+		"histogram_fraction":           {args: []promqlType{s, s, v}, returns: v},
+		"histogram_quantile":           {args: []promqlType{s, v}, returns: v},
+		"histogram_stddev":             {args: []promqlType{v}, returns: v},
+		"histogram_stdvar":             {args: []promqlType{v}, returns: v},
+		"histogram_sum":                {args: []promqlType{v}, returns: v},
+		"holt_winters":                 {args: []promqlType{m, s, s}, returns: v},
+		"idelta":                       {args: []promqlType{m}, returns: v},
+		"increase":                     {args: []promqlType{m}, returns: v},
+		"irate":                        {args: []promqlType{m}, returns: v},
+		"label_join":                   {args: []promqlType{v, str, str, str}, optional: 1, variadic: true, returns: v},
+		"label_replace":                {args: []promqlType{v, str, str, str, str}, returns: v},
+		"last_over_time":               {args: []promqlType{m}, returns: v},
+		"ln":                           {args: []promqlType{v}, returns: v},
+		"log10":                        {args: []promqlType{v}, returns: v},
+		"log2":                         {args: []promqlType{v}, returns: v},
+		"mad_over_time":                {args: []promqlType{m}, returns: v},
+		"max_over_time":                {args: []promqlType{m}, returns: v},
+		"min_over_time":                {args: []promqlType{m}, returns: v},
+		"pi":                           {returns: s},
+		"predict_linear":               {args: []promqlType{m, s}, returns: v},
+		"present_over_time":            {args: []promqlType{m}, returns: v},
+		"quantile_over_time":           {args: []promqlType{s, m}, returns: v},
+		"rad":                          {args: []promqlType{v}, returns: v},
+		"rate":                         {args: []promqlType{m}, returns: v},
+		"resets":                       {args: []promqlType{m}, returns: v},
+		"round":                        {args: []promqlType{v, s}, optional: 1, returns: v},
+		"scalar":                       {args: []promqlType{v}, returns: s},
+		"sgn":                          {args: []promqlType{v}, returns: v},
+		"sort":                         {args: []promqlType{v}, returns: v},
+		"sort_by_label":                {args: []promqlType{v, str}, optional: 1, variadic: true, returns: v},
+		"sort_by_label_desc":           {args: []promqlType{v, str}, optional: 1, variadic: true, returns: v},
+		"sort_desc":                    {args: []promqlType{v}, returns: v},
+		"sqrt":                         {args: []promqlType{v}, returns: v},
+		"stddev_over_time":             {args: []promqlType{m}, returns: v},
+		"stdvar_over_time":             {args: []promqlType{m}, returns: v},
+		"sum_over_time":                {args: []promqlType{m}, returns: v},
+		"time":                         {returns: s},
+		"timestamp":                    {args: []promqlType{v}, returns: v},
+		"vector":                       {args: []promqlType{s}, returns: v},
+	}
+	for _, name := range []string{"acos", "acosh", "asin", "asinh", "atan", "atanh", "cos", "cosh", "sin", "sinh", "tan", "tanh"} {
+		functions[name] = promqlFunction{args: []promqlType{v}, returns: v}
+	}
+	for _, name := range []string{"day_of_month", "day_of_week", "day_of_year", "days_in_month", "hour", "minute", "month", "year"} {
+		functions[name] = promqlFunction{args: []promqlType{v}, optional: 1, returns: v}
+	}
+	return functions
+}()
+
+// promqlAggregations maps the aggregation operators to the type of their parameter, if any
+var promqlAggregations = map[string]promqlType{
+	"sum": "", "avg": "", "count": "", "min": "", "max": "", "group": "", "stddev": "", "stdvar": "",
+	"topk": promqlScalar, "bottomk": promqlScalar, "quantile": promqlScalar, "limitk": promqlScalar, "limit_ratio": promqlScalar,
+	"count_values": promqlString,
+}
+
+// promqlBinaryPrecedence maps the binary operators to their precedence, higher binds tighter
+var promqlBinaryPrecedence = map[string]int{
+	"or":  1,
+	"and": 2, "unless": 2,
+	"==": 3, "!=": 3, "<=": 3, "<": 3, ">=": 3, ">": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5, "atan2": 5,
+	"^": 6,
+}
+
+// promqlParser is a recursive descent parser over the tokens of a query. Errors are raised
+// with panic and recovered by parsePromQL.
+type promqlParser struct {
+	tokens    []promqlToken
+	i         int
+	selectors []PromQLSelector
+}
+
+// parsePromQL checks query and returns the type it evaluates to and the vector selectors it references
+func parsePromQL(query string) (result promqlType, selectors []PromQLSelector, err error) {
+	tokens, err := lexPromQL(query)
+	if err != nil {
+		return "", nil, err
+	}
+	p := &promqlParser{tokens: tokens, selectors: []PromQLSelector{}}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*promqlError)
+			if !ok {
+				panic(r)
+			}
+			result, selectors, err = "", nil, perr
+		}
+	}()
+
+	if p.peek().kind == tokenEOF {
+		p.fail(p.peek(), "no expression found in input")
+	}
+	result = p.parseExpr(1)
+	if t := p.peek(); t.kind != tokenEOF {
+		p.fail(t, "unexpected %s", t)
+	}
+	return result, p.selectors, nil
+}
+
+func (p *promqlParser) peek() promqlToken {
+	return p.tokens[p.i]
+}
+
+func (p *promqlParser) next() promqlToken {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *promqlParser) fail(t promqlToken, format string, args ...any) {
+	panic(&promqlError{pos: t.pos, msg: fmt.Sprintf(format, args...)})
+}
+
+// isOp reports whether t is the operator or punctuation op
+func (t promqlToken) isOp(op string) bool {
+	return t.kind == tokenOp && t.text == op
+}
+
+// isKeyword reports whether t is the case-insensitive keyword
+func (t promqlToken) isKeyword(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (p *promqlParser) expectOp(op, context string) promqlToken {
+	t := p.next()
+	if !t.isOp(op) {
+		p.fail(t, "unexpected %s %s, expected %q", t, context, op)
+	}
+	return t
+}
+
+// binaryOperator returns the binary operator t is, if any
+func binaryOperator(t promqlToken) (string, bool) {
+	op := t.text
+	if t.kind == tokenIdent {
+		op = strings.ToLower(op)
+	} else if t.kind != tokenOp {
+		return "", false
+	}
+	_, ok := promqlBinaryPrecedence[op]
+	return op, ok
+}
+
+func (p *promqlParser) parseExpr(minPrecedence int) promqlType {
+	lhs := p.parseUnary()
+	for {
+		opToken := p.peek()
+		op, ok := binaryOperator(opToken)
+		if !ok || promqlBinaryPrecedence[op] < minPrecedence {
+			return lhs
+		}
+		p.next()
+
+		comparison := promqlBinaryPrecedence[op] == 3
+		setOp := op == "and" || op == "or" || op == "unless"
+		returnBool := false
+		if p.peek().isKeyword("bool") {
+			if !comparison {
+				p.fail(p.peek(), "bool modifier can only be used on comparison operators")
+			}
+			p.next()
+			returnBool = true
+		}
+		matching := false
+		if t := p.peek(); t.isKeyword("on") || t.isKeyword("ignoring") {
+			p.next()
+			p.parseLabelList(strings.ToLower(t.text))
+			matching = true
+			if g := p.peek(); g.isKeyword("group_left") || g.isKeyword("group_right") {
+				if setOp {
+					p.fail(g, "no grouping allowed for %q operation", op)
+				}
+				p.next()
+				if p.peek().isOp("(") {
+					p.parseLabelList(strings.ToLower(g.text))
+				}
+			}
+		} else if g := p.peek(); g.isKeyword("group_left") || g.isKeyword("group_right") {
+			p.fail(g, "%s must be preceded by on or ignoring", strings.ToLower(g.text))
+		}
+
+		// ^ is right associative, all other operators are left associative
+		nextPrecedence := promqlBinaryPrecedence[op] + 1
+		if op == "^" {
+			nextPrecedence = promqlBinaryPrecedence[op]
+		}
+		rhs := p.parseExpr(nextPrecedence)
+
+		switch {
+		case lhs == promqlMatrix || lhs == promqlString || rhs == promqlMatrix || rhs == promqlString:
+			p.fail(opToken, "binary expression must contain only scalar and instant vector types")
+		case setOp && (lhs == promqlScalar || rhs == promqlScalar):
+			p.fail(opToken, "set operator %q not allowed in binary scalar expression", op)
+		case comparison && lhs == promqlScalar && rhs == promqlScalar && !returnBool:
+			p.fail(opToken, "comparisons between scalars must use BOOL modifier")
+		case matching && (lhs == promqlScalar || rhs == promqlScalar):
+			p.fail(opToken, "vector matching only allowed between instant vectors")
+		}
+		if lhs != promqlScalar || rhs != promqlScalar {
+			lhs = promqlVector
+		}
+	}
+}
+
+func (p *promqlParser) parseUnary() promqlType {
+	if t := p.peek(); t.isOp("-") || t.isOp("+") {
+		p.next()
+		operand := p.parseUnary()
+		if operand != promqlScalar && operand != promqlVector {
+			p.fail(t, "unary expression only allowed on expressions of type scalar or instant vector, got %s", operand)
+		}
+		return operand
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary expression followed by range, subquery, offset and @ modifiers
+func (p *promqlParser) parsePostfix() promqlType {
+	start := p.peek()
+	result, selector := p.parsePrimary()
+	// modifiable is true for selectors and subqueries, which accept offset and @
+	modifiable := selector
+	for {
+		t := p.peek()
+		switch {
+		case t.isOp("["):
+			p.next()
+			p.expectDuration("in range")
+			if p.peek().isOp(":") {
+				p.next()
+				if p.peek().kind == tokenDuration {
+					p.next()
+				}
+				if result != promqlVector {
+					p.fail(start, "subquery is only allowed on instant vector, got %s", result)
+				}
+			} else if !selector || result != promqlVector {
+				p.fail(t, "ranges only allowed for vector selectors")
+			}
+			p.expectOp("]", "in range")
+			result, selector, modifiable = promqlMatrix, false, true
+		case t.isKeyword("offset"):
+			if !modifiable {
+				p.fail(t, "offset modifier must be preceded by an instant vector selector or range vector selector or a subquery")
+			}
+			p.next()
+			if p.peek().isOp("-") {
+				p.next()
+			}
+			p.expectDuration("in offset")
+			selector = false
+		case t.isOp("@"):
+			if !modifiable {
+				p.fail(t, "@ modifier must be preceded by an instant vector selector or range vector selector or a subquery")
+			}
+			p.next()
+			switch at := p.next(); {
+			case at.kind == tokenNumber:
+			case at.isKeyword("start") || at.isKeyword("end"):
+				p.expectOp("(", "in @ modifier")
+				p.expectOp(")", "in @ modifier")
+			default:
+				p.fail(at, "unexpected %s in @ modifier, expected timestamp, start() or end()", at)
+			}
+			selector = false
+		default:
+			return result
+		}
+	}
+}
+
+func (p *promqlParser) expectDuration(context string) {
+	if t := p.next(); t.kind != tokenDuration {
+		p.fail(t, "unexpected %s %s, expected duration", t, context)
+	}
+}
+
+// parsePrimary parses a literal, a parenthesized expression, an aggregation, a function call or
+// a vector selector. selector is true for vector selectors.
+func (p *promqlParser) parsePrimary() (result promqlType, selector bool) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenNumber:
+		p.next()
+		return promqlScalar, false
+	case t.kind == tokenString:
+		p.next()
+		if _, err := unquotePromQL(t); err != nil {
+			panic(err)
+		}
+		return promqlString, false
+	case t.isOp("("):
+		p.next()
+		result = p.parseExpr(1)
+		p.expectOp(")", "in parenthesized expression")
+		return result, false
+	case t.isOp("{"):
+		p.parseVectorSelector("")
+		return promqlVector, true
+	case t.kind == tokenIdent:
+		name := t.text
+		if _, ok := promqlAggregations[strings.ToLower(name)]; ok {
+			return p.parseAggregation(), false
+		}
+		if strings.EqualFold(name, "inf") || strings.EqualFold(name, "nan") {
+			p.next()
+			return promqlScalar, false
+		}
+		if p.tokens[p.i+1].isOp("(") {
+			return p.parseCall(), false
+		}
+		p.next()
+		p.parseVectorSelector(name)
+		return promqlVector, true
+	case t.kind == tokenEOF:
+		p.fail(t, "unexpected end of input, expected an expression")
+	}
+	p.fail(t, "unexpected %s, expected an expression", t)
+	return "", false
+}
+
+// parseVectorSelector parses the optional label matchers following the metric name
+func (p *promqlParser) parseVectorSelector(metric string) {
+	start := p.peek()
+	selector := PromQLSelector{Metric: metric, Matchers: []PromQLMatcher{}}
+	nonEmpty := metric != ""
+	if p.peek().isOp("{") {
+		p.next()
+		for !p.peek().isOp("}") {
+			label := p.next()
+			name := p.labelName(label, "label matching")
+			// A quoted name without an operator is the metric name, e.g. {"http.requests"}
+			if label.kind == tokenString && (p.peek().isOp(",") || p.peek().isOp("}")) {
+				if selector.Metric != "" {
+					p.fail(label, "metric name must not be set twice: %q or %q", selector.Metric, name)
+				}
+				selector.Metric = name
+				nonEmpty = true
+				p.skipComma()
+				continue
+			}
+			op := p.next()
+			if !op.isOp("=") && !op.isOp("!=") && !op.isOp("=~") && !op.isOp("!~") {
+				p.fail(op, "unexpected %s in label matching, expected one of \"=\", \"!=\", \"=~\" or \"!~\"", op)
+			}
+			valueToken := p.next()
+			if valueToken.kind != tokenString {
+				p.fail(valueToken, "unexpected %s in label matching, expected string", valueToken)
+			}
+			value, err := unquotePromQL(valueToken)
+			if err != nil {
+				panic(err)
+			}
+			matchesEmpty := p.matchesEmpty(op, value, valueToken)
+
+			if name == "__name__" {
+				if selector.Metric != "" {
+					p.fail(label, "metric name must not be set twice: %q or %q", selector.Metric, value)
+				}
+				if op.text == "=" {
+					selector.Metric = value
+					nonEmpty = nonEmpty || value != ""
+					p.skipComma()
+					continue
+				}
+			}
+			nonEmpty = nonEmpty || !matchesEmpty
+			selector.Matchers = append(selector.Matchers, PromQLMatcher{Label: name, Op: op.text, Value: value})
+			p.skipComma()
+		}
+		p.next()
+	}
+	if !nonEmpty {
+		p.fail(start, "vector selector must contain at least one non-empty matcher")
+	}
+	p.selectors = append(p.selectors, selector)
+}
+
+// skipComma consumes the comma separating label matchers, which may also trail the last one
+func (p *promqlParser) skipComma() {
+	if p.peek().isOp(",") {
+		p.next()
+		return
+	}
+	if t := p.peek(); !t.isOp("}") {
+		p.fail(t, "unexpected %s in label matching, expected \",\" or \"}\"", t)
+	}
+}
+
+// matchesEmpty reports whether a label matcher matches series without the label. Regular
+// expressions are anchored, as in Prometheus.
+func (p *promqlParser) matchesEmpty(op promqlToken, value string, valueToken promqlToken) bool {
+	switch op.text {
+	case "=":
+		return value == ""
+	case "!=":
+		return value != ""
+	}
+	re, err := regexp.Compile("^(?:" + value + ")$")
+	if err != nil {
+		p.fail(valueToken, "invalid regular expression %q: %v", value, err)
+	}
+	return re.MatchString("") == (op.text == "=~")
+}
+
+// labelName returns the label name t is, an identifier or, as in Prometheus 3, a quoted
+// string allowing any UTF-8 name such as "k8s.pod.name"
+func (p *promqlParser) labelName(t promqlToken, context string) string {
+	switch {
+	case t.kind == tokenIdent && !strings.Contains(t.text, ":"):
+		return t.text
+	case t.kind == tokenString:
+		name, err := unquotePromQL(t)
+		if err != nil {
+			panic(err)
+		}
+		if name == "" {
+			p.fail(t, "label name must not be empty in %s", context)
+		}
+		return name
+	}
+	p.fail(t, "unexpected %s in %s, expected label name", t, context)
+	return ""
+}
+
+// parseLabelList parses a parenthesized, comma separated list of label names
+func (p *promqlParser) parseLabelList(context string) {
+	p.expectOp("(", "in "+context)
+	for !p.peek().isOp(")") {
+		p.labelName(p.next(), context)
+		if p.peek().isOp(",") {
+			p.next()
+		} else if t := p.peek(); !t.isOp(")") {
+			p.fail(t, "unexpected %s in %s, expected \",\" or \")\"", t, context)
+		}
+	}
+	p.next()
+}
+
+func (p *promqlParser) parseAggregation() promqlType {
+	opToken := p.next()
+	op := strings.ToLower(opToken.text)
+	grouped := false
+	if t := p.peek(); t.isKeyword("by") || t.isKeyword("without") {
+		p.next()
+		p.parseLabelList(strings.ToLower(t.text))
+		grouped = true
+	}
+	p.expectOp("(", "in aggregation")
+	if param := promqlAggregations[op]; param != "" {
+		paramToken := p.peek()
+		if got := p.parseExpr(1); got != param {
+			p.fail(paramToken, "expected type %s in aggregation parameter of %q, got %s", param, op, got)
+		}
+		p.expectOp(",", "in aggregation")
+	}
+	exprToken := p.peek()
+	if got := p.parseExpr(1); got != promqlVector {
+		p.fail(exprToken, "expected type instant vector in aggregation expression, got %s", got)
+	}
+	p.expectOp(")", "in aggregation")
+	if t := p.peek(); t.isKeyword("by") || t.isKeyword("without") {
+		if grouped {
+			p.fail(t, "aggregation %q already has a %s clause", op, "by or without")
+		}
+		p.next()
+		p.parseLabelList(strings.ToLower(t.text))
+	}
+	return promqlVector
+}
+
+func (p *promqlParser) parseCall() promqlType {
+	nameToken := p.next()
+	name := nameToken.text
+	function, ok := promqlFunctions[name]
+	if !ok {
+		p.fail(nameToken, "unknown function with name %q", name)
+	}
+	p.next()
+
+	var args []promqlToken
+	var types []promqlType
+	for !p.peek().isOp(")") {
+		args = append(args, p.peek())
+		types = append(types, p.parseExpr(1))
+		if p.peek().isOp(",") {
+			p.next()
+		} else if t := p.peek(); !t.isOp(")") {
+			p.fail(t, "unexpected %s in call to function %q, expected \",\" or \")\"", t, name)
+		}
+	}
+	p.next()
+
+	required := len(function.args) - function.optional
+	exact := function.optional == 0 && !function.variadic
+	switch {
+	case exact && len(types) != required:
+		p.fail(nameToken, "expected %d argument(s) in call to %q, got %d", required, name, len(types))
+	case len(types) < required:
+		p.fail(nameToken, "expected at least %d argument(s) in call to %q, got %d", required, name, len(types))
+	case len(types) > len(function.args) && !function.variadic:
+		p.fail(nameToken, "expected at most %d argument(s) in call to %q, got %d", len(function.args), name, len(types))
+	}
+	for i, got := range types {
+		want := function.args[min(i, len(function.args)-1)]
+		if got != want {
+			p.fail(args[i], "expected type %s in call to function %q, got %s", want, name, got)
+		}
+	}
+	return function.returns
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePromQL(t *testing.T) {
+	valid := []struct {
+		query      string
+		resultType promqlType
+	}{
+		{query: `up`, resultType: promqlVector},
+		{query: `1 + 2 * 3`, resultType: promqlScalar},
+		{query: `"text"`, resultType: promqlString},
+		{query: `http_requests_total{code=~"5..", method!="GET"}[5m]`, resultType: promqlMatrix},
+		{query: `{__name__=~"node_.*", job="node"}`, resultType: promqlVector},
+		{query: `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="default"}[5m]))`, resultType: promqlVector},
+		{query: `sum(rate(x[5m])) without (instance)`, resultType: promqlVector},
+		{query: `topk(5, sum by (pod) (up))`, resultType: promqlVector},
+		{query: `count_values("version", build_info)`, resultType: promqlVector},
+		{query: `max_over_time(rate(x[1m])[30m:1m])`, resultType: promqlVector},
+		{query: `rate(x[5m] offset -1h @ 1700000000)`, resultType: promqlVector},
+		{query: `x @ start() offset 1h30m`, resultType: promqlVector},
+		{query: `a / on(pod) group_left(node) b`, resultType: promqlVector},
+		{query: `a > bool 1`, resultType: promqlVector},
+		{query: `1 > bool 2`, resultType: promqlScalar},
+		{query: `-a ^ 2 ^ 3`, resultType: promqlVector},
+		{query: `a and b or c unless d`, resultType: promqlVector},
+		{query: `label_replace(up, "host", "$1", "instance", "(.*):.*")`, resultType: promqlVector},
+		{query: `label_join(up, "dst", "-", 'a', 'b\'s')`, resultType: promqlVector},
+		{query: `round(x)`, resultType: promqlVector},
+		{query: `scalar(sum(x)) * time()`, resultType: promqlScalar},
+		{query: "up # the targets\n and on() vector(1)", resultType: promqlVector},
+		{query: `SUM BY (pod) (x)`, resultType: promqlVector},
+		{query: `x{a="b",}`, resultType: promqlVector},
+		{query: `node:cpu:rate5m`, resultType: promqlVector},
+		{query: `0x1F + 1.5e3 + Inf`, resultType: promqlScalar},
+		{query: `x{"utf8.name"="v"}`, resultType: promqlVector},
+		{query: `{"http.server.duration", "k8s.pod.name"=~"api-.*"}`, resultType: promqlVector},
+		{query: `sum by ("k8s.namespace.name") (x)`, resultType: promqlVector},
+		{query: `a / on("host.name") group_left('k8s.node.name') b`, resultType: promqlVector},
+	}
+	for _, tt := range valid {
+		t.Run(tt.query, func(t *testing.T) {
+			resultType, _, err := parsePromQL(tt.query)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.resultType, resultType)
+		})
+	}
+
+	invalid := []struct {
+		query  string
+		err    string
+		column int
+	}{
+		{query: ``, err: "no expression found in input", column: 1},
+		{query: `rate(x)`, err: `expected type range vector in call to function "rate", got instant vector`, column: 6},
+		{query: `sum(rate(x[5m])`, err: `unexpected end of input in aggregation, expected ")"`, column: 16},
+		{query: `x{a="b"`, err: `unexpected end of input in label matching, expected "," or "}"`, column: 8},
+		{query: `x{a=b}`, err: `unexpected "b" in label matching, expected string`, column: 5},
+		{query: `x{a~"b"}`, err: `unexpected character '~'`, column: 4},
+		{query: `x{a="b}`, err: "unterminated quoted string", column: 5},
+		{query: `x{a=~"("}`, err: "invalid regular expression \"(\": error parsing regexp: missing closing ): `^(?:()$`", column: 6},
+		{query: `{a=""}`, err: "vector selector must contain at least one non-empty matcher", column: 1},
+		{query: `x{__name__="y"}`, err: `metric name must not be set twice: "x" or "y"`, column: 3},
+		{query: `foo(x)`, err: `unknown function with name "foo"`, column: 1},
+		{query: `clamp(x, 1)`, err: `expected 3 argument(s) in call to "clamp", got 2`, column: 1},
+		{query: `round(x, 1, 2)`, err: `expected at most 2 argument(s) in call to "round", got 3`, column: 1},
+		{query: `label_join(x, "a")`, err: `expected at least 3 argument(s) in call to "label_join", got 2`, column: 1},
+		{query: `topk(x, y)`, err: `expected type scalar in aggregation parameter of "topk", got instant vector`, column: 6},
+		{query: `sum(x[5m])`, err: "expected type instant vector in aggregation expression, got range vector", column: 5},
+		{query: `rate(x[5m]) + y[5m]`, err: "binary expression must contain only scalar and instant vector types", column: 13},
+		{query: `1 > 2`, err: "comparisons between scalars must use BOOL modifier", column: 3},
+		{query: `1 and x`, err: `set operator "and" not allowed in binary scalar expression`, column: 3},
+		{query: `a + bool b`, err: "bool modifier can only be used on comparison operators", column: 5},
+		{query: `a / group_left b`, err: "group_left must be preceded by on or ignoring", column: 5},
+		{query: `a and on(x) group_left b`, err: `no grouping allowed for "and" operation`, column: 13},
+		{query: `sum(x)[5m]`, err: "ranges only allowed for vector selectors", column: 7},
+		{query: `rate(x[5m])[5m]`, err: "ranges only allowed for vector selectors", column: 12},
+		{query: `x[5m][10m:1m]`, err: "subquery is only allowed on instant vector, got range vector", column: 1},
+		{query: `x[5]`, err: `unexpected "5" in range, expected duration`, column: 3},
+		{query: `x[1h30]`, err: `bad duration "1h30": missing unit after 30`, column: 3},
+		{query: `rate(x[5m]) @ 1700000000`, err: "@ modifier must be preceded by an instant vector selector or range vector selector or a subquery", column: 13},
+		{query: `sum(x) offset 5m`, err: "offset modifier must be preceded by an instant vector selector or range vector selector or a subquery", column: 8},
+		{query: "up\n  and on(pod\n  down", err: `unexpected "down" in on, expected "," or ")"`, column: 3},
+		{query: `-"a"`, err: "unary expression only allowed on expressions of type scalar or instant vector, got string", column: 1},
+		{query: `up up`, err: `unexpected "up"`, column: 4},
+		{query: `x{"y"}`, err: `metric name must not be set twice: "x" or "y"`, column: 3},
+		{query: `x{""="v"}`, err: "label name must not be empty in label matching", column: 3},
+		{query: `sum by (1) (x)`, err: `unexpected "1" in by, expected label name`, column: 9},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.query, func(t *testing.T) {
+			_, _, err := parsePromQL(tt.query)
+
+			require.EqualError(t, err, tt.err)
+			_, column := err.(*promqlError).position(tt.query)
+			assert.Equal(t, tt.column, column)
+		})
+	}
+}
+
+func TestParsePromQLSelectors(t *testing.T) {
+	_, selectors, err := parsePromQL(`sum(rate(http_requests_total{code=~"5..", job='api'}[5m])) / sum(rate({__name__="http_requests_total", job!=""}[5m]))`)
+
+	require.NoError(t, err)
+	assert.Equal(t, []PromQLSelector{
+		{Metric: "http_requests_total", Matchers: []PromQLMatcher{{Label: "code", Op: "=~", Value: "5.."}, {Label: "job", Op: "=", Value: "api"}}},
+		{Metric: "http_requests_total", Matchers: []PromQLMatcher{{Label: "job", Op: "!=", Value: ""}}},
+	}, selectors)
+}
+
+func TestParsePromQLQuotedNames(t *testing.T) {
+	_, selectors, err := parsePromQL(`x{"utf8.name"="v"} + {"http.server.duration", job="api"}`)
+
+	require.NoError(t, err)
+	assert.Equal(t, []PromQLSelector{
+		{Metric: "x", Matchers: []PromQLMatcher{{Label: "utf8.name", Op: "=", Value: "v"}}},
+		{Metric: "http.server.duration", Matchers: []PromQLMatcher{{Label: "job", Op: "=", Value: "api"}}},
+	}, selectors)
+	assert.Equal(t, `"utf8.name"="v"`, selectors[0].Matchers[0].String(), "names that are not valid unquoted are quoted")
+	assert.Equal(t, `job="api"`, selectors[1].Matchers[0].String())
+}
+
+func TestSyntaxReferencePromQLExamplesAreValid(t *testing.T) {
+	examples := regexp.MustCompile(`(?m)^- [^:]+: (.+)$`).FindAllStringSubmatch(syntaxReference["promql"]["examples"], -1)
+	require.NotEmpty(t, examples)
+
+	for _, example := range examples {
+		query := strings.TrimSpace(example[1])
+		_, _, err := parsePromQL(query)
+		assert.NoError(t, err, query)
+	}
+}
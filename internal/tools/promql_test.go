@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePromQL(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	t.Run("valid query lists its selectors", func(t *testing.T) {
+		params := ValidatePromQLParams{Query: `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="default", pod=~"api-.*"}[5m]))`}
+
+		result, validation, err := tools.ValidatePromQL(ctx, nil, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, &PromQLValidation{
+			Valid:      true,
+			ResultType: "instant vector",
+			Selectors: []PromQLSelector{{
+				Metric:   "container_cpu_usage_seconds_total",
+				Matchers: []PromQLMatcher{{Label: "namespace", Op: "=", Value: "default"}, {Label: "pod", Op: "=~", Value: "api-.*"}},
+			}},
+		}, validation)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Valid PromQL, result type: instant vector.")
+		assert.Contains(t, output, `| container_cpu_usage_seconds_total | namespace="default", pod=~"api-.*" |`)
+		mockClient.AssertNotCalled(t, "QueryRangeMetric")
+	})
+
+	t.Run("valid query without selectors", func(t *testing.T) {
+		result, validation, err := tools.ValidatePromQL(ctx, nil, ValidatePromQLParams{Query: "1 + 1"})
+
+		require.NoError(t, err)
+		assert.True(t, validation.Valid)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "The query references no metric selectors.")
+	})
+
+	t.Run("invalid query reports the error position", func(t *testing.T) {
+		params := ValidatePromQLParams{Query: "sum(\n  rate(http_requests_total)\n)"}
+
+		result, validation, err := tools.ValidatePromQL(ctx, nil, params)
+
+		require.NoError(t, err)
+		assert.Equal(t, &PromQLValidation{
+			Error:  `expected type range vector in call to function "rate", got instant vector`,
+			Line:   2,
+			Column: 8,
+		}, validation)
+		assert.Equal(t, "Invalid PromQL at line 2, column 8: expected type range vector in call to function \"rate\", got instant vector\n\n"+
+			"```\n  rate(http_requests_total)\n       ^\n```", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, _, err := tools.ValidatePromQL(ctx, nil, ValidatePromQLParams{Query: " "})
+
+		assert.EqualError(t, err, "query is required")
+	})
+}
@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Types of the parameters of a saved query
+const (
+	savedParamString   = "string"
+	savedParamNumber   = "number"
+	savedParamDuration = "duration"
+)
+
+var (
+	// savedQueryNamePattern matches the names a saved query can be registered as a tool with
+	savedQueryNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,63}$`)
+	// savedQueryPlaceholder matches the $name placeholders of a saved query
+	savedQueryPlaceholder = regexp.MustCompile(`\$([a-zA-Z_][a-zA-Z0-9_]*)`)
+)
+
+// savedQueryOptions are the arguments every saved query tool takes besides its parameters,
+// their names cannot be taken by a parameter
+type savedQueryOptions struct {
+	Time  string `json:"time,omitempty" jsonschema:"Time to evaluate the query at: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d') (default: now)" default:"now" examples:"now;1h;now-1d/d"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of series listed (default: 20)"`
+}
+
+// SavedQuery is a named PromQL template registered as a tool of its own, so golden queries
+// are run by name instead of being written again. Its $name placeholders are replaced by
+// the arguments of the call, see ParseSavedQueries.
+type SavedQuery struct {
+	Name        string            `json:"-"`
+	Description string            `json:"description"`
+	Query       string            `json:"query"`
+	Params      []SavedQueryParam `json:"params,omitempty"`
+}
+
+// SavedQueryParam is a parameter of a saved query. A parameter without default is required.
+type SavedQueryParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// ParseSavedQueries parses a JSON object mapping tool names to saved queries, sorted by name.
+// Every query is validated: its placeholders must be its parameters and the other way
+// round, string placeholders must be inside double quotes as their values are escaped as
+// label values, and the query must be valid PromQL once sample values are filled in.
+func ParseSavedQueries(data []byte) ([]SavedQuery, error) {
+	var byName map[string]SavedQuery
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&byName); err != nil {
+		return nil, fmt.Errorf("invalid saved queries, expected a JSON object mapping tool names to queries: %w", err)
+	}
+
+	queries := make([]SavedQuery, 0, len(byName))
+	for name, q := range byName {
+		q.Name = name
+		if err := q.validate(); err != nil {
+			return nil, fmt.Errorf("saved query %s: %w", name, err)
+		}
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries, nil
+}
+
+// validate checks the name, parameters and template of q, filling in the default type of
+// its parameters
+func (q *SavedQuery) validate() error {
+	if !savedQueryNamePattern.MatchString(q.Name) {
+		return fmt.Errorf("invalid name: a tool name starts with a letter and consists of at most 64 letters, digits, underscores and dashes")
+	}
+	if strings.TrimSpace(q.Query) == "" {
+		return fmt.Errorf("query is required")
+	}
+	reserved, err := InputSchema[savedQueryOptions]()
+	if err != nil {
+		return err
+	}
+
+	declared := map[string]bool{}
+	for i := range q.Params {
+		p := &q.Params[i]
+		if !labelNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("invalid parameter name '%s': a parameter name consists of letters, digits and underscores and does not start with a digit", p.Name)
+		}
+		if reserved.Properties[p.Name] != nil {
+			return fmt.Errorf("parameter name '%s' is reserved for the argument every saved query takes", p.Name)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("parameter '%s' is declared twice", p.Name)
+		}
+		declared[p.Name] = true
+		if p.Type == "" {
+			p.Type = savedParamString
+		}
+		if p.Default != nil {
+			if _, err := p.value(p.Default); err != nil {
+				return fmt.Errorf("invalid default of parameter '%s': %w", p.Name, err)
+			}
+		}
+	}
+
+	used := map[string]bool{}
+	for _, m := range savedQueryPlaceholder.FindAllStringSubmatch(q.Query, -1) {
+		if !declared[m[1]] {
+			return fmt.Errorf("placeholder $%s is not a declared parameter", m[1])
+		}
+		used[m[1]] = true
+	}
+	for _, p := range q.Params {
+		if !used[p.Name] {
+			return fmt.Errorf("parameter '%s' is not used in the query, refer to it as $%s", p.Name, p.Name)
+		}
+	}
+
+	// The sample string ends any single quoted or raw string around its placeholder, so
+	// only a placeholder inside double quotes leaves a valid query
+	samples := map[string]any{}
+	for _, p := range q.Params {
+		switch p.Type {
+		case savedParamNumber:
+			samples[p.Name] = 1.0
+		case savedParamDuration:
+			samples[p.Name] = "5m"
+		default:
+			samples[p.Name] = "x'`\"y"
+		}
+	}
+	query, err := q.render(samples)
+	if err != nil {
+		return err
+	}
+	if _, _, err := parsePromQL(query); err != nil {
+		return fmt.Errorf("query is not valid PromQL with sample parameter values (string parameters must be inside double quotes, e.g. namespace=\"$namespace\"): %w", err)
+	}
+	return nil
+}
+
+// value converts an argument of p into the text its placeholder is replaced by. Strings are
+// escaped as the content of a double quoted PromQL string.
+func (p SavedQueryParam) value(arg any) (string, error) {
+	switch p.Type {
+	case savedParamString:
+		s, ok := arg.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a string, got %v", arg)
+		}
+		return promQLString(s), nil
+	case savedParamNumber:
+		n, ok := arg.(float64)
+		if !ok {
+			return "", fmt.Errorf("expected a number, got %v", arg)
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64), nil
+	case savedParamDuration:
+		s, ok := arg.(string)
+		if !ok {
+			return "", fmt.Errorf("expected a duration, got %v", arg)
+		}
+		tokens, err := lexPromQL(s)
+		if err != nil || len(tokens) != 2 || tokens[0].kind != tokenDuration {
+			return "", fmt.Errorf("invalid duration '%s', expected a PromQL duration like '5m' or '1h30m'", s)
+		}
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown type '%s', must be one of %s, %s and %s", p.Type, savedParamString, savedParamNumber, savedParamDuration)
+}
+
+// render replaces the placeholders of q by the arguments, taking the defaults of the
+// parameters not passed
+func (q SavedQuery) render(args map[string]any) (string, error) {
+	values := make(map[string]string, len(q.Params))
+	for _, p := range q.Params {
+		arg, ok := args[p.Name]
+		if !ok || arg == nil {
+			if p.Default == nil {
+				return "", fmt.Errorf("%s is required", p.Name)
+			}
+			arg = p.Default
+		}
+		v, err := p.value(arg)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %w", p.Name, err)
+		}
+		values[p.Name] = v
+	}
+	return savedQueryPlaceholder.ReplaceAllStringFunc(q.Query, func(placeholder string) string {
+		return values[placeholder[1:]]
+	}), nil
+}
+
+// InputSchema returns the schema of the arguments of the tool of q: its parameters and the
+// time and limit every saved query takes
+func (q SavedQuery) InputSchema() (*jsonschema.Schema, error) {
+	schema, err := InputSchema[savedQueryOptions]()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range q.Params {
+		prop := &jsonschema.Schema{Type: "string", Description: p.Description}
+		if p.Type == savedParamNumber {
+			prop.Type = "number"
+		}
+		if p.Default != nil {
+			if prop.Default, err = json.Marshal(p.Default); err != nil {
+				return nil, err
+			}
+		} else {
+			schema.Required = append(schema.Required, p.Name)
+		}
+		schema.Properties[p.Name] = prop
+	}
+	return schema, nil
+}
+
+// ToolDescription describes the tool of q in the layout of the built-in tools
+func (q SavedQuery) ToolDescription() string {
+	var sb strings.Builder
+	if q.Description != "" {
+		sb.WriteString(strings.TrimSpace(q.Description) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("Saved PromQL query, evaluated as an instant query: %s\n", q.Query))
+	sb.WriteString("Arguments:\n")
+	for _, p := range q.Params {
+		optional := "required"
+		if p.Default != nil {
+			optional = "optional"
+		}
+		sb.WriteString(fmt.Sprintf("- %s (%s %s)", p.Name, optional, p.Type))
+		if p.Description != "" {
+			sb.WriteString(": " + p.Description)
+		}
+		if p.Default != nil {
+			sb.WriteString(fmt.Sprintf(" (default: %v)", p.Default))
+		}
+		sb.WriteString(".\n")
+	}
+	sb.WriteString("- time (optional): Time to evaluate the query at, 'now', a duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (default: now).\n")
+	sb.WriteString("- limit (optional): Maximum number of series listed (default: 20).\n")
+	sb.WriteString("Returns:\n")
+	sb.WriteString("The query with the arguments filled in and a table of the label sets of the series with their value. Pass the query to getMetrics for the series over time.")
+	return sb.String()
+}
+
+// SavedQuery returns the handler of the tool of q. It fills in the arguments and evaluates
+// the query like getMetricValue.
+func (t tool) SavedQuery(q SavedQuery) mcp.ToolHandlerFor[map[string]any, *MetricValueResult] {
+	return func(ctx context.Context, request *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, *MetricValueResult, error) {
+		// The server rejects unknown arguments with the schema, -run-tool does not
+		var options savedQueryOptions
+		for name, arg := range args {
+			switch {
+			case name == "time":
+				s, ok := arg.(string)
+				if !ok {
+					return nil, nil, fmt.Errorf("invalid time: expected a string, got %v", arg)
+				}
+				options.Time = s
+			case name == "limit":
+				n, ok := arg.(float64)
+				if !ok || n != float64(int(n)) {
+					return nil, nil, fmt.Errorf("invalid limit: expected an integer, got %v", arg)
+				}
+				options.Limit = int(n)
+			case !slices.ContainsFunc(q.Params, func(p SavedQueryParam) bool { return p.Name == name }):
+				return nil, nil, fmt.Errorf("unknown argument '%s' of %s", name, q.Name)
+			}
+		}
+		query, err := q.render(args)
+		if err != nil {
+			return nil, nil, err
+		}
+		return t.GetMetricValue(ctx, request, GetMetricValueParams{Query: query, Time: options.Time, Limit: options.Limit})
+	}
+}
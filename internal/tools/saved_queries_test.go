@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const savedQueriesJSON = `{
+	"namespaceCpuSaturation": {
+		"description": "CPU usage of a namespace relative to its requests.",
+		"query": "sum(rate(container_cpu_usage_seconds_total{namespace=\"$namespace\"}[$window])) / sum(kube_pod_container_resource_requests{namespace=\"$namespace\", resource=\"cpu\"}) > $threshold",
+		"params": [
+			{"name": "namespace", "description": "The namespace to check"},
+			{"name": "window", "type": "duration", "default": "5m"},
+			{"name": "threshold", "type": "number", "default": 0}
+		]
+	},
+	"apiServerLatency": {
+		"query": "histogram_quantile(0.99, sum by (le) (rate(apiserver_request_duration_seconds_bucket[5m])))"
+	}
+}`
+
+func TestParseSavedQueries(t *testing.T) {
+	t.Run("queries are sorted by name", func(t *testing.T) {
+		queries, err := ParseSavedQueries([]byte(savedQueriesJSON))
+
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+		assert.Equal(t, "apiServerLatency", queries[0].Name)
+		assert.Equal(t, "namespaceCpuSaturation", queries[1].Name)
+		assert.Equal(t, savedParamString, queries[1].Params[0].Type, "the type defaults to string")
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{
+			name:  "not an object",
+			query: `[]`,
+			err:   "expected a JSON object mapping tool names to queries",
+		},
+		{
+			name:  "unknown field",
+			query: `{"q": {"query": "up", "parameters": []}}`,
+			err:   `unknown field "parameters"`,
+		},
+		{
+			name:  "invalid tool name",
+			query: `{"cpu saturation": {"query": "up"}}`,
+			err:   "saved query cpu saturation: invalid name",
+		},
+		{
+			name:  "missing query",
+			query: `{"q": {"description": "nothing"}}`,
+			err:   "saved query q: query is required",
+		},
+		{
+			name:  "undeclared placeholder",
+			query: `{"q": {"query": "up{namespace=\"$namespace\"}"}}`,
+			err:   "placeholder $namespace is not a declared parameter",
+		},
+		{
+			name:  "unused parameter",
+			query: `{"q": {"query": "up", "params": [{"name": "namespace"}]}}`,
+			err:   "parameter 'namespace' is not used in the query, refer to it as $namespace",
+		},
+		{
+			name:  "reserved parameter",
+			query: `{"q": {"query": "up offset $time", "params": [{"name": "time", "type": "duration"}]}}`,
+			err:   "parameter name 'time' is reserved",
+		},
+		{
+			name:  "unknown type",
+			query: `{"q": {"query": "up > $n", "params": [{"name": "n", "type": "int"}]}}`,
+			err:   "unknown type 'int'",
+		},
+		{
+			name:  "default of the wrong type",
+			query: `{"q": {"query": "up[$window]", "params": [{"name": "window", "type": "duration", "default": "five minutes"}]}}`,
+			err:   "invalid default of parameter 'window': invalid duration 'five minutes'",
+		},
+		{
+			name:  "unquoted string placeholder",
+			query: `{"q": {"query": "up{namespace=$namespace}", "params": [{"name": "namespace"}]}}`,
+			err:   "string parameters must be inside double quotes",
+		},
+		{
+			name:  "single quoted string placeholder",
+			query: `{"q": {"query": "up{namespace='$namespace'}", "params": [{"name": "namespace"}]}}`,
+			err:   "string parameters must be inside double quotes",
+		},
+		{
+			name:  "invalid PromQL",
+			query: `{"q": {"query": "sum(up{namespace=\"$namespace\"}", "params": [{"name": "namespace"}]}}`,
+			err:   "query is not valid PromQL with sample parameter values",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSavedQueries([]byte(tt.query))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestSavedQueryInputSchema(t *testing.T) {
+	queries, err := ParseSavedQueries([]byte(savedQueriesJSON))
+	require.NoError(t, err)
+
+	schema, err := queries[1].InputSchema()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"namespace"}, schema.Required)
+	assert.Equal(t, "string", schema.Properties["namespace"].Type)
+	assert.Equal(t, "The namespace to check", schema.Properties["namespace"].Description)
+	assert.Equal(t, "number", schema.Properties["threshold"].Type)
+	assert.JSONEq(t, `"5m"`, string(schema.Properties["window"].Default))
+	assert.Contains(t, schema.Properties, "time")
+	assert.Contains(t, schema.Properties, "limit")
+	assert.Contains(t, queries[1].ToolDescription(), "- namespace (required string): The namespace to check.\n- window (optional duration) (default: 5m).\n")
+}
+
+func TestSavedQuery(t *testing.T) {
+	ctx := context.Background()
+	queries, err := ParseSavedQueries([]byte(savedQueriesJSON))
+	require.NoError(t, err)
+	saturation := queries[1]
+	vector := &suseobservability.MetricQueryResponse{Status: "success", Data: suseobservability.MetricData{ResultType: "vector", Result: []suseobservability.MetricResult{
+		{Labels: map[string]string{}, Points: []suseobservability.MetricPoint{{Timestamp: 1700000000, Value: 1.25}}},
+	}}}
+
+	t.Run("arguments and defaults are filled in", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		expected := `sum(rate(container_cpu_usage_seconds_total{namespace="shop"}[5m])) / sum(kube_pod_container_resource_requests{namespace="shop", resource="cpu"}) > 0.8`
+		mockClient.On("QueryMetric", ctx, expected, mock.Anything, "").Return(vector, nil).Once()
+
+		result, structured, err := NewBaseTool(mockClient).SavedQuery(saturation)(ctx, nil, map[string]any{"namespace": "shop", "threshold": 0.8})
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, structured.Query)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| 1.25 |")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("string arguments are escaped as label values", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		expected := `sum(rate(container_cpu_usage_seconds_total{namespace="shop\"} or vector(1) #\\"}[1h])) / sum(kube_pod_container_resource_requests{namespace="shop\"} or vector(1) #\\", resource="cpu"}) > 0`
+		mockClient.On("QueryMetric", ctx, expected, mock.Anything, "").Return(vector, nil).Once()
+
+		_, _, err := NewBaseTool(mockClient).SavedQuery(saturation)(ctx, nil, map[string]any{"namespace": `shop"} or vector(1) #\`, "window": "1h"})
+
+		require.NoError(t, err)
+		_, _, err = parsePromQL(expected)
+		assert.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("time and limit are passed on", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		tools.clock = newFakeClock()
+		at := tools.clock.Now().Add(-time.Hour)
+		query := "histogram_quantile(0.99, sum by (le) (rate(apiserver_request_duration_seconds_bucket[5m])))"
+		mockClient.On("QueryMetric", ctx, query, at, "").Return(vector, nil).Once()
+
+		_, structured, err := tools.SavedQuery(queries[0])(ctx, nil, map[string]any{"time": "1h", "limit": float64(5)})
+
+		require.NoError(t, err)
+		assert.Equal(t, at.UTC(), structured.Time)
+		mockClient.AssertExpectations(t)
+	})
+
+	errors := []struct {
+		name string
+		args string
+		err  string
+	}{
+		{"missing required parameter", `{}`, "namespace is required"},
+		{"wrong type", `{"namespace": "shop", "threshold": "high"}`, "invalid threshold: expected a number, got high"},
+		{"invalid duration", `{"namespace": "shop", "window": "5 minutes"}`, "invalid window: invalid duration '5 minutes'"},
+		{"unknown argument", `{"namespace": "shop", "cluster": "prod"}`, "unknown argument 'cluster' of namespaceCpuSaturation"},
+	}
+	for _, tt := range errors {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.args), &args))
+
+			_, _, err := NewBaseTool(new(MockSuseObservabilityClient)).SavedQuery(saturation)(ctx, nil, args)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// InputSchema returns the JSON schema of the arguments of a tool, inferred from its params
+// struct like the SDK does, completed with the machine-readable metadata of the field tags:
+//
+//	enum:"flat,grouped,pivot"  the only values accepted, comma-separated
+//	default:"markdown"         the value an omitted field behaves like
+//	examples:"15m;2d"          example values, semicolon-separated as they may contain commas
+//
+// Values of non-string fields are decoded as JSON, enum and examples of a slice field describe
+// its items. The SDK validates the arguments against the schema and fills in the defaults
+// before the handler runs, so enum is only set on fields the tool matches exactly, and a
+// default must behave exactly like the omitted field.
+func InputSchema[In any]() (*jsonschema.Schema, error) {
+	t := reflect.TypeFor[In]()
+	schema, err := jsonschema.ForType(t, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if err := annotateSchema(t, schema); err != nil {
+		return nil, fmt.Errorf("schema of %s: %w", t, err)
+	}
+	return schema, nil
+}
+
+// annotateSchema applies the tags of the fields of t, and of the structs it holds, to schema
+func annotateSchema(t reflect.Type, schema *jsonschema.Schema) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if schema.Items != nil {
+			return annotateSchema(t.Elem(), schema.Items)
+		}
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(t) {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			prop := schema.Properties[name]
+			if field.Anonymous || prop == nil {
+				continue
+			}
+			if err := annotateField(field, prop); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			if err := annotateSchema(field.Type, prop); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func annotateField(field reflect.StructField, prop *jsonschema.Schema) error {
+	t, target := field.Type, prop
+	if t.Kind() == reflect.Slice && prop.Items != nil {
+		t, target = t.Elem(), prop.Items
+	}
+	if s, ok := field.Tag.Lookup("enum"); ok {
+		values, err := tagValues(t, strings.Split(s, ","))
+		if err != nil {
+			return fmt.Errorf("enum: %w", err)
+		}
+		target.Enum = values
+	}
+	if s, ok := field.Tag.Lookup("examples"); ok {
+		values, err := tagValues(t, strings.Split(s, ";"))
+		if err != nil {
+			return fmt.Errorf("examples: %w", err)
+		}
+		target.Examples = values
+	}
+	if s, ok := field.Tag.Lookup("default"); ok {
+		values, err := tagValues(field.Type, []string{s})
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		if prop.Default, err = json.Marshal(values[0]); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// tagValues returns the values of a tag for a field of type t: strings as is, other types
+// decoded as JSON
+func tagValues(t reflect.Type, raw []string) ([]any, error) {
+	values := make([]any, 0, len(raw))
+	for _, r := range raw {
+		if t.Kind() == reflect.String {
+			values = append(values, r)
+			continue
+		}
+		var v any
+		if err := json.Unmarshal([]byte(r), &v); err != nil {
+			return nil, fmt.Errorf("value %q of %s: %w", r, t, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
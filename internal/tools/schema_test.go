@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInputSchema(t *testing.T) {
+	t.Run("tags become enum, default and examples", func(t *testing.T) {
+		type params struct {
+			Format string   `json:"format,omitempty" jsonschema:"Output format" enum:"markdown,json" default:"markdown"`
+			Limit  int      `json:"limit,omitempty" default:"50"`
+			Window string   `json:"window,omitempty" examples:"15m;sum(a, b)"`
+			Kinds  []string `json:"kinds,omitempty" enum:"a,b"`
+			Nested []struct {
+				Alias string `json:"alias" examples:"cpu"`
+			} `json:"nested,omitempty"`
+		}
+
+		schema, err := InputSchema[params]()
+
+		require.NoError(t, err)
+		format := schema.Properties["format"]
+		assert.Equal(t, "Output format", format.Description)
+		assert.Equal(t, []any{"markdown", "json"}, format.Enum)
+		assert.Equal(t, json.RawMessage(`"markdown"`), format.Default)
+		assert.Equal(t, json.RawMessage(`50`), schema.Properties["limit"].Default)
+		assert.Equal(t, []any{"15m", "sum(a, b)"}, schema.Properties["window"].Examples)
+		assert.Equal(t, []any{"a", "b"}, schema.Properties["kinds"].Items.Enum)
+		assert.Equal(t, []any{"cpu"}, schema.Properties["nested"].Items.Properties["alias"].Examples)
+	})
+
+	t.Run("invalid values of non-string fields", func(t *testing.T) {
+		type params struct {
+			Limit int `json:"limit,omitempty" default:"many"`
+		}
+
+		_, err := InputSchema[params]()
+
+		assert.ErrorContains(t, err, `field Limit: default: value "many" of int`)
+	})
+
+}
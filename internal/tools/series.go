@@ -0,0 +1,304 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+)
+
+// Series is a time series of a metrics query result. A query result is converted to series
+// once, the getMetrics formatters and analyses all work on them.
+type Series struct {
+	Labels map[string]string
+	Points []Point
+	// Unit is the unit the markdown tables humanize the values in, empty for plain numbers
+	Unit string
+	// Text is the text of a string result, whose single point is NaN
+	Text string
+}
+
+// Point is a sample of a series, the timestamp is in unix seconds
+type Point struct {
+	Timestamp int64
+	Value     float64
+}
+
+// AlignedPoint holds the values of two series sampled at the same timestamp
+type AlignedPoint struct {
+	Timestamp int64
+	Value     float64
+	Other     float64
+}
+
+// aggregation reduces the values of a series or of a bucket to a single value
+type aggregation string
+
+const (
+	aggregateMin   aggregation = "min"
+	aggregateMax   aggregation = "max"
+	aggregateMean  aggregation = "mean"
+	aggregateSum   aggregation = "sum"
+	aggregateLast  aggregation = "last"
+	aggregateCount aggregation = "count"
+)
+
+// newSeries converts a client query result, keeping the order of series and points.
+// Labels are never nil.
+func newSeries(results []suseobservability.MetricResult) []Series {
+	series := make([]Series, 0, len(results))
+	for _, res := range results {
+		s := Series{Labels: res.Labels, Points: make([]Point, 0, len(res.Points)), Text: res.String}
+		if s.Labels == nil {
+			s.Labels = map[string]string{}
+		}
+		for _, p := range res.Points {
+			s.Points = append(s.Points, Point{Timestamp: p.Timestamp, Value: p.Value})
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// values returns the values of the points in order, without NaN and ±Inf
+func (s Series) values() []float64 {
+	values := make([]float64, 0, len(s.Points))
+	for _, p := range s.Points {
+		if isFinite(p.Value) {
+			values = append(values, p.Value)
+		}
+	}
+	return values
+}
+
+// NonFinite returns the number of NaN and ±Inf values, such as the result of a division by zero
+func (s Series) NonFinite() int {
+	n := 0
+	for _, p := range s.Points {
+		if !isFinite(p.Value) {
+			n++
+		}
+	}
+	return n
+}
+
+// isFinite tells whether v is neither NaN nor ±Inf
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Aggregate reduces the values of the series, NaN and ±Inf values are skipped. ok is false when
+// no value is left, except for aggregateCount which is 0 then.
+func (s Series) Aggregate(agg aggregation) (value float64, ok bool) {
+	return aggregate(s.values(), agg)
+}
+
+func aggregate(values []float64, agg aggregation) (float64, bool) {
+	if agg == aggregateCount {
+		return float64(len(values)), true
+	}
+	if len(values) == 0 {
+		return 0, false
+	}
+	switch agg {
+	case aggregateMin:
+		m := values[0]
+		for _, v := range values[1:] {
+			m = min(m, v)
+		}
+		return m, true
+	case aggregateMax:
+		m := values[0]
+		for _, v := range values[1:] {
+			m = max(m, v)
+		}
+		return m, true
+	case aggregateSum, aggregateMean:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		if agg == aggregateMean {
+			return sum / float64(len(values)), true
+		}
+		return sum, true
+	case aggregateLast:
+		return values[len(values)-1], true
+	}
+	return 0, false
+}
+
+// Quantile interpolates the q-quantile of the values of the series, NaN and ±Inf values are skipped.
+// ok is false when no value is left.
+func (s Series) Quantile(q float64) (value float64, ok bool) {
+	values := s.values()
+	if len(values) == 0 {
+		return 0, false
+	}
+	sort.Float64s(values)
+	return quantile(values, q), true
+}
+
+// quantile interpolates linearly between the closest ranks of the sorted values
+func quantile(sorted []float64, q float64) float64 {
+	rank := q * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+// Bucket groups the points in windows of width aligned to the unix epoch and aggregates
+// every window into a point at its start. Windows without values are left out.
+func (s Series) Bucket(width time.Duration, agg aggregation) Series {
+	bucketed := Series{Labels: s.Labels, Points: []Point{}, Unit: s.Unit}
+	seconds := int64(width / time.Second)
+	if seconds <= 0 {
+		return bucketed
+	}
+
+	var (
+		start  int64
+		values []float64
+		open   bool
+	)
+	// flush adds the open window, values is empty before the first one
+	flush := func() {
+		if v, ok := aggregate(values, agg); ok && len(values) > 0 {
+			bucketed.Points = append(bucketed.Points, Point{Timestamp: start, Value: v})
+		}
+	}
+	for _, p := range s.sorted() {
+		bucketStart := p.Timestamp - ((p.Timestamp%seconds)+seconds)%seconds
+		if !open || bucketStart != start {
+			flush()
+			start, values, open = bucketStart, nil, true
+		}
+		if isFinite(p.Value) {
+			values = append(values, p.Value)
+		}
+	}
+	flush()
+	return bucketed
+}
+
+// sorted returns the points ordered by timestamp, without modifying the series
+func (s Series) sorted() []Point {
+	points := append([]Point(nil), s.Points...)
+	sort.SliceStable(points, func(a, b int) bool {
+		return points[a].Timestamp < points[b].Timestamp
+	})
+	return points
+}
+
+// LastAge returns how long before now the latest point was sampled. ok is false without points.
+func (s Series) LastAge(now time.Time) (age time.Duration, ok bool) {
+	if len(s.Points) == 0 {
+		return 0, false
+	}
+	latest := s.Points[0].Timestamp
+	for _, p := range s.Points[1:] {
+		latest = max(latest, p.Timestamp)
+	}
+	return now.Sub(time.Unix(latest, 0)), true
+}
+
+// CounterResets returns the timestamps at which the series drops below its previous value,
+// in timestamp order. Only series named like a counter (_total, _count or _sum suffix) are
+// checked, a drop of any other series is a real decrease. NaN and ±Inf values are skipped.
+func (s Series) CounterResets() []int64 {
+	if metricType, _ := guessMetricMetadata(s.Labels["__name__"]); metricType != "counter" {
+		return nil
+	}
+	var resets []int64
+	prev := math.NaN()
+	for _, p := range s.sorted() {
+		if !isFinite(p.Value) {
+			continue
+		}
+		if p.Value < prev {
+			resets = append(resets, p.Timestamp)
+		}
+		prev = p.Value
+	}
+	return resets
+}
+
+// AlignWith pairs the points of the series with the points of other sampled at the same
+// timestamp, in timestamp order. Points without a counterpart are left out.
+func (s Series) AlignWith(other Series) []AlignedPoint {
+	others := make(map[int64]float64, len(other.Points))
+	for _, p := range other.Points {
+		others[p.Timestamp] = p.Value
+	}
+	aligned := []AlignedPoint{}
+	for _, p := range s.sorted() {
+		if o, ok := others[p.Timestamp]; ok {
+			aligned = append(aligned, AlignedPoint{Timestamp: p.Timestamp, Value: p.Value, Other: o})
+			delete(others, p.Timestamp)
+		}
+	}
+	return aligned
+}
+
+// Stats summarizes the series. Samples, From and To cover every point, the statistics skip
+// NaN and ±Inf values, which are counted in NonFinite.
+func (s Series) Stats() SeriesStats {
+	stats := SeriesStats{Labels: s.Labels, Samples: len(s.Points), NonFinite: s.NonFinite()}
+	if len(s.Points) == 0 {
+		return stats
+	}
+	stats.From, stats.To = s.Points[0].Timestamp, s.Points[0].Timestamp
+	for _, p := range s.Points {
+		stats.From = min(stats.From, p.Timestamp)
+		stats.To = max(stats.To, p.Timestamp)
+	}
+	stats.Min, _ = s.Aggregate(aggregateMin)
+	stats.Max, _ = s.Aggregate(aggregateMax)
+	stats.Mean, _ = s.Aggregate(aggregateMean)
+	stats.Last, _ = s.Aggregate(aggregateLast)
+	stats.P50, _ = s.Quantile(0.5)
+	stats.P95, _ = s.Quantile(0.95)
+	return stats
+}
+
+// rankAggregations maps the rank_by values of getMetrics to the statistic series are ranked by
+var rankAggregations = map[string]aggregation{
+	"max":  aggregateMax,
+	"last": aggregateLast,
+	"avg":  aggregateMean,
+}
+
+// rankSeries orders series by a statistic of their values, highest first, so capping them
+// keeps the top series. Series without finite values come last and ties are ordered by their
+// labels, so the same result is always ranked the same way.
+func rankSeries(series []Series, agg aggregation) []Series {
+	type rankedSeries struct {
+		series Series
+		name   string
+		value  float64
+		ok     bool
+	}
+	ranked := make([]rankedSeries, 0, len(series))
+	for _, s := range series {
+		value, ok := s.Aggregate(agg)
+		ranked = append(ranked, rankedSeries{series: s, name: seriesName(s.Labels), value: value, ok: ok})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if a.ok && a.value != b.value {
+			return a.value > b.value
+		}
+		return a.name < b.name
+	})
+
+	sorted := make([]Series, 0, len(ranked))
+	for _, r := range ranked {
+		sorted = append(sorted, r.series)
+	}
+	return sorted
+}
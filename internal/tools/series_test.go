@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSeries(t *testing.T) {
+	series := newSeries([]suseobservability.MetricResult{
+		{Labels: map[string]string{"pod": "a"}, Points: []suseobservability.MetricPoint{{Timestamp: 1700000060, Value: 2}, {Timestamp: 1700000000, Value: 1}}},
+		{},
+	})
+
+	assert.Equal(t, []Series{
+		{Labels: map[string]string{"pod": "a"}, Points: []Point{{Timestamp: 1700000060, Value: 2}, {Timestamp: 1700000000, Value: 1}}},
+		{Labels: map[string]string{}, Points: []Point{}},
+	}, series)
+	assert.Empty(t, newSeries(nil))
+}
+
+func TestSeriesAggregate(t *testing.T) {
+	s := Series{Points: []Point{{1, 4}, {2, math.NaN()}, {3, 1}, {4, 7}, {5, math.NaN()}}}
+
+	tests := []struct {
+		agg      aggregation
+		expected float64
+	}{
+		{aggregateMin, 1},
+		{aggregateMax, 7},
+		{aggregateMean, 4},
+		{aggregateSum, 12},
+		{aggregateLast, 7},
+		{aggregateCount, 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.agg), func(t *testing.T) {
+			value, ok := s.Aggregate(tt.agg)
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	t.Run("without values", func(t *testing.T) {
+		empty := Series{Points: []Point{{1, math.NaN()}}}
+
+		_, ok := empty.Aggregate(aggregateMax)
+		assert.False(t, ok)
+		count, ok := empty.Aggregate(aggregateCount)
+		assert.True(t, ok)
+		assert.Zero(t, count)
+		_, ok = empty.Aggregate("median")
+		assert.False(t, ok)
+	})
+}
+
+func TestSeriesQuantile(t *testing.T) {
+	s := Series{Points: []Point{{1, 3}, {2, 1}, {3, math.NaN()}, {4, 2}, {5, 4}}}
+
+	p50, ok := s.Quantile(0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, p50)
+	p0, _ := s.Quantile(0)
+	assert.Equal(t, 1.0, p0)
+	p100, _ := s.Quantile(1)
+	assert.Equal(t, 4.0, p100)
+
+	_, ok = Series{}.Quantile(0.5)
+	assert.False(t, ok)
+}
+
+func TestSeriesBucket(t *testing.T) {
+	s := Series{
+		Labels: map[string]string{"pod": "a"},
+		Points: []Point{
+			{Timestamp: 1700000190, Value: 6},
+			{Timestamp: 1700000000, Value: 1},
+			{Timestamp: 1700000030, Value: 3},
+			{Timestamp: 1700000100, Value: math.NaN()},
+			{Timestamp: 1700000180, Value: 2},
+		},
+	}
+
+	t.Run("aggregates windows aligned to the epoch", func(t *testing.T) {
+		bucketed := s.Bucket(time.Minute, aggregateMax)
+
+		assert.Equal(t, Series{
+			Labels: map[string]string{"pod": "a"},
+			Points: []Point{{Timestamp: 1699999980, Value: 3}, {Timestamp: 1700000160, Value: 6}},
+		}, bucketed)
+	})
+
+	t.Run("windows without values are left out", func(t *testing.T) {
+		bucketed := s.Bucket(30*time.Second, aggregateCount)
+
+		assert.Equal(t, []Point{{Timestamp: 1699999980, Value: 1}, {Timestamp: 1700000010, Value: 1}, {Timestamp: 1700000160, Value: 1}, {Timestamp: 1700000190, Value: 1}}, bucketed.Points)
+	})
+
+	t.Run("the series is left untouched", func(t *testing.T) {
+		s.Bucket(time.Hour, aggregateMean)
+
+		assert.Equal(t, int64(1700000190), s.Points[0].Timestamp)
+	})
+
+	t.Run("width below a second", func(t *testing.T) {
+		assert.Empty(t, s.Bucket(time.Millisecond, aggregateMean).Points)
+	})
+}
+
+func TestSeriesLastAge(t *testing.T) {
+	s := Series{Points: []Point{{Timestamp: 1700000120, Value: 1}, {Timestamp: 1700000000, Value: 2}}}
+
+	age, ok := s.LastAge(time.Unix(1700000300, 0))
+
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Minute, age)
+	_, ok = Series{}.LastAge(time.Unix(1700000300, 0))
+	assert.False(t, ok)
+}
+
+func TestSeriesAlignWith(t *testing.T) {
+	s := Series{Points: []Point{{Timestamp: 180, Value: 3}, {Timestamp: 60, Value: 1}, {Timestamp: 120, Value: 2}}}
+	other := Series{Points: []Point{{Timestamp: 60, Value: 10}, {Timestamp: 180, Value: 30}, {Timestamp: 240, Value: 40}}}
+
+	assert.Equal(t, []AlignedPoint{{Timestamp: 60, Value: 1, Other: 10}, {Timestamp: 180, Value: 3, Other: 30}}, s.AlignWith(other))
+	assert.Empty(t, s.AlignWith(Series{}))
+}
+
+func TestRankSeries(t *testing.T) {
+	pod := func(name string, values ...float64) Series {
+		s := Series{Labels: map[string]string{"pod": name}}
+		for i, v := range values {
+			s.Points = append(s.Points, Point{Timestamp: int64(60 * (i + 1)), Value: v})
+		}
+		return s
+	}
+	names := func(series []Series) []string {
+		var names []string
+		for _, s := range series {
+			names = append(names, s.Labels["pod"])
+		}
+		return names
+	}
+	series := []Series{
+		pod("a", 1, 9, 2),
+		pod("b", 5, 5, 5),
+		pod("c", math.NaN()),
+		pod("d", 3, 4, 8),
+		pod("e", 8, 1, 1),
+	}
+
+	assert.Equal(t, []string{"a", "d", "e", "b", "c"}, names(rankSeries(series, aggregateMax)))
+	assert.Equal(t, []string{"d", "b", "a", "e", "c"}, names(rankSeries(series, aggregateLast)))
+	assert.Equal(t, []string{"b", "d", "a", "e", "c"}, names(rankSeries(series, aggregateMean)), "b and d tie on 5")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names(series), "the input is not reordered")
+
+	t.Run("ties are ordered by labels", func(t *testing.T) {
+		tied := []Series{pod("z", 1), pod("x", 1), pod("y", 2), pod("w"), pod("v")}
+		reversed := slices.Clone(tied)
+		slices.Reverse(reversed)
+
+		assert.Equal(t, []string{"y", "x", "z", "v", "w"}, names(rankSeries(tied, aggregateMax)))
+		assert.Equal(t, rankSeries(tied, aggregateMax), rankSeries(reversed, aggregateMax))
+	})
+}
+
+func TestSeriesStatsSkipNonFinite(t *testing.T) {
+	s := Series{Labels: map[string]string{}, Points: []Point{{Timestamp: 60, Value: 2}, {Timestamp: 120, Value: math.NaN()}, {Timestamp: 180, Value: 4}}}
+
+	assert.Equal(t, SeriesStats{Labels: map[string]string{}, Samples: 3, NonFinite: 1, From: 60, To: 180, Min: 2, Max: 4, Mean: 3, P50: 3, P95: 3.9, Last: 4}, s.Stats())
+
+	s = Series{Labels: map[string]string{}, Points: []Point{{Timestamp: 60, Value: math.Inf(1)}, {Timestamp: 120, Value: 1}, {Timestamp: 180, Value: math.Inf(-1)}}}
+	assert.Equal(t, SeriesStats{Labels: map[string]string{}, Samples: 3, NonFinite: 2, From: 60, To: 180, Min: 1, Max: 1, Mean: 1, P50: 1, P95: 1, Last: 1}, s.Stats())
+}
+
+func TestSeriesCounterResets(t *testing.T) {
+	points := []Point{{5, 8}, {1, 10}, {2, 12}, {3, 3}, {4, math.NaN()}, {6, 9}}
+
+	t.Run("counter", func(t *testing.T) {
+		s := Series{Labels: map[string]string{"__name__": "http_requests_total"}, Points: points}
+
+		// in timestamp order 10, 12, 3, NaN, 8, 9: only the drop to 3 is a reset
+		assert.Equal(t, []int64{3}, s.CounterResets())
+	})
+
+	t.Run("count and sum of a histogram", func(t *testing.T) {
+		for _, name := range []string{"request_duration_seconds_count", "request_duration_seconds_sum"} {
+			s := Series{Labels: map[string]string{"__name__": name}, Points: []Point{{1, 5}, {2, 1}, {3, 0}}}
+			assert.Equal(t, []int64{2, 3}, s.CounterResets(), name)
+		}
+	})
+
+	t.Run("gauges have no resets", func(t *testing.T) {
+		s := Series{Labels: map[string]string{"__name__": "node_memory_free_bytes"}, Points: points}
+
+		assert.Nil(t, s.CounterResets())
+	})
+
+	t.Run("series without a name have no resets", func(t *testing.T) {
+		s := Series{Labels: map[string]string{"pod": "a"}, Points: points}
+
+		assert.Nil(t, s.CounterResets())
+	})
+}
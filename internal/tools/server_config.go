@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetServerConfigParams struct{}
+
+// ServerConfig is the effective configuration reported by getServerConfig.
+// It must never hold credentials: it is returned to MCP clients as is.
+type ServerConfig struct {
+	Version           string            `json:"version"`
+	InstanceHost      string            `json:"instance_host"`
+	MetricsHost       string            `json:"metrics_host,omitempty"`
+	Transport         string            `json:"transport"`
+	TokenType         string            `json:"token_type"`
+	RequestTimeout    string            `json:"request_timeout"`
+	WriteToolsEnabled bool              `json:"write_tools_enabled"`
+	EnabledTools      []string          `json:"enabled_tools"`
+	Limits            Limits            `json:"limits"`
+	DefaultWindows    map[string]string `json:"default_windows"`
+	// ComponentDisplayName is the template rendering component names
+	ComponentDisplayName string `json:"component_display_name"`
+}
+
+// GetServerConfig reports the non-secret effective configuration of the server
+func (t tool) GetServerConfig(ctx context.Context, request *mcp.CallToolRequest, params GetServerConfigParams) (*mcp.CallToolResult, *ServerConfig, error) {
+	cfg := t.config
+	cfg.Limits = t.limits
+	cfg.ComponentDisplayName = t.componentDisplayName.String()
+	cfg.DefaultWindows = map[string]string{"listMetrics": formatStep(listMetricsWindow)}
+	if cfg.EnabledTools == nil {
+		cfg.EnabledTools = []string{}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Server configuration:\n\n")
+	sb.WriteString(fmt.Sprintf("- Version: %s\n", valueOrDash(cfg.Version)))
+	sb.WriteString(fmt.Sprintf("- Instance: %s\n", valueOrDash(cfg.InstanceHost)))
+	if cfg.MetricsHost != "" {
+		sb.WriteString(fmt.Sprintf("- Metrics instance: %s\n", cfg.MetricsHost))
+	}
+	sb.WriteString(fmt.Sprintf("- Transport: %s\n", valueOrDash(cfg.Transport)))
+	sb.WriteString(fmt.Sprintf("- Token type: %s\n", valueOrDash(cfg.TokenType)))
+	sb.WriteString(fmt.Sprintf("- Request timeout: %s\n", valueOrDash(cfg.RequestTimeout)))
+	sb.WriteString(fmt.Sprintf("- Write tools enabled: %t\n", cfg.WriteToolsEnabled))
+	sb.WriteString(fmt.Sprintf("- Enabled tools: %s\n", valueOrDash(strings.Join(cfg.EnabledTools, ", "))))
+	sb.WriteString(fmt.Sprintf("- listMetrics window: %s\n", cfg.DefaultWindows["listMetrics"]))
+	sb.WriteString(fmt.Sprintf("- Component display name: `%s`\n", cfg.ComponentDisplayName))
+	sb.WriteString("\n| Limit | Value |\n")
+	sb.WriteString("|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| Metric target points per series | %d |\n", cfg.Limits.MetricTargetPoints))
+	sb.WriteString(fmt.Sprintf("| Metric max points per series | %d |\n", cfg.Limits.MetricMaxPoints))
+	sb.WriteString(fmt.Sprintf("| Metric backend max points per request | %d |\n", cfg.Limits.MetricBackendMaxPoints))
+	sb.WriteString(fmt.Sprintf("| Metric max series | %d |\n", cfg.Limits.MetricMaxSeries))
+	sb.WriteString(fmt.Sprintf("| Metric max rows | %d |\n", cfg.Limits.MetricMaxRows))
+	sb.WriteString(fmt.Sprintf("| Metric pivot max series | %d |\n", cfg.Limits.MetricPivotMaxSeries))
+	sb.WriteString(fmt.Sprintf("| Metric max label columns | %d |\n", cfg.Limits.MetricMaxLabelColumns))
+	sb.WriteString(fmt.Sprintf("| Metric cardinality samples | %d |\n", cfg.Limits.MetricCardinalitySamples))
+	sb.WriteString(fmt.Sprintf("| Metric fallback hours | %d |\n", cfg.Limits.MetricFallbackHours))
+	sb.WriteString(fmt.Sprintf("| Metrics listed | %d |\n", cfg.Limits.MetricListRows))
+	sb.WriteString(fmt.Sprintf("| Components listed | %d |\n", cfg.Limits.ComponentRows))
+	sb.WriteString(fmt.Sprintf("| Monitors listed | %d |\n", cfg.Limits.MonitorRows))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: sb.String(),
+			},
+		},
+	}, &cfg, nil
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServerConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reports the configured limits", func(t *testing.T) {
+		limits := DefaultLimits()
+		limits.MetricMaxRows = 42
+		tools := NewBaseTool(new(MockSuseObservabilityClient)).
+			WithLimits(limits).
+			WithServerConfig(ServerConfig{Version: "v1.2.3", InstanceHost: "observability.example.com", EnabledTools: []string{"getMetrics"}})
+
+		result, structured, err := tools.GetServerConfig(ctx, nil, GetServerConfigParams{})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "- Version: v1.2.3\n")
+		assert.Contains(t, output, "- Enabled tools: getMetrics\n")
+		assert.Contains(t, output, "- listMetrics window: 1h\n")
+		assert.Contains(t, output, "- Component display name: `{{.Name}}`\n")
+		assert.Contains(t, output, "| Metric max rows | 42 |")
+		assert.Equal(t, limits, structured.Limits)
+	})
+
+	t.Run("unset values are shown as dashes", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		result, structured, err := tools.GetServerConfig(ctx, nil, GetServerConfigParams{})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "- Instance: -\n")
+		assert.NotNil(t, structured.EnabledTools)
+	})
+}
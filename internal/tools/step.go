@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// minStep is the finest resolution used when the step is chosen automatically
+const minStep = time.Minute
+
+// niceSteps are the candidate resolutions for an automatically chosen step
+var niceSteps = []time.Duration{
+	time.Minute,
+	2 * time.Minute,
+	5 * time.Minute,
+	10 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+}
+
+// resolveStep returns the step to use for a range query over [start, end].
+// Without a requested step it picks the finest nice step keeping the number of points
+// per series under the target. A requested step producing more points than the maximum
+// is coarsened. The note explains how the step was chosen.
+func resolveStep(requested string, start, end time.Time, limits Limits) (step string, note string, err error) {
+	defaults := DefaultLimits()
+	if limits.MetricTargetPoints <= 0 {
+		limits.MetricTargetPoints = defaults.MetricTargetPoints
+	}
+	if limits.MetricMaxPoints <= 0 {
+		limits.MetricMaxPoints = defaults.MetricMaxPoints
+	}
+
+	span := end.Sub(start)
+	if span < 0 {
+		span = 0
+	}
+
+	if requested == "" {
+		d := minStep
+		if target := span / time.Duration(limits.MetricTargetPoints); target > d {
+			d = niceStep(target)
+		}
+		return formatStep(d), fmt.Sprintf("auto-selected for at most %d points per series", limits.MetricTargetPoints), nil
+	}
+
+	d, err := parseStep(requested)
+	if err != nil {
+		return "", "", err
+	}
+
+	points := int64(span / d)
+	if points <= int64(limits.MetricMaxPoints) {
+		return requested, "", nil
+	}
+
+	adjusted := niceStep(span / time.Duration(limits.MetricMaxPoints))
+	note = fmt.Sprintf("adjusted from %s, which would return %d points per series (limit %d)", requested, points, limits.MetricMaxPoints)
+	return formatStep(adjusted), note, nil
+}
+
+// parseStep parses a step in duration format (e.g. '30s') or as a float number of seconds
+func parseStep(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		secs, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return 0, fmt.Errorf("invalid step '%s' (expected a duration like '1m' or a number of seconds)", s)
+		}
+		d = time.Duration(secs * float64(time.Second))
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid step '%s': must be positive", s)
+	}
+	return d, nil
+}
+
+// niceStep rounds d up to the next candidate step, or to whole days beyond the largest one
+func niceStep(d time.Duration) time.Duration {
+	for _, s := range niceSteps {
+		if s >= d {
+			return s
+		}
+	}
+	day := 24 * time.Hour
+	return (d + day - 1) / day * day
+}
+
+// formatStep renders d in the largest unit dividing it, e.g. '5m' or '2h'
+func formatStep(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveStep(t *testing.T) {
+	end := time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC)
+	limits := Limits{MetricTargetPoints: 200, MetricMaxPoints: 1000}
+
+	tests := []struct {
+		name      string
+		requested string
+		span      time.Duration
+		step      string
+		note      string
+	}{
+		{name: "short range keeps the minimum step", span: time.Hour, step: "1m", note: "auto-selected"},
+		{name: "seven days picks a coarser step", span: 7 * 24 * time.Hour, step: "1h", note: "auto-selected"},
+		{name: "one day", span: 24 * time.Hour, step: "10m", note: "auto-selected"},
+		{name: "requested step within the cap", requested: "15s", span: time.Hour, step: "15s"},
+		{name: "requested step in seconds", requested: "30", span: time.Hour, step: "30"},
+		{name: "requested step above the cap", requested: "1m", span: 7 * 24 * time.Hour, step: "15m", note: "adjusted from 1m, which would return 10080 points per series (limit 1000)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, note, err := resolveStep(tt.requested, end.Add(-tt.span), end, limits)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.step, step)
+			if tt.note == "" {
+				assert.Empty(t, note)
+			} else {
+				assert.Contains(t, note, tt.note)
+			}
+		})
+	}
+
+	t.Run("invalid step", func(t *testing.T) {
+		_, _, err := resolveStep("fast", end.Add(-time.Hour), end, limits)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid step 'fast'")
+	})
+
+	t.Run("non positive step", func(t *testing.T) {
+		_, _, err := resolveStep("0s", end.Add(-time.Hour), end, limits)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be positive")
+	})
+}
+
+func TestNiceStep(t *testing.T) {
+	assert.Equal(t, time.Minute, niceStep(10*time.Second))
+	assert.Equal(t, 5*time.Minute, niceStep(3*time.Minute))
+	assert.Equal(t, 48*time.Hour, niceStep(25*time.Hour))
+}
+
+func TestFormatStep(t *testing.T) {
+	assert.Equal(t, "45s", formatStep(45*time.Second))
+	assert.Equal(t, "90m", formatStep(90*time.Minute))
+	assert.Equal(t, "2h", formatStep(2*time.Hour))
+}
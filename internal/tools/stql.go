@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ValidateSTQLParams struct {
+	Query string `json:"query" jsonschema:"required,The STQL query to validate" examples:"type = \"pod\" AND label = \"namespace:shop\""`
+}
+
+// ValidateSTQL checks the syntax of an STQL query with SUSE Observability, without fetching
+// the components it matches
+func (t tool) ValidateSTQL(ctx context.Context, request *mcp.CallToolRequest, params ValidateSTQLParams) (*mcp.CallToolResult, *suseobservability.STQLValidation, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, nil, fmt.Errorf("query is required")
+	}
+
+	validation, err := t.client.ValidateSTQL(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to validate STQL: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatSTQLValidation(validation),
+			},
+		},
+	}, validation, nil
+}
+
+func formatSTQLValidation(v *suseobservability.STQLValidation) string {
+	if v.Valid {
+		return "Valid STQL."
+	}
+	var sb strings.Builder
+	sb.WriteString("Invalid STQL:\n\n")
+	for _, e := range v.Errors {
+		sb.WriteString(fmt.Sprintf("- %s\n", e))
+	}
+	if len(v.Errors) == 0 {
+		sb.WriteString("- SUSE Observability rejected the query without a message\n")
+	}
+	sb.WriteString(fmt.Sprintf("\nThe query was checked as `%s` to match no component, so positions on its first line are one column further. "+
+		"getQuerySyntaxHelp with language 'stql' lists the filters and operators.\n", v.CheckedQuery))
+	return sb.String()
+}
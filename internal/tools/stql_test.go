@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSTQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid query", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		validation := &suseobservability.STQLValidation{Valid: true, CheckedQuery: `(type = "pod") AND identifier = "x"`}
+		mockClient.On("ValidateSTQL", ctx, `type = "pod"`).Return(validation, nil).Once()
+
+		result, structured, err := tools.ValidateSTQL(ctx, nil, ValidateSTQLParams{Query: ` type = "pod" `})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Valid STQL.", result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, validation, structured)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("ValidateSTQL", ctx, `tpye = "pod"`).Return(&suseobservability.STQLValidation{
+			Errors:       []string{"Unknown filter 'tpye' at line 1, column 2"},
+			CheckedQuery: `(tpye = "pod") AND identifier = "x"`,
+		}, nil).Once()
+
+		result, structured, err := tools.ValidateSTQL(ctx, nil, ValidateSTQLParams{Query: `tpye = "pod"`})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Invalid STQL:\n\n- Unknown filter 'tpye' at line 1, column 2\n\n"+
+			"The query was checked as `(tpye = \"pod\") AND identifier = \"x\"` to match no component, so positions on its first line are one column further. "+
+			"getQuerySyntaxHelp with language 'stql' lists the filters and operators.\n", result.Content[0].(*mcp.TextContent).Text)
+		assert.False(t, structured.Valid)
+	})
+
+	t.Run("request failure", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		mockClient.On("ValidateSTQL", ctx, "name = 'x'").Return(nil, errors.New("timeout")).Once()
+
+		_, _, err := tools.ValidateSTQL(ctx, nil, ValidateSTQLParams{Query: "name = 'x'"})
+
+		assert.EqualError(t, err, "failed to validate STQL: timeout")
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, _, err := NewBaseTool(new(MockSuseObservabilityClient)).ValidateSTQL(ctx, nil, ValidateSTQLParams{Query: " "})
+
+		assert.EqualError(t, err, "query is required")
+	})
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type QuerySyntaxHelpParams struct {
+	Language string `json:"language" jsonschema:"Query language to describe: 'stql' or 'promql'" examples:"stql;promql"`
+	Topic    string `json:"topic,omitempty" jsonschema:"Optional topic: 'functions', 'operators' or 'examples'. Omit to get all topics" examples:"functions;operators;examples"`
+}
+
+// syntaxTopics lists the topics available for every language, in display order
+var syntaxTopics = []string{"functions", "operators", "examples"}
+
+// syntaxReference holds the built-in reference, keyed by language and topic.
+// Keep it in sync with the query builders in topology.go and metrics.go.
+var syntaxReference = map[string]map[string]string{
+	"stql": {
+		"functions": `STQL functions:
+- withNeighborsOf(components = (<filter>), levels = "<1-14|all>", direction = "<up|down|both>")
+  Adds the components connected to the ones matched by <filter>.
+  The inner filter must be a complete STQL expression wrapped in parentheses.
+  getComponents combines it with the base filter as: <filter> OR withNeighborsOf(components = (<filter>), ...)`,
+		"operators": `STQL operators:
+- Equality: name = "checkout-service"
+- Set membership: type IN ("pod", "service"), healthstate NOT IN ("CLEAR")
+- Boolean: AND, OR, NOT, with parentheses for grouping
+Filterable fields used by this server: name, type, healthstate, domain (cluster name), namespace, label.
+Values are always double-quoted. Labels (tags) use the "key:value" format, e.g. label IN ("namespace:default", "cluster-name:prod").`,
+		"examples": `STQL examples:
+- name IN ("checkout-service", "redis-master") AND type IN ("service")
+- healthstate IN ("CRITICAL", "DEVIATING") AND domain IN ("prod-cluster")
+- namespace = "kube-system" AND type IN ("pod")
+- label IN ("namespace:default") AND type IN ("deployment")
+- name IN ("db-master") OR withNeighborsOf(components = (name IN ("db-master")), levels = "2", direction = "down")`,
+	},
+	"promql": {
+		"functions": `PromQL functions:
+- rate(v[5m]), irate(v[5m]), increase(v[1h]): per-second rate / increase of counters (metrics ending in _total, _count, _sum)
+- sum by (label) (v), avg by (label) (v), max, min, count, topk(k, v), bottomk(k, v)
+- histogram_quantile(0.95, sum by (le) (rate(metric_bucket[5m])))
+- avg_over_time(v[10m]), max_over_time(v[10m]), min_over_time(v[10m])
+- absent(v), clamp_min(v, 0), round(v)`,
+		"operators": `PromQL operators:
+- Label matchers: =, !=, =~ (regex), !~ (negative regex), e.g. metric{namespace="default", pod=~"api-.*"}
+- Arithmetic: + - * / % ^ between vectors and scalars
+- Comparison: == != > < >= <= (add "bool" to return 0/1 instead of filtering)
+- Set: and, or, unless
+- Vector matching: on(label), ignoring(label), group_left, group_right
+Range selectors use durations such as [5m], [1h], [1d].`,
+		"examples": `PromQL examples:
+- CPU usage per pod: sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="default"}[5m]))
+- Memory working set: sum by (pod) (container_memory_working_set_bytes{namespace="default"})
+- Error ratio: sum(rate(http_requests_total{code=~"5.."}[5m])) / sum(rate(http_requests_total[5m]))
+- p95 latency: histogram_quantile(0.95, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))
+- Restarts in the last hour: increase(kube_pod_container_status_restarts_total[1h])
+Use getMetrics with start '1h', end 'now' and step '1m' to run a range query.`,
+	},
+}
+
+// GetQuerySyntaxHelp returns the built-in STQL or PromQL syntax reference
+func (t tool) GetQuerySyntaxHelp(ctx context.Context, request *mcp.CallToolRequest, params QuerySyntaxHelpParams) (*mcp.CallToolResult, any, error) {
+	text, err := querySyntaxHelp(params.Language, params.Topic)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}, nil, nil
+}
+
+func querySyntaxHelp(language, topic string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	topic = strings.ToLower(strings.TrimSpace(topic))
+
+	topics, ok := syntaxReference[language]
+	if !ok {
+		languages := make([]string, 0, len(syntaxReference))
+		for l := range syntaxReference {
+			languages = append(languages, l)
+		}
+		sort.Strings(languages)
+		return "", fmt.Errorf("unknown language '%s'. Must be one of: %s", language, strings.Join(languages, ", "))
+	}
+
+	if topic != "" {
+		text, ok := topics[topic]
+		if !ok {
+			return "", fmt.Errorf("unknown topic '%s'. Must be one of: %s", topic, strings.Join(syntaxTopics, ", "))
+		}
+		return text, nil
+	}
+
+	sections := make([]string, 0, len(syntaxTopics))
+	for _, tp := range syntaxTopics {
+		sections = append(sections, topics[tp])
+	}
+	return strings.Join(sections, "\n\n"), nil
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetQuerySyntaxHelp(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	t.Run("every language covers every topic", func(t *testing.T) {
+		for language, topics := range syntaxReference {
+			for _, topic := range syntaxTopics {
+				assert.NotEmpty(t, topics[topic], "%s is missing topic %s", language, topic)
+			}
+		}
+	})
+
+	t.Run("single topic", func(t *testing.T) {
+		params := QuerySyntaxHelpParams{Language: "STQL", Topic: "functions"}
+
+		result, _, err := tools.GetQuerySyntaxHelp(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "withNeighborsOf")
+		assert.NotContains(t, output, "STQL examples")
+	})
+
+	t.Run("all topics when topic is omitted", func(t *testing.T) {
+		params := QuerySyntaxHelpParams{Language: "promql"}
+
+		result, _, err := tools.GetQuerySyntaxHelp(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "PromQL functions")
+		assert.Contains(t, output, "PromQL operators")
+		assert.Contains(t, output, "PromQL examples")
+	})
+
+	t.Run("label filter format", func(t *testing.T) {
+		output, err := querySyntaxHelp("stql", "operators")
+
+		assert.NoError(t, err)
+		assert.Contains(t, output, "key:value")
+	})
+
+	t.Run("unknown language", func(t *testing.T) {
+		result, _, err := tools.GetQuerySyntaxHelp(ctx, nil, QuerySyntaxHelpParams{Language: "sql"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unknown language 'sql'")
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		result, _, err := tools.GetQuerySyntaxHelp(ctx, nil, QuerySyntaxHelpParams{Language: "stql", Topic: "joins"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unknown topic 'joins'")
+	})
+}
@@ -9,18 +9,144 @@ import (
 
 type SuseObservabilityClient interface {
 	GetBoundMetricsWithData(ctx context.Context, componentID int64, start, end time.Time) (*suseobservability.BoundMetricsResponse, error)
+	ListMetrics(ctx context.Context, start, end time.Time) ([]string, error)
+	GetMetricLabels(ctx context.Context, metric string, start, end time.Time) ([]string, error)
+	GetMetricLabelValues(ctx context.Context, metric, label string, start, end time.Time) ([]string, error)
+	GetMetricSeries(ctx context.Context, metric string, start, end time.Time, limit int) ([]map[string]string, error)
+	GetMetricMetadata(ctx context.Context) (map[string][]suseobservability.MetricMetadata, error)
+	QueryMetric(ctx context.Context, query string, at time.Time, timeout string) (*suseobservability.MetricQueryResponse, error)
 	QueryRangeMetric(ctx context.Context, query string, start time.Time, end time.Time, step, timeout string) (*suseobservability.MetricQueryResponse, error)
 	GetComponent(ctx context.Context, componentID int64) (*suseobservability.ComponentResponse, error)
 	SnapShotTopologyQuery(ctx context.Context, query string) ([]suseobservability.ViewComponent, error)
+	SnapShotTopologyQueryAt(ctx context.Context, query string, at time.Time) ([]suseobservability.ViewComponent, error)
+	ValidateSTQL(ctx context.Context, query string) (*suseobservability.STQLValidation, error)
+	ListViews(ctx context.Context) ([]suseobservability.QueryView, error)
+	GetMonitors(ctx context.Context) (*suseobservability.MonitorList, error)
+	GetMonitor(ctx context.Context, monitorIdOrUrn string) (*suseobservability.Monitor, error)
+	GetMonitorsOverview(ctx context.Context) (*suseobservability.MonitorOverviewList, error)
+	PostEvent(ctx context.Context, event suseobservability.IntakeEvent) error
+	EachMonitorCheckStatePage(ctx context.Context, monitorIdOrUrn string, healthState string, pageSize int, timestamp int64, fn func(states []suseobservability.ViewCheckState) (bool, error)) error
+	GetTrace(ctx context.Context, id string) (*suseobservability.Trace, error)
+	QueryTraces(ctx context.Context, req *suseobservability.TraceQueryRequest) (*suseobservability.TraceQueryResponse, error)
+	GetEvents(ctx context.Context, req *suseobservability.EventListRequest) (*suseobservability.EventItemsWithTotal, error)
+	GetLogs(ctx context.Context, req *suseobservability.LogListRequest) (*suseobservability.LogLinesWithTotal, error)
+	Ping(ctx context.Context) (*suseobservability.PingResult, error)
+}
+
+// Limits bounds the amount of data the tools request and render
+type Limits struct {
+	// MetricTargetPoints is the number of points per series an automatically chosen step aims for
+	MetricTargetPoints int `json:"metric_target_points"`
+	// MetricMaxPoints is the maximum number of points per series a user supplied step may produce
+	MetricMaxPoints int `json:"metric_max_points"`
+	// MetricBackendMaxPoints is the number of points per series the backend returns for one
+	// range query at most, longer getMetrics queries are split into chunks, 0 to never split
+	MetricBackendMaxPoints int `json:"metric_backend_max_points"`
+	// MetricMaxSeries is the default number of series rendered by getMetrics
+	MetricMaxSeries int `json:"metric_max_series"`
+	// MetricMaxRows is the default number of rows rendered by getMetrics
+	MetricMaxRows int `json:"metric_max_rows"`
+	// MetricPivotMaxSeries is the number of series above which getMetrics refuses the pivot layout
+	MetricPivotMaxSeries int `json:"metric_pivot_max_series"`
+	// MetricMaxLabelColumns is the number of label columns of the getMetrics tables above which
+	// the labels telling the series apart least are collapsed into one column, 0 for no maximum
+	MetricMaxLabelColumns int `json:"metric_max_label_columns"`
+	// MetricMaxRangeHours is the longest time range getMetrics queries in raw mode, 0 for no maximum
+	MetricMaxRangeHours int `json:"metric_max_range_hours"`
+	// MetricCardinalitySamples is the number of series getMetricCardinality counts labels over at most
+	MetricCardinalitySamples int `json:"metric_cardinality_samples"`
+	// MetricFallbackHours is the window listMetrics searches for metrics without samples in
+	// the lookback when a search has no match, 0 to disable the fallback
+	MetricFallbackHours int `json:"metric_fallback_hours"`
+	// MetricListRows is the default number of metrics listed by listMetrics
+	MetricListRows int `json:"metric_list_rows"`
+	// ComponentRows is the default number of components listed by getComponents
+	ComponentRows int `json:"component_rows"`
+	// MonitorRows is the default number of monitors listed by listMonitors
+	MonitorRows int `json:"monitor_rows"`
+}
+
+// DefaultLimits returns the limits used when none are configured
+func DefaultLimits() Limits {
+	return Limits{
+		MetricTargetPoints:       200,
+		MetricMaxPoints:          1000,
+		MetricBackendMaxPoints:   11000,
+		MetricMaxSeries:          20,
+		MetricMaxRows:            500,
+		MetricPivotMaxSeries:     5,
+		MetricMaxLabelColumns:    8,
+		MetricMaxRangeHours:      7 * 24,
+		MetricCardinalitySamples: 10000,
+		MetricFallbackHours:      24,
+		MetricListRows:           50,
+		ComponentRows:            100,
+		MonitorRows:              50,
+	}
+}
+
+// NamespaceQueries are the PromQL queries getNamespaceResourceUsage aggregates per namespace.
+// Each must return one series per namespace label, $cluster and $window are replaced by the
+// cluster name and the window of the call. An empty query leaves its column out.
+type NamespaceQueries struct {
+	CPU    string
+	Memory string
+	Pods   string
+}
+
+// DefaultNamespaceQueries returns the queries on the standard cAdvisor container metrics
+func DefaultNamespaceQueries() NamespaceQueries {
+	return NamespaceQueries{
+		CPU:    `sum by (namespace) (rate(container_cpu_usage_seconds_total{cluster_name="$cluster", container!=""}[$window]))`,
+		Memory: `sum by (namespace) (avg_over_time(container_memory_working_set_bytes{cluster_name="$cluster", container!=""}[$window]))`,
+		Pods:   `count by (namespace) (count by (namespace, pod) (container_memory_working_set_bytes{cluster_name="$cluster", container!=""}))`,
+	}
 }
 
 type tool struct {
-	client SuseObservabilityClient
+	client           SuseObservabilityClient
+	limits           Limits
+	namespaceQueries NamespaceQueries
+	// componentDisplayName renders component names, unless a call passes its own template
+	componentDisplayName DisplayName
+	config               ServerConfig
+	watchers             *healthWatchers
+	// clock is the time the tools query back from and render ages against
+	clock clock
 }
 
 // NewBaseTool returns a tool factory
 func NewBaseTool(c SuseObservabilityClient) (t *tool) {
 	t = new(tool)
 	t.client = c
+	t.limits = DefaultLimits()
+	t.namespaceQueries = DefaultNamespaceQueries()
+	t.componentDisplayName = DefaultDisplayName()
+	t.watchers = newHealthWatchers(realClock{})
+	t.clock = realClock{}
 	return
 }
+
+// WithLimits overrides the default limits
+func (t *tool) WithLimits(l Limits) *tool {
+	t.limits = l
+	return t
+}
+
+// WithNamespaceQueries overrides the default queries of getNamespaceResourceUsage
+func (t *tool) WithNamespaceQueries(q NamespaceQueries) *tool {
+	t.namespaceQueries = q
+	return t
+}
+
+// WithComponentDisplayName overrides the default display name of components
+func (t *tool) WithComponentDisplayName(d DisplayName) *tool {
+	t.componentDisplayName = d
+	return t
+}
+
+// WithServerConfig sets the configuration reported by getServerConfig
+func (t *tool) WithServerConfig(c ServerConfig) *tool {
+	t.config = c
+	return t
+}
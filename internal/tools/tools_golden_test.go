@@ -0,0 +1,684 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/mock"
+)
+
+// goldenCase renders a tool call against canned client responses. Every case runs with the
+// fake clock, so outputs depending on the current time are stable.
+type goldenCase struct {
+	name  string
+	setup func(m *MockSuseObservabilityClient)
+	call  func(tl *tool) (*mcp.CallToolResult, error)
+}
+
+// goldenOutput renders a tool result as compared to its golden file: the text contents,
+// marked when the result is partial, or the error the call failed with
+func goldenOutput(result *mcp.CallToolResult, err error) string {
+	if err != nil {
+		return "error: " + err.Error() + "\n"
+	}
+	var texts []string
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	output := strings.Join(texts, "\n")
+	if IsPartial(result) {
+		output += "\n[partial result]\n"
+	}
+	return output
+}
+
+// goldenComponents are components with the characters escaping must survive: pipes, line
+// breaks and non ASCII names
+func goldenComponents() []suseobservability.ViewComponent {
+	components := []suseobservability.ViewComponent{
+		{ID: 1, Name: "checkout", Type: 10, Layer: 3, Domain: 5, Tags: []string{"namespace:shop", "cluster-name:prod"}},
+		{ID: 2, Name: "cart | legacy", Description: "Stores carts\nper session", Type: 10, Layer: 3, Domain: 5},
+		{ID: 3, Name: "決済サービス 💳", Type: 11, Layer: 3, Domain: 5, OutgoingRelations: []int64{1, 2, 4, 5}},
+		{ID: 4, Name: "café-é", Type: 11, Layer: 4, Domain: 5, IncomingRelations: []int64{3}},
+	}
+	components[0].State.HealthState = "CRITICAL"
+	components[0].State.PropagatedHealthState = "CRITICAL"
+	components[1].State.HealthState = "CLEAR"
+	components[2].State.HealthState = "DEVIATING"
+	return components
+}
+
+// goldenRange returns a range query response of the series, each a value per minute up to
+// the time of the fake clock
+func goldenRange(series map[string][]float64) *suseobservability.MetricQueryResponse {
+	return goldenRangeUntil(1735732800, series)
+}
+
+// goldenRangeUntil returns a range query response of the series, each a value per minute up
+// to end
+func goldenRangeUntil(end int64, series map[string][]float64) *suseobservability.MetricQueryResponse {
+	res := &suseobservability.MetricQueryResponse{Status: "success", Data: suseobservability.MetricData{ResultType: "matrix"}}
+	for _, pod := range sortedKeys(series) {
+		result := suseobservability.MetricResult{Labels: map[string]string{"__name__": "cpu", "pod": pod}}
+		for i, v := range series[pod] {
+			result.Points = append(result.Points, suseobservability.MetricPoint{Timestamp: end - int64(len(series[pod])-1-i)*60, Value: v})
+		}
+		res.Data.Result = append(res.Data.Result, result)
+	}
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var goldenTools = map[string][]goldenCase{
+	"getComponents": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("SnapShotTopologyQuery", mock.Anything, mock.Anything).Return(goldenComponents(), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetComponents(context.Background(), nil, GetComponentsParams{Types: "service"})
+				return r, err
+			},
+		},
+		{
+			name: "empty",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("SnapShotTopologyQuery", mock.Anything, mock.Anything).Return([]suseobservability.ViewComponent{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetComponents(context.Background(), nil, GetComponentsParams{Names: "missing"})
+				return r, err
+			},
+		},
+		{
+			name: "truncated",
+			setup: func(m *MockSuseObservabilityClient) {
+				var components []suseobservability.ViewComponent
+				for i := 0; i < 8; i++ {
+					components = append(components, suseobservability.ViewComponent{ID: int64(100 + i), Name: fmt.Sprintf("pod-%02d", i)})
+				}
+				m.On("SnapShotTopologyQuery", mock.Anything, mock.Anything).Return(components, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				tl.limits.ComponentRows = 5
+				r, _, err := tl.GetComponents(context.Background(), nil, GetComponentsParams{Types: "pod"})
+				return r, err
+			},
+		},
+	},
+	"getComponent": {
+		{
+			name: "details",
+			setup: func(m *MockSuseObservabilityClient) {
+				c := goldenComponents()[2]
+				c.Description = "Payments | ビジネス\ncritical path"
+				c.LastUpdateTimestamp = 1735732500000
+				c.Identifiers = []string{"urn:service:/payments"}
+				c.Properties = map[string]string{"team": "payments | core", "region": "eu-west-1"}
+				m.On("SnapShotTopologyQuery", mock.Anything, "id = 3").Return([]suseobservability.ViewComponent{c}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetComponent(context.Background(), nil, GetComponentParams{ID: 3})
+				return r, err
+			},
+		},
+		{
+			name: "not_found",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("SnapShotTopologyQuery", mock.Anything, "id = 9").Return([]suseobservability.ViewComponent{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetComponent(context.Background(), nil, GetComponentParams{ID: 9})
+				return r, err
+			},
+		},
+	},
+	"topologyDiff": {
+		{
+			name: "changes",
+			setup: func(m *MockSuseObservabilityClient) {
+				now := newFakeClock().Now()
+				before := goldenComponents()[:3]
+				after := goldenComponents()[1:]
+				after[0].State.HealthState = "CRITICAL"
+				m.On("SnapShotTopologyQueryAt", mock.Anything, `type = "pod"`, now.Add(-24*time.Hour)).Return(before, nil)
+				m.On("SnapShotTopologyQueryAt", mock.Anything, `type = "pod"`, now).Return(after, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.TopologyDiff(context.Background(), nil, TopologyDiffParams{Query: `type = "pod"`, From: "24h"})
+				return r, err
+			},
+		},
+	},
+	"getEvents": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetEvents", mock.Anything, mock.Anything).Return(&suseobservability.EventItemsWithTotal{Items: []suseobservability.TopologyEvent{
+					topologyEvent("Deployment checkout | v2 rolled out", suseobservability.EventCategoryDeployments, "Kubernetes", 1735732740000),
+					topologyEvent("Pod restarted\nafter OOMKill", suseobservability.EventCategoryChanges, "Kubernetes", 1735732680000),
+					topologyEvent("CPU スロットリング ⚠️", suseobservability.EventCategoryAlerts, "", 1735732620000),
+				}, Total: 12}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetEvents(context.Background(), nil, GetEventsParams{ComponentID: 42, Limit: 3})
+				return r, err
+			},
+		},
+		{
+			name: "empty",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetEvents", mock.Anything, mock.Anything).Return(&suseobservability.EventItemsWithTotal{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetEvents(context.Background(), nil, GetEventsParams{Categories: []string{"alerts"}})
+				return r, err
+			},
+		},
+	},
+	"getLogs": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetLogs", mock.Anything, mock.Anything).Return(&suseobservability.LogLinesWithTotal{Items: []suseobservability.LogLine{
+					{Timestamp: 1735732740000, Level: "error", Message: "panic: runtime error\n\tgoroutine 1 [running]:\n"},
+					{Timestamp: 1735732680000, Level: "WARN", Message: "ユーザー 42 | 再試行 🔁"},
+					{Timestamp: 1735732620000, Message: "GET /cart 200"},
+				}, Total: 40}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetLogs(context.Background(), nil, GetLogsParams{ComponentID: 42, Limit: 3})
+				return r, err
+			},
+		},
+		{
+			name: "empty",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetLogs", mock.Anything, mock.Anything).Return(&suseobservability.LogLinesWithTotal{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetLogs(context.Background(), nil, GetLogsParams{ComponentURN: "urn:kubernetes:/prod:shop:pod/checkout-1", Filter: "timeout"})
+				return r, err
+			},
+		},
+	},
+	"listTraces": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryTraces", mock.Anything, mock.Anything).Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(2), Page: 0, PageSize: 2, MatchesTotal: 5}, nil)
+				m.On("GetTrace", mock.Anything, "trace-0").Return(&suseobservability.Trace{TraceID: "trace-0", Spans: []suseobservability.Span{
+					{SpanID: "a", SpanName: "GET /checkout | v2", ServiceName: "checkout", StartTime: suseobservability.SpanTime{Timestamp: 1735732700000}, DurationNanos: 250_000_000},
+					{SpanID: "b", ParentSpanID: "a", SpanName: "SELECT 注文", ServiceName: "db", StartTime: suseobservability.SpanTime{Timestamp: 1735732700010}, DurationNanos: 40_000_000},
+				}}, nil)
+				m.On("GetTrace", mock.Anything, "trace-1").Return(nil, errors.New("trace not found"))
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListTraces(context.Background(), nil, ListTracesParams{ServiceName: "checkout", PageSize: 2})
+				return r, err
+			},
+		},
+		{
+			name: "empty",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryTraces", mock.Anything, mock.Anything).Return(&suseobservability.TraceQueryResponse{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListTraces(context.Background(), nil, ListTracesParams{ServiceName: "checkout"})
+				return r, err
+			},
+		},
+	},
+	"listMetrics": {
+		{
+			name: "search",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("ListMetrics", mock.Anything, mock.Anything, mock.Anything).Return([]string{"cpu_seconds_total", "cpu_throttled_seconds_total", "memory_bytes"}, nil)
+				m.On("GetMetricLabels", mock.Anything, "cpu_seconds_total", mock.Anything, mock.Anything).Return([]string{"__name__", "pod", "namespace"}, nil)
+				m.On("GetMetricLabels", mock.Anything, "cpu_throttled_seconds_total", mock.Anything, mock.Anything).Return([]string{"pod"}, nil)
+				m.On("GetMetricMetadata", mock.Anything).Return(map[string][]suseobservability.MetricMetadata{
+					"cpu_seconds_total": {{Type: "counter", Help: "CPU time | user and system", Unit: "seconds"}},
+				}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListMetrics(context.Background(), nil, ListMetricsParams{Search: "cpu"})
+				return r, err
+			},
+		},
+	},
+	"getMetricLabels": {
+		{
+			name: "values",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetMetricSeries", mock.Anything, "up", mock.Anything, mock.Anything, 0).Return([]map[string]string{
+					{"__name__": "up", "job": "api", "pod": "api-1"},
+					{"__name__": "up", "job": "api", "pod": "api-2"},
+					{"__name__": "up", "job": "ワーカー", "pod": "worker|1"},
+				}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMetricLabels(context.Background(), nil, GetMetricLabelsParams{MetricName: "up", IncludeValues: true})
+				return r, err
+			},
+		},
+	},
+	"getMetricLabelValues": {
+		{
+			name: "values",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetMetricLabelValues", mock.Anything, "up", "job", mock.Anything, mock.Anything).Return([]string{"worker|1", "api", "ワーカー"}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMetricLabelValues(context.Background(), nil, GetMetricLabelValuesParams{MetricName: "up", Label: "job", Limit: 2})
+				return r, err
+			},
+		},
+	},
+	"getMetricCardinality": {
+		{
+			name: "labels",
+			setup: func(m *MockSuseObservabilityClient) {
+				var sets []map[string]string
+				for i := 0; i < 6; i++ {
+					sets = append(sets, map[string]string{"__name__": "up", "job": "api", "pod": fmt.Sprintf("api-%d", i), "zone": fmt.Sprintf("z%d", i%2)})
+				}
+				m.On("GetMetricSeries", mock.Anything, "up", mock.Anything, mock.Anything, mock.Anything).Return(sets, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMetricCardinality(context.Background(), nil, GetMetricCardinalityParams{MetricName: "up"})
+				return r, err
+			},
+		},
+	},
+	"getMetrics": {
+		{
+			name: "flat",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.Anything, mock.Anything, "").
+					Return(goldenRange(map[string][]float64{"api-1": {0.5, 0.75, 1}, "ワーカー|1": {2, 2.5}}), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Query: "cpu", Start: "5m"})
+				return r, err
+			},
+		},
+		{
+			name: "summary",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.Anything, mock.Anything, "").
+					Return(goldenRange(map[string][]float64{"api-1": {0.5, 0.75, 1}, "api-2": {2, 2.5}}), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Query: "cpu", Start: "5m", Mode: modeSummary})
+				return r, err
+			},
+		},
+		{
+			name: "truncated",
+			setup: func(m *MockSuseObservabilityClient) {
+				series := map[string][]float64{}
+				for i := 0; i < 5; i++ {
+					series[fmt.Sprintf("api-%d", i)] = []float64{float64(i), float64(i) + 0.5, float64(i) + 1}
+				}
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.Anything, mock.Anything, "").Return(goldenRange(series), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Query: "cpu", Start: "5m", MaxSeries: 2, MaxRows: 4})
+				return r, err
+			},
+		},
+		{
+			name: "empty",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.Anything, mock.Anything, "").Return(goldenRange(nil), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Query: "cpu", Start: "5m"})
+				return r, err
+			},
+		},
+		{
+			name: "chunked",
+			setup: func(m *MockSuseObservabilityClient) {
+				now := newFakeClock().Now()
+				m.On("QueryRangeMetric", mock.Anything, "cpu", now.Add(-5*time.Minute), now.Add(-3*time.Minute), "1m", "").
+					Return(goldenRangeUntil(now.Add(-3*time.Minute).Unix(), map[string][]float64{"api-1": {0.25, 0.5, 0.75}}), nil)
+				// the second chunk repeats the last sample of the first one
+				m.On("QueryRangeMetric", mock.Anything, "cpu", now.Add(-2*time.Minute), now, "1m", "").
+					Return(goldenRange(map[string][]float64{"api-1": {0.75, 1, 1.25, 1.5}}), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				tl.limits.MetricBackendMaxPoints = 3
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Query: "cpu", Start: "5m", Step: "1m"})
+				return r, err
+			},
+		},
+		{
+			name: "batch_partial",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.Anything, mock.Anything, "").
+					Return(goldenRange(map[string][]float64{"api-1": {0.5, 1}}), nil)
+				m.On("QueryRangeMetric", mock.Anything, "memory", mock.Anything, mock.Anything, mock.Anything, "").
+					Return(nil, errors.New("query timed out"))
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.QueryMetric(context.Background(), nil, QueryMetricParams{Queries: []MetricQuery{{Query: "cpu"}, {Query: "memory"}}, Start: "5m"})
+				return r, err
+			},
+		},
+	},
+	"compareMetrics": {
+		{
+			name: "changes",
+			setup: func(m *MockSuseObservabilityClient) {
+				now := newFakeClock().Now()
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.MatchedBy(func(end time.Time) bool { return end.Equal(now) }), mock.Anything, "").
+					Return(goldenRange(map[string][]float64{"api-1": {2, 3}, "api-2": {1, 1}, "new": {5}}), nil)
+				m.On("QueryRangeMetric", mock.Anything, "cpu", mock.Anything, mock.MatchedBy(func(end time.Time) bool { return end.Equal(now.Add(-24 * time.Hour)) }), mock.Anything, "").
+					Return(goldenRange(map[string][]float64{"api-1": {1, 1}, "api-2": {1, 1}, "gone": {4}}), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.CompareMetrics(context.Background(), nil, CompareMetricsParams{Query: "cpu", Window: "1h", Offset: "24h"})
+				return r, err
+			},
+		},
+	},
+	"getHistogramQuantiles": {
+		{
+			name: "default quantiles",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetMetricSeries", mock.Anything, "latency_seconds_bucket", mock.Anything, mock.Anything, 1).
+					Return([]map[string]string{{"__name__": "latency_seconds_bucket", "le": "+Inf"}}, nil)
+				for q, values := range map[string][]float64{"0.5": {0.02, 0.03}, "0.9": {0.1, 0.2}, "0.99": {0.4, math.NaN()}} {
+					res := goldenRange(map[string][]float64{"api-1": values})
+					res.Data.Result[0].Labels = map[string]string{}
+					m.On("QueryRangeMetric", mock.Anything, "histogram_quantile("+q+", sum by (le) (rate(latency_seconds_bucket[5m])))", mock.Anything, mock.Anything, mock.Anything, "").
+						Return(res, nil)
+				}
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetHistogramQuantiles(context.Background(), nil, GetHistogramQuantilesParams{Metric: "latency_seconds"})
+				return r, err
+			},
+		},
+		{
+			name: "not a histogram",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetMetricSeries", mock.Anything, "up_bucket", mock.Anything, mock.Anything, 1).Return([]map[string]string{}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetHistogramQuantiles(context.Background(), nil, GetHistogramQuantilesParams{Metric: "up"})
+				return r, err
+			},
+		},
+	},
+	"getMetricValue": {
+		{
+			name: "vector",
+			setup: func(m *MockSuseObservabilityClient) {
+				res := goldenRange(map[string][]float64{"api-1": {3}, "ワーカー|1": {0.25}})
+				res.Data.ResultType = "vector"
+				m.On("QueryMetric", mock.Anything, "cpu", mock.Anything, "").Return(res, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMetricValue(context.Background(), nil, GetMetricValueParams{Query: "cpu"})
+				return r, err
+			},
+		},
+	},
+	"validatePromQL": {
+		{
+			name: "valid",
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ValidatePromQL(context.Background(), nil, ValidatePromQLParams{Query: `sum by (pod) (rate(http_requests_total{job="api"}[5m]))`})
+				return r, err
+			},
+		},
+		{
+			name: "invalid",
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ValidatePromQL(context.Background(), nil, ValidatePromQLParams{Query: `sum(rate(http_requests_total[5m])`})
+				return r, err
+			},
+		},
+	},
+	"validateSTQL": {
+		{
+			name: "invalid",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("ValidateSTQL", mock.Anything, `nme = "checkout"`).Return(&suseobservability.STQLValidation{
+					Errors:       []string{"Unexpected token 'nme' at line 1, column 2", "Expected a filter such as name = \"x\""},
+					CheckedQuery: `(nme = "checkout") AND identifier = "urn:suse-observability-mcp:stql-validation"`,
+				}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ValidateSTQL(context.Background(), nil, ValidateSTQLParams{Query: `nme = "checkout"`})
+				return r, err
+			},
+		},
+	},
+	"listViews": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("ListViews", mock.Anything).Return(testViews(), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListViews(context.Background(), nil, ListViewsParams{})
+				return r, err
+			},
+		},
+	},
+	"listMonitors": {
+		{
+			name: "list",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetComponent", mock.Anything, int64(42)).Return(&suseobservability.ComponentResponse{Node: suseobservability.ComponentNode{
+					ID: 42, Name: "checkout",
+					SyncedCheckStates: []map[string]interface{}{
+						{"name": "High CPU | pods", "health": "CRITICAL", "data": map[string]interface{}{"remediationHint": "Scale the deployment\nor raise limits"}},
+						{"name": "レイテンシ 🐢", "health": "DEVIATING"},
+					},
+				}}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListMonitors(context.Background(), nil, ListMonitorsParams{ComponentID: 42})
+				return r, err
+			},
+		},
+	},
+	"getMonitors": {
+		{
+			name: "firing",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("GetMonitorsOverview", mock.Anything).Return(&suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{
+					monitorOverview("pod restarts", 0, 4),
+					monitorOverview("disk | full", 2, 1),
+					monitorOverview("quiet", 0, 0),
+					monitorOverview("ノード停止", 3, 0),
+				}}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMonitors(context.Background(), nil, GetMonitorsParams{State: "CRITICAL,DEVIATING"})
+				return r, err
+			},
+		},
+	},
+	"getMonitor": {
+		{
+			name: "details",
+			setup: func(m *MockSuseObservabilityClient) {
+				monitor := &suseobservability.Monitor{
+					Id: 42, Name: "Pod restarts", Identifier: "urn:stackpack:kubernetes:monitor:pod-restarts",
+					Description: "Pods restarting\ntoo often", RemediationHint: "Check the pod logs.", IntervalSeconds: 30,
+					Status: suseobservability.MonitorStatusEnabled, RuntimeStatus: suseobservability.MonitorRuntimeStatusEnabled,
+					LastUpdateTimestamp: 1735732000000,
+				}
+				critical := checkStatePages(0, 1, 2, "CRITICAL")
+				critical[0][1].Message = "Restarted 12 times\nin the last 10 minutes | OOMKilled"
+				m.On("GetMonitor", mock.Anything, "42").Return(monitor, nil)
+				m.On("GetMonitorsOverview", mock.Anything).Return(&suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{{
+					Monitor: *monitor,
+					RuntimeMetrics: suseobservability.MonitorRuntimeMetrics{
+						CriticalCount: 2, ClearCount: 10, HealthStatesCount: 12,
+						LastRunTimestamp: 1735732740000, LastSuccessfulRunTimestamp: 1735732740000,
+					},
+				}}}, nil)
+				m.On("EachMonitorCheckStatePage", mock.Anything, "42", "CRITICAL", monitorCheckStatePageSize, int64(0)).Return(critical, nil)
+				m.On("EachMonitorCheckStatePage", mock.Anything, "42", mock.Anything, monitorCheckStatePageSize, int64(0)).Return(nil, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMonitor(context.Background(), nil, GetMonitorParams{ID: "42"})
+				return r, err
+			},
+		},
+	},
+	"getMonitorCheckStates": {
+		{
+			name: "truncated",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("EachMonitorCheckStatePage", mock.Anything, "42", "CRITICAL", mock.Anything, int64(0)).Return(checkStatePages(0, 1, 4, "CRITICAL"), nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetMonitorCheckStates(context.Background(), nil, GetMonitorCheckStatesParams{Monitor: "42", State: "CRITICAL", Limit: 3})
+				return r, err
+			},
+		},
+	},
+	"listMonitorsForType": {
+		{
+			name: "pod",
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.ListMonitorsForType(context.Background(), nil, ListMonitorsForTypeParams{Type: "pod"})
+				return r, err
+			},
+		},
+	},
+	"getClusterHealth": {
+		{
+			name: "cluster",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("SnapShotTopologyQuery", mock.Anything, mock.Anything).Return([]suseobservability.ViewComponent{
+					viewComponent(1, "api", "CRITICAL"),
+					viewComponent(2, "db", "CLEAR"),
+					viewComponent(3, "キャッシュ", "DEVIATING"),
+					viewComponent(4, "queue", ""),
+				}, nil)
+				m.On("GetMonitorsOverview", mock.Anything).Return(&suseobservability.MonitorOverviewList{Monitors: []suseobservability.MonitorOverview{
+					monitorOverview("node down", 1, 0, "cluster-name:prod"),
+					monitorOverview("disk | full", 0, 2, "cluster-name:prod"),
+					monitorOverview("global", 3, 0),
+					monitorOverview("staging only", 5, 0, "cluster-name:staging"),
+				}}, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetClusterHealth(context.Background(), nil, ClusterHealthParams{Cluster: "prod"})
+				return r, err
+			},
+		},
+	},
+	"getNamespaceResourceUsage": {
+		{
+			name: "usage",
+			setup: func(m *MockSuseObservabilityClient) {
+				queryInstant := func(prefix string) *mock.Call {
+					return m.On("QueryMetric", mock.Anything, mock.MatchedBy(func(q string) bool { return strings.HasPrefix(q, prefix) }), mock.Anything, "")
+				}
+				queryInstant("sum by (namespace) (rate(").Return(namespaceResponse(map[string]float64{"web": 1.25, "db": 0.5, "ミドル": 0.125}), nil)
+				queryInstant("sum by (namespace) (avg_over_time(").Return(namespaceResponse(map[string]float64{"web": 512 * 1024 * 1024, "db": 3 * 1024 * 1024 * 1024}), nil)
+				queryInstant("count by (namespace)").Return(nil, errors.New("query timed out"))
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetNamespaceResourceUsage(context.Background(), nil, GetNamespaceResourceUsageParams{Cluster: "prod"})
+				return r, err
+			},
+		},
+	},
+	"getQuerySyntaxHelp": {
+		{
+			name: "promql",
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.GetQuerySyntaxHelp(context.Background(), nil, QuerySyntaxHelpParams{Language: "promql"})
+				return r, err
+			},
+		},
+	},
+	"healthCheck": {
+		{
+			name: "reachable",
+			setup: func(m *MockSuseObservabilityClient) {
+				res := &suseobservability.PingResult{TokenType: "api token", Latency: 42 * time.Millisecond}
+				res.Server.Version.Major, res.Server.Version.Minor, res.Server.Version.Patch = 7, 1, 3
+				res.Server.DeploymentMode = "SaaS"
+				m.On("Ping", mock.Anything).Return(res, nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.HealthCheck(context.Background(), nil, HealthCheckParams{})
+				return r, err
+			},
+		},
+	},
+	"createAnnotation": {
+		{
+			name: "created",
+			setup: func(m *MockSuseObservabilityClient) {
+				m.On("PostEvent", mock.Anything, mock.Anything).Return(nil)
+			},
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				r, _, err := tl.CreateAnnotation(context.Background(), nil, CreateAnnotationParams{Message: "Deployed v2 | カナリア 🚀", ComponentIdentifier: "urn:service:/checkout", Tags: []string{"release"}})
+				return r, err
+			},
+		},
+	},
+	"getServerConfig": {
+		{
+			name: "config",
+			call: func(tl *tool) (*mcp.CallToolResult, error) {
+				tl.WithServerConfig(ServerConfig{Version: "1.2.3", InstanceHost: "observability.example.com", Transport: "stdio", TokenType: "api token", RequestTimeout: "30s", EnabledTools: []string{"getMetrics"}})
+				r, _, err := tl.GetServerConfig(context.Background(), nil, GetServerConfigParams{})
+				return r, err
+			},
+		},
+	},
+	// watchHealth is left out: its output depends on the state of the session between calls,
+	// watch_test.go covers it with the fake clock
+}
+
+func TestToolsGolden(t *testing.T) {
+	for _, name := range sortedKeys(goldenTools) {
+		for _, tc := range goldenTools[name] {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				mockClient := new(MockSuseObservabilityClient)
+				mockClient.On("GetMonitors", mock.Anything).Return(loadMonitorDefinitions(t), nil).Maybe()
+				if tc.setup != nil {
+					tc.setup(mockClient)
+				}
+				tl := NewBaseTool(mockClient)
+				tl.clock = newFakeClock()
+
+				assertGolden(t, filepath.Join("tools", name, tc.name+".md"), goldenOutput(tc.call(tl)))
+			})
+		}
+	}
+}
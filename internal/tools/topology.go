@@ -3,7 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"suse-observability-mcp/client/suseobservability"
 
@@ -12,51 +15,217 @@ import (
 
 type GetComponentsParams struct {
 	// Filters - all support multiple comma-separated values
-	Names        string `json:"names,omitempty" jsonschema:"Component names to match (comma-separated for multiple values, e.g., 'checkout-service,redis-master')"`
-	Types        string `json:"types,omitempty" jsonschema:"Component types to filter (comma-separated, e.g., 'pod,service,deployment')"`
-	HealthStates string `json:"healthstates,omitempty" jsonschema:"Health states to filter (comma-separated, e.g., 'CRITICAL,DEVIATING')"`
-	Domains      string `json:"domains,omitempty" jsonschema:"Cluster names to filter (comma-separated, e.g., 'prod-cluster,staging-cluster'). Domain represents the cluster name."`
-	Namespace    string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to filter (e.g., 'default', 'kube-system')"`
+	Names        string `json:"names,omitempty" jsonschema:"Component names to match (comma-separated for multiple values, e.g., 'checkout-service,redis-master')" examples:"checkout-service,redis-master"`
+	Types        string `json:"types,omitempty" jsonschema:"Component types to filter (comma-separated, e.g., 'pod,service,deployment')" examples:"pod,service,deployment"`
+	HealthStates string `json:"healthstates,omitempty" jsonschema:"Health states to filter (comma-separated, e.g., 'CRITICAL,DEVIATING'), a component in any of them matches" examples:"CRITICAL;CRITICAL,DEVIATING"`
+	Domains      string `json:"domains,omitempty" jsonschema:"Cluster names to filter (comma-separated, e.g., 'prod-cluster,staging-cluster'). Domain represents the cluster name." examples:"prod-cluster,staging-cluster"`
+	Namespace    string `json:"namespace,omitempty" jsonschema:"Kubernetes namespace to filter (e.g., 'default', 'kube-system')" examples:"default;kube-system"`
+	ID           int64  `json:"id,omitempty" jsonschema:"The ID of the component to match"`
+	Identifier   string `json:"identifier,omitempty" jsonschema:"An identifier (URN) of the component to match, e.g. 'urn:kubernetes:/prod:shop:pod/checkout-1'" examples:"urn:kubernetes:/prod:shop:pod/checkout-1"`
+	// MatchAny combines the filters with OR instead of AND
+	MatchAny bool `json:"match_any,omitempty" jsonschema:"Match components matching any of the filters instead of all of them, e.g. type 'pod' OR healthstate 'CRITICAL'. The values of a single filter always match any of them (default: false)" default:"false"`
 
 	// withNeighborsOf parameters
 	WithNeighbors          bool   `json:"with_neighbors,omitempty" jsonschema:"Include connected components using withNeighborsOf function"`
-	WithNeighborsLevels    string `json:"with_neighbors_levels,omitempty" jsonschema:"Number of levels (1-14) or 'all' for withNeighborsOf,default=1"`
-	WithNeighborsDirection string `json:"with_neighbors_direction,omitempty" jsonschema:"Direction: 'up', 'down', or 'both' for withNeighborsOf,default=both"`
+	WithNeighborsLevels    string `json:"with_neighbors_levels,omitempty" jsonschema:"Number of levels (1-14) or 'all' for withNeighborsOf (default: 1)" default:"1" examples:"1;3;all"`
+	WithNeighborsDirection string `json:"with_neighbors_direction,omitempty" jsonschema:"Direction: 'up', 'down', or 'both' for withNeighborsOf (default: both)" enum:"up,down,both" default:"both"`
+
+	At          string `json:"at,omitempty" jsonschema:"Time of the topology snapshot to search: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d') (default: now)" default:"now" examples:"now;1h;24h;now-1d/d"`
+	Limit       int    `json:"limit,omitempty" jsonschema:"Maximum number of components listed (default: 100)"`
+	DisplayName string `json:"display_name,omitempty" jsonschema:"Go template rendering component names, e.g. '{{.Namespace}}/{{.Name}}' (default: the server's -component-display-name)" examples:"{{.Namespace}}/{{.Name}}"`
+}
+
+type GetComponentParams struct {
+	ID          int64  `json:"id" jsonschema:"required,The ID of the component to inspect"`
+	DisplayName string `json:"display_name,omitempty" jsonschema:"Go template rendering the component name, e.g. '{{.Namespace}}/{{.Name}}' (default: the server's -component-display-name)"`
 }
 
 type Component struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Type  string `json:"type"`
-	State string `json:"state,omitempty"`
+	ID                int64             `json:"id"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description,omitempty"`
+	TypeID            int64             `json:"typeId"`
+	LayerID           int               `json:"layerId"`
+	DomainID          int               `json:"domainId"`
+	State             string            `json:"state,omitempty"`
+	PropagatedState   string            `json:"propagatedState,omitempty"`
+	LastUpdate        int64             `json:"lastUpdateTimestamp,omitempty"`
+	Identifiers       []string          `json:"identifiers,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	Properties        map[string]string `json:"properties,omitempty"`
+	Relations         []int64           `json:"relations,omitempty"`
+	IncomingRelations []int64           `json:"incomingRelations,omitempty"`
 }
 
-// GetComponents searches for topology components using STQL filters
-func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, params GetComponentsParams) (*mcp.CallToolResult, any, error) {
-	var query string
+// ComponentsResult is the structured content returned by getComponents. Unlike the markdown
+// table it is not capped by limit.
+type ComponentsResult struct {
+	Query string `json:"query"`
+	// At is the time of the snapshot, unset for the current topology
+	At         *time.Time  `json:"at,omitempty"`
+	Total      int         `json:"total"`
+	Components []Component `json:"components"`
+}
 
-	// Build STQL query from parameters using IN/NOT IN operators
-	var queryParts []string
+// simplifyViewComponents converts snapshot components into the tool representation
+func simplifyViewComponents(components []suseobservability.ViewComponent) []Component {
+	simplified := make([]Component, 0, len(components))
+	for _, c := range components {
+		simplified = append(simplified, Component{
+			ID:                c.ID,
+			Name:              c.Name,
+			Description:       c.Description,
+			TypeID:            c.Type,
+			LayerID:           c.Layer,
+			DomainID:          c.Domain,
+			State:             c.State.HealthState,
+			PropagatedState:   c.State.PropagatedHealthState,
+			LastUpdate:        c.LastUpdateTimestamp,
+			Identifiers:       c.Identifiers,
+			Tags:              c.Tags,
+			Properties:        c.Properties,
+			Relations:         c.OutgoingRelations,
+			IncomingRelations: c.IncomingRelations,
+		})
+	}
+	return simplified
+}
 
-	// Helper function to parse comma-separated values and build IN clause
-	buildInClause := func(fieldName, values string) string {
-		if values == "" {
-			return ""
-		}
-		parts := strings.Split(values, ",")
-		quoted := make([]string, 0, len(parts))
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p != "" {
-				quoted = append(quoted, fmt.Sprintf("\"%s\"", p))
-			}
+// GetComponent fetches a single component by ID and renders its full details
+func (t tool) GetComponent(ctx context.Context, request *mcp.CallToolRequest, params GetComponentParams) (*mcp.CallToolResult, any, error) {
+	displayName, err := t.displayName(params.DisplayName)
+	if err != nil {
+		return nil, nil, err
+	}
+	query := fmt.Sprintf("id = %d", params.ID)
+	components, err := t.client.SnapShotTopologyQuery(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query topology (STQL: %s): %w", query, err)
+	}
+
+	text := fmt.Sprintf("No component found with ID %d.", params.ID)
+	if len(components) > 0 {
+		text = formatComponentDetails(simplifyViewComponents(components)[0], displayName)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}, nil, nil
+}
+
+func formatComponentDetails(c Component, displayName DisplayName) string {
+	var sb strings.Builder
+
+	name := displayName.Render(c)
+	sb.WriteString(fmt.Sprintf("Component '%s' (ID: %d):\n\n", name, c.ID))
+	sb.WriteString("| Field | Value |\n")
+	sb.WriteString("|---|---|\n")
+	if name != c.Name {
+		sb.WriteString(fmt.Sprintf("| Name | %s |\n", c.Name))
+	}
+	if c.Description != "" {
+		sb.WriteString(fmt.Sprintf("| Description | %s |\n", c.Description))
+	}
+	sb.WriteString(fmt.Sprintf("| Health State | %s |\n", valueOrDash(c.State)))
+	sb.WriteString(fmt.Sprintf("| Propagated Health State | %s |\n", valueOrDash(c.PropagatedState)))
+	sb.WriteString(fmt.Sprintf("| Type ID | %d |\n", c.TypeID))
+	sb.WriteString(fmt.Sprintf("| Layer ID | %d |\n", c.LayerID))
+	sb.WriteString(fmt.Sprintf("| Domain ID | %d |\n", c.DomainID))
+	if c.LastUpdate > 0 {
+		sb.WriteString(fmt.Sprintf("| Last Update | %s |\n", time.UnixMilli(c.LastUpdate).UTC().Format(time.RFC3339)))
+	}
+
+	writeList(&sb, "Identifiers", c.Identifiers)
+	writeList(&sb, "Tags", c.Tags)
+	writeList(&sb, "Outgoing relations", formatIDs(c.Relations))
+	writeList(&sb, "Incoming relations", formatIDs(c.IncomingRelations))
+
+	if len(c.Properties) > 0 {
+		keys := make([]string, 0, len(c.Properties))
+		for k := range c.Properties {
+			keys = append(keys, k)
 		}
-		if len(quoted) == 0 {
-			return ""
+		sort.Strings(keys)
+
+		sb.WriteString(fmt.Sprintf("\nProperties (%d):\n\n", len(keys)))
+		sb.WriteString("| Key | Value |\n")
+		sb.WriteString("|---|---|\n")
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", k, c.Properties[k]))
 		}
-		return fmt.Sprintf("%s IN (%s)", fieldName, strings.Join(quoted, ", "))
 	}
 
+	return sb.String()
+}
+
+// writeList renders a titled bullet list, or nothing when it is empty
+func writeList(sb *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n%s (%d):\n", title, len(items)))
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+}
+
+// maxRelationsShown is the number of related component IDs listed in a table cell
+const maxRelationsShown = 3
+
+// formatRelations renders the relation count followed by the first few related IDs
+func formatRelations(ids []int64) string {
+	if len(ids) == 0 {
+		return "-"
+	}
+	shown := formatIDs(ids)
+	if len(shown) > maxRelationsShown {
+		shown = append(shown[:maxRelationsShown], ellipsis)
+	}
+	return fmt.Sprintf("%d: %s", len(ids), strings.Join(shown, ", "))
+}
+
+func formatIDs(ids []int64) []string {
+	formatted := make([]string, 0, len(ids))
+	for _, id := range ids {
+		formatted = append(formatted, strconv.FormatInt(id, 10))
+	}
+	return formatted
+}
+
+// maxNeighborsLevels is the deepest withNeighborsOf level STQL accepts besides 'all'
+const maxNeighborsLevels = 14
+
+// validateNeighborsLevels accepts 'all' or an integer between 1 and maxNeighborsLevels
+func validateNeighborsLevels(levels string) error {
+	if levels == "all" {
+		return nil
+	}
+	if n, err := strconv.Atoi(levels); err == nil && n >= 1 && n <= maxNeighborsLevels {
+		return nil
+	}
+	return fmt.Errorf("invalid with_neighbors_levels '%s'. Must be 'all' or an integer between 1 and %d", levels, maxNeighborsLevels)
+}
+
+// GetComponents searches for topology components using STQL filters
+func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, params GetComponentsParams) (*mcp.CallToolResult, *ComponentsResult, error) {
+	var query string
+
+	limit, err := displayLimit(params.Limit, t.limits.ComponentRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	displayName, err := t.displayName(params.DisplayName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Build STQL query from parameters using IN/NOT IN operators
+	var queryParts []string
+
 	// Add names filter
 	if clause := buildInClause("name", params.Names); clause != "" {
 		queryParts = append(queryParts, clause)
@@ -82,9 +251,26 @@ func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, p
 		queryParts = append(queryParts, fmt.Sprintf("namespace = \"%s\"", params.Namespace))
 	}
 
-	// Combine basic filters with AND
-	if len(queryParts) > 0 {
-		query = strings.Join(queryParts, " AND ")
+	// Add id and identifier filters, STQL strings are escaped like PromQL strings
+	if params.ID < 0 {
+		return nil, nil, fmt.Errorf("id must not be negative, got %d", params.ID)
+	}
+	if params.ID > 0 {
+		queryParts = append(queryParts, fmt.Sprintf("id = %d", params.ID))
+	}
+	if identifier := strings.TrimSpace(params.Identifier); identifier != "" {
+		queryParts = append(queryParts, fmt.Sprintf("identifier = \"%s\"", promQLString(identifier)))
+	}
+
+	// Combine basic filters with AND, or with OR in parentheses so a withNeighborsOf
+	// appended below cannot change the precedence
+	filters := strings.Join(queryParts, " AND ")
+	if params.MatchAny {
+		filters = strings.Join(queryParts, " OR ")
+	}
+	query = filters
+	if params.MatchAny && len(queryParts) > 1 {
+		query = "(" + filters + ")"
 	}
 
 	// Add withNeighborsOf if requested
@@ -103,6 +289,10 @@ func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, p
 			direction = "both"
 		}
 
+		if err := validateNeighborsLevels(levels); err != nil {
+			return nil, nil, err
+		}
+
 		// Validate direction
 		validDirections := map[string]bool{"up": true, "down": true, "both": true}
 		if !validDirections[direction] {
@@ -111,21 +301,35 @@ func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, p
 
 		// Build withNeighborsOf function
 		// According to STQL spec, combine the base filters with OR when using withNeighborsOf
-		neighborsQuery := fmt.Sprintf("withNeighborsOf(components = (%s), levels = \"%s\", direction = \"%s\")", query, levels, direction)
+		neighborsQuery := fmt.Sprintf("withNeighborsOf(components = (%s), levels = \"%s\", direction = \"%s\")", filters, levels, direction)
 		query = fmt.Sprintf("%s OR %s", query, neighborsQuery)
 	}
 
 	if query == "" {
-		return nil, nil, fmt.Errorf("at least one filter (names, types, healthstates, domains, namespace) must be provided")
+		return nil, nil, fmt.Errorf("at least one filter (names, types, healthstates, domains, namespace, id, identifier) must be provided")
 	}
 
-	// Execute topology query
-	components, err := t.client.SnapShotTopologyQuery(ctx, query)
+	// Execute topology query, on a past snapshot when asked for
+	var at *time.Time
+	if params.At != "" && params.At != "now" {
+		snapshot, err := parseTime(params.At, t.clock.Now())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse at: %w", err)
+		}
+		snapshot = snapshot.UTC()
+		at = &snapshot
+	}
+	var components []suseobservability.ViewComponent
+	if at != nil {
+		components, err = t.client.SnapShotTopologyQueryAt(ctx, query, *at)
+	} else {
+		components, err = t.client.SnapShotTopologyQuery(ctx, query)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to query topology (STQL: %s): %w", query, err)
 	}
 
-	table := formatComponentsTable(components, params, query)
+	table := formatComponentsTable(components, params, query, at, limit, displayName)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -133,18 +337,42 @@ func (t tool) GetComponents(ctx context.Context, request *mcp.CallToolRequest, p
 				Text: table,
 			},
 		},
-	}, nil, nil
+	}, &ComponentsResult{Query: query, At: at, Total: len(components), Components: simplifyViewComponents(components)}, nil
 }
 
-func formatComponentsTable(components []suseobservability.ViewComponent, params GetComponentsParams, query string) string {
+// buildInClause parses comma-separated values and builds an STQL IN clause
+func buildInClause(fieldName, values string) string {
+	if values == "" {
+		return ""
+	}
+	parts := strings.Split(values, ",")
+	quoted := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			quoted = append(quoted, fmt.Sprintf("\"%s\"", p))
+		}
+	}
+	if len(quoted) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s IN (%s)", fieldName, strings.Join(quoted, ", "))
+}
+
+// formatComponentsTable renders the components of the snapshot at, nil for the current one
+func formatComponentsTable(components []suseobservability.ViewComponent, params GetComponentsParams, query string, at *time.Time, limit int, displayName DisplayName) string {
+	snapshot := ""
+	if at != nil {
+		snapshot = " at " + at.Format(time.RFC3339)
+	}
 	if len(components) == 0 {
-		return fmt.Sprintf("No components found for query: %s", query)
+		return fmt.Sprintf("No components found%s for query: %s", snapshot, query)
 	}
 
 	var sb strings.Builder
 
 	// Summary
-	sb.WriteString(fmt.Sprintf("Found %d component(s)", len(components)))
+	sb.WriteString(fmt.Sprintf("Found %d component(s)%s", len(components), snapshot))
 
 	filters := []string{}
 	if params.Names != "" {
@@ -162,18 +390,31 @@ func formatComponentsTable(components []suseobservability.ViewComponent, params
 	if params.Namespace != "" {
 		filters = append(filters, fmt.Sprintf("namespace: %s", params.Namespace))
 	}
-	if len(filters) > 0 {
+	if params.ID > 0 {
+		filters = append(filters, fmt.Sprintf("id: %d", params.ID))
+	}
+	if params.Identifier != "" {
+		filters = append(filters, fmt.Sprintf("identifier: %s", strings.TrimSpace(params.Identifier)))
+	}
+	switch {
+	case len(filters) > 1 && params.MatchAny:
+		sb.WriteString(" (matching any of " + strings.Join(filters, ", ") + ")")
+	case len(filters) > 0:
 		sb.WriteString(" (" + strings.Join(filters, ", ") + ")")
 	}
-	sb.WriteString(":\n\n")
+	shown := simplifyViewComponents(components)
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+	sb.WriteString(", " + countSummary(len(shown), len(components)) + ":\n\n")
 
 	// Header
-	sb.WriteString("| Component Name | ID | State |\n")
-	sb.WriteString("|---|---|---|\n")
+	sb.WriteString("| Component Name | ID | State | Relations |\n")
+	sb.WriteString("|---|---|---|---|\n")
 
 	// Data rows
-	for _, c := range components {
-		sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", c.Name, c.ID, c.State.HealthState))
+	for _, c := range shown {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", displayName.Render(c), c.ID, c.State, formatRelations(c.Relations)))
 	}
 
 	return sb.String()
@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type TopologyDiffParams struct {
+	Query       string `json:"query" jsonschema:"required,The STQL query selecting the components to compare, e.g. 'type = \"pod\" AND label = \"namespace:shop\"'" examples:"type = \"pod\" AND label = \"namespace:shop\""`
+	From        string `json:"from" jsonschema:"required,Time of the earlier snapshot: 'now', duration back from now (e.g. '1h') or 'now-1h', '/d' aligns it to the start of the day (e.g. 'now-1d/d')" examples:"1h;24h;now-1d/d"`
+	To          string `json:"to,omitempty" jsonschema:"Time of the later snapshot: 'now', duration back from now or 'now-1h' like from, must be after from (default: now)" default:"now" examples:"now;30m;now/d"`
+	Limit       int    `json:"limit,omitempty" jsonschema:"Maximum number of components listed per change (default: 100)"`
+	DisplayName string `json:"display_name,omitempty" jsonschema:"Go template rendering component names, e.g. '{{.Namespace}}/{{.Name}}' (default: the server's -component-display-name)" examples:"{{.Namespace}}/{{.Name}}"`
+}
+
+// ComponentStateChange is a component whose health state differs between the snapshots
+type ComponentStateChange struct {
+	Component Component `json:"component"`
+	Before    string    `json:"before"`
+	After     string    `json:"after"`
+}
+
+// TopologyDiffResult is the structured content returned by topologyDiff. Unlike the markdown
+// tables it is not capped by limit.
+type TopologyDiffResult struct {
+	Query     string                 `json:"query"`
+	From      time.Time              `json:"from"`
+	To        time.Time              `json:"to"`
+	Before    int                    `json:"before"`
+	After     int                    `json:"after"`
+	Added     []Component            `json:"added"`
+	Removed   []Component            `json:"removed"`
+	Changed   []ComponentStateChange `json:"changed"`
+	Unchanged int                    `json:"unchanged"`
+}
+
+// TopologyDiff runs an STQL query against the topology at two points in time and reports
+// the components added, removed and changing health state between them
+func (t tool) TopologyDiff(ctx context.Context, request *mcp.CallToolRequest, params TopologyDiffParams) (*mcp.CallToolResult, *TopologyDiffResult, error) {
+	query := strings.TrimSpace(params.Query)
+	if query == "" {
+		return nil, nil, fmt.Errorf("query is required")
+	}
+	if params.From == "" {
+		return nil, nil, fmt.Errorf("from is required, e.g. '1h' to compare with the topology an hour ago")
+	}
+	// Both times are relative to the same instant
+	now := t.clock.Now()
+	from, err := parseTime(params.From, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse from: %w", err)
+	}
+	to, toParam := now, "now"
+	if params.To != "" {
+		toParam = params.To
+		if to, err = parseTime(params.To, now); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return nil, nil, fmt.Errorf("from '%s' must be before to '%s'. Durations look back from now, so from needs the longer one, e.g. from '2h' and to '1h'", params.From, toParam)
+	}
+	limit, err := displayLimit(params.Limit, t.limits.ComponentRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	displayName, err := t.displayName(params.DisplayName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before, err := t.client.SnapShotTopologyQueryAt(ctx, query, from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query the topology at %s (STQL: %s): %w", from.UTC().Format(time.RFC3339), query, err)
+	}
+	after, err := t.client.SnapShotTopologyQueryAt(ctx, query, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query the topology at %s (STQL: %s): %w", to.UTC().Format(time.RFC3339), query, err)
+	}
+
+	diff := diffComponents(simplifyViewComponents(before), simplifyViewComponents(after))
+	diff.Query, diff.From, diff.To = query, from.UTC(), to.UTC()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatTopologyDiff(diff, limit, displayName),
+			},
+		},
+	}, diff, nil
+}
+
+// diffComponents matches the components of two snapshots by ID. Every list is ordered by
+// component ID, so repeated calls render the same tables.
+func diffComponents(before, after []Component) *TopologyDiffResult {
+	diff := &TopologyDiffResult{
+		Before:  len(before),
+		After:   len(after),
+		Added:   []Component{},
+		Removed: []Component{},
+		Changed: []ComponentStateChange{},
+	}
+	earlier := make(map[int64]Component, len(before))
+	for _, c := range before {
+		earlier[c.ID] = c
+	}
+	later := make(map[int64]bool, len(after))
+	for _, c := range after {
+		later[c.ID] = true
+		prev, ok := earlier[c.ID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, c)
+		case prev.State != c.State:
+			diff.Changed = append(diff.Changed, ComponentStateChange{Component: c, Before: prev.State, After: c.State})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for _, c := range before {
+		if !later[c.ID] {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Component.ID < diff.Changed[j].Component.ID })
+	return diff
+}
+
+// formatTopologyDiff renders a section per kind of change, each listing limit components at most
+func formatTopologyDiff(diff *TopologyDiffResult, limit int, displayName DisplayName) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Topology of `%s` at %s (%d components) compared with %s (%d components): %d added, %d removed, %d changed health state, %d unchanged.\n",
+		diff.Query, diff.From.Format(time.RFC3339), diff.Before, diff.To.Format(time.RFC3339), diff.After,
+		len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Unchanged))
+
+	writeComponents := func(title string, components []Component) {
+		if len(components) == 0 {
+			return
+		}
+		shown := components[:min(limit, len(components))]
+		sb.WriteString(fmt.Sprintf("\n%s, %s:\n\n", title, countSummary(len(shown), len(components))))
+		sb.WriteString("| Component Name | ID | State |\n")
+		sb.WriteString("|---|---|---|\n")
+		for _, c := range shown {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", displayName.Render(c), c.ID, valueOrDash(c.State)))
+		}
+	}
+	writeComponents("Added", diff.Added)
+	writeComponents("Removed", diff.Removed)
+
+	if len(diff.Changed) > 0 {
+		shown := diff.Changed[:min(limit, len(diff.Changed))]
+		sb.WriteString(fmt.Sprintf("\nChanged health state, %s:\n\n", countSummary(len(shown), len(diff.Changed))))
+		sb.WriteString("| Component Name | ID | Before | After |\n")
+		sb.WriteString("|---|---|---|---|\n")
+		for _, c := range shown {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", displayName.Render(c.Component), c.Component.ID, valueOrDash(c.Before), valueOrDash(c.After)))
+		}
+	}
+	return sb.String()
+}
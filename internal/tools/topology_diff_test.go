@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffComponents(t *testing.T) {
+	before := simplifyViewComponents([]suseobservability.ViewComponent{
+		viewComponent(3, "cart", "CLEAR"),
+		viewComponent(1, "checkout", "CLEAR"),
+		viewComponent(2, "old", "CLEAR"),
+		viewComponent(4, "db", "DEVIATING"),
+	})
+	after := simplifyViewComponents([]suseobservability.ViewComponent{
+		viewComponent(5, "new", "CLEAR"),
+		viewComponent(1, "checkout", "CRITICAL"),
+		viewComponent(3, "cart", "CLEAR"),
+		viewComponent(4, "db", "CLEAR"),
+	})
+
+	diff := diffComponents(before, after)
+
+	assert.Equal(t, 4, diff.Before)
+	assert.Equal(t, 4, diff.After)
+	assert.Equal(t, []Component{{ID: 5, Name: "new", State: "CLEAR"}}, diff.Added)
+	assert.Equal(t, []Component{{ID: 2, Name: "old", State: "CLEAR"}}, diff.Removed)
+	assert.Equal(t, []ComponentStateChange{
+		{Component: Component{ID: 1, Name: "checkout", State: "CRITICAL"}, Before: "CLEAR", After: "CRITICAL"},
+		{Component: Component{ID: 4, Name: "db", State: "CLEAR"}, Before: "DEVIATING", After: "CLEAR"},
+	}, diff.Changed)
+	assert.Equal(t, 1, diff.Unchanged)
+}
+
+func TestTopologyDiff(t *testing.T) {
+	ctx := context.Background()
+	query := `type = "pod"`
+	clock := newFakeClock()
+	now := clock.Now()
+
+	t.Run("snapshots at both times", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		tools.clock = clock
+		mockClient.On("SnapShotTopologyQueryAt", ctx, query, now.Add(-time.Hour)).
+			Return([]suseobservability.ViewComponent{viewComponent(1, "checkout", "CLEAR"), viewComponent(2, "old", "CLEAR")}, nil).Once()
+		mockClient.On("SnapShotTopologyQueryAt", ctx, query, now).
+			Return([]suseobservability.ViewComponent{viewComponent(1, "checkout", "CRITICAL"), viewComponent(3, "new", "CLEAR")}, nil).Once()
+
+		result, structured, err := tools.TopologyDiff(ctx, nil, TopologyDiffParams{Query: query, From: "1h"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Topology of `type = \"pod\"` at 2025-01-01T11:00:00Z (2 components) compared with 2025-01-01T12:00:00Z (2 components): "+
+			"1 added, 1 removed, 1 changed health state, 0 unchanged.\n"+
+			"\nAdded, showing 1 of 1 fetched:\n\n| Component Name | ID | State |\n|---|---|---|\n| new | 3 | CLEAR |\n"+
+			"\nRemoved, showing 1 of 1 fetched:\n\n| Component Name | ID | State |\n|---|---|---|\n| old | 2 | CLEAR |\n"+
+			"\nChanged health state, showing 1 of 1 fetched:\n\n| Component Name | ID | Before | After |\n|---|---|---|---|\n| checkout | 1 | CLEAR | CRITICAL |\n",
+			result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, query, structured.Query)
+		assert.Equal(t, now.Add(-time.Hour), structured.From)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("failing snapshot", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		tools.clock = clock
+		mockClient.On("SnapShotTopologyQueryAt", ctx, query, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+		_, _, err := tools.TopologyDiff(ctx, nil, TopologyDiffParams{Query: query, From: "1h"})
+
+		assert.EqualError(t, err, "failed to query the topology at 2025-01-01T11:00:00Z (STQL: type = \"pod\"): boom")
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+		tests := []struct {
+			name   string
+			params TopologyDiffParams
+			err    string
+		}{
+			{name: "missing query", params: TopologyDiffParams{From: "1h"}, err: "query is required"},
+			{name: "missing from", params: TopologyDiffParams{Query: query}, err: "from is required, e.g. '1h' to compare with the topology an hour ago"},
+			{name: "from after to", params: TopologyDiffParams{Query: query, From: "1h", To: "2h"}, err: "from '1h' must be before to '2h'. Durations look back from now, so from needs the longer one, e.g. from '2h' and to '1h'"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := tools.TopologyDiff(ctx, nil, tt.params)
+
+				assert.EqualError(t, err, tt.err)
+			})
+		}
+	})
+}
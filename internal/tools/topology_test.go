@@ -2,14 +2,18 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"suse-observability-mcp/client/suseobservability"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetComponents(t *testing.T) {
@@ -63,6 +67,230 @@ func TestGetComponents(t *testing.T) {
 		assert.NotNil(t, result)
 	})
 
+	t.Run("id and identifier filters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params GetComponentsParams
+			query  string
+		}{
+			{
+				name:   "id only",
+				params: GetComponentsParams{ID: 42},
+				query:  "id = 42",
+			},
+			{
+				name:   "identifier only",
+				params: GetComponentsParams{Identifier: " urn:kubernetes:/prod:shop:pod/checkout-1 "},
+				query:  `identifier = "urn:kubernetes:/prod:shop:pod/checkout-1"`,
+			},
+			{
+				name:   "identifier with quotes is escaped",
+				params: GetComponentsParams{Identifier: `urn:x:"odd"`},
+				query:  `identifier = "urn:x:\"odd\""`,
+			},
+			{
+				name:   "combined with the other filters",
+				params: GetComponentsParams{Types: "pod", Namespace: "shop", ID: 42, Identifier: "urn:kubernetes:/prod:shop:pod/checkout-1"},
+				query:  `type IN ("pod") AND namespace = "shop" AND id = 42 AND identifier = "urn:kubernetes:/prod:shop:pod/checkout-1"`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient := new(MockSuseObservabilityClient)
+				tools := NewBaseTool(mockClient)
+				mockClient.On("SnapShotTopologyQuery", ctx, tt.query).
+					Return([]suseobservability.ViewComponent{{ID: 42, Name: "checkout-1"}}, nil).Once()
+
+				result, _, err := tools.GetComponents(ctx, nil, tt.params)
+
+				require.NoError(t, err)
+				assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "| checkout-1 | 42 |")
+				mockClient.AssertExpectations(t)
+			})
+		}
+
+		t.Run("negative id", func(t *testing.T) {
+			_, _, err := tools.GetComponents(ctx, nil, GetComponentsParams{ID: -1})
+
+			assert.EqualError(t, err, "id must not be negative, got -1")
+		})
+	})
+
+	t.Run("match_any", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params GetComponentsParams
+			query  string
+		}{
+			{
+				name:   "health states match any state and stay ANDed with the other filters",
+				params: GetComponentsParams{Namespace: "shop", HealthStates: "CRITICAL,DEVIATING"},
+				query:  `healthstate IN ("CRITICAL", "DEVIATING") AND namespace = "shop"`,
+			},
+			{
+				name:   "filters are grouped with OR",
+				params: GetComponentsParams{Types: "pod", HealthStates: "CRITICAL", MatchAny: true},
+				query:  `(type IN ("pod") OR healthstate IN ("CRITICAL"))`,
+			},
+			{
+				name:   "a single filter needs no group",
+				params: GetComponentsParams{HealthStates: "CRITICAL,DEVIATING", MatchAny: true},
+				query:  `healthstate IN ("CRITICAL", "DEVIATING")`,
+			},
+			{
+				name:   "the group keeps its precedence with neighbors",
+				params: GetComponentsParams{Names: "db", HealthStates: "CRITICAL", MatchAny: true, WithNeighbors: true},
+				query:  `(name IN ("db") OR healthstate IN ("CRITICAL")) OR withNeighborsOf(components = (name IN ("db") OR healthstate IN ("CRITICAL")), levels = "1", direction = "both")`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient := new(MockSuseObservabilityClient)
+				tools := NewBaseTool(mockClient)
+				mockClient.On("SnapShotTopologyQuery", ctx, tt.query).
+					Return([]suseobservability.ViewComponent{{ID: 1, Name: "db"}}, nil).Once()
+
+				result, _, err := tools.GetComponents(ctx, nil, tt.params)
+
+				require.NoError(t, err)
+				if tt.params.MatchAny && tt.params.Types != "" {
+					assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Found 1 component(s) (matching any of types: pod, healthstates: CRITICAL)")
+				}
+				mockClient.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("configured and per-call display names", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		displayName, err := ParseDisplayName("{{.Cluster}}:{{.Namespace}}:{{.Name}}")
+		require.NoError(t, err)
+		tools := NewBaseTool(mockClient).WithComponentDisplayName(displayName)
+		components := []suseobservability.ViewComponent{
+			{ID: 1, Name: "checkout", Tags: []string{"namespace:shop", "cluster-name:prod"}},
+			{ID: 2, Name: "node-1"},
+		}
+		mockClient.On("SnapShotTopologyQuery", ctx, "type IN (\"service\")").Return(components, nil).Twice()
+
+		result, _, err := tools.GetComponents(ctx, nil, GetComponentsParams{Types: "service"})
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| prod:shop:checkout | 1 |")
+		assert.Contains(t, output, "| ::node-1 | 2 |")
+
+		result, _, err = tools.GetComponents(ctx, nil, GetComponentsParams{Types: "service", DisplayName: "{{with .Namespace}}{{.}}/{{end}}{{.Name}}"})
+		require.NoError(t, err)
+		output = result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| shop/checkout | 1 |")
+		assert.Contains(t, output, "| node-1 | 2 |")
+	})
+
+	t.Run("limit separates shown from fetched", func(t *testing.T) {
+		params := GetComponentsParams{Types: "pod", Limit: 2}
+
+		mockClient.On("SnapShotTopologyQuery", ctx, "type IN (\"pod\")").
+			Return([]suseobservability.ViewComponent{
+				{ID: 1, Name: "pod-a"},
+				{ID: 2, Name: "pod-b"},
+				{ID: 3, Name: "pod-c"},
+			}, nil).Once()
+
+		result, _, err := tools.GetComponents(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Found 3 component(s) (types: pod), showing 2 of 3 fetched:")
+		assert.Contains(t, output, "pod-b")
+		assert.NotContains(t, output, "pod-c")
+	})
+
+	t.Run("relations column", func(t *testing.T) {
+		params := GetComponentsParams{Names: "gateway"}
+
+		mockClient.On("SnapShotTopologyQuery", ctx, "name IN (\"gateway\")").
+			Return([]suseobservability.ViewComponent{
+				{ID: 1, Name: "gateway", OutgoingRelations: []int64{11, 12, 13, 14, 15}},
+				{ID: 2, Name: "gateway", OutgoingRelations: []int64{21}},
+				{ID: 3, Name: "gateway"},
+			}, nil).Once()
+
+		result, _, err := tools.GetComponents(ctx, nil, params)
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| Component Name | ID | State | Relations |")
+		assert.Contains(t, output, "| gateway | 1 |  | 5: 11, 12, 13, … |")
+		assert.Contains(t, output, "| gateway | 2 |  | 1: 21 |")
+		assert.Contains(t, output, "| gateway | 3 |  | - |")
+	})
+
+	t.Run("with_neighbors_levels validation", func(t *testing.T) {
+		tests := []struct {
+			levels string
+			valid  bool
+		}{
+			{levels: "0", valid: false},
+			{levels: "1", valid: true},
+			{levels: "14", valid: true},
+			{levels: "15", valid: false},
+			{levels: "all", valid: true},
+			{levels: "abc", valid: false},
+		}
+
+		for _, tt := range tests {
+			params := GetComponentsParams{Names: "db-master", WithNeighbors: true, WithNeighborsLevels: tt.levels}
+			if tt.valid {
+				expectedQuery := fmt.Sprintf("name IN (\"db-master\") OR withNeighborsOf(components = (name IN (\"db-master\")), levels = \"%s\", direction = \"both\")", tt.levels)
+				mockClient.On("SnapShotTopologyQuery", ctx, expectedQuery).
+					Return([]suseobservability.ViewComponent{{ID: 1, Name: "db-master"}}, nil).Once()
+			}
+
+			result, _, err := tools.GetComponents(ctx, nil, params)
+
+			if tt.valid {
+				assert.NoError(t, err, tt.levels)
+				assert.NotNil(t, result, tt.levels)
+			} else {
+				assert.EqualError(t, err, fmt.Sprintf("invalid with_neighbors_levels '%s'. Must be 'all' or an integer between 1 and 14", tt.levels))
+				assert.Nil(t, result, tt.levels)
+			}
+		}
+	})
+
+	t.Run("past snapshot", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		tools.clock = newFakeClock()
+		at := tools.clock.Now().Add(-2 * time.Hour)
+		mockClient.On("SnapShotTopologyQueryAt", ctx, `type IN ("pod")`, at).
+			Return([]suseobservability.ViewComponent{{ID: 1, Name: "checkout-1"}}, nil).Once()
+
+		result, structured, err := tools.GetComponents(ctx, nil, GetComponentsParams{Types: "pod", At: "2h"})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Found 1 component(s) at 2025-01-01T10:00:00Z (types: pod)")
+		require.NotNil(t, structured.At)
+		assert.Equal(t, at, *structured.At)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("now queries the current snapshot", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("SnapShotTopologyQuery", ctx, `type IN ("pod")`).Return([]suseobservability.ViewComponent{}, nil).Once()
+
+		_, structured, err := NewBaseTool(mockClient).GetComponents(ctx, nil, GetComponentsParams{Types: "pod", At: "now"})
+
+		require.NoError(t, err)
+		assert.Nil(t, structured.At)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid at", func(t *testing.T) {
+		_, _, err := tools.GetComponents(ctx, nil, GetComponentsParams{Types: "pod", At: "yesterday"})
+
+		assert.EqualError(t, err, "failed to parse at: invalid time format: yesterday (expected 'now', a duration like '1h' or 'now-1h', optionally followed by '/d' for the start of that day)")
+	})
+
 	t.Run("error missing filters", func(t *testing.T) {
 		params := GetComponentsParams{}
 
@@ -86,3 +314,117 @@ func TestGetComponents(t *testing.T) {
 		assert.Contains(t, err.Error(), "client error")
 	})
 }
+
+func TestGetComponentsStructuredContent(t *testing.T) {
+	ctx := context.Background()
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	tools.limits.ComponentRows = 2
+	mockClient.On("SnapShotTopologyQuery", mock.Anything, `type IN ("pod")`).Return(goldenComponents(), nil)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "getComponents"}, tools.GetComponents)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "getComponents", Arguments: map[string]any{"types": "pod"}})
+
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	b, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+	var structured ComponentsResult
+	require.NoError(t, json.Unmarshal(b, &structured))
+	assert.Equal(t, `type IN ("pod")`, structured.Query)
+	assert.Equal(t, 4, structured.Total)
+	assert.Equal(t, simplifyViewComponents(goldenComponents()), structured.Components, "the structured content is not capped by limit")
+	// the rendered rows are the first components of the structured content
+	text := result.Content[0].(*mcp.TextContent).Text
+	for i, c := range structured.Components {
+		row := fmt.Sprintf("| %d |", c.ID)
+		if i < 2 {
+			assert.Contains(t, text, row)
+		} else {
+			assert.NotContains(t, text, row)
+		}
+	}
+}
+
+func TestGetComponent(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	tools := NewBaseTool(mockClient)
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		component := suseobservability.ViewComponent{
+			ID:                42,
+			Name:              "checkout",
+			Identifiers:       []string{"urn:a", "urn:b", "urn:c"},
+			Tags:              []string{"namespace:shop", "cluster-name:prod", "app:checkout"},
+			OutgoingRelations: []int64{7, 8},
+			Properties:        map[string]string{"namespace": "shop"},
+		}
+		component.State.HealthState = "CRITICAL"
+		component.State.PropagatedHealthState = "DEVIATING"
+
+		mockClient.On("SnapShotTopologyQuery", ctx, "id = 42").
+			Return([]suseobservability.ViewComponent{component}, nil).Once()
+
+		result, _, err := tools.GetComponent(ctx, nil, GetComponentParams{ID: 42})
+
+		assert.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Component 'checkout' (ID: 42)")
+		assert.Contains(t, output, "| Health State | CRITICAL |")
+		assert.Contains(t, output, "| Propagated Health State | DEVIATING |")
+		assert.Contains(t, output, "- urn:c")
+		assert.Contains(t, output, "- app:checkout")
+		assert.Contains(t, output, "Outgoing relations (2)")
+		assert.Contains(t, output, "| namespace | shop |")
+	})
+
+	t.Run("display name", func(t *testing.T) {
+		component := suseobservability.ViewComponent{ID: 45, Name: "checkout", Tags: []string{"namespace:shop"}}
+		mockClient.On("SnapShotTopologyQuery", ctx, "id = 45").
+			Return([]suseobservability.ViewComponent{component}, nil).Once()
+
+		result, _, err := tools.GetComponent(ctx, nil, GetComponentParams{ID: 45, DisplayName: "{{.Namespace}}/{{.Name}}"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Component 'shop/checkout' (ID: 45)")
+		assert.Contains(t, output, "| Name | checkout |")
+	})
+
+	t.Run("invalid display name", func(t *testing.T) {
+		_, _, err := tools.GetComponent(ctx, nil, GetComponentParams{ID: 46, DisplayName: "{{.Label}}"})
+
+		assert.ErrorContains(t, err, "can't evaluate field Label")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockClient.On("SnapShotTopologyQuery", ctx, "id = 43").
+			Return([]suseobservability.ViewComponent{}, nil).Once()
+
+		result, _, err := tools.GetComponent(ctx, nil, GetComponentParams{ID: 43})
+
+		assert.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "No component found with ID 43")
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		mockClient.On("SnapShotTopologyQuery", ctx, "id = 44").
+			Return(nil, errors.New("client error")).Once()
+
+		result, _, err := tools.GetComponent(ctx, nil, GetComponentParams{ID: 44})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "client error")
+	})
+}
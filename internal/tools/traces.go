@@ -0,0 +1,470 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ListTracesParams struct {
+	ServiceName string `json:"service_name,omitempty" jsonschema:"The name of the service to list traces for" examples:"checkout"`
+	// ComponentID and ComponentName name the service by its otel service component, resolved
+	// with a topology query before the traces are queried
+	ComponentID   int64  `json:"component_id,omitempty" jsonschema:"The ID of the otel service component to list traces for"`
+	ComponentName string `json:"component_name,omitempty" jsonschema:"The name of the otel service component to list traces for, must match a single component"`
+	Page          int    `json:"page,omitempty" jsonschema:"Page of traces to list, starting at 0 (default: 0)"`
+	PageSize      int    `json:"page_size,omitempty" jsonschema:"Number of traces per page (default: 20, max: 1000), the spans of the first 20 traces are looked up" default:"20"`
+	Format        string `json:"format,omitempty" jsonschema:"Output format: 'markdown' (default) or 'json' for the raw query response" enum:"markdown,json" default:"markdown"`
+	// MinDurationMs is passed to the trace query as span duration filter, so paging and the
+	// number of matches only count the slow traces
+	MinDurationMs int64 `json:"min_duration_ms,omitempty" jsonschema:"Only list traces with a span of the service lasting at least this many milliseconds"`
+	// ErrorsOnly is passed to the trace query as span status filter, it applies to the same
+	// spans as the service and duration filters
+	ErrorsOnly bool `json:"errors_only,omitempty" jsonschema:"Only list traces with a span of the service that has error status"`
+	// AroundHealthChange replaces the last hour by the window around the most recent health
+	// state change of the component given by component_id
+	AroundHealthChange bool `json:"around_health_change,omitempty" jsonschema:"With component_id, list the traces from 15 minutes before to 15 minutes after the most recent health state change of the component instead of the last hour"`
+}
+
+const (
+	defaultTracePageSize = 20
+	maxTracePageSize     = 1000
+)
+
+// maxTraceLookups is the number of traces of a page listTraces looks up the spans of, as the
+// query response only holds trace IDs. The other traces only show their ID.
+const maxTraceLookups = 20
+
+// listTracesWindow is the time range listTraces looks for traces in
+const listTracesWindow = time.Hour
+
+const (
+	// healthChangeMargin is the time listTraces looks for traces before and after a health change
+	healthChangeMargin = 15 * time.Minute
+	// healthChangeLookback is how far back listTraces looks for the health change of a component
+	healthChangeLookback = 7 * 24 * time.Hour
+)
+
+// healthChangedEventType is the type of the events recording a health state transition
+const healthChangedEventType = "HealthStateChangedEvent"
+
+// healthChangeEvents is the number of health change events lastHealthChange takes the most
+// recent of, the events API does not document their order
+const healthChangeEvents = 100
+
+// traceWorkers is the number of trace lookups listTraces runs concurrently
+const traceWorkers = 8
+
+// TraceListResult is the structured content returned by listTraces
+type TraceListResult struct {
+	Service string    `json:"service"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	// HealthChange is the time of the health change the window is centered on, when
+	// around_health_change found one
+	HealthChange *time.Time `json:"health_change,omitempty"`
+	Page         int        `json:"page"`
+	PageSize     int        `json:"page_size"`
+	MatchesTotal int        `json:"matches_total,omitempty"`
+	TotalPages   int        `json:"total_pages,omitempty"`
+	// HasMore tells whether pages after this one hold traces. It is estimated from a full
+	// page when the server reports neither hasMore, totalPages nor matchesTotal.
+	HasMore          bool     `json:"has_more"`
+	HasMoreEstimated bool     `json:"has_more_estimated,omitempty"`
+	NextPage         int      `json:"next_page,omitempty"` // 0 on the last page
+	TraceIDs         []string `json:"trace_ids"`
+}
+
+// traceSummary is a row of the listTraces table
+type traceSummary struct {
+	TraceID  string
+	RootSpan string
+	Service  string
+	Start    time.Time
+	Duration time.Duration
+	Spans    int
+}
+
+// ListTraces lists the traces with spans of a service, newest first, one page at a time
+func (t tool) ListTraces(ctx context.Context, request *mcp.CallToolRequest, params ListTracesParams) (*mcp.CallToolResult, *TraceListResult, error) {
+	if params.Page < 0 {
+		return nil, nil, fmt.Errorf("page must not be negative, got %d", params.Page)
+	}
+	pageSize, err := displayLimit(params.PageSize, defaultTracePageSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("page_size must not be negative, got %d", params.PageSize)
+	}
+	if pageSize > maxTracePageSize {
+		return nil, nil, fmt.Errorf("page_size must be at most %d, got %d", maxTracePageSize, pageSize)
+	}
+	format := params.Format
+	if format == "" {
+		format = formatMarkdown
+	}
+	if format != formatMarkdown && format != formatJSON {
+		return nil, nil, fmt.Errorf("invalid format '%s', must be 'markdown' or 'json'", params.Format)
+	}
+	if params.MinDurationMs < 0 {
+		return nil, nil, fmt.Errorf("min_duration_ms must not be negative, got %d", params.MinDurationMs)
+	}
+	if params.AroundHealthChange && params.ComponentID == 0 {
+		return nil, nil, fmt.Errorf("around_health_change requires component_id, the health changes are looked up for that component")
+	}
+	minDuration := time.Duration(params.MinDurationMs) * time.Millisecond
+	var statusCodes []suseobservability.StatusCode
+	spans := "spans"
+	if params.ErrorsOnly {
+		statusCodes = []suseobservability.StatusCode{suseobservability.StatusError}
+		spans = "error spans"
+	}
+	service, err := t.traceService(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	subject := fmt.Sprintf("service '%s'", service)
+	switch {
+	case minDuration > 0:
+		subject += fmt.Sprintf(" with %s of at least %s", spans, minDuration)
+	case params.ErrorsOnly:
+		subject += " with error spans"
+	}
+
+	end := t.clock.Now()
+	start := end.Add(-listTracesWindow)
+	window := fmt.Sprintf("in the last %s", formatStep(listTracesWindow))
+	var (
+		note         string
+		partial      bool
+		healthChange *time.Time
+	)
+	if params.AroundHealthChange {
+		change, err := t.lastHealthChange(ctx, params.ComponentID, end)
+		switch {
+		case err != nil:
+			note = fmt.Sprintf("Could not look up the health changes of component ID %d (%v), listing the traces of the last %s instead.",
+				params.ComponentID, err, formatStep(listTracesWindow))
+			partial = true
+		case change == nil:
+			note = fmt.Sprintf("No health state change of component ID %d in the last %s, listing the traces of the last %s instead.",
+				params.ComponentID, formatLookback(healthChangeLookback), formatStep(listTracesWindow))
+		default:
+			at := time.UnixMilli(change.EventTime).UTC()
+			healthChange = &at
+			start, end = healthChangeWindow(at, end)
+			window = fmt.Sprintf("from %s to %s", start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+			to := ""
+			if state := newHealthState(change); state != "" {
+				to = " to " + state
+			}
+			note = fmt.Sprintf("Window: %s, %s around the health change of component ID %d%s at %s.",
+				window, formatStep(healthChangeMargin), params.ComponentID, to, at.Format(time.RFC3339))
+		}
+	}
+	respond := func(text string) *mcp.CallToolResult {
+		result := &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: text,
+				},
+			},
+		}
+		if partial {
+			markPartial(result)
+		}
+		return result
+	}
+
+	res, err := t.client.QueryTraces(ctx, &suseobservability.TraceQueryRequest{
+		TraceQuery: suseobservability.TraceQuery{
+			SpanFilter: suseobservability.SpanFilter{
+				ServiceName:       []string{service},
+				DurationFromNanos: minDuration.Nanoseconds(),
+				StatusCode:        statusCodes,
+			},
+			SortBy: []suseobservability.SortBy{
+				{Field: suseobservability.SpanSortStartTime, Direction: suseobservability.SortDirectionDescending},
+			},
+		},
+		Start:    start,
+		End:      end,
+		Page:     params.Page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query traces: %w", err)
+	}
+
+	more, estimated := moreTraces(res, params.Page, pageSize)
+	structured := &TraceListResult{
+		Service:          service,
+		Start:            start,
+		End:              end,
+		HealthChange:     healthChange,
+		Page:             params.Page,
+		PageSize:         pageSize,
+		MatchesTotal:     res.MatchesTotal,
+		HasMore:          more,
+		HasMoreEstimated: estimated,
+		TraceIDs:         make([]string, 0, len(res.Traces)),
+	}
+	if res.TotalPages != nil {
+		structured.TotalPages = *res.TotalPages
+	}
+	if more {
+		structured.NextPage = params.Page + 1
+	}
+	for _, ref := range res.Traces {
+		structured.TraceIDs = append(structured.TraceIDs, ref.TraceID)
+	}
+
+	if format == formatJSON {
+		b, err := json.Marshal(res)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode traces: %w", err)
+		}
+		return respond(string(b)), structured, nil
+	}
+
+	var sb strings.Builder
+	if note != "" {
+		sb.WriteString(note + "\n\n")
+	}
+	if len(res.Traces) == 0 {
+		text := fmt.Sprintf("No traces found for %s %s.", subject, window)
+		if params.Page > 0 {
+			text = fmt.Sprintf("No traces found for %s on page %d, %d traces matched in total.", subject, params.Page, res.MatchesTotal)
+		}
+		sb.WriteString(text)
+		return respond(sb.String()), structured, nil
+	}
+
+	summary := countSummary(len(res.Traces), len(res.Traces))
+	if res.MatchesTotal > 0 {
+		summary = totalSummary(len(res.Traces), len(res.Traces), res.MatchesTotal)
+	}
+	sb.WriteString(fmt.Sprintf("Traces of %s %s, page %d, %s:\n\n", subject, window, params.Page, summary))
+	lookups := min(len(res.Traces), maxTraceLookups)
+	traces, err := t.fetchTraces(ctx, res.Traces[:lookups])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get traces: %w", err)
+	}
+	for _, ref := range res.Traces[lookups:] {
+		traces = append(traces, traceSummary{TraceID: ref.TraceID})
+	}
+	sb.WriteString(formatTraceSummaries(traces))
+	if lookups < len(res.Traces) {
+		sb.WriteString(fmt.Sprintf("\nThe spans of the first %d traces were looked up, the other %d only show their ID. Pass a page_size of at most %d to see the details of every trace.\n",
+			lookups, len(res.Traces)-lookups, maxTraceLookups))
+		partial = true
+	}
+	switch {
+	case more && estimated:
+		sb.WriteString(fmt.Sprintf("\nMore traces likely exist, pass page %d to see them.\n", structured.NextPage))
+	case more:
+		sb.WriteString(fmt.Sprintf("\nMore traces exist, pass page %d to see them.\n", structured.NextPage))
+	}
+
+	return respond(sb.String()), structured, nil
+}
+
+// lastHealthChange returns the most recent health state change of the component within
+// healthChangeLookback before now, nil when it did not change. The most recent of a page of
+// changes is taken, rather than relying on the order the events are returned in.
+func (t tool) lastHealthChange(ctx context.Context, componentID int64, now time.Time) (*suseobservability.TopologyEvent, error) {
+	res, err := t.client.GetEvents(ctx, &suseobservability.EventListRequest{
+		StartTimestampMs: now.Add(-healthChangeLookback).UnixMilli(),
+		EndTimestampMs:   now.UnixMilli(),
+		TopologyQuery:    fmt.Sprintf("id = %d", componentID),
+		Limit:            healthChangeEvents,
+		EventTypes:       []string{healthChangedEventType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var last *suseobservability.TopologyEvent
+	for i, e := range res.Items {
+		if last == nil || e.EventTime > last.EventTime {
+			last = &res.Items[i]
+		}
+	}
+	return last, nil
+}
+
+// healthChangeWindow returns the trace window of healthChangeMargin around a health change,
+// ending at now when the change is more recent than the margin
+func healthChangeWindow(change, now time.Time) (time.Time, time.Time) {
+	start, end := change.Add(-healthChangeMargin), change.Add(healthChangeMargin)
+	if end.After(now) {
+		end = now
+	}
+	return start, end
+}
+
+// newHealthState returns the state a health change event moved the component to, empty
+// when the event data does not name it
+func newHealthState(e *suseobservability.TopologyEvent) string {
+	for _, key := range []string{"newHealthState", "newState"} {
+		if state, ok := e.Data[key].(string); ok && state != "" {
+			return state
+		}
+	}
+	return ""
+}
+
+// otelServiceType is the component type of the services traces are reported for
+const otelServiceType = "otel service"
+
+// traceService returns the service name listTraces filters spans on, taken from service_name
+// or from the name of the otel service component given by component_id or component_name
+func (t tool) traceService(ctx context.Context, params ListTracesParams) (string, error) {
+	service := strings.TrimSpace(params.ServiceName)
+	name := strings.TrimSpace(params.ComponentName)
+	given := 0
+	for _, set := range []bool{service != "", params.ComponentID != 0, name != ""} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return "", fmt.Errorf("exactly one of service_name, component_id or component_name must be provided")
+	}
+	if service != "" {
+		return service, nil
+	}
+
+	var query, component string
+	if params.ComponentID != 0 {
+		query = fmt.Sprintf("type = %q AND id = %d", otelServiceType, params.ComponentID)
+		component = fmt.Sprintf("ID %d", params.ComponentID)
+	} else {
+		// STQL strings are escaped like PromQL strings
+		query = fmt.Sprintf("type = %q AND name = \"%s\"", otelServiceType, promQLString(name))
+		component = fmt.Sprintf("'%s'", name)
+	}
+	components, err := t.client.SnapShotTopologyQuery(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to query topology (STQL: %s): %w", query, err)
+	}
+	switch len(components) {
+	case 0:
+		return "", fmt.Errorf("no otel service component found with %s, find the service with getComponents (types: '%s')", component, otelServiceType)
+	case 1:
+		return components[0].Name, nil
+	}
+	ids := make([]string, 0, len(components))
+	for _, c := range components {
+		ids = append(ids, fmt.Sprintf("%d", c.ID))
+	}
+	return "", fmt.Errorf("component name '%s' matches %d otel service components (IDs %s), pass component_id instead", name, len(components), strings.Join(ids, ", "))
+}
+
+// fetchTraces looks up the spans of every trace, running at most traceWorkers lookups at a
+// time. The summaries are returned in the order of refs; a failed lookup leaves only the
+// trace ID of its summary set.
+func (t tool) fetchTraces(ctx context.Context, refs []suseobservability.TraceRef) ([]traceSummary, error) {
+	summaries := make([]traceSummary, len(refs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(traceWorkers, len(refs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				summaries[i] = traceSummary{TraceID: refs[i].TraceID}
+				trace, err := t.client.GetTrace(ctx, refs[i].TraceID)
+				if err != nil {
+					slog.Warn("Trace lookup failed", "trace", refs[i].TraceID, "error", err)
+					continue
+				}
+				summaries[i] = summarizeTrace(refs[i].TraceID, trace.Spans)
+			}
+		}()
+	}
+
+feed:
+	for i := range refs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// summarizeTrace describes a trace by its root span, the span without a parent. When the
+// root span is missing, e.g. not yet received, the earliest span is used instead.
+func summarizeTrace(traceID string, spans []suseobservability.Span) traceSummary {
+	summary := traceSummary{TraceID: traceID, Spans: len(spans)}
+	if len(spans) == 0 {
+		return summary
+	}
+	root := -1
+	for i, s := range spans {
+		if s.ParentSpanID == "" {
+			root = i
+			break
+		}
+	}
+	if root < 0 {
+		root = 0
+		for i, s := range spans {
+			if s.StartTime.Timestamp < spans[root].StartTime.Timestamp {
+				root = i
+			}
+		}
+	}
+	summary.RootSpan = spans[root].SpanName
+	summary.Service = spans[root].ServiceName
+	summary.Start = time.UnixMilli(spans[root].StartTime.Timestamp).UTC()
+	summary.Duration = time.Duration(spans[root].DurationNanos)
+	return summary
+}
+
+// formatTraceSummaries renders a row per trace, traces that could not be looked up only
+// show their ID
+func formatTraceSummaries(traces []traceSummary) string {
+	var sb strings.Builder
+	sb.WriteString("| Trace ID | Root Span | Service | Start | Duration | Spans |\n")
+	sb.WriteString("|---|---|---|---|---|---|\n")
+	for _, t := range traces {
+		if t.Spans == 0 {
+			sb.WriteString(fmt.Sprintf("| %s | - | - | - | - | - |\n", t.TraceID))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %d |\n", t.TraceID, valueOrDash(t.RootSpan), valueOrDash(t.Service),
+			t.Start.Format("2006-01-02T15:04:05.000Z07:00"), t.Duration.Round(time.Microsecond), t.Spans))
+	}
+	return sb.String()
+}
+
+// moreTraces tells whether pages after the given one hold traces, from the paging metadata
+// of the response in the order hasMore, totalPages and matchesTotal. Without metadata a full
+// page is taken as a hint, which is reported as estimated.
+func moreTraces(res *suseobservability.TraceQueryResponse, page, pageSize int) (more, estimated bool) {
+	switch {
+	case res.HasMore != nil:
+		return *res.HasMore, false
+	case res.TotalPages != nil:
+		return page+1 < *res.TotalPages, false
+	case res.MatchesTotal > 0:
+		return (page+1)*pageSize < res.MatchesTotal, false
+	}
+	more = len(res.Traces) == pageSize
+	return more, more
+}
@@ -0,0 +1,450 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func traceRefs(n int) []suseobservability.TraceRef {
+	refs := make([]suseobservability.TraceRef, n)
+	for i := range refs {
+		refs[i] = suseobservability.TraceRef{TraceID: fmt.Sprintf("trace-%d", i), SpanID: fmt.Sprintf("span-%d", i)}
+	}
+	return refs
+}
+
+func TestListTraces(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("page and page size are passed through", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		var req *suseobservability.TraceQueryRequest
+		mockClient.On("QueryTraces", ctx, mock.AnythingOfType("*suseobservability.TraceQueryRequest")).
+			Run(func(args mock.Arguments) { req = args.Get(1).(*suseobservability.TraceQueryRequest) }).
+			Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(20), Page: 2, PageSize: 20, MatchesTotal: 75}, nil).Once()
+
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", Page: 2, PageSize: 20})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, req.Page)
+		assert.Equal(t, 20, req.PageSize)
+		assert.Equal(t, []string{"checkout"}, req.TraceQuery.SpanFilter.ServiceName)
+		assert.Equal(t, listTracesWindow, req.End.Sub(req.Start))
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Traces of service 'checkout' in the last 1h, page 2, showing 20 of 20 fetched (75 total reported by server):")
+		assert.Contains(t, output, "| trace-0 | - | - | - | - | - |")
+		assert.Contains(t, output, "More traces exist, pass page 3 to see them.")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("paging metadata decides whether more pages exist", func(t *testing.T) {
+		hasMore, noMore, twoPages := true, false, 2
+		tests := []struct {
+			name   string
+			res    *suseobservability.TraceQueryResponse
+			hint   string
+			result TraceListResult
+		}{
+			{
+				name:   "has more reported",
+				res:    &suseobservability.TraceQueryResponse{Traces: traceRefs(2), HasMore: &hasMore},
+				hint:   "More traces exist, pass page 2 to see them.",
+				result: TraceListResult{HasMore: true, NextPage: 2},
+			},
+			{
+				name:   "no more reported on a full page",
+				res:    &suseobservability.TraceQueryResponse{Traces: traceRefs(5), HasMore: &noMore, MatchesTotal: 100},
+				result: TraceListResult{MatchesTotal: 100},
+			},
+			{
+				name:   "total pages reported",
+				res:    &suseobservability.TraceQueryResponse{Traces: traceRefs(5), TotalPages: &twoPages},
+				result: TraceListResult{TotalPages: 2},
+			},
+			{
+				name:   "full page without metadata",
+				res:    &suseobservability.TraceQueryResponse{Traces: traceRefs(5)},
+				hint:   "More traces likely exist, pass page 2 to see them.",
+				result: TraceListResult{HasMore: true, HasMoreEstimated: true, NextPage: 2},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockClient := new(MockSuseObservabilityClient)
+				tools := NewBaseTool(mockClient)
+				mockClient.On("QueryTraces", ctx, mock.Anything).Return(tt.res, nil).Once()
+				mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+				result, structured, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", Page: 1, PageSize: 5})
+
+				require.NoError(t, err)
+				output := result.Content[0].(*mcp.TextContent).Text
+				if tt.hint == "" {
+					assert.NotContains(t, output, "More traces")
+				} else {
+					assert.Contains(t, output, tt.hint)
+				}
+				want := tt.result
+				want.Service, want.Page, want.PageSize = "checkout", 1, 5
+				want.Start, want.End = structured.Start, structured.End
+				for _, ref := range tt.res.Traces {
+					want.TraceIDs = append(want.TraceIDs, ref.TraceID)
+				}
+				assert.Equal(t, &want, structured)
+			})
+		}
+	})
+
+	t.Run("defaults to the first page of 20 traces", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return req.Page == 0 && req.PageSize == defaultTracePageSize
+		})).Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(3), MatchesTotal: 3}, nil).Once()
+
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "page 0, showing 3 of 3 fetched (3 total reported by server)")
+		assert.NotContains(t, output, "More traces")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("min duration filters the query", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return req.TraceQuery.SpanFilter.DurationFromNanos == 500_000_000
+		})).Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(1), MatchesTotal: 1}, nil).Once()
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", MinDurationMs: 500})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Traces of service 'checkout' with spans of at least 500ms in the last 1h, page 0, showing 1 of 1 fetched (1 total reported by server):")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("errors only keeps the traces with error spans", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		all := []suseobservability.TraceRef{{TraceID: "ok-1"}, {TraceID: "error-1"}, {TraceID: "ok-2"}, {TraceID: "error-2"}}
+		status := map[string]suseobservability.StatusCode{"ok-1": suseobservability.StatusOk, "error-1": suseobservability.StatusError, "ok-2": suseobservability.StatusUnset, "error-2": suseobservability.StatusError}
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(func(_ context.Context, req *suseobservability.TraceQueryRequest) (*suseobservability.TraceQueryResponse, error) {
+			res := &suseobservability.TraceQueryResponse{}
+			for _, ref := range all {
+				if len(req.TraceQuery.SpanFilter.StatusCode) == 0 || slices.Contains(req.TraceQuery.SpanFilter.StatusCode, status[ref.TraceID]) {
+					res.Traces = append(res.Traces, ref)
+				}
+			}
+			res.MatchesTotal = len(res.Traces)
+			return res, nil
+		}).Once()
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", MinDurationMs: 100, ErrorsOnly: true})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Traces of service 'checkout' with error spans of at least 100ms in the last 1h, page 0, showing 2 of 2 fetched (2 total reported by server):")
+		assert.Contains(t, output, "| error-1 |")
+		assert.Contains(t, output, "| error-2 |")
+		assert.NotContains(t, output, "| ok-")
+		req := mockClient.Calls[0].Arguments.Get(1).(*suseobservability.TraceQueryRequest)
+		assert.Equal(t, []string{"checkout"}, req.TraceQuery.SpanFilter.ServiceName)
+		assert.Equal(t, int64(100_000_000), req.TraceQuery.SpanFilter.DurationFromNanos)
+	})
+
+	t.Run("no min duration leaves the filter unset", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return req.TraceQuery.SpanFilter.DurationFromNanos == 0
+		})).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout"})
+
+		require.NoError(t, err)
+		assert.NotContains(t, result.Content[0].(*mcp.TextContent).Text, "with spans of at least")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("full page without a total hints at more traces", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(5)}, nil).Once()
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", PageSize: 5})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "page 0, showing 5 of 5 fetched:")
+		assert.Contains(t, output, "pass page 1")
+	})
+
+	t.Run("spans are looked up for the first traces of a large page only", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(&suseobservability.TraceQueryResponse{Traces: traceRefs(maxTraceLookups + 5), MatchesTotal: maxTraceLookups + 5}, nil).Once()
+		mockClient.On("GetTrace", ctx, mock.Anything).Return(&suseobservability.Trace{}, nil).Times(maxTraceLookups)
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", PageSize: 100})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| trace-24 | - | - | - | - | - |")
+		assert.Contains(t, output, "The spans of the first 20 traces were looked up, the other 5 only show their ID. Pass a page_size of at most 20 to see the details of every trace.")
+		assert.True(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("renders a table of trace summaries", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(&suseobservability.TraceQueryResponse{
+			Traces:       []suseobservability.TraceRef{{TraceID: "abc"}, {TraceID: "def"}, {TraceID: "gone"}},
+			MatchesTotal: 3,
+		}, nil).Once()
+		mockClient.On("GetTrace", ctx, "abc").Return(&suseobservability.Trace{TraceID: "abc", Spans: []suseobservability.Span{
+			{SpanName: "SELECT orders", ServiceName: "db", ParentSpanID: "1", StartTime: suseobservability.SpanTime{Timestamp: 1700000000100}, DurationNanos: 2_000_000},
+			{SpanName: "POST /checkout", ServiceName: "checkout", StartTime: suseobservability.SpanTime{Timestamp: 1700000000000}, DurationNanos: 153_456_789},
+		}}, nil).Once()
+		// the root span has not been received, the earliest span stands in
+		mockClient.On("GetTrace", ctx, "def").Return(&suseobservability.Trace{TraceID: "def", Spans: []suseobservability.Span{
+			{SpanName: "publish", ServiceName: "queue", ParentSpanID: "9", StartTime: suseobservability.SpanTime{Timestamp: 1700000005000}, DurationNanos: 1500},
+			{SpanName: "consume", ServiceName: "worker", ParentSpanID: "9", StartTime: suseobservability.SpanTime{Timestamp: 1700000004000}, DurationNanos: 4_000_000_000},
+		}}, nil).Once()
+		mockClient.On("GetTrace", ctx, "gone").Return(nil, errors.New("not found")).Once()
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout"})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "| Trace ID | Root Span | Service | Start | Duration | Spans |\n|---|---|---|---|---|---|\n"+
+			"| abc | POST /checkout | checkout | 2023-11-14T22:13:20.000Z | 153.457ms | 2 |\n"+
+			"| def | consume | worker | 2023-11-14T22:13:24.000Z | 4s | 2 |\n"+
+			"| gone | - | - | - | - | - |\n")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("json returns the raw response", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(&suseobservability.TraceQueryResponse{
+			Traces: []suseobservability.TraceRef{{TraceID: "abc", SpanID: "1"}}, PageSize: 100, MatchesTotal: 1,
+		}, nil).Once()
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", Format: "json"})
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"traces":[{"traceId":"abc","spanId":"1"}],"pageSize":100,"page":0,"matchesTotal":1}`, result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+		tests := []struct {
+			params ListTracesParams
+			err    string
+		}{
+			{ListTracesParams{}, "exactly one of service_name, component_id or component_name must be provided"},
+			{ListTracesParams{ServiceName: "checkout", ComponentID: 7}, "exactly one of service_name, component_id or component_name must be provided"},
+			{ListTracesParams{ServiceName: "checkout", Page: -1}, "page must not be negative, got -1"},
+			{ListTracesParams{ServiceName: "checkout", PageSize: -5}, "page_size must not be negative, got -5"},
+			{ListTracesParams{ServiceName: "checkout", PageSize: 5000}, "page_size must be at most 1000, got 5000"},
+			{ListTracesParams{ServiceName: "checkout", Format: "csv"}, "invalid format 'csv', must be 'markdown' or 'json'"},
+			{ListTracesParams{ServiceName: "checkout", MinDurationMs: -1}, "min_duration_ms must not be negative, got -1"},
+		}
+		for _, tt := range tests {
+			_, _, err := tools.ListTraces(ctx, nil, tt.params)
+			assert.EqualError(t, err, tt.err)
+		}
+	})
+
+	t.Run("component name resolves to its service", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND name = "checkout"`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}}, nil).Once()
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return slices.Equal(req.TraceQuery.SpanFilter.ServiceName, []string{"checkout"})
+		})).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentName: " checkout "})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No traces found for service 'checkout' in the last 1h.", result.Content[0].(*mcp.TextContent).Text)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("component id resolves to its service", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND id = 7`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}}, nil).Once()
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return slices.Equal(req.TraceQuery.SpanFilter.ServiceName, []string{"checkout"})
+		})).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		_, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentID: 7})
+
+		require.NoError(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown component name", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND name = "cart"`).
+			Return([]suseobservability.ViewComponent{}, nil).Once()
+
+		_, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentName: "cart"})
+
+		assert.EqualError(t, err, "no otel service component found with 'cart', find the service with getComponents (types: 'otel service')")
+		mockClient.AssertNotCalled(t, "QueryTraces", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ambiguous component name", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND name = "checkout"`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}, {ID: 9, Name: "checkout"}}, nil).Once()
+
+		_, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentName: "checkout"})
+
+		assert.EqualError(t, err, "component name 'checkout' matches 2 otel service components (IDs 7, 9), pass component_id instead")
+		mockClient.AssertNotCalled(t, "QueryTraces", mock.Anything, mock.Anything)
+	})
+
+	t.Run("around health change lists the traces around the last transition", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+		change := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND id = 7`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}}, nil).Once()
+		mockClient.On("GetEvents", ctx, mock.MatchedBy(func(req *suseobservability.EventListRequest) bool {
+			return req.TopologyQuery == "id = 7" && slices.Equal(req.EventTypes, []string{"HealthStateChangedEvent"}) && req.Limit == healthChangeEvents
+		})).Return(&suseobservability.EventItemsWithTotal{Items: []suseobservability.TopologyEvent{
+			// the most recent change is taken whatever the order of the events
+			{EventType: "HealthStateChangedEvent", EventTime: change.Add(-time.Hour).UnixMilli(), Data: map[string]interface{}{"newHealthState": "DEVIATING"}},
+			{EventType: "HealthStateChangedEvent", EventTime: change.UnixMilli(), Data: map[string]interface{}{"newHealthState": "CRITICAL"}},
+			{EventType: "HealthStateChangedEvent", EventTime: change.Add(-2 * time.Hour).UnixMilli(), Data: map[string]interface{}{"newHealthState": "CLEAR"}},
+		}}, nil).Once()
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return req.Start.Equal(change.Add(-15*time.Minute)) && req.End.Equal(change.Add(15*time.Minute))
+		})).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		result, structured, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentID: 7, AroundHealthChange: true})
+
+		require.NoError(t, err)
+		window := fmt.Sprintf("from %s to %s", change.Add(-15*time.Minute).UTC().Format(time.RFC3339), change.Add(15*time.Minute).UTC().Format(time.RFC3339))
+		assert.Equal(t, fmt.Sprintf("Window: %s, 15m around the health change of component ID 7 to CRITICAL at %s.\n\nNo traces found for service 'checkout' %s.",
+			window, change.UTC().Format(time.RFC3339), window), result.Content[0].(*mcp.TextContent).Text)
+		require.NotNil(t, structured.HealthChange)
+		assert.True(t, structured.HealthChange.Equal(change))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("around health change falls back to the last hour without a transition", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND id = 7`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}}, nil).Once()
+		mockClient.On("GetEvents", ctx, mock.Anything).Return(&suseobservability.EventItemsWithTotal{}, nil).Once()
+		mockClient.On("QueryTraces", ctx, mock.MatchedBy(func(req *suseobservability.TraceQueryRequest) bool {
+			return req.End.Sub(req.Start) == time.Hour
+		})).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		result, structured, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentID: 7, AroundHealthChange: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No health state change of component ID 7 in the last 7d, listing the traces of the last 1h instead.\n\n"+
+			"No traces found for service 'checkout' in the last 1h.", result.Content[0].(*mcp.TextContent).Text)
+		assert.Nil(t, structured.HealthChange)
+		assert.False(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("around health change falls back to the last hour when the lookup fails", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		mockClient.On("SnapShotTopologyQuery", ctx, `type = "otel service" AND id = 7`).
+			Return([]suseobservability.ViewComponent{{ID: 7, Name: "checkout"}}, nil).Once()
+		mockClient.On("GetEvents", ctx, mock.Anything).Return(nil, errors.New("boom")).Once()
+		mockClient.On("QueryTraces", ctx, mock.Anything).Return(&suseobservability.TraceQueryResponse{}, nil).Once()
+
+		result, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ComponentID: 7, AroundHealthChange: true})
+
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Could not look up the health changes of component ID 7 (boom), listing the traces of the last 1h instead.")
+		assert.True(t, IsPartial(result))
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("around health change requires a component id", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		tools := NewBaseTool(mockClient)
+
+		_, _, err := tools.ListTraces(ctx, nil, ListTracesParams{ServiceName: "checkout", AroundHealthChange: true})
+
+		assert.EqualError(t, err, "around_health_change requires component_id, the health changes are looked up for that component")
+		mockClient.AssertNotCalled(t, "GetEvents", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHealthChangeWindow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		change     time.Time
+		start, end time.Time
+	}{
+		{"margin on both sides", time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC),
+			time.Date(2026, 10, 16, 13, 45, 0, 0, time.UTC), time.Date(2026, 10, 16, 14, 15, 0, 0, time.UTC)},
+		{"recent change ends now", time.Date(2026, 10, 16, 14, 50, 0, 0, time.UTC),
+			time.Date(2026, 10, 16, 14, 35, 0, 0, time.UTC), now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := healthChangeWindow(tt.change, now)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ListViewsParams struct {
+	Search string `json:"search,omitempty" jsonschema:"Only list the views whose name, description or identifier contains this text, case-insensitive" examples:"pod;namespace"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Maximum number of views listed (default: 100)"`
+}
+
+// ViewsResult is the structured content returned by listViews. Unlike the markdown table
+// it is not capped by limit and holds the full queries.
+type ViewsResult struct {
+	Search string                        `json:"search,omitempty"`
+	Views  []suseobservability.QueryView `json:"views"`
+}
+
+const (
+	// defaultViewRows is the number of views listViews lists unless another limit is asked for
+	defaultViewRows = 100
+	// maxViewQueryLength is the maximum number of characters of a view query in the table
+	maxViewQueryLength = 200
+)
+
+// ListViews lists the saved topology views with their ID, description and STQL query, so
+// the curated perspectives of an instance can be found and their queries reused
+func (t tool) ListViews(ctx context.Context, request *mcp.CallToolRequest, params ListViewsParams) (*mcp.CallToolResult, *ViewsResult, error) {
+	limit, err := displayLimit(params.Limit, defaultViewRows)
+	if err != nil {
+		return nil, nil, err
+	}
+	search := strings.TrimSpace(params.Search)
+
+	views, err := t.client.ListViews(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list views: %w", err)
+	}
+
+	structured := &ViewsResult{Search: search, Views: []suseobservability.QueryView{}}
+	needle := strings.ToLower(search)
+	for _, v := range views {
+		if needle == "" || strings.Contains(strings.ToLower(v.Name+"\n"+v.Description+"\n"+v.Identifier), needle) {
+			structured.Views = append(structured.Views, v)
+		}
+	}
+	sort.SliceStable(structured.Views, func(i, j int) bool {
+		a, b := structured.Views[i], structured.Views[j]
+		if !strings.EqualFold(a.Name, b.Name) {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.ID < b.ID
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: formatViews(structured, len(views), limit),
+			},
+		},
+	}, structured, nil
+}
+
+// formatViews renders the views of result, limit at most, out of the total views of the instance
+func formatViews(result *ViewsResult, total, limit int) string {
+	if len(result.Views) == 0 {
+		if result.Search != "" {
+			return fmt.Sprintf("No views match '%s' among the %d view(s). Search for a shorter part of the name, or leave search unset to list every view.", result.Search, total)
+		}
+		return "No views found: no topology view is saved on this instance."
+	}
+	shown := result.Views[:min(limit, len(result.Views))]
+
+	var sb strings.Builder
+	if result.Search != "" {
+		sb.WriteString(fmt.Sprintf("Views matching '%s' out of %d, %s:\n\n", result.Search, total, countSummary(len(shown), len(result.Views))))
+	} else {
+		sb.WriteString(fmt.Sprintf("Views, %s:\n\n", countSummary(len(shown), len(result.Views))))
+	}
+	sb.WriteString("| View | ID | Description | Query |\n")
+	sb.WriteString("|---|---|---|---|\n")
+	for _, v := range shown {
+		// Descriptions are rendered on a single line so the table stays intact
+		description := Truncate(strings.Join(strings.Fields(v.Description), " "), 100)
+		query := "-"
+		if q := strings.Join(strings.Fields(v.Query), " "); q != "" {
+			query = "`" + Truncate(q, maxViewQueryLength) + "`"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", valueOrDash(v.Name), v.ID, valueOrDash(description), query))
+	}
+	sb.WriteString("\nThe query of a view selects its components: pass it to topologyDiff to see what changed in the view, " +
+		"or to validateSTQL before editing it. Structured content holds the full queries.\n")
+	return sb.String()
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testViews returns the saved views of an instance, in the order of the server
+func testViews() []suseobservability.QueryView {
+	return []suseobservability.QueryView{
+		{ID: 30, Identifier: "urn:stackpack:kubernetes:query-view:pods", Name: "Pods", Description: "All pods\nof every cluster", Query: `type = "pod"`},
+		{ID: 10, Identifier: "urn:system:default:query-view:shop", Name: "checkout", Description: "Checkout services of the shop", Query: `label = "namespace:shop" AND type IN ("service", "deployment")`},
+		{ID: 20, Identifier: "urn:system:default:query-view:critical", Name: "Critical", Query: `healthstate = "CRITICAL"`},
+	}
+}
+
+func TestListViews(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("views are listed by name", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("ListViews", ctx).Return(testViews(), nil).Once()
+
+		result, structured, err := NewBaseTool(mockClient).ListViews(ctx, nil, ListViewsParams{})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "Views, showing 3 of 3 fetched:\n\n| View | ID | Description | Query |\n|---|---|---|---|\n"+
+			"| checkout | 10 | Checkout services of the shop | `label = \"namespace:shop\" AND type IN (\"service\", \"deployment\")` |\n"+
+			"| Critical | 20 | - | `healthstate = \"CRITICAL\"` |\n"+
+			"| Pods | 30 | All pods of every cluster | `type = \"pod\"` |\n")
+		require.Len(t, structured.Views, 3)
+		assert.Equal(t, int64(10), structured.Views[0].ID)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("search matches the name, description and identifier", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("ListViews", ctx).Return(testViews(), nil)
+		tools := NewBaseTool(mockClient)
+
+		result, structured, err := tools.ListViews(ctx, nil, ListViewsParams{Search: "SHOP"})
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Views matching 'SHOP' out of 3, showing 1 of 1 fetched:")
+		require.Len(t, structured.Views, 1)
+		assert.Equal(t, "checkout", structured.Views[0].Name)
+
+		_, structured, err = tools.ListViews(ctx, nil, ListViewsParams{Search: "kubernetes"})
+		require.NoError(t, err)
+		require.Len(t, structured.Views, 1)
+		assert.Equal(t, "Pods", structured.Views[0].Name)
+
+		result, structured, err = tools.ListViews(ctx, nil, ListViewsParams{Search: "nodes"})
+		require.NoError(t, err)
+		assert.Equal(t, "No views match 'nodes' among the 3 view(s). Search for a shorter part of the name, or leave search unset to list every view.",
+			result.Content[0].(*mcp.TextContent).Text)
+		assert.Empty(t, structured.Views)
+	})
+
+	t.Run("limit caps the table, not the structured content", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("ListViews", ctx).Return(testViews(), nil).Once()
+
+		result, structured, err := NewBaseTool(mockClient).ListViews(ctx, nil, ListViewsParams{Limit: 1})
+
+		require.NoError(t, err)
+		output := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, output, "showing 1 of 3 fetched")
+		assert.NotContains(t, output, "| Pods |")
+		assert.Len(t, structured.Views, 3)
+	})
+
+	t.Run("no views", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("ListViews", ctx).Return([]suseobservability.QueryView{}, nil).Once()
+
+		result, _, err := NewBaseTool(mockClient).ListViews(ctx, nil, ListViewsParams{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "No views found: no topology view is saved on this instance.", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("request failure", func(t *testing.T) {
+		mockClient := new(MockSuseObservabilityClient)
+		mockClient.On("ListViews", ctx).Return(nil, errors.New("timeout")).Once()
+
+		_, _, err := NewBaseTool(mockClient).ListViews(ctx, nil, ListViewsParams{})
+
+		assert.EqualError(t, err, "failed to list views: timeout")
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		_, _, err := NewBaseTool(new(MockSuseObservabilityClient)).ListViews(ctx, nil, ListViewsParams{Limit: -1})
+
+		assert.EqualError(t, err, "limit must not be negative, got -1")
+	})
+}
@@ -0,0 +1,302 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type WatchHealthParams struct {
+	Filter          string `json:"filter,omitempty" jsonschema:"Only watch monitors whose name contains this text (case-insensitive)"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty" jsonschema:"Polling interval in seconds (default: 60, minimum: 10)" default:"60"`
+	Stop            bool   `json:"stop,omitempty" jsonschema:"Stop watching health for this session"`
+}
+
+const (
+	defaultWatchInterval = time.Minute
+	minWatchInterval     = 10 * time.Second
+	// healthNotifyMinGap is the minimum time between two notifications sent to a session
+	healthNotifyMinGap = time.Minute
+	// maxHealthChangeLines is the number of changes listed in a single notification
+	maxHealthChangeLines = 20
+	healthWatchLogger    = "health-watch"
+)
+
+// clock abstracts time so the health watcher and the credential monitor can be tested
+type clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) (<-chan time.Time, func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// notifySession is the part of an MCP session the health watcher uses
+type notifySession interface {
+	ID() string
+	Log(ctx context.Context, params *mcp.LoggingMessageParams) error
+	Wait() error
+}
+
+// healthWatchers tracks the running health watcher of every session
+type healthWatchers struct {
+	clock  clock
+	minGap time.Duration
+
+	mu     sync.Mutex
+	active map[string]*healthWatch
+}
+
+type healthWatch struct {
+	cancel context.CancelFunc
+	closed <-chan struct{}
+}
+
+func newHealthWatchers(c clock) *healthWatchers {
+	return &healthWatchers{
+		clock:  c,
+		minGap: healthNotifyMinGap,
+		active: make(map[string]*healthWatch),
+	}
+}
+
+// healthChange is a monitor whose firing state changed between two polls
+type healthChange struct {
+	ID   int64
+	Name string
+	From string
+	To   string
+}
+
+// firingMonitor is the state a monitor is firing with, with the name it is reported under
+type firingMonitor struct {
+	Name  string
+	State string
+}
+
+func (c healthChange) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("%s started firing (%s)", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("%s stopped firing (was %s)", c.Name, c.From)
+	default:
+		return fmt.Sprintf("%s changed from %s to %s", c.Name, c.From, c.To)
+	}
+}
+
+// WatchHealth starts or stops background health notifications for the calling session
+func (t tool) WatchHealth(ctx context.Context, request *mcp.CallToolRequest, params WatchHealthParams) (*mcp.CallToolResult, any, error) {
+	if request == nil || request.Session == nil {
+		return nil, nil, errors.New("watchHealth requires an MCP session")
+	}
+
+	var text string
+	if params.Stop {
+		if t.watchers.stop(request.Session.ID()) {
+			text = "Stopped watching health for this session."
+		} else {
+			text = "No health watch is running for this session."
+		}
+	} else {
+		interval := defaultWatchInterval
+		if params.IntervalSeconds != 0 {
+			interval = time.Duration(params.IntervalSeconds) * time.Second
+		}
+		if interval < minWatchInterval {
+			return nil, nil, fmt.Errorf("interval_seconds must be at least %d, got %d", int(minWatchInterval.Seconds()), params.IntervalSeconds)
+		}
+
+		t.watchers.start(t.client, request.Session, params.Filter, interval)
+		scope := "all monitors"
+		if params.Filter != "" {
+			scope = fmt.Sprintf("monitors matching '%s'", params.Filter)
+		}
+		text = fmt.Sprintf("Watching %s every %s. Monitors starting or stopping to fire are sent as '%s' log notifications "+
+			"(at most one every %s); set the logging level to 'info' to receive them.", scope, interval, healthWatchLogger, t.watchers.minGap)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}, nil, nil
+}
+
+// start runs a watcher for the session, replacing any previous one
+func (w *healthWatchers) start(client SuseObservabilityClient, session notifySession, filter string, interval time.Duration) {
+	id := session.ID()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w.mu.Lock()
+	var closed <-chan struct{}
+	if prev, ok := w.active[id]; ok {
+		prev.cancel()
+		closed = prev.closed
+	} else {
+		ch := make(chan struct{})
+		go func() {
+			_ = session.Wait()
+			close(ch)
+		}()
+		closed = ch
+	}
+	watch := &healthWatch{cancel: cancel, closed: closed}
+	w.active[id] = watch
+	w.mu.Unlock()
+
+	go func() {
+		defer w.remove(id, watch)
+		w.run(ctx, client, session, filter, interval, closed)
+	}()
+}
+
+// stop cancels the watcher of the session and reports whether one was running
+func (w *healthWatchers) stop(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watch, ok := w.active[id]
+	if ok {
+		watch.cancel()
+		delete(w.active, id)
+	}
+	return ok
+}
+
+func (w *healthWatchers) remove(id string, watch *healthWatch) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.active[id] == watch {
+		watch.cancel()
+		delete(w.active, id)
+	}
+}
+
+func (w *healthWatchers) running(id string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.active[id]
+	return ok
+}
+
+func (w *healthWatchers) run(ctx context.Context, client SuseObservabilityClient, session notifySession, filter string, interval time.Duration, closed <-chan struct{}) {
+	ticks, stopTicker := w.clock.NewTicker(interval)
+	defer stopTicker()
+
+	var (
+		previous map[int64]firingMonitor
+		pending  []healthChange
+		lastSent time.Time
+	)
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		case now = <-ticks:
+		}
+
+		overview, err := client.GetMonitorsOverview(ctx)
+		if err != nil {
+			slog.Warn("Health watch poll failed", "session", session.ID(), "error", err)
+			continue
+		}
+
+		current := firingStates(overview.Monitors, filter)
+		if previous != nil {
+			pending = append(pending, diffHealth(previous, current)...)
+		}
+		previous = current
+
+		if len(pending) == 0 || (!lastSent.IsZero() && now.Sub(lastSent) < w.minGap) {
+			continue
+		}
+		if err := session.Log(ctx, healthNotification(pending)); err != nil {
+			slog.Warn("Health watch notification failed", "session", session.ID(), "error", err)
+			continue
+		}
+		lastSent = now
+		pending = nil
+	}
+}
+
+// firingStates maps the ID of every firing monitor whose name matches the filter to the state
+// it is firing with. Monitors are keyed by ID as the monitors installed per cluster share a name.
+func firingStates(monitors []suseobservability.MonitorOverview, filter string) map[int64]firingMonitor {
+	filter = strings.ToLower(filter)
+	states := make(map[int64]firingMonitor)
+	for _, m := range monitors {
+		if filter != "" && !strings.Contains(strings.ToLower(m.Monitor.Name), filter) {
+			continue
+		}
+		if state := monitorState(m); state != "" {
+			states[m.Monitor.Id] = firingMonitor{Name: m.Monitor.Name, State: state}
+		}
+	}
+	return states
+}
+
+// diffHealth lists the monitors that started, stopped or changed firing, sorted by name and ID
+func diffHealth(previous, current map[int64]firingMonitor) []healthChange {
+	var changes []healthChange
+	for id, m := range current {
+		if previous[id].State != m.State {
+			changes = append(changes, healthChange{ID: id, Name: m.Name, From: previous[id].State, To: m.State})
+		}
+	}
+	for id, m := range previous {
+		if _, ok := current[id]; !ok {
+			changes = append(changes, healthChange{ID: id, Name: m.Name, From: m.State})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+func healthNotification(changes []healthChange) *mcp.LoggingMessageParams {
+	level := mcp.LoggingLevel("info")
+	lines := make([]string, 0, len(changes))
+	for i, c := range changes {
+		if c.To != "" {
+			level = "warning"
+		}
+		if i < maxHealthChangeLines {
+			lines = append(lines, "- "+c.String())
+		}
+	}
+	if len(changes) > maxHealthChangeLines {
+		lines = append(lines, fmt.Sprintf("- and %d more", len(changes)-maxHealthChangeLines))
+	}
+
+	return &mcp.LoggingMessageParams{
+		Level:  level,
+		Logger: healthWatchLogger,
+		Data:   fmt.Sprintf("Monitor health changed:\n%s", strings.Join(lines, "\n")),
+	}
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"suse-observability-mcp/client/suseobservability"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	ticks chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), ticks: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(time.Duration) (<-chan time.Time, func()) {
+	return c.ticks, func() {}
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// tick delivers a tick once the watcher is waiting for one, i.e. after the previous tick was processed
+func (c *fakeClock) tick(t *testing.T) {
+	select {
+	case c.ticks <- c.Now():
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not wait for a tick")
+	}
+}
+
+type recordingSession struct {
+	id     string
+	logs   chan *mcp.LoggingMessageParams
+	closed chan struct{}
+}
+
+func newRecordingSession(id string) *recordingSession {
+	return &recordingSession{id: id, logs: make(chan *mcp.LoggingMessageParams, 10), closed: make(chan struct{})}
+}
+
+func (s *recordingSession) ID() string {
+	return s.id
+}
+
+func (s *recordingSession) Log(ctx context.Context, params *mcp.LoggingMessageParams) error {
+	s.logs <- params
+	return nil
+}
+
+func (s *recordingSession) Wait() error {
+	<-s.closed
+	return nil
+}
+
+func (s *recordingSession) next(t *testing.T) *mcp.LoggingMessageParams {
+	select {
+	case params := <-s.logs:
+		return params
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+		return nil
+	}
+}
+
+func overviewOf(monitors ...suseobservability.MonitorOverview) *suseobservability.MonitorOverviewList {
+	return &suseobservability.MonitorOverviewList{Monitors: monitors}
+}
+
+// monitorWithID is monitorOverview for a monitor with the given ID
+func monitorWithID(id int64, name string, critical, deviating int) suseobservability.MonitorOverview {
+	m := monitorOverview(name, critical, deviating)
+	m.Monitor.Id = id
+	return m
+}
+
+func TestDiffHealth(t *testing.T) {
+	changes := diffHealth(
+		map[int64]firingMonitor{1: {"a", "CRITICAL"}, 2: {"b", "DEVIATING"}, 3: {"c", "CRITICAL"}},
+		map[int64]firingMonitor{1: {"a", "CRITICAL"}, 2: {"b", "CRITICAL"}, 4: {"d", "DEVIATING"}},
+	)
+
+	assert.Equal(t, []healthChange{
+		{ID: 2, Name: "b", From: "DEVIATING", To: "CRITICAL"},
+		{ID: 3, Name: "c", From: "CRITICAL"},
+		{ID: 4, Name: "d", To: "DEVIATING"},
+	}, changes)
+	assert.Equal(t, "c stopped firing (was CRITICAL)", changes[1].String())
+	assert.Equal(t, "d started firing (DEVIATING)", changes[2].String())
+}
+
+func TestFiringStates(t *testing.T) {
+	states := firingStates([]suseobservability.MonitorOverview{
+		monitorWithID(1, "Pod restarts", 1, 0),
+		monitorWithID(2, "Pod ready", 0, 0),
+		monitorWithID(3, "Node disk", 0, 2),
+		monitorWithID(4, "Pod restarts", 0, 3),
+	}, "pod")
+
+	assert.Equal(t, map[int64]firingMonitor{
+		1: {Name: "Pod restarts", State: "CRITICAL"},
+		4: {Name: "Pod restarts", State: "DEVIATING"},
+	}, states)
+}
+
+func TestDiffHealthSameName(t *testing.T) {
+	// Monitors installed per cluster share their name, each one is diffed on its own
+	changes := diffHealth(
+		map[int64]firingMonitor{1: {"Pod restarts", "CRITICAL"}},
+		map[int64]firingMonitor{2: {"Pod restarts", "CRITICAL"}},
+	)
+
+	assert.Equal(t, []healthChange{
+		{ID: 1, Name: "Pod restarts", From: "CRITICAL"},
+		{ID: 2, Name: "Pod restarts", To: "CRITICAL"},
+	}, changes)
+}
+
+func TestHealthWatcher(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	clock := newFakeClock()
+	watchers := newHealthWatchers(clock)
+	session := newRecordingSession("session-1")
+
+	mockClient.On("GetMonitorsOverview", mock.Anything).Return(overviewOf(monitorWithID(1, "a", 1, 0)), nil).Once()
+	mockClient.On("GetMonitorsOverview", mock.Anything).Return(overviewOf(monitorWithID(1, "a", 1, 0), monitorWithID(2, "b", 0, 1)), nil).Once()
+	mockClient.On("GetMonitorsOverview", mock.Anything).Return(overviewOf(monitorWithID(2, "b", 0, 1)), nil).Once()
+	mockClient.On("GetMonitorsOverview", mock.Anything).Return(overviewOf(monitorWithID(2, "b", 0, 1), monitorWithID(3, "c", 1, 0)), nil).Once()
+
+	watchers.start(mockClient, session, "", time.Minute)
+	require.True(t, watchers.running("session-1"))
+
+	// The first poll only records the baseline
+	clock.tick(t)
+
+	clock.tick(t)
+	params := session.next(t)
+	assert.Equal(t, mcp.LoggingLevel("warning"), params.Level)
+	assert.Equal(t, healthWatchLogger, params.Logger)
+	assert.Contains(t, params.Data, "b started firing (DEVIATING)")
+
+	// Within the minimum gap the change is held back
+	clock.tick(t)
+
+	clock.Advance(2 * time.Minute)
+	clock.tick(t)
+	params = session.next(t)
+	assert.Contains(t, params.Data, "a stopped firing (was CRITICAL)")
+	assert.Contains(t, params.Data, "c started firing (CRITICAL)")
+
+	// Closing the session tears the watcher down
+	close(session.closed)
+	assert.Eventually(t, func() bool { return !watchers.running("session-1") }, time.Second, 10*time.Millisecond)
+	mockClient.AssertExpectations(t)
+}
+
+func TestHealthWatcherStop(t *testing.T) {
+	mockClient := new(MockSuseObservabilityClient)
+	watchers := newHealthWatchers(newFakeClock())
+	session := newRecordingSession("session-2")
+	defer close(session.closed)
+
+	watchers.start(mockClient, session, "", time.Minute)
+	assert.True(t, watchers.stop("session-2"))
+	assert.False(t, watchers.running("session-2"))
+	assert.False(t, watchers.stop("session-2"))
+}
+
+func TestWatchHealthWithoutSession(t *testing.T) {
+	tools := NewBaseTool(new(MockSuseObservabilityClient))
+
+	result, _, err := tools.WatchHealth(context.Background(), nil, WatchHealthParams{})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "requires an MCP session")
+}